@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// aliasFile maps variant names to their canonical form. County renames are
+// applied first; municipality entries may be restricted to one county
+// (matched against the already-renamed county).
+//
+//	{
+//	  "counties": {"CAPEMAY": "CAPE MAY"},
+//	  "municipalities": [
+//	    {"county": "HUDSON", "from": "GUTTENBERG TOWN", "to": "GUTTENBERG"}
+//	  ]
+//	}
+type aliasFile struct {
+	Counties       map[string]string `json:"counties"`
+	Municipalities []muniAlias       `json:"municipalities"`
+}
+
+type muniAlias struct {
+	County string `json:"county,omitempty"` // empty matches any county
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+func loadAliases(path string) (aliasFile, error) {
+	var a aliasFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return a, err
+	}
+	if err := json.Unmarshal(data, &a); err != nil {
+		return a, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return a, nil
+}
+
+// applyAliases renames counties and municipalities in place, refreshing
+// the entity ID and history links of renamed records. Names are compared
+// case-insensitively. Returns the number of records changed.
+func applyAliases(stats []parser.MunicipalityStats, a aliasFile) int {
+	counties := make(map[string]string, len(a.Counties))
+	for from, to := range a.Counties {
+		counties[normalizeName(from)] = to
+	}
+
+	changed := 0
+	for i := range stats {
+		s := &stats[i]
+		modified := false
+		if to, ok := counties[normalizeName(s.County)]; ok && to != s.County {
+			s.County = to
+			modified = true
+		}
+		for _, m := range a.Municipalities {
+			if m.County != "" && normalizeName(m.County) != normalizeName(s.County) {
+				continue
+			}
+			if normalizeName(m.From) == normalizeName(s.Municipality) && m.To != s.Municipality {
+				s.Municipality = m.To
+				modified = true
+				break
+			}
+		}
+		if modified {
+			parser.AnnotateHistory(s)
+			changed++
+		}
+	}
+	return changed
+}
+
+// aliasCSV applies a to the County, Municipality and EntityID columns of
+// the CSV at path, leaving every other cell as it is.
+func aliasCSV(path string, a aliasFile) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	countyCol, muniCol, idCol := -1, -1, -1
+	for i, name := range rows[0] {
+		switch name {
+		case "County":
+			countyCol = i
+		case "Municipality":
+			muniCol = i
+		case "EntityID":
+			idCol = i
+		}
+	}
+	if countyCol < 0 || muniCol < 0 {
+		return fmt.Errorf("no County and Municipality columns")
+	}
+
+	stats := make([]parser.MunicipalityStats, len(rows)-1)
+	for i, row := range rows[1:] {
+		stats[i] = parser.MunicipalityStats{County: row[countyCol], Municipality: row[muniCol]}
+	}
+	if applyAliases(stats, a) == 0 {
+		return nil
+	}
+	for i, row := range rows[1:] {
+		s := stats[i]
+		if s.County == row[countyCol] && s.Municipality == row[muniCol] {
+			continue
+		}
+		row[countyCol], row[muniCol] = s.County, s.Municipality
+		if idCol >= 0 {
+			row[idCol] = entityID(s)
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func normalizeName(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// ApplyAliases implements the "apply-aliases" subcommand: rewrite names in
+// already-parsed JSON files (and their sibling CSVs) according to an alias
+// file, so naming decisions don't require re-parsing the PDFs.
+func ApplyAliases(args []string) {
+	fs := flag.NewFlagSet("apply-aliases", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report changes without writing files")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt apply-aliases <aliases.json> <parsed-dir> [--dry-run]\n\nRewrite county and municipality names in parsed JSON/CSV files.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	aliases, err := loadAliases(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading aliases: %v\n", err)
+		os.Exit(1)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(fs.Arg(1), "*.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error globbing directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	var files, total int
+	for _, path := range matches {
+		if !isOutputJSON(path) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(path), err)
+			continue
+		}
+		out, err := parser.DecodeOutput(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(path), err)
+			continue
+		}
+		stats := out.Records
+
+		n := applyAliases(stats, aliases)
+		if n == 0 {
+			continue
+		}
+		files++
+		total += n
+		fmt.Fprintf(os.Stderr, "%s: %d records renamed\n", filepath.Base(path), n)
+		if *dryRun {
+			continue
+		}
+
+		// Keep the original provenance: the records still come from the
+		// same PDF, only their names changed.
+		if err := writeOutputJSON(path, out.Provenance, stats); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error writing JSON: %v\n", filepath.Base(path), err)
+			continue
+		}
+		// Rename in the CSV too, leaving its other columns as the parse
+		// that wrote them chose (--sections, --rows, --clean-numbers).
+		csvPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".csv"
+		if _, err := os.Stat(csvPath); err == nil {
+			if err := aliasCSV(csvPath, aliases); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: error updating CSV: %v\n", filepath.Base(csvPath), err)
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Done: %d records renamed in %d files\n", total, files)
+}
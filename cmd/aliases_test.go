@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestApplyAliases(t *testing.T) {
+	stats := []parser.MunicipalityStats{
+		stat("CAPEMAY", "AVALON BORO"),
+		stat("HUDSON", "Guttenberg Town"),
+		stat("BERGEN", "GUTTENBERG TOWN"),
+		stat("ESSEX", "NEWARK"),
+	}
+	a := aliasFile{
+		Counties: map[string]string{"capemay": "CAPE MAY"},
+		Municipalities: []muniAlias{
+			{County: "CAPE MAY", From: "AVALON BORO", To: "AVALON"},
+			{County: "HUDSON", From: "GUTTENBERG TOWN", To: "GUTTENBERG"},
+		},
+	}
+
+	if n := applyAliases(stats, a); n != 2 {
+		t.Errorf("changed = %d, want 2", n)
+	}
+	want := []parser.MunicipalityStats{
+		stat("CAPE MAY", "AVALON"),
+		stat("HUDSON", "GUTTENBERG"),
+		stat("BERGEN", "GUTTENBERG TOWN"), // county-restricted alias doesn't apply
+		stat("ESSEX", "NEWARK"),
+	}
+	for i := range want {
+		if stats[i].County != want[i].County || stats[i].Municipality != want[i].Municipality {
+			t.Errorf("stats[%d] = %s/%s, want %s/%s", i, stats[i].County, stats[i].Municipality, want[i].County, want[i].Municipality)
+		}
+	}
+}
+
+func TestAliasCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "municipal-courts-2024-06.csv")
+	stats := []parser.MunicipalityStats{stat("CAPEMAY", "AVALON BORO"), stat("ESSEX", "NEWARK")}
+	stats[0].Filings.CurrentPeriod.GrandTotal = "1,234"
+	opts := tableOptions{cleanNumbers: true, sections: []string{"filings"}, rows: []string{"current"}}
+	if err := writeCSV(path, stats, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	a := aliasFile{
+		Counties:       map[string]string{"CAPEMAY": "CAPE MAY"},
+		Municipalities: []muniAlias{{From: "AVALON BORO", To: "AVALON"}},
+	}
+	if err := aliasCSV(path, a); err != nil {
+		t.Fatal(err)
+	}
+
+	applyAliases(stats, a)
+	want := [][]string{recordColumns(opts), recordValues(stats[0], opts), recordValues(stats[1], opts)}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CSV = %q\nwant %q", got, want)
+	}
+	if got[1][len(got[1])-1] != "cape-may.avalon" {
+		t.Errorf("EntityID = %q, want the renamed court's", got[1][len(got[1])-1])
+	}
+}
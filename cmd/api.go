@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// API implements the "api" subcommand: serve the JSON API on its own,
+// without the dashboard, for use behind another front end.
+func API(args []string) {
+	fs := flag.NewFlagSet("api", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	prefix := fs.String("prefix", "/api", "path prefix for every endpoint (\"\" to serve them at the root)")
+	var datasetFlags datasetFlag
+	fs.Var(&datasetFlags, "dataset", "named dataset as name=dir (repeatable; the first is the default)")
+	var origins stringList
+	fs.Var(&origins, "cors-origin", "origin allowed to call the API from a browser, or * for any (repeatable)")
+	quiet := fs.Bool("quiet", false, "don't log requests")
+	limitOpts := addLimitFlags(fs)
+	tlsOpts := addTLSFlags(fs)
+	enableGraphQL := fs.Bool("graphql", false, "also serve a GraphQL endpoint at <prefix>/graphql")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt api <parsed-dir> [--addr :8080] [--prefix /api] [--dataset name=dir ...] [--cors-origin origin ...] [--read-timeout 10s] [--write-timeout 60s] [--rate-limit 10] [--rate-burst 40] [--request-timeout 60s] [--max-body bytes] [--max-response bytes] [--trust-proxy] [--tls-domain host | --cert file --key file] [--quiet] [--graphql]\n\nServe the JSON API without the dashboard.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if len(datasetFlags) == 0 {
+		datasetFlags = datasetFlag{{"default", *dir}}
+	}
+	p := strings.TrimSuffix(*prefix, "/")
+	if p != "" && !strings.HasPrefix(p, "/") {
+		fmt.Fprintf(os.Stderr, "--prefix must start with /\n")
+		os.Exit(1)
+	}
+	limits, err := limitOpts.limits()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	tlsConfig, plain, err := tlsOpts.config()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	api, err := loadDatasets(datasetFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	api.graphql = *enableGraphQL
+
+	srv := &http.Server{
+		Addr:         defaultTLSAddr(*addr, flagSet(fs, "addr"), tlsConfig),
+		Handler:      apiHandler(api, p, origins, limits, !*quiet),
+		ReadTimeout:  limits.read,
+		WriteTimeout: limits.write,
+	}
+	fmt.Printf("serving API on %s%s/\n", serveURL(srv.Addr, tlsOpts, tlsConfig), p)
+	if err := tlsOpts.serve(srv, tlsConfig, plain); err != nil {
+		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// apiEndpoints lists the routes registered by apiServer.register, for the
+// index served at the prefix root.
+var apiEndpoints = []string{
+	"GET /metadata",
+	"GET /status",
+	"GET /series",
+	"GET /chart.png",
+	"POST /report",
+	"GET /export.html",
+	"POST /export.html",
+	"GET /county/{name}",
+	"GET /stats",
+	"GET /detail",
+	"GET /dashboard",
+}
+
+// apiHandler builds the headless API's routing and middleware: JSON
+// responses for unknown paths and panics, the rate and size limits, CORS
+// for the given origins, and optionally a request log.
+func apiHandler(api *apiServer, prefix string, origins []string, limits serverLimits, logRequests bool) http.Handler {
+	mux := http.NewServeMux()
+	api.register(mux, prefix)
+	mux.HandleFunc(prefix+"/{$}", func(w http.ResponseWriter, r *http.Request) {
+		routes := apiEndpoints
+		if api.graphql {
+			routes = append(routes[:len(routes):len(routes)], "POST /graphql")
+		}
+		endpoints := make([]string, len(routes))
+		for i, e := range routes {
+			method, path, _ := strings.Cut(e, " ")
+			endpoints[i] = method + " " + prefix + path
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"version": version(), "datasets": api.names, "endpoints": endpoints})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	})
+
+	var h http.Handler = mux
+	h = withRecover(h)
+	h = limits.wrap(h)
+	if len(origins) > 0 {
+		h = withCORS(h, origins)
+	}
+	if logRequests {
+		h = withRequestLog(h)
+	}
+	return h
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// withCORS lets browsers on the allowed origins call the API, and answers
+// their preflight requests.
+func withCORS(next http.Handler, origins []string) http.Handler {
+	anyOrigin := contains(origins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && (anyOrigin || contains(origins, origin)) {
+			if anyOrigin {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+			}
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+				w.Header().Set("Access-Control-Max-Age", "3600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withRecover turns a panicking handler into a 500 rather than a dropped
+// connection.
+func withRecover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err) // a deliberate abort, e.g. by withResponseCap
+				}
+				fmt.Fprintf(os.Stderr, "panic serving %s: %v\n", r.URL.Path, err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// withRequestLog writes one line per request to stderr.
+func withRequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		fmt.Fprintf(os.Stderr, "%s %s %s %d %s\n", start.UTC().Format(time.RFC3339), r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
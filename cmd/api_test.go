@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestAPIHandler(t *testing.T) {
+	ds := &dataset{name: "default", records: []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{stat("ATLANTIC", "ABSECON")}},
+	}}
+	ds.statusJSON, _ = json.Marshal(buildStatus(ds.records, time.Time{}))
+	api := &apiServer{names: []string{"default"}, datasets: map[string]*dataset{"default": ds}, defaultDataset: "default"}
+	h := apiHandler(api, "/v1", []string{"https://example.org"}, serverLimits{}, false)
+
+	do := func(method, path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("GET", "/v1/status", "https://example.org")
+	var status statusResponse
+	if rec.Code != 200 || json.Unmarshal(rec.Body.Bytes(), &status) != nil || status.LatestPeriod != "2024-06" {
+		t.Errorf("status: %d %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.org" {
+		t.Errorf("allowed origin header = %q", got)
+	}
+	if got := do("GET", "/v1/status", "https://evil.example").Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("other origin got header %q", got)
+	}
+	if rec := do("OPTIONS", "/v1/series", "https://example.org"); rec.Code != http.StatusNoContent {
+		t.Errorf("preflight status = %d", rec.Code)
+	}
+
+	// The dashboard and the unprefixed paths are not served.
+	for _, path := range []string{"/", "/api/status", "/snapshot"} {
+		rec := do("GET", path, "")
+		if rec.Code != http.StatusNotFound || rec.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("GET %s: %d %s", path, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	var index struct{ Endpoints []string }
+	rec = do("GET", "/v1/", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &index); err != nil || len(index.Endpoints) != len(apiEndpoints) || index.Endpoints[0] != "GET /v1/metadata" {
+		t.Errorf("index: %s", rec.Body)
+	}
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// archiveIndexPages are index pages from the courts' older site layouts
+// that linked reports no longer on today's statistics page.
+var archiveIndexPages = []string{
+	"http://www.judiciary.state.nj.us/quant/index.htm",
+}
+
+// waybackBase is the Wayback Machine, serving its capture index and
+// captures.
+var waybackBase = "https://web.archive.org"
+
+// archiveFlags holds the back-catalog discovery flags shared by download and
+// list-remote.
+type archiveFlags struct {
+	archive *bool
+	pages   stringList
+	wayback *bool
+	limit   *int           // captures fetched per page, newest first; 0 for all
+	delay   *time.Duration // pause before each capture fetch
+}
+
+func addArchiveFlags(fs *flag.FlagSet) *archiveFlags {
+	a := &archiveFlags{}
+	a.archive = fs.Bool("archive", false, "also crawl the courts' older archive index pages for reports no longer linked today")
+	fs.Var(&a.pages, "archive-page", "archive index page URL to crawl, e.g. a mirror of an old statistics page (repeatable)")
+	a.wayback = fs.Bool("wayback", false, "also crawl the Wayback Machine's captures of the statistics page and the archive index pages")
+	a.limit = fs.Int("wayback-limit", 0, "with --wayback, fetch at most this many of each page's captures, newest first (0 for all)")
+	a.delay = fs.Duration("wayback-delay", time.Second, "with --wayback, wait this long between capture fetches, to go easy on the archive")
+	return a
+}
+
+// check validates the parsed flags.
+func (a *archiveFlags) check() error {
+	if *a.limit < 0 || *a.delay < 0 {
+		return fmt.Errorf("--wayback-limit and --wayback-delay can't be negative")
+	}
+	return nil
+}
+
+// enabled reports whether any discovery beyond today's page was asked for.
+func (a *archiveFlags) enabled() bool {
+	return *a.archive || len(a.pages) > 0 || *a.wayback
+}
+
+// indexPages returns the archive index pages to crawl directly: the
+// built-in ones with --archive, then any --archive-page.
+func (a *archiveFlags) indexPages() []string {
+	var pages []string
+	if *a.archive {
+		pages = append(pages, archiveIndexPages...)
+	}
+	return append(pages, a.pages...)
+}
+
+// discover crawls the archive index pages and, with --wayback, every
+// distinct capture of them and of the statistics page, newest first, up to
+// --wayback-limit per page and --wayback-delay apart. Pages that can't be
+// fetched are reported on stderr and skipped. A period
+// linked from several pages keeps the first link found.
+func (a *archiveFlags) discover(patterns []urlPattern) []pdfLink {
+	var links []pdfLink
+	add := func(found []pdfLink) {
+		links = mergeLinks(links, found)
+	}
+
+	pages := a.indexPages()
+	for _, p := range pages {
+		fmt.Fprintf(os.Stderr, "Fetching archive page %s\n", p)
+		page, _, err := fetchStatisticsPage(p, pageCache{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  skipped: %v\n", err)
+			continue
+		}
+		base, _ := url.Parse(p)
+		add(extractPDFLinks(page.Body, base, patterns))
+	}
+	if !*a.wayback {
+		return links
+	}
+
+	fetched := 0
+	for _, p := range append([]string{statisticsPageURL}, pages...) {
+		captures, err := waybackCaptures(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Wayback lookup for %s failed: %v\n", p, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Wayback has %d distinct captures of %s\n", len(captures), p)
+		if *a.limit > 0 && len(captures) > *a.limit {
+			fmt.Fprintf(os.Stderr, "  fetching the newest %d (--wayback-limit)\n", *a.limit)
+			captures = captures[len(captures)-*a.limit:]
+		}
+		for i := len(captures) - 1; i >= 0; i-- {
+			c := captures[i]
+			if fetched > 0 {
+				time.Sleep(*a.delay)
+			}
+			fetched++
+			page, _, err := fetchStatisticsPage(waybackURL(c.timestamp, c.original), pageCache{})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "  capture %s skipped: %v\n", c.timestamp, err)
+				continue
+			}
+			base, _ := url.Parse(c.original)
+			found := extractPDFLinks(page.Body, base, patterns)
+			for j := range found {
+				found[j].fallback = waybackURL(c.timestamp, found[j].url)
+			}
+			add(found)
+		}
+	}
+	return links
+}
+
+// mergeLinks appends to links those of more whose period it doesn't have.
+func mergeLinks(links, more []pdfLink) []pdfLink {
+	have := make(map[string]bool, len(links))
+	for _, l := range links {
+		have[l.period] = true
+	}
+	for _, l := range more {
+		if !have[l.period] {
+			have[l.period] = true
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+// waybackCapture is one capture in the Wayback Machine's index.
+type waybackCapture struct {
+	timestamp string // YYYYMMDDhhmmss
+	original  string // the URL as captured
+}
+
+// waybackCaptures lists the successful captures of pageURL with distinct
+// content, oldest first.
+func waybackCaptures(pageURL string) ([]waybackCapture, error) {
+	q := url.Values{}
+	q.Set("url", pageURL)
+	q.Set("output", "json")
+	q.Set("fl", "timestamp,original")
+	q.Set("filter", "statuscode:200")
+	q.Set("collapse", "digest")
+	req, err := http.NewRequest("GET", waybackBase+"/cdx/search/cdx?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; municourt/1.0)")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseWaybackCDX(body)
+}
+
+// parseWaybackCDX reads the CDX server's JSON output: an array of rows
+// whose first row names the fields. A URL with no captures yields an
+// empty body.
+func parseWaybackCDX(body []byte) ([]waybackCapture, error) {
+	if strings.TrimSpace(string(body)) == "" {
+		return nil, nil
+	}
+	var rows [][]string
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("reading capture index: %w", err)
+	}
+	var captures []waybackCapture
+	for i, row := range rows {
+		if i == 0 || len(row) < 2 {
+			continue
+		}
+		captures = append(captures, waybackCapture{timestamp: row[0], original: row[1]})
+	}
+	return captures, nil
+}
+
+// waybackURL returns the Wayback Machine's unmodified copy of u nearest to
+// timestamp. The id_ suffix skips the replay toolbar and link rewriting.
+func waybackURL(timestamp, u string) string {
+	return waybackBase + "/web/" + timestamp + "id_/" + u
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWaybackCDX(t *testing.T) {
+	got, err := parseWaybackCDX([]byte(`[["timestamp","original"],["20100101000000","http://example.org/quant/index.htm"],["20120101000000","http://example.org/quant/index.htm"]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].timestamp != "20120101000000" || got[1].original != "http://example.org/quant/index.htm" {
+		t.Errorf("captures = %+v", got)
+	}
+	if got, err := parseWaybackCDX([]byte("\n")); err != nil || got != nil {
+		t.Errorf("empty index = %+v, %v", got, err)
+	}
+	if _, err := parseWaybackCDX([]byte("<html>")); err == nil {
+		t.Error("expected an error for a non-JSON index")
+	}
+}
+
+func TestMergeLinks(t *testing.T) {
+	links := []pdfLink{{period: "2024-06", url: "new"}}
+	got := mergeLinks(links, []pdfLink{{period: "2024-06", url: "old"}, {period: "2008-06", url: "old"}, {period: "2008-06", url: "older"}})
+	if len(got) != 2 || got[0].url != "new" || got[1].period != "2008-06" || got[1].url != "old" {
+		t.Errorf("merged = %+v", got)
+	}
+}
+
+func TestArchiveDiscover(t *testing.T) {
+	const original = "http://old.example/quant/index.htm"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/old/index.htm":
+			w.Write([]byte(`<a href="munm0806.pdf">2008</a> <a href="/files/munm2406.pdf">2024</a>`))
+		case r.URL.Path == "/cdx/search/cdx":
+			w.Write([]byte(`[["timestamp","original"],["20100101000000","` + original + `"]]`))
+		case strings.HasPrefix(r.URL.Path, "/web/20100101000000id_/"+original):
+			w.Write([]byte(`<a href="munm0706.pdf">2007</a> <a href="munm0806.pdf">2008</a>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(old string) { waybackBase = old }(waybackBase)
+	waybackBase = srv.URL
+
+	archive, wayback, limit, delay := false, true, 0, time.Duration(0)
+	a := &archiveFlags{archive: &archive, pages: stringList{srv.URL + "/old/index.htm"}, wayback: &wayback, limit: &limit, delay: &delay}
+	got := a.discover(defaultPatterns)
+
+	want := map[string]pdfLink{
+		"2008-06": {period: "2008-06", url: srv.URL + "/old/munm0806.pdf"},
+		"2024-06": {period: "2024-06", url: srv.URL + "/files/munm2406.pdf"},
+		"2007-06": {period: "2007-06", url: "http://old.example/quant/munm0706.pdf",
+			fallback: srv.URL + "/web/20100101000000id_/http://old.example/quant/munm0706.pdf"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("links = %+v", got)
+	}
+	for _, l := range got {
+		if l != want[l.period] {
+			t.Errorf("%s: got %+v, want %+v", l.period, l, want[l.period])
+		}
+	}
+}
+
+func TestArchiveDiscover_WaybackLimit(t *testing.T) {
+	const original = "http://old.example/quant/index.htm"
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/cdx/search/cdx":
+			w.Write([]byte(`[["timestamp","original"],["20080101000000","` + original + `"],["20100101000000","` + original + `"],["20120101000000","` + original + `"]]`))
+		case strings.HasPrefix(r.URL.Path, "/web/"):
+			fetched = append(fetched, strings.SplitN(r.URL.Path, "/", 4)[2])
+			w.Write([]byte(`<a href="munm0706.pdf">2007</a>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	defer func(old string) { waybackBase = old }(waybackBase)
+	waybackBase = srv.URL
+
+	archive, wayback, limit, delay := false, true, 2, 20*time.Millisecond
+	a := &archiveFlags{archive: &archive, wayback: &wayback, limit: &limit, delay: &delay}
+	start := time.Now()
+	a.discover(defaultPatterns)
+
+	want := []string{"20120101000000id_", "20100101000000id_"}
+	if strings.Join(fetched, ",") != strings.Join(want, ",") {
+		t.Errorf("fetched captures %v, want %v", fetched, want)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("two capture fetches took %v, want at least the %v delay", elapsed, delay)
+	}
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// baselineFigures are a metric's value in the latest period and its change
+// from the same months a year earlier: a percentage for counts, points for
+// rates. Either is NaN where there's no value.
+type baselineFigures struct {
+	Value, Change float64
+}
+
+// baselineRow is one metric for a municipality and the averages it is
+// compared against; County or State is nil when not compared.
+type baselineRow struct {
+	Metric        string
+	Rate          bool
+	Municipality  baselineFigures
+	County, State *baselineFigures
+}
+
+// baselineReport is the output of the baseline subcommand.
+type baselineReport struct {
+	Municipality, County, Type string
+	Date, DateRange            string // latest period, and the months it covers as printed
+	Rows                       []baselineRow
+}
+
+// baselineChange is the change from from to to: in points for a rate and
+// as a percentage otherwise, NaN from zero.
+func baselineChange(from, to float64, rate bool) float64 {
+	if rate {
+		return to - from
+	}
+	if from == 0 {
+		return math.NaN()
+	}
+	return (to - from) / math.Abs(from) * 100
+}
+
+// vsAverage is how far v is from the average avg, in the units of
+// baselineChange.
+func vsAverage(v, avg float64, rate bool) float64 {
+	return baselineChange(avg, v, rate)
+}
+
+// muniCounties returns the counties with a court named muni in records.
+func muniCounties(records []timeRecord, muni string) []string {
+	seen := make(map[string]bool)
+	for _, rec := range records {
+		for _, s := range rec.stats {
+			if matchesEntity(s, muni) {
+				seen[strings.ToUpper(s.County)] = true
+			}
+		}
+	}
+	counties := make([]string, 0, len(seen))
+	for c := range seen {
+		counties = append(counties, c)
+	}
+	sort.Strings(counties)
+	return counties
+}
+
+// priorRows returns s with each section's prior-period row, the same
+// months a year earlier, in place of its current one.
+func priorRows(s parser.MunicipalityStats) parser.MunicipalityStats {
+	for _, sec := range []*parser.SectionWithChange{&s.Filings, &s.Resolutions, &s.Backlog, &s.BacklogPer100, &s.ActivePending} {
+		sec.CurrentPeriod = sec.PriorPeriod
+	}
+	for _, sec := range []*parser.SectionTwoRow{&s.Clearance, &s.ClearancePct, &s.BacklogPct} {
+		sec.CurrentPeriod = sec.PriorPeriod
+	}
+	return s
+}
+
+// buildBaseline compares muni, in county, with the average municipality in
+// its county and statewide, as stateAverage defines it, for every metric,
+// in the newest period the municipality appears in. Trends are read from
+// that report's prior-period rows, as summary's are, so counts that run
+// from July are set against the same months a year earlier whichever
+// other periods are on disk.
+func buildBaseline(records []timeRecord, county, muni, caseType, against string, weighted bool) baselineReport {
+	rep := baselineReport{Municipality: muni, County: county, Type: caseType}
+	var latest *timeRecord
+	for i := range records {
+		for _, s := range records[i].stats {
+			if strings.ToUpper(s.County) == county && matchesEntity(s, muni) {
+				latest = &records[i]
+				rep.DateRange = strings.Join(strings.Fields(s.DateRange), " ")
+				break
+			}
+		}
+	}
+	if latest == nil {
+		return rep
+	}
+	rep.Date = latest.date
+
+	const prior = "prior"
+	pair := []timeRecord{{date: rep.Date, stats: latest.stats}, {date: prior}}
+	for _, s := range latest.stats {
+		pair[1].stats = append(pair[1].stats, priorRows(s))
+	}
+	figures := func(at func(date string) float64, rate bool) baselineFigures {
+		v := at(rep.Date)
+		return baselineFigures{Value: v, Change: baselineChange(at(prior), v, rate)}
+	}
+	average := func(metric string, peerCounty, level, key string, rate bool) *baselineFigures {
+		peers, _ := aggregateSeries(pair, metric, caseType, "municipality", peerCounty, "", weighted)
+		agg, _ := aggregateSeries(pair, metric, caseType, level, peerCounty, "", weighted)
+		avg := stateAverage(peers, agg[key], rate)
+		f := figures(func(date string) float64 {
+			if v, ok := avg[date]; ok {
+				return v
+			}
+			return math.NaN()
+		}, rate)
+		return &f
+	}
+
+	for _, metric := range validMetrics {
+		rate := rateMetrics[metric]
+		row := baselineRow{Metric: metric, Rate: rate}
+		series, _ := aggregateSeries(pair, metric, caseType, "municipality", county, muni, weighted)
+		var pts []dataPoint
+		for _, p := range series {
+			pts = append(pts, p...)
+		}
+		row.Municipality = figures(func(date string) float64 {
+			for _, p := range pts {
+				if p.date == date {
+					return p.value
+				}
+			}
+			return math.NaN()
+		}, rate)
+		if against != "state" {
+			row.County = average(metric, county, "county", county, rate)
+		}
+		if against != "county" {
+			row.State = average(metric, "", "state", "STATEWIDE", rate)
+		}
+		rep.Rows = append(rep.Rows, row)
+	}
+	return rep
+}
+
+// MarshalJSON writes NaN as null, which encoding/json can't write, and adds
+// each average's difference from the municipality.
+func (r baselineReport) MarshalJSON() ([]byte, error) {
+	rows := make([]map[string]any, len(r.Rows))
+	for i, row := range r.Rows {
+		m := map[string]any{
+			"metric": row.Metric, "rate": row.Rate,
+			"value": jsonFloat(row.Municipality.Value), "change": jsonFloat(row.Municipality.Change),
+		}
+		for name, avg := range map[string]*baselineFigures{"county": row.County, "state": row.State} {
+			if avg != nil {
+				m[name] = map[string]any{
+					"average": jsonFloat(avg.Value), "change": jsonFloat(avg.Change),
+					"vsAverage": jsonFloat(vsAverage(row.Municipality.Value, avg.Value, row.Rate)),
+				}
+			}
+		}
+		rows[i] = m
+	}
+	return json.Marshal(map[string]any{
+		"municipality": r.Municipality, "county": r.County, "type": r.Type,
+		"date": r.Date, "dateRange": r.DateRange, "metrics": rows,
+	})
+}
+
+// Baseline implements the "baseline" subcommand: a municipality's latest
+// value and trend for every metric beside its county's and the state's
+// average municipality.
+func Baseline(args []string) {
+	fs := flag.NewFlagSet("baseline", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	municipality := fs.String("municipality", "", "municipality to report on (required)")
+	county := fs.String("county", "", "county of the municipality, where the name is in more than one")
+	against := fs.String("against", "both", "averages to compare with: county, state, both")
+	caseType := fs.String("type", "grand-total", "case type column")
+	weighted := fs.Bool("weighted", false, "compute rate averages as ratios of summed components instead of averaging")
+	format := fs.String("format", "table", "output format: table, json")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt baseline [dir] --municipality NAME [--county NAME] [--against county|state|both] [--format table|json]\n\nCompare a municipality's latest values and trends with the average municipality in its county and statewide.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if *municipality == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *against != "county" && *against != "state" && *against != "both" {
+		fmt.Fprintf(os.Stderr, "invalid --against %q; valid options: county, state, both\n", *against)
+		os.Exit(1)
+	}
+	if !contains(validTypes, *caseType) {
+		fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, json\n", *format)
+		os.Exit(1)
+	}
+	if *county != "" {
+		c, ok := parser.NormalizeCounty(*county)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown --county %q\n", *county)
+			os.Exit(1)
+		}
+		*county = c
+	}
+	*municipality = strings.ToUpper(*municipality)
+
+	records, err := loadMetricRecords(*dir, validMetrics...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	if *county == "" {
+		counties := muniCounties(records, *municipality)
+		switch len(counties) {
+		case 0:
+			fmt.Fprintf(os.Stderr, "no data for municipality %q\n", *municipality)
+			os.Exit(1)
+		case 1:
+			*county = counties[0]
+		default:
+			fmt.Fprintf(os.Stderr, "%s is in more than one county (%s); add --county\n", *municipality, strings.Join(counties, ", "))
+			os.Exit(1)
+		}
+	}
+
+	rep := buildBaseline(records, *county, *municipality, *caseType, *against, *weighted)
+	if rep.Date == "" {
+		fmt.Fprintf(os.Stderr, "no data for municipality %q in %s\n", *municipality, *county)
+		os.Exit(1)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	renderBaseline(rep)
+}
+
+func renderBaseline(rep baselineReport) {
+	fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("%s, %s%s%s (%s, %s)",
+		rep.Municipality, rep.County, glyphs.dash, rep.Date, rep.DateRange, typeLabel(rep.Type))))
+	fmt.Println()
+
+	head := fmt.Sprintf("%-16s  %10s %9s", "Metric", "Value", "Change")
+	if rep.Rows[0].County != nil {
+		head += fmt.Sprintf("  %10s %9s %9s", "County avg", "Change", "vs avg")
+	}
+	if rep.Rows[0].State != nil {
+		head += fmt.Sprintf("  %10s %9s %9s", "State avg", "Change", "vs avg")
+	}
+	fmt.Println(paint(colorStdout, styleDim, head))
+
+	for _, row := range rep.Rows {
+		line := fmt.Sprintf("%-16s  %10s %9s", metricLabel(row.Metric),
+			formatBaselineValue(row.Municipality.Value, row.Rate), formatBaselineChange(row.Municipality.Change, row.Rate))
+		for _, avg := range []*baselineFigures{row.County, row.State} {
+			if avg != nil {
+				line += fmt.Sprintf("  %10s %9s %9s", formatBaselineValue(avg.Value, row.Rate), formatBaselineChange(avg.Change, row.Rate),
+					formatBaselineChange(vsAverage(row.Municipality.Value, avg.Value, row.Rate), row.Rate))
+			}
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("\nChange is against the same months a year earlier. Changes and differences from the average")
+	fmt.Println("are percentages for counts and points for rates.")
+}
+
+// formatBaselineValue formats a value for the baseline table: counts, which
+// are averages for the comparison columns, to the nearest whole number.
+func formatBaselineValue(v float64, rate bool) string {
+	if math.IsNaN(v) || !rate {
+		return formatNum(math.Round(v))
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+func formatBaselineChange(v float64, rate bool) string {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return "- -"
+	case rate:
+		return fmt.Sprintf("%+.1f pts", v)
+	}
+	return fmt.Sprintf("%+.1f%%", v)
+}
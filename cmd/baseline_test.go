@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func baselineStat(county, muni, filings, priorFilings, clearancePct, priorClearancePct string) parser.MunicipalityStats {
+	s := rateStat(county, muni, filings, "0", clearancePct)
+	s.Filings.PriorPeriod.GrandTotal = priorFilings
+	s.ClearancePct.PriorPeriod.GrandTotal = priorClearancePct
+	return s
+}
+
+func TestBuildBaseline(t *testing.T) {
+	records := []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			baselineStat("ESSEX", "NEWARK", "1", "1", "1%", "1%"),
+		}},
+		{date: "2025-06", stats: []parser.MunicipalityStats{
+			baselineStat("ESSEX", "NEWARK", "300", "200", "90%", "80%"),
+			baselineStat("ESSEX", "BELLEVILLE", "100", "100", "100%", "100%"),
+			baselineStat("ATLANTIC", "ABSECON", "20", "10", "80%", "70%"),
+		}},
+	}
+
+	rep := buildBaseline(records, "ESSEX", "NEWARK", "grand-total", "both", false)
+	if rep.Date != "2025-06" || len(rep.Rows) != len(validMetrics) {
+		t.Fatalf("date %s, %d rows", rep.Date, len(rep.Rows))
+	}
+	var filings, clearance baselineRow
+	for _, row := range rep.Rows {
+		switch row.Metric {
+		case "filings":
+			filings = row
+		case "clearance-pct":
+			clearance = row
+		}
+	}
+
+	// Newark: 300 filings, up 50% on the prior year's 200.
+	if f := filings.Municipality; f.Value != 300 || f.Change != 50 {
+		t.Errorf("filings = %+v", f)
+	}
+	// Average Essex court: (300+100)/2 = 200, up from 150.
+	if c := filings.County; c == nil || c.Value != 200 || math.Abs(c.Change-100.0/3) > 1e-9 {
+		t.Errorf("county filings = %+v", c)
+	}
+	if got := vsAverage(filings.Municipality.Value, filings.County.Value, false); got != 50 {
+		t.Errorf("vs county average = %v, want +50%%", got)
+	}
+	// Average court statewide: 420/3 = 140.
+	if s := filings.State; s == nil || s.Value != 140 {
+		t.Errorf("state filings = %+v", s)
+	}
+
+	// Rates change in points: Newark 80% -> 90%, the state 250/3 -> 90.
+	if f := clearance.Municipality; f.Value != 90 || f.Change != 10 {
+		t.Errorf("clearance = %+v", f)
+	}
+	if s := clearance.State; s == nil || s.Value != 90 || math.Abs(s.Change-(90-250.0/3)) > 1e-9 {
+		t.Errorf("state clearance = %+v", s)
+	}
+
+	if rep := buildBaseline(records, "ESSEX", "NEWARK", "grand-total", "county", false); rep.Rows[0].State != nil || rep.Rows[0].County == nil {
+		t.Error("--against county should leave out the state average")
+	}
+	if rep := buildBaseline(records, "ATLANTIC", "NEWARK", "grand-total", "both", false); rep.Date != "" {
+		t.Errorf("NEWARK in ATLANTIC: date %q, want none", rep.Date)
+	}
+}
+
+func TestMuniCounties(t *testing.T) {
+	records := []timeRecord{{date: "2025-06", stats: []parser.MunicipalityStats{
+		stat("SOMERSET", "FRANKLIN TWP"), stat("WARREN", "Franklin Twp"), stat("ESSEX", "NEWARK"),
+	}}}
+	got := muniCounties(records, "FRANKLIN TWP")
+	if len(got) != 2 || got[0] != "SOMERSET" || got[1] != "WARREN" {
+		t.Errorf("muniCounties = %v", got)
+	}
+}
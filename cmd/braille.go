@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// brailleCanvas is a grid of dots drawn with Unicode Braille patterns: each
+// terminal cell holds a 2×4 block of dots, so a chart gets twice the
+// horizontal and four times the vertical resolution of one glyph per cell.
+type brailleCanvas struct {
+	cols, rows int // in cells
+	cells      [][]rune
+}
+
+// brailleBits maps a dot's [x][y] position within its cell to its bit in
+// the pattern (U+2800 plus the bits).
+var brailleBits = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+func newBrailleCanvas(cols, rows int) *brailleCanvas {
+	c := &brailleCanvas{cols: cols, rows: rows, cells: make([][]rune, rows)}
+	for r := range c.cells {
+		c.cells[r] = make([]rune, cols)
+	}
+	return c
+}
+
+// set turns on the dot at (x, y), counting y from the top. Dots off the
+// canvas are ignored.
+func (c *brailleCanvas) set(x, y int) {
+	if x < 0 || y < 0 || x >= c.cols*2 || y >= c.rows*4 {
+		return
+	}
+	c.cells[y/4][x/2] |= brailleBits[x%2][y%4]
+}
+
+// line draws a straight line of dots between two points (Bresenham).
+func (c *brailleCanvas) line(x0, y0, x1, y1 int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.set(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// row returns one line of cells as text; empty cells are spaces.
+func (c *brailleCanvas) row(r int) string {
+	var sb strings.Builder
+	for _, bits := range c.cells[r] {
+		if bits == 0 {
+			sb.WriteRune(' ')
+		} else {
+			sb.WriteRune(0x2800 + bits)
+		}
+	}
+	return sb.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// renderBrailleChart draws the same chart as renderChart with Braille dots,
+// the periods spread evenly across the columns right of the y-axis labels
+// (at the default 100×15, 180 × 60 dots). Periods in marks get a marker
+// under the x axis.
+func renderBrailleChart(title string, points []dataPoint, width, height int, marks map[string]bool) {
+	var filtered []dataPoint
+	for _, p := range points {
+		if !math.IsNaN(p.value) {
+			filtered = append(filtered, p)
+		}
+	}
+	fmt.Println(paint(colorStdout, styleBold, title))
+	if len(filtered) == 0 {
+		fmt.Println("(no data)")
+		return
+	}
+	points = filtered
+	sort.Slice(points, func(i, j int) bool { return points[i].date < points[j].date })
+	fmt.Println()
+
+	width -= chartLabelWidth
+	canvas := newBrailleCanvas(width, height)
+	dotsX, dotsY := width*2, height*4
+
+	minVal, maxVal := points[0].value, points[0].value
+	for _, p := range points {
+		minVal = math.Min(minVal, p.value)
+		maxVal = math.Max(maxVal, p.value)
+	}
+	valRange := maxVal - minVal
+	if valRange == 0 {
+		valRange = 1
+		minVal -= 0.5
+	}
+
+	// x of each period's dot; a lone period sits in the middle.
+	xs := make([]int, len(points))
+	for i := range points {
+		if len(points) == 1 {
+			xs[i] = dotsX / 2
+		} else {
+			xs[i] = int(math.Round(float64(i) / float64(len(points)-1) * float64(dotsX-1)))
+		}
+	}
+	yOf := func(v float64) int {
+		return dotsY - 1 - int(math.Round((v-minVal)/valRange*float64(dotsY-1)))
+	}
+	for i, p := range points {
+		canvas.set(xs[i], yOf(p.value))
+		if i > 0 {
+			canvas.line(xs[i-1], yOf(points[i-1].value), xs[i], yOf(p.value))
+		}
+	}
+
+	// Y-axis labels on 5 evenly spaced rows, valued at each row's center.
+	yLabels := make(map[int]string)
+	for i := 0; i < 5; i++ {
+		r := int(math.Round(float64(i) / 4.0 * float64(height-1)))
+		y := float64(r*4) + 1.5
+		yLabels[r] = formatCompact(minVal + (float64(dotsY-1)-y)/float64(dotsY-1)*valRange)
+	}
+	for r := 0; r < height; r++ {
+		fmt.Printf("%8s %s%s\n", yLabels[r], glyphs.vaxis, canvas.row(r))
+	}
+	fmt.Printf("%8s %s%s\n", "", glyphs.corner, strings.Repeat(glyphs.hrule, width))
+
+	centers := make([]int, len(xs))
+	marked := make([]bool, len(xs))
+	for i, x := range xs {
+		centers[i] = x / 2
+		marked[i] = marks[points[i].date]
+	}
+	if len(marks) > 0 {
+		fmt.Printf("%8s  %s\n", "", markLine(marked, centers, width))
+	}
+	fmt.Printf("%8s  %s\n", "", xAxisLabels(points, centers, width))
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import "testing"
+
+func TestBrailleCanvas(t *testing.T) {
+	c := newBrailleCanvas(2, 1)
+	c.set(0, 0)
+	c.set(1, 3)
+	c.set(9, 9) // off the canvas
+	if got, want := c.row(0), "⢁ "; got != want {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+
+	c = newBrailleCanvas(2, 1)
+	c.line(0, 3, 3, 0)
+	// Dots (0,3) (1,2) in the first cell, (2,1) (3,0) in the second.
+	if got, want := c.row(0), string(rune(0x2800+0x40+0x20))+string(rune(0x2800+0x02+0x08)); got != want {
+		t.Errorf("line row = %q, want %q", got, want)
+	}
+}
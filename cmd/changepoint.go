@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// changeModels are the choices for --model: breaks in a series' level
+// (its mean) or in its trend (a straight line's intercept and slope).
+var changeModels = []string{"level", "trend"}
+
+// changepoint is a break in a series: the segments before and after it,
+// each summarized by its fitted value next to the break and, for the trend
+// model, its slope per period.
+type changepoint struct {
+	Date        string   `json:"date"` // first period after the break
+	Before      float64  `json:"before"`
+	After       float64  `json:"after"`
+	SlopeBefore *float64 `json:"slopeBefore,omitempty"`
+	SlopeAfter  *float64 `json:"slopeAfter,omitempty"`
+}
+
+// entityChangepoints are the breaks found in one entity's series.
+type entityChangepoints struct {
+	Entity string        `json:"entity"`
+	Breaks []changepoint `json:"breaks"`
+}
+
+// segmentFit fits vals[lo:hi] by its mean, or with trend by a least-squares
+// line over the indices, returning the residual sum of squares, the
+// intercept at lo and the slope.
+func segmentFit(vals []float64, lo, hi int, trend bool) (sse, intercept, slope float64) {
+	n := float64(hi - lo)
+	var sx, sy, sxx, sxy float64
+	for i := lo; i < hi; i++ {
+		x := float64(i - lo)
+		sx += x
+		sy += vals[i]
+		sxx += x * x
+		sxy += x * vals[i]
+	}
+	intercept = sy / n
+	if trend && n > 1 {
+		slope = (n*sxy - sx*sy) / (n*sxx - sx*sx)
+		intercept = (sy - slope*sx) / n
+	}
+	for i := lo; i < hi; i++ {
+		r := vals[i] - intercept - slope*float64(i-lo)
+		sse += r * r
+	}
+	return sse, intercept, slope
+}
+
+// noiseVariance estimates the variance of vals' noise from the median
+// absolute deviation of its first differences (second differences for the
+// trend model), which a few breaks barely move. When most differences are
+// equal (a flat series, or monthly values spread evenly over a gap between
+// reports) the MAD is zero and their standard deviation is used instead.
+func noiseVariance(vals []float64, trend bool) float64 {
+	d := diffs(vals)
+	scale := math.Sqrt2 // sd of a difference of two noise terms
+	if trend {
+		d, scale = diffs(d), math.Sqrt(6)
+	}
+	if len(d) == 0 {
+		return 0
+	}
+	sigma := 1.4826 * mad(d)
+	if sigma == 0 {
+		var sum, sq float64
+		for _, v := range d {
+			sum += v
+			sq += v * v
+		}
+		mean := sum / float64(len(d))
+		sigma = math.Sqrt(math.Max(sq/float64(len(d))-mean*mean, 0))
+	}
+	sigma /= scale
+	return sigma * sigma
+}
+
+func diffs(vals []float64) []float64 {
+	var out []float64
+	for i := 1; i < len(vals); i++ {
+		out = append(out, vals[i]-vals[i-1])
+	}
+	return out
+}
+
+// mad returns the median absolute deviation of vals from their median.
+func mad(vals []float64) float64 {
+	med := median(vals)
+	dev := make([]float64, len(vals))
+	for i, v := range vals {
+		dev[i] = math.Abs(v - med)
+	}
+	return median(dev)
+}
+
+func median(vals []float64) float64 {
+	s := append([]float64(nil), vals...)
+	sort.Float64s(s)
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
+
+// detectChangepoints finds breaks in vals by binary segmentation: the
+// segment whose best split most reduces the residual sum of squares is
+// split, as long as the reduction beats a BIC penalty of penalty × (number
+// of parameters a break adds) × noise variance × ln(n), each segment
+// keeping at least minSegment values. It returns the index of the first
+// value after each break, in order. maxChanges of 0 means no limit.
+func detectChangepoints(vals []float64, trend bool, minSegment int, penalty float64, maxChanges int) []int {
+	n := len(vals)
+	if trend {
+		minSegment = max(minSegment, 2) // a line needs two points
+	}
+	if n < 2*minSegment {
+		return nil
+	}
+	params := 2.0 // the new mean and the break's location
+	if trend {
+		params = 3 // intercept, slope and location
+	}
+	variance := noiseVariance(vals, trend)
+	if variance == 0 {
+		return nil // constant, or a perfectly straight line
+	}
+	threshold := penalty * params * variance * math.Log(float64(n))
+
+	// bestSplit returns the split of [lo, hi) that most reduces the cost.
+	bestSplit := func(lo, hi int) (at int, gain float64) {
+		whole, _, _ := segmentFit(vals, lo, hi, trend)
+		at = -1
+		for t := lo + minSegment; t <= hi-minSegment; t++ {
+			left, _, _ := segmentFit(vals, lo, t, trend)
+			right, _, _ := segmentFit(vals, t, hi, trend)
+			if g := whole - left - right; at < 0 || g > gain {
+				at, gain = t, g
+			}
+		}
+		return at, gain
+	}
+
+	breaks := []int{0, n}
+	for maxChanges <= 0 || len(breaks)-2 < maxChanges {
+		bestAt, bestGain := -1, threshold
+		for i := 0; i+1 < len(breaks); i++ {
+			if at, gain := bestSplit(breaks[i], breaks[i+1]); at >= 0 && gain > bestGain {
+				bestAt, bestGain = at, gain
+			}
+		}
+		if bestAt < 0 {
+			break
+		}
+		breaks = append(breaks, bestAt)
+		sort.Ints(breaks)
+	}
+	return breaks[1 : len(breaks)-1]
+}
+
+// describeChangepoints summarizes the segments either side of each break in
+// points (sorted, without NaN) for the report.
+func describeChangepoints(points []dataPoint, breaks []int, trend bool) []changepoint {
+	vals := make([]float64, len(points))
+	for i, p := range points {
+		vals[i] = p.value
+	}
+	bounds := append(append([]int{0}, breaks...), len(points))
+	var out []changepoint
+	for i := 1; i+1 < len(bounds); i++ {
+		lo, at, hi := bounds[i-1], bounds[i], bounds[i+1]
+		_, b0, b1 := segmentFit(vals, lo, at, trend)
+		_, a0, a1 := segmentFit(vals, at, hi, trend)
+		c := changepoint{Date: points[at].date, Before: b0 + b1*float64(at-1-lo), After: a0}
+		if trend {
+			c.SlopeBefore, c.SlopeAfter = &b1, &a1
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// Changepoints implements the "changepoints" subcommand: find the periods
+// where a series' level or trend breaks, for one entity or every entity at
+// a level.
+func Changepoints(args []string) {
+	fs := flag.NewFlagSet("changepoints", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	metric := fs.String("metric", "filings", "metric to examine")
+	caseType := fs.String("type", "grand-total", "case type column")
+	level := fs.String("level", "municipality", "aggregation level: state, county, municipality")
+	county := fs.String("county", "", "county filter")
+	municipality := fs.String("municipality", "", "municipality filter")
+	model := fs.String("model", "level", "what breaks: level (shifts in the mean) or trend (changes in a fitted line's level or slope)")
+	penalty := fs.Float64("penalty", 1, "multiply the BIC penalty for each break; higher finds fewer breaks")
+	minSegment := fs.Int("min-segment", 3, "fewest periods between breaks")
+	maxChanges := fs.Int("max-changes", 0, "most breaks per series (0 for no limit)")
+	values := fs.String("values", "cumulative", "filings, resolutions and clearance as reported or per month: "+strings.Join(validValues, ", "))
+	interval := fs.String("interval", "month", "roll periods up to: "+strings.Join(validIntervals, ", "))
+	weighted := fs.Bool("weighted", false, "compute rate metrics as ratios of summed components instead of averaging")
+	chart := fs.Bool("chart", false, "draw each series with breaks as a line chart, breaks marked under the x axis")
+	width := fs.Int("width", 100, "with --chart: chart width in characters")
+	height := fs.Int("height", 15, "with --chart: chart height in rows")
+	format := fs.String("format", "table", "output format: table, csv, json")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt changepoints [dir] [--metric filings] [--level municipality] [--municipality NEWARK] [--model level|trend] [--chart] [--format table|csv|json]\n\nFind the periods where a series' level or trend breaks, by binary segmentation.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	useASCII(*ascii)
+	useColor(color)
+	if !contains(validMetrics, *metric) {
+		fmt.Fprintf(os.Stderr, "invalid --metric %q; valid options: %s\n", *metric, strings.Join(validMetrics, ", "))
+		os.Exit(1)
+	}
+	if !contains(validTypes, *caseType) {
+		fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+		os.Exit(1)
+	}
+	if *level != "state" && *level != "county" && *level != "municipality" {
+		fmt.Fprintf(os.Stderr, "invalid --level %q; valid options: state, county, municipality\n", *level)
+		os.Exit(1)
+	}
+	if !contains(changeModels, *model) {
+		fmt.Fprintf(os.Stderr, "invalid --model %q; valid options: %s\n", *model, strings.Join(changeModels, ", "))
+		os.Exit(1)
+	}
+	if !contains(validValues, *values) {
+		fmt.Fprintf(os.Stderr, "invalid --values %q; valid options: %s\n", *values, strings.Join(validValues, ", "))
+		os.Exit(1)
+	}
+	if !contains(validIntervals, *interval) {
+		fmt.Fprintf(os.Stderr, "invalid --interval %q; valid options: %s\n", *interval, strings.Join(validIntervals, ", "))
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, csv, json\n", *format)
+		os.Exit(1)
+	}
+	if *penalty <= 0 || *minSegment < 1 {
+		fmt.Fprintf(os.Stderr, "--penalty must be positive and --min-segment at least 1\n")
+		os.Exit(1)
+	}
+	if *width < minChartWidth || *height < minChartHeight {
+		fmt.Fprintf(os.Stderr, "--width must be at least %d and --height at least %d\n", minChartWidth, minChartHeight)
+		os.Exit(1)
+	}
+	*county = strings.ToUpper(*county)
+	*municipality = strings.ToUpper(*municipality)
+
+	records, err := loadMetricRecords(*dir, *metric)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+	if *values == "monthly" {
+		records = monthlyRecords(records)
+	}
+
+	series, _ := intervalSeries(records, *metric, *caseType, *level, *county, *municipality, *weighted, *interval)
+	if len(series) == 0 {
+		fmt.Fprintf(os.Stderr, "no data matched the given filters\n")
+		os.Exit(1)
+	}
+
+	trend := *model == "trend"
+	var found []entityChangepoints
+	for _, name := range sortedSeriesNames(series) {
+		points := cleanPoints(series[name])
+		series[name] = points
+		vals := make([]float64, len(points))
+		for i, p := range points {
+			vals[i] = p.value
+		}
+		breaks := detectChangepoints(vals, trend, *minSegment, *penalty, *maxChanges)
+		if len(breaks) > 0 {
+			found = append(found, entityChangepoints{Entity: name, Breaks: describeChangepoints(points, breaks, trend)})
+		}
+	}
+
+	title := metricLabel(*metric) + glyphs.dash + typeLabel(*caseType)
+	switch *format {
+	case "json":
+		if found == nil {
+			found = []entityChangepoints{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(found)
+	case "csv":
+		err = writeChangepointsCSV(os.Stdout, found)
+	default:
+		fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("%s, %s breaks (%d of %d series)", title, *model, len(found), len(series))))
+		fmt.Println()
+		for _, e := range found {
+			renderChangepoints(e, trend)
+			if *chart {
+				marks := make(map[string]bool)
+				for _, c := range e.Breaks {
+					marks[c.Date] = true
+				}
+				fmt.Println()
+				renderChart(title+glyphs.dash+e.Entity, series[e.Entity], *width, *height, marks)
+				fmt.Println()
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// sortedSeriesNames returns the keys of series in order.
+func sortedSeriesNames(series map[string][]dataPoint) []string {
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cleanPoints returns pts sorted by date without NaN values.
+func cleanPoints(pts []dataPoint) []dataPoint {
+	var out []dataPoint
+	for _, p := range pts {
+		if !math.IsNaN(p.value) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].date < out[j].date })
+	return out
+}
+
+func renderChangepoints(e entityChangepoints, trend bool) {
+	fmt.Println(e.Entity)
+	for _, c := range e.Breaks {
+		line := fmt.Sprintf("  %-9s %10s %s %-10s", c.Date, formatCompact(c.Before), glyphs.arrow, formatCompact(c.After))
+		if c.Before != 0 {
+			line += fmt.Sprintf(" (%+.1f%%)", (c.After-c.Before)/math.Abs(c.Before)*100)
+		}
+		if trend {
+			line += fmt.Sprintf("  slope %s %s %s per period", formatNum(math.Round(*c.SlopeBefore*10)/10), glyphs.arrow, formatNum(math.Round(*c.SlopeAfter*10)/10))
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+}
+
+func writeChangepointsCSV(out io.Writer, found []entityChangepoints) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"Entity", "Date", "Before", "After", "SlopeBefore", "SlopeAfter"})
+	num := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', 2, 64)
+	}
+	for _, e := range found {
+		for _, c := range e.Breaks {
+			w.Write([]string{e.Entity, c.Date, num(&c.Before), num(&c.After), num(c.SlopeBefore), num(c.SlopeAfter)})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectChangepointsLevel(t *testing.T) {
+	// Noisy around 100, then around 60 from index 8, then 90 from 14.
+	vals := []float64{101, 99, 102, 98, 100, 101, 99, 100, 61, 59, 60, 62, 58, 60, 90, 91, 89, 90, 92, 88}
+	if got := detectChangepoints(vals, false, 3, 1, 0); !reflect.DeepEqual(got, []int{8, 14}) {
+		t.Errorf("breaks = %v, want [8 14]", got)
+	}
+	if got := detectChangepoints(vals, false, 3, 1, 1); !reflect.DeepEqual(got, []int{8}) {
+		t.Errorf("with --max-changes 1, breaks = %v, want the larger [8]", got)
+	}
+	if got := detectChangepoints(vals[:8], false, 3, 1, 0); len(got) != 0 {
+		t.Errorf("noise alone gave breaks %v", got)
+	}
+}
+
+func TestDetectChangepointsTrend(t *testing.T) {
+	// Rising by 5 a period, then falling by 3 from index 10.
+	var vals []float64
+	for i := range 10 {
+		vals = append(vals, 100+5*float64(i)+float64(i%3-1))
+	}
+	for i := range 10 {
+		vals = append(vals, 145-3*float64(i)+float64(i%3-1))
+	}
+	got := detectChangepoints(vals, true, 3, 1, 0)
+	if !reflect.DeepEqual(got, []int{10}) {
+		t.Fatalf("breaks = %v, want [10]", got)
+	}
+
+	var pts []dataPoint
+	for i, v := range vals {
+		pts = append(pts, dataPoint{date: string(rune('a' + i)), value: v})
+	}
+	c := describeChangepoints(pts, got, true)[0]
+	if c.Date != "k" || math.Abs(*c.SlopeBefore-5) > 0.5 || math.Abs(*c.SlopeAfter+3) > 0.5 {
+		t.Errorf("changepoint = %+v (slopes %v, %v), want at k with slopes 5 and -3", c, *c.SlopeBefore, *c.SlopeAfter)
+	}
+}
+
+func TestDetectChangepointsFlatSteps(t *testing.T) {
+	// No noise at all, as with monthly values spread over gaps between
+	// reports: the step is still found and a constant series has none.
+	vals := []float64{5, 5, 5, 5, 5, 9, 9, 9, 9, 9}
+	if got := detectChangepoints(vals, false, 2, 1, 0); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("breaks = %v, want [5]", got)
+	}
+	if got := detectChangepoints(vals[:5], false, 2, 1, 0); len(got) != 0 {
+		t.Errorf("constant series gave breaks %v", got)
+	}
+}
+
+func TestMarkLine(t *testing.T) {
+	useASCII(true)
+	defer useASCII(false)
+	if got := markLine([]bool{false, true, false, true}, []int{1, 4, 7, 10}, 11); got != strings.Repeat(" ", 4)+"^"+strings.Repeat(" ", 5)+"^" {
+		t.Errorf("markLine = %q", got)
+	}
+}
@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strings"
+)
+
+// shapeSeries is one municipality's series, z-normalized so that clusters
+// group trajectories rather than sizes.
+type shapeSeries struct {
+	name   string // "COUNTY / MUNICIPALITY"
+	values []float64
+}
+
+// shapeCluster is a group of municipalities with similar trajectories.
+type shapeCluster struct {
+	Centroid []float64     `json:"centroid"` // z-scores, one per period
+	Members  []shapeMember `json:"members"`  // nearest the centroid first
+}
+
+type shapeMember struct {
+	Name     string  `json:"name"`
+	Distance float64 `json:"distance"`
+}
+
+// shapeSeriesFrom builds each municipality's normalized series, rolled up
+// to interval (see intervalSeries), and returns them with the periods they
+// cover. Municipalities with values in fewer than minCoverage (a fraction)
+// of the periods are left out; the gaps of the rest are filled by linear
+// interpolation, or the nearest value at either end.
+func shapeSeriesFrom(records []timeRecord, metric, caseType, county, interval string, minCoverage float64) ([]shapeSeries, []string) {
+	byName := make(map[string][]dataPoint)
+	allDates := make(map[string]bool)
+	for _, rec := range records {
+		allDates[rec.date] = true
+		for _, s := range rec.stats {
+			if county != "" && !strings.EqualFold(s.County, county) {
+				continue
+			}
+			name := strings.ToUpper(s.County) + " / " + entityLabel(s)
+			if v := getField(getRow(s, metric), caseType); !math.IsNaN(v) {
+				byName[name] = append(byName[name], dataPoint{date: rec.date, value: v})
+			}
+		}
+	}
+	dates := sortDates(intervalDates(allDates, interval))
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []shapeSeries
+	for _, name := range names {
+		vals := alignValues(rollup(byName[name], interval, rollupCombine(metric)), dates)
+		n := 0
+		for _, v := range vals {
+			if !math.IsNaN(v) {
+				n++
+			}
+		}
+		if n < 2 || float64(n) < minCoverage*float64(len(vals)) {
+			continue
+		}
+		out = append(out, shapeSeries{name: name, values: zNormalize(fillGaps(vals))})
+	}
+	return out, dates
+}
+
+// fillGaps replaces NaN values by linear interpolation between their
+// neighbors, or the nearest value at either end. vals must hold at least
+// one number.
+func fillGaps(vals []float64) []float64 {
+	out := append([]float64(nil), vals...)
+	prev := -1
+	for i, v := range out {
+		if math.IsNaN(v) {
+			continue
+		}
+		for j := prev + 1; j < i; j++ {
+			if prev < 0 {
+				out[j] = v
+			} else {
+				out[j] = out[prev] + (v-out[prev])*float64(j-prev)/float64(i-prev)
+			}
+		}
+		prev = i
+	}
+	for j := prev + 1; j < len(out); j++ {
+		out[j] = out[prev]
+	}
+	return out
+}
+
+// zNormalize rescales vals to mean 0 and standard deviation 1; a flat
+// series becomes all zeros.
+func zNormalize(vals []float64) []float64 {
+	mean := sumValues(vals) / float64(len(vals))
+	var ss float64
+	for _, v := range vals {
+		ss += (v - mean) * (v - mean)
+	}
+	sd := math.Sqrt(ss / float64(len(vals)))
+	out := make([]float64, len(vals))
+	for i, v := range vals {
+		if sd > 0 {
+			out[i] = (v - mean) / sd
+		}
+	}
+	return out
+}
+
+func euclidean(a, b []float64) float64 {
+	var ss float64
+	for i := range a {
+		ss += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(ss)
+}
+
+// dtwDistance is the dynamic time warping distance between a and b with a
+// Sakoe-Chiba band of window periods, so a shape shifted by a few periods
+// still matches.
+func dtwDistance(a, b []float64, window int) float64 {
+	n, m := len(a), len(b)
+	window = max(window, abs(n-m))
+	prev, cur := make([]float64, m+1), make([]float64, m+1)
+	for j := range prev {
+		prev[j] = math.Inf(1)
+	}
+	prev[0] = 0
+	for i := 1; i <= n; i++ {
+		for j := range cur {
+			cur[j] = math.Inf(1)
+		}
+		for j := max(1, i-window); j <= min(m, i+window); j++ {
+			d := (a[i-1] - b[j-1]) * (a[i-1] - b[j-1])
+			cur[j] = d + min(prev[j], cur[j-1], prev[j-1])
+		}
+		prev, cur = cur, prev
+	}
+	return math.Sqrt(prev[m])
+}
+
+// clusterShapes groups series into k clusters. With the euclidean distance
+// it runs k-means, the centroid being each cluster's mean; with dtw it runs
+// k-medoids, since DTW has no meaningful mean, and the centroid is the
+// member closest to all the others. Seeding is k-means++ from seed, so
+// results are repeatable.
+func clusterShapes(series []shapeSeries, k int, distance func(a, b []float64) float64, medoids bool, seed uint64) []shapeCluster {
+	if len(series) == 0 {
+		return nil
+	}
+	k = min(k, len(series))
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	centroids := [][]float64{series[rng.IntN(len(series))].values}
+	for len(centroids) < k {
+		weights := make([]float64, len(series))
+		total := 0.0
+		for i, s := range series {
+			d := math.Inf(1)
+			for _, c := range centroids {
+				d = min(d, distance(s.values, c))
+			}
+			weights[i] = d * d
+			total += weights[i]
+		}
+		if total == 0 {
+			break // fewer distinct shapes than k
+		}
+		r := rng.Float64() * total
+		pick := len(series) - 1
+		for i, w := range weights {
+			if r -= w; r < 0 {
+				pick = i
+				break
+			}
+		}
+		centroids = append(centroids, series[pick].values)
+	}
+
+	assign := make([]int, len(series))
+	for iter := 0; iter < 100; iter++ {
+		changed := false
+		for i, s := range series {
+			best, bestD := 0, math.Inf(1)
+			for c, centroid := range centroids {
+				if d := distance(s.values, centroid); d < bestD {
+					best, bestD = c, d
+				}
+			}
+			if iter == 0 || assign[i] != best {
+				changed = true
+				assign[i] = best
+			}
+		}
+		if !changed {
+			break
+		}
+		for c := range centroids {
+			var members []int
+			for i, a := range assign {
+				if a == c {
+					members = append(members, i)
+				}
+			}
+			if len(members) == 0 {
+				continue
+			}
+			if medoids {
+				best, bestSum := members[0], math.Inf(1)
+				for _, i := range members {
+					sum := 0.0
+					for _, j := range members {
+						sum += distance(series[i].values, series[j].values)
+					}
+					if sum < bestSum {
+						best, bestSum = i, sum
+					}
+				}
+				centroids[c] = series[best].values
+				continue
+			}
+			mean := make([]float64, len(series[0].values))
+			for _, i := range members {
+				for t, v := range series[i].values {
+					mean[t] += v / float64(len(members))
+				}
+			}
+			centroids[c] = mean
+		}
+	}
+
+	clusters := make([]shapeCluster, len(centroids))
+	for c := range clusters {
+		clusters[c].Centroid = centroids[c]
+	}
+	for i, s := range series {
+		c := &clusters[assign[i]]
+		c.Members = append(c.Members, shapeMember{Name: s.name, Distance: distance(s.values, c.Centroid)})
+	}
+	var out []shapeCluster
+	for _, c := range clusters {
+		if len(c.Members) == 0 {
+			continue
+		}
+		sort.SliceStable(c.Members, func(i, j int) bool { return c.Members[i].Distance < c.Members[j].Distance })
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].Members) > len(out[j].Members) })
+	return out
+}
+
+// Cluster implements the "cluster" subcommand: group municipalities whose
+// series have similar shapes.
+func Cluster(args []string) {
+	fs := flag.NewFlagSet("cluster", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	metric := fs.String("metric", "filings", "metric to cluster on")
+	caseType := fs.String("type", "grand-total", "case type column")
+	county := fs.String("county", "", "only cluster municipalities in this county")
+	k := fs.Int("k", 4, "number of clusters")
+	distanceName := fs.String("distance", "euclidean", "distance between shapes: euclidean (k-means) or dtw (k-medoids with dynamic time warping)")
+	window := fs.Int("dtw-window", 2, "with --distance dtw: how many periods a shape may shift")
+	minCoverage := fs.Float64("min-coverage", 0.8, "leave out municipalities with values in fewer than this fraction of periods")
+	values := fs.String("values", "cumulative", "filings, resolutions and clearance as reported or per month: "+strings.Join(validValues, ", "))
+	interval := fs.String("interval", "month", "roll periods up to: "+strings.Join(validIntervals, ", "))
+	seed := fs.Uint64("seed", 1, "random seed for choosing the starting centroids")
+	show := fs.Int("show", 10, "members listed per cluster (0 for all)")
+	format := fs.String("format", "table", "output format: table, json")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt cluster [dir] [--metric filings] [--k 4] [--distance euclidean|dtw] [--county name] [--format table|json]\n\nGroup municipalities whose series have similar shapes, whatever their size.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if !contains(validMetrics, *metric) {
+		fmt.Fprintf(os.Stderr, "invalid --metric %q; valid options: %s\n", *metric, strings.Join(validMetrics, ", "))
+		os.Exit(1)
+	}
+	if !contains(validTypes, *caseType) {
+		fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+		os.Exit(1)
+	}
+	if *distanceName != "euclidean" && *distanceName != "dtw" {
+		fmt.Fprintf(os.Stderr, "invalid --distance %q; valid options: euclidean, dtw\n", *distanceName)
+		os.Exit(1)
+	}
+	if !contains(validValues, *values) {
+		fmt.Fprintf(os.Stderr, "invalid --values %q; valid options: %s\n", *values, strings.Join(validValues, ", "))
+		os.Exit(1)
+	}
+	if !contains(validIntervals, *interval) {
+		fmt.Fprintf(os.Stderr, "invalid --interval %q; valid options: %s\n", *interval, strings.Join(validIntervals, ", "))
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, json\n", *format)
+		os.Exit(1)
+	}
+	if *k < 1 {
+		fmt.Fprintf(os.Stderr, "--k must be at least 1\n")
+		os.Exit(1)
+	}
+
+	records, err := loadMetricRecords(*dir, *metric)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if *values == "monthly" {
+		records = monthlyRecords(records)
+	}
+	series, dates := shapeSeriesFrom(records, *metric, *caseType, *county, *interval, *minCoverage)
+	if len(series) == 0 {
+		fmt.Fprintf(os.Stderr, "no municipalities with enough data in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	distance, medoids := euclidean, false
+	if *distanceName == "dtw" {
+		distance = func(a, b []float64) float64 { return dtwDistance(a, b, *window) }
+		medoids = true
+	}
+	clusters := clusterShapes(series, *k, distance, medoids, *seed)
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"metric": *metric, "type": *caseType, "periods": dates, "clusters": clusters}); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("%s%s%s: %d municipalities in %d clusters by trend shape (%s to %s)", metricLabel(*metric), glyphs.dash, typeLabel(*caseType),
+		len(series), len(clusters), dates[0], dates[len(dates)-1])))
+	for i, c := range clusters {
+		fmt.Printf("\nCluster %d (%d)  %s\n", i+1, len(c.Members), sparkline(c.Centroid))
+		members := c.Members
+		if *show > 0 && len(members) > *show {
+			members = members[:*show]
+		}
+		for _, m := range members {
+			fmt.Printf("  %-44s %.2f\n", m.Name, m.Distance)
+		}
+		if len(members) < len(c.Members) {
+			fmt.Printf("  ... and %d more\n", len(c.Members)-len(members))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFillGaps(t *testing.T) {
+	nan := math.NaN()
+	got := fillGaps([]float64{nan, 2, nan, nan, 8, nan})
+	want := []float64{2, 2, 4, 6, 8, 8}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("fillGaps = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDTWDistance(t *testing.T) {
+	a := []float64{0, 0, 1, 2, 1, 0}
+	shifted := []float64{0, 1, 2, 1, 0, 0}
+	if d := dtwDistance(a, shifted, 2); d != 0 {
+		t.Errorf("dtw of a shape shifted one period = %v, want 0", d)
+	}
+	if d := dtwDistance(a, shifted, 0); d != euclidean(a, shifted) {
+		t.Errorf("dtw with no warping = %v, want the euclidean %v", d, euclidean(a, shifted))
+	}
+}
+
+func TestClusterShapes(t *testing.T) {
+	series := []shapeSeries{
+		{"UP 1", zNormalize([]float64{1, 2, 3, 4})},
+		{"UP 2", zNormalize([]float64{10, 25, 30, 41})},
+		{"DOWN 1", zNormalize([]float64{4, 3, 2, 1})},
+		{"DOWN 2", zNormalize([]float64{400, 310, 180, 100})},
+		{"DOWN 3", zNormalize([]float64{9, 8, 6, 1})},
+	}
+	for _, medoids := range []bool{false, true} {
+		clusters := clusterShapes(series, 2, euclidean, medoids, 1)
+		if len(clusters) != 2 || len(clusters[0].Members) != 3 || len(clusters[1].Members) != 2 {
+			t.Fatalf("medoids=%v: clusters = %+v, want the three falling then the two rising", medoids, clusters)
+		}
+		for _, m := range clusters[0].Members {
+			if m.Name[:4] != "DOWN" {
+				t.Errorf("medoids=%v: %s in the falling cluster", medoids, m.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+)
+
+// style is an SGR escape parameter for colored terminal output.
+type style string
+
+const (
+	styleBold   style = "1"
+	styleDim    style = "2"
+	styleRed    style = "31"
+	styleYellow style = "33"
+)
+
+// colorStdout and colorStderr say whether output to each stream is
+// colored; useColor sets them. Both default to off, so output is plain
+// unless a command opts in.
+var colorStdout, colorStderr bool
+
+// colorFlags holds --no-color and --force-color; see addColorFlags.
+type colorFlags struct {
+	no, force *bool
+}
+
+// addColorFlags registers --no-color and --force-color. Pass the result to
+// useColor after parsing.
+func addColorFlags(fs *flag.FlagSet) colorFlags {
+	return colorFlags{
+		no:    fs.Bool("no-color", false, "never color output (also set by a non-empty NO_COLOR)"),
+		force: fs.Bool("force-color", false, "color output even when it isn't a terminal"),
+	}
+}
+
+// useColor decides, for stdout and stderr separately, whether to color
+// output: --force-color wins, then --no-color, NO_COLOR and TERM=dumb turn
+// it off, and otherwise only a terminal gets color.
+func useColor(f colorFlags) {
+	colorStdout = colorWanted(os.Stdout, *f.no, *f.force)
+	colorStderr = colorWanted(os.Stderr, *f.no, *f.force)
+}
+
+func colorWanted(out *os.File, no, force bool) bool {
+	switch {
+	case force:
+		return true
+	case no, os.Getenv("NO_COLOR") != "", os.Getenv("TERM") == "dumb":
+		return false
+	}
+	return isTerminal(out)
+}
+
+// isTerminal reports whether f is a character device, i.e. a terminal
+// rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps s in st when on. Pad s to its column width first: the escape
+// codes have no width but count towards fmt's.
+func paint(on bool, st style, s string) string {
+	if !on || s == "" {
+		return s
+	}
+	return "\x1b[" + string(st) + "m" + s + "\x1b[0m"
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestColorWanted(t *testing.T) {
+	// A regular file stands in for piped or redirected output.
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		noColor, term string
+		no, force     bool
+		want          bool
+	}{
+		{"", "xterm", false, false, false}, // not a terminal
+		{"", "xterm", false, true, true},
+		{"1", "xterm", false, true, true}, // --force-color wins over NO_COLOR
+		{"", "xterm", true, true, true},
+		{"1", "xterm", false, false, false},
+		{"", "dumb", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.noColor)
+		t.Setenv("TERM", tt.term)
+		if got := colorWanted(file, tt.no, tt.force); got != tt.want {
+			t.Errorf("NO_COLOR=%q TERM=%q no=%v force=%v: got %v, want %v", tt.noColor, tt.term, tt.no, tt.force, got, tt.want)
+		}
+	}
+}
+
+func TestPaint(t *testing.T) {
+	if got := paint(false, styleBold, "title"); got != "title" {
+		t.Errorf("off: %q", got)
+	}
+	if got := paint(true, styleRed, "x"); got != "\x1b[31mx\x1b[0m" {
+		t.Errorf("on: %q", got)
+	}
+	if got := paint(true, styleRed, ""); got != "" {
+		t.Errorf("empty: %q", got)
+	}
+}
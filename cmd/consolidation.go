@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// consolidation is a likely merger of courts: between two consecutive
+// periods one or more courts in a county stopped reporting filings while
+// another court's filings rose by about as much.
+type consolidation struct {
+	Date     string               `json:"date"`     // first period without the closed courts
+	Previous string               `json:"previous"` // last period they reported
+	County   string               `json:"county"`
+	From     []closedCourt        `json:"from"`
+	To       string               `json:"to"`
+	Kind     string               `json:"kind"`   // "rename", "joint-court" if To is new, otherwise "absorbed"
+	Before   float64              `json:"before"` // To's filings in Previous (0 if new)
+	After    float64              `json:"after"`
+	Match    float64              `json:"match"` // To's increase divided by the closed courts' filings
+	Known    *parser.HistoryEvent `json:"known,omitempty"`
+}
+
+type closedCourt struct {
+	Municipality string  `json:"municipality"`
+	Filings      float64 `json:"filings"` // in Previous
+}
+
+// consolidationOptions tune findConsolidations. A closed court must have
+// had at least minFilings, and the receiving court's increase must be
+// within tolerance (a fraction) of the closed courts' combined filings.
+type consolidationOptions struct {
+	minFilings float64
+	tolerance  float64
+}
+
+// findConsolidations scans each pair of consecutive periods. Courts whose
+// grand-total filings go from at least minFilings to missing, "- -" or zero
+// are closed; courts whose filings rose (including courts that are new) are
+// gainers. Within each county, a new court with a closed court's name and
+// about its filings is a rename. Other gainers are taken largest increase
+// first and given each remaining closed court, largest first, that brings
+// the total closer to the increase; a gainer that ends up within tolerance
+// is reported. Events that match the embedded rename/merger timeline carry
+// it in Known.
+func findConsolidations(records []timeRecord, opts consolidationOptions) []consolidation {
+	var events []consolidation
+	for i := 1; i < len(records); i++ {
+		prev, cur := filingsByCourt(records[i-1]), filingsByCourt(records[i])
+		counties := make(map[string]bool)
+		for k := range prev {
+			counties[k[0]] = true
+		}
+		for _, county := range sortedKeys(counties) {
+			var closed []closedCourt
+			type gainer struct {
+				name          string
+				before, after float64
+			}
+			var gainers []gainer
+			for k, before := range prev {
+				if k[0] != county {
+					continue
+				}
+				after, ok := cur[k]
+				if before >= opts.minFilings && (!ok || math.IsNaN(after) || after == 0) {
+					closed = append(closed, closedCourt{k[1], before})
+				}
+			}
+			if len(closed) == 0 {
+				continue
+			}
+			for k, after := range cur {
+				if k[0] != county || math.IsNaN(after) {
+					continue
+				}
+				before, ok := prev[k]
+				if !ok || math.IsNaN(before) {
+					before = 0
+				}
+				if after-before >= opts.minFilings {
+					gainers = append(gainers, gainer{k[1], before, after})
+				}
+			}
+			sort.Slice(closed, func(a, b int) bool {
+				if closed[a].Filings != closed[b].Filings {
+					return closed[a].Filings > closed[b].Filings
+				}
+				return closed[a].Municipality < closed[b].Municipality
+			})
+			sort.Slice(gainers, func(a, b int) bool {
+				da, db := gainers[a].after-gainers[a].before, gainers[b].after-gainers[b].before
+				if da != db {
+					return da > db
+				}
+				return gainers[a].name < gainers[b].name
+			})
+
+			used := make([]bool, len(closed))
+			report := func(g gainer, picked []int) {
+				var from []closedCourt
+				sum := 0.0
+				for _, j := range picked {
+					used[j] = true
+					from = append(from, closed[j])
+					sum += closed[j].Filings
+				}
+				ev := consolidation{
+					Date: records[i].date, Previous: records[i-1].date, County: county,
+					From: from, To: g.name, Kind: "absorbed", Before: g.before, After: g.after,
+					Match: math.Round((g.after-g.before)/sum*100) / 100,
+				}
+				switch {
+				case len(from) == 1 && sameCourt(from[0].Municipality, g.name):
+					ev.Kind = "rename"
+				case g.before == 0:
+					ev.Kind = "joint-court"
+				}
+				ev.Known = knownConsolidation(ev)
+				events = append(events, ev)
+			}
+			within := func(sum, increase float64) bool {
+				return sum > 0 && math.Abs(sum-increase) <= increase*opts.tolerance
+			}
+
+			// Renames first, so a renamed court isn't taken as a merger
+			// partner by a larger gainer.
+			var rest []gainer
+			for _, g := range gainers {
+				renamed := -1
+				for j, c := range closed {
+					if !used[j] && g.before == 0 && sameCourt(c.Municipality, g.name) && within(c.Filings, g.after) {
+						renamed = j
+						break
+					}
+				}
+				if renamed >= 0 {
+					report(g, []int{renamed})
+				} else {
+					rest = append(rest, g)
+				}
+			}
+			for _, g := range rest {
+				increase := g.after - g.before
+				var picked []int
+				sum := 0.0
+				for j, c := range closed {
+					if !used[j] && math.Abs(sum+c.Filings-increase) < math.Abs(sum-increase) {
+						picked = append(picked, j)
+						sum += c.Filings
+					}
+				}
+				if within(sum, increase) {
+					report(g, picked)
+				}
+			}
+		}
+	}
+	return events
+}
+
+// filingsByCourt maps [county, municipality] to grand-total filings in the
+// current period, NaN where not reported.
+func filingsByCourt(rec timeRecord) map[[2]string]float64 {
+	out := make(map[[2]string]float64, len(rec.stats))
+	for _, s := range rec.stats {
+		out[[2]string{s.County, strings.ToUpper(s.Municipality)}] = getField(s.Filings.CurrentPeriod, "grand-total")
+	}
+	return out
+}
+
+// sameCourt reports whether two court names differ only in designation,
+// punctuation, court words or a parenthesized county tag, as in
+// "HAMILTON TWP (ATL)" and "HAMILTON TWP".
+func sameCourt(a, b string) bool {
+	norm := func(s string) string {
+		if i := strings.Index(s, "("); i > 0 {
+			s = s[:i]
+		}
+		return stripMunicipalSuffix(joinName(s))
+	}
+	return norm(a) == norm(b)
+}
+
+// knownConsolidation returns the history event, if any, in which ev's
+// receiving court succeeded one of its closed courts.
+func knownConsolidation(ev consolidation) *parser.HistoryEvent {
+	for _, e := range parser.HistoryFor(ev.County, ev.To) {
+		if e.To != ev.To {
+			continue
+		}
+		for _, c := range ev.From {
+			if contains(e.From, c.Municipality) {
+				return &e
+			}
+		}
+	}
+	return nil
+}
+
+// Consolidations implements the "consolidations" subcommand: report likely
+// court mergers found in the parsed data.
+func Consolidations(args []string) {
+	fs := flag.NewFlagSet("consolidations", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	minFilings := fs.Float64("min-filings", 100, "ignore courts with fewer filings before closing")
+	tolerance := fs.Float64("tolerance", 0.5, "how far the receiving court's increase may be from the closed courts' filings, as a fraction")
+	unknown := fs.Bool("unknown", false, "only list events missing from the built-in rename/merger timeline")
+	format := fs.String("format", "table", "output format: table, csv, json")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt consolidations [dir] [--min-filings 100] [--tolerance 0.5] [--unknown] [--format table|csv|json]\n\nList likely court consolidations: courts that stop reporting while another court in the county grows by about as much.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, csv, json\n", *format)
+		os.Exit(1)
+	}
+	if *tolerance <= 0 || *tolerance >= 1 {
+		fmt.Fprintf(os.Stderr, "--tolerance must be between 0 and 1\n")
+		os.Exit(1)
+	}
+
+	records, err := loadMetricRecords(*dir, "filings")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) < 2 {
+		fmt.Fprintf(os.Stderr, "need at least two periods in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	events := findConsolidations(records, consolidationOptions{minFilings: *minFilings, tolerance: *tolerance})
+	if *unknown {
+		var kept []consolidation
+		for _, ev := range events {
+			if ev.Known == nil {
+				kept = append(kept, ev)
+			}
+		}
+		events = kept
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if events == nil {
+			events = []consolidation{}
+		}
+		err = enc.Encode(events)
+	case "csv":
+		err = writeConsolidationsCSV(os.Stdout, events)
+	default:
+		renderConsolidations(events)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func renderConsolidations(events []consolidation) {
+	if len(events) == 0 {
+		fmt.Println("No likely consolidations found.")
+		return
+	}
+	for _, ev := range events {
+		known := ""
+		if ev.Known != nil {
+			known = " [known " + ev.Known.Kind + "]"
+		}
+		fmt.Printf("%s  %-12s %s %s (%s, filings %s %s %s, %.0f%% of closed courts' filings)%s\n",
+			ev.Date, ev.County, glyphs.arrow, ev.To, ev.Kind, formatNum(ev.Before), glyphs.arrow, formatNum(ev.After), ev.Match*100, known)
+		for _, c := range ev.From {
+			fmt.Printf("             %-28s last reported %s filings in %s\n", c.Municipality, formatNum(c.Filings), ev.Previous)
+		}
+	}
+}
+
+func writeConsolidationsCSV(out io.Writer, events []consolidation) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"Date", "Previous", "County", "From", "FromFilings", "To", "Kind", "Before", "After", "Match", "Known"})
+	for _, ev := range events {
+		var names, filings []string
+		for _, c := range ev.From {
+			names = append(names, c.Municipality)
+			filings = append(filings, strconv.FormatFloat(c.Filings, 'f', -1, 64))
+		}
+		w.Write([]string{ev.Date, ev.Previous, ev.County, strings.Join(names, "; "), strings.Join(filings, "; "), ev.To, ev.Kind,
+			strconv.FormatFloat(ev.Before, 'f', -1, 64), strconv.FormatFloat(ev.After, 'f', -1, 64),
+			strconv.FormatFloat(ev.Match, 'f', 2, 64), strconv.FormatBool(ev.Known != nil)})
+	}
+	w.Flush()
+	return w.Error()
+}
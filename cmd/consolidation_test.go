@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestFindConsolidations(t *testing.T) {
+	records := []timeRecord{
+		{date: "2011-06", stats: []parser.MunicipalityStats{
+			rateStat("GLOUCESTER", "MONROE TWP", "10,000", "", ""),
+			rateStat("GLOUCESTER", "FRANKLIN TWP", "5,000", "", ""),
+			rateStat("GLOUCESTER", "ELK TWP", "3,000", "", ""),
+			rateStat("GLOUCESTER", "TINY BORO", "50", "", ""),
+			rateStat("GLOUCESTER", "WOOLWICH TWP (GLOU)", "6,000", "", ""),
+			rateStat("SUSSEX", "WANTAGE TWP", "1,500", "", ""),
+		}},
+		{date: "2012-06", stats: []parser.MunicipalityStats{
+			rateStat("GLOUCESTER", "MONROE TWP", "18,500", "", ""),
+			rateStat("GLOUCESTER", "FRANKLIN TWP", "- -", "", ""),
+			rateStat("GLOUCESTER", "WOOLWICH TWP", "6,100", "", ""),
+			rateStat("SUSSEX", "WANTAGE TWP", "1,450", "", ""), // small drift, not a closure
+		}},
+	}
+	events := findConsolidations(records, consolidationOptions{minFilings: 100, tolerance: 0.5})
+	if len(events) != 2 {
+		t.Fatalf("got %d events: %+v", len(events), events)
+	}
+
+	monroe := events[1]
+	if monroe.To != "MONROE TWP" || monroe.Kind != "absorbed" || monroe.Date != "2012-06" || monroe.Previous != "2011-06" {
+		t.Errorf("event = %+v", monroe)
+	}
+	if len(monroe.From) != 2 || monroe.From[0].Municipality != "FRANKLIN TWP" || monroe.From[1].Municipality != "ELK TWP" {
+		t.Errorf("from = %+v, want FRANKLIN TWP and ELK TWP (TINY BORO is under --min-filings)", monroe.From)
+	}
+	if monroe.Match != 1.06 {
+		t.Errorf("match = %v, want 1.06", monroe.Match)
+	}
+
+	if rename := events[0]; rename.To != "WOOLWICH TWP" || rename.Kind != "rename" || rename.Before != 0 {
+		t.Errorf("event = %+v", rename)
+	}
+}
+
+func TestSameCourt(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"HAMILTON TWP (ATL)", "HAMILTON TWP"},
+		{"CLINTON TWP MUNICIPAL CT", "CLINTON TOWNSHIP"},
+	} {
+		if !sameCourt(pair[0], pair[1]) {
+			t.Errorf("sameCourt(%q, %q) = false", pair[0], pair[1])
+		}
+	}
+	if sameCourt("EGG HARBOR CITY", "WOOLWICH TWP") {
+		t.Error("different courts reported as the same")
+	}
+}
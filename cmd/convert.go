@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// convertFormats are the --to choices. csv, jsonl and parquet are export's
+// flat table; typed-json keeps the record structure with numeric values.
+var convertFormats = []string{"csv", "jsonl", "parquet", "typed-json"}
+
+// typedSchemaVersion is the version of the typed-json layout.
+const typedSchemaVersion = 1
+
+// typedOutput is the typed-json document: the records of a parsed output
+// file with every value as a number, or null where the report has no data,
+// and a tally of how confidently the values were parsed.
+type typedOutput struct {
+	SchemaVersion int                      `json:"schemaVersion"`
+	Provenance    *parser.Provenance       `json:"provenance,omitempty"`
+	Confidence    parser.ConfidenceSummary `json:"confidence"`
+	Records       []typedRecord            `json:"records"`
+}
+
+type typedRecord struct {
+	Period        string       `json:"period"` // YYYY-MM
+	County        string       `json:"county"`
+	Municipality  string       `json:"municipality"`
+	ID            string       `json:"id"`
+	DateRange     string       `json:"dateRange"`
+	Start         string       `json:"start,omitempty"` // YYYY-MM-DD, first day DateRange covers
+	End           string       `json:"end,omitempty"`   // YYYY-MM-DD, last day
+	Filings       typedSection `json:"filings"`
+	Resolutions   typedSection `json:"resolutions"`
+	Clearance     typedSection `json:"clearance"`
+	ClearancePct  typedSection `json:"clearancePercent"`
+	Backlog       typedSection `json:"backlog"`
+	BacklogPer100 typedSection `json:"backlogPer100MthlyFilings"`
+	BacklogPct    typedSection `json:"backlogPercent"`
+	ActivePending typedSection `json:"activePending"`
+
+	Predecessors []string `json:"predecessors,omitempty"`
+	Successor    string   `json:"successor,omitempty"`
+	SourceFile   string   `json:"sourceFile,omitempty"`
+	PageNumber   int      `json:"pageNumber,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// typedSection is a report section; PctChange is absent for the two-row
+// sections (clearance, clearance % and backlog %).
+type typedSection struct {
+	PriorPeriod   typedRow  `json:"priorPeriod"`
+	CurrentPeriod typedRow  `json:"currentPeriod"`
+	PctChange     *typedRow `json:"pctChange,omitempty"`
+}
+
+// typedRow is parser.RowData with the values converted as by cleanNumber:
+// commas dropped, percentages as decimals (98.1% is 0.981) and "- -" null.
+// Confidence scores every value by how it was recovered from the page.
+type typedRow struct {
+	Label         string   `json:"label"`
+	Start         string   `json:"start,omitempty"` // days Label covers, as on typedRecord
+	End           string   `json:"end,omitempty"`
+	Indictables   *float64 `json:"indictables"`
+	DPAndPDP      *float64 `json:"dpAndPdp"`
+	OtherCriminal *float64 `json:"otherCriminal"`
+	CriminalTotal *float64 `json:"criminalTotal"`
+	DWI           *float64 `json:"dwi"`
+	TrafficMoving *float64 `json:"trafficMoving"`
+	Parking       *float64 `json:"parking"`
+	TrafficTotal  *float64 `json:"trafficTotal"`
+	GrandTotal    *float64 `json:"grandTotal"`
+
+	Confidence map[string]float64 `json:"confidence"`
+}
+
+// typedValue converts one report value. ok is false for text that isn't a
+// number or a no-data marker, which is written as null.
+func typedValue(s string) (v *float64, ok bool) {
+	c := cleanNumber(s)
+	if c == "" {
+		return nil, true
+	}
+	f, err := strconv.ParseFloat(c, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &f, true
+}
+
+// formatDay formats t as YYYY-MM-DD, or "" if it is zero.
+func formatDay(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// typedRowFrom converts r, adding a warning to *warnings, named by where,
+// for each value that isn't a number.
+func typedRowFrom(r parser.RowData, where string, warnings *[]string) typedRow {
+	out := typedRow{Label: r.Label, Start: formatDay(r.Start), End: formatDay(r.End), Confidence: make(map[string]float64, len(parser.RowFields))}
+	for _, f := range parser.RowFields {
+		out.Confidence[f] = r.Confidence(f)
+	}
+	fields := []struct {
+		name string
+		in   string
+		out  **float64
+	}{
+		{"Indictables", r.Indictables, &out.Indictables},
+		{"DPAndPDP", r.DPAndPDP, &out.DPAndPDP},
+		{"OtherCriminal", r.OtherCriminal, &out.OtherCriminal},
+		{"CriminalTotal", r.CriminalTotal, &out.CriminalTotal},
+		{"DWI", r.DWI, &out.DWI},
+		{"TrafficMoving", r.TrafficMoving, &out.TrafficMoving},
+		{"Parking", r.Parking, &out.Parking},
+		{"TrafficTotal", r.TrafficTotal, &out.TrafficTotal},
+		{"GrandTotal", r.GrandTotal, &out.GrandTotal},
+	}
+	for _, f := range fields {
+		v, ok := typedValue(f.in)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s_%s %q is not a number; written as null", where, f.name, f.in))
+		}
+		*f.out = v
+	}
+	return out
+}
+
+// typedRecordFrom converts s, reported for period. Values that aren't
+// numbers are noted in the record's warnings.
+func typedRecordFrom(s parser.MunicipalityStats, period string) typedRecord {
+	t := typedRecord{
+		Period: period, County: s.County, Municipality: s.Municipality, ID: entityID(s), DateRange: s.DateRange,
+		Start: formatDay(s.Start), End: formatDay(s.End),
+		Predecessors: s.Predecessors, Successor: s.Successor,
+		SourceFile: s.SourceFile, PageNumber: s.PageNumber,
+		Warnings: append([]string(nil), s.Warnings...),
+	}
+	three := func(sec parser.SectionWithChange, prefix string) typedSection {
+		change := typedRowFrom(sec.PctChange, prefix+"_PctChange", &t.Warnings)
+		return typedSection{
+			PriorPeriod:   typedRowFrom(sec.PriorPeriod, prefix+"_Prior", &t.Warnings),
+			CurrentPeriod: typedRowFrom(sec.CurrentPeriod, prefix+"_Current", &t.Warnings),
+			PctChange:     &change,
+		}
+	}
+	two := func(sec parser.SectionTwoRow, prefix string) typedSection {
+		return typedSection{
+			PriorPeriod:   typedRowFrom(sec.PriorPeriod, prefix+"_Prior", &t.Warnings),
+			CurrentPeriod: typedRowFrom(sec.CurrentPeriod, prefix+"_Current", &t.Warnings),
+		}
+	}
+	t.Filings = three(s.Filings, "Filings")
+	t.Resolutions = three(s.Resolutions, "Resolutions")
+	t.Clearance = two(s.Clearance, "Clearance")
+	t.ClearancePct = two(s.ClearancePct, "ClearancePct")
+	t.Backlog = three(s.Backlog, "Backlog")
+	t.BacklogPer100 = three(s.BacklogPer100, "BacklogPer100")
+	t.BacklogPct = two(s.BacklogPct, "BacklogPct")
+	t.ActivePending = three(s.ActivePending, "ActivePending")
+	return t
+}
+
+// typedOutputFrom converts records; prov is kept for a single output file.
+// It also returns the number of values written as null because they
+// weren't numbers.
+func typedOutputFrom(records []timeRecord, prov *parser.Provenance) (typedOutput, int) {
+	out := typedOutput{SchemaVersion: typedSchemaVersion, Provenance: prov, Records: []typedRecord{}}
+	bad := 0
+	var all []parser.MunicipalityStats
+	for _, rec := range records {
+		all = append(all, rec.stats...)
+		for _, s := range rec.stats {
+			t := typedRecordFrom(s, rec.date)
+			bad += len(t.Warnings) - len(s.Warnings)
+			out.Records = append(out.Records, t)
+		}
+	}
+	out.Confidence = parser.SummarizeConfidence(all)
+	return out, bad
+}
+
+// loadConvertInput reads a parsed output file, or a dataset written by
+// merge, as records by period. The period of an output file comes from its
+// name, or failing that from its first record's date range.
+func loadConvertInput(path string) ([]timeRecord, *parser.Provenance, error) {
+	if strings.HasSuffix(path, ".gz") {
+		records, err := loadDatasetRecords(path, nil)
+		return records, nil, err
+	}
+	out, err := decodeOutputFile(path, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(out.Records) == 0 {
+		// A merged dataset decodes as an output file without records.
+		if _, err := readDataset(path); err == nil {
+			records, err := loadDatasetRecords(path, nil)
+			return records, nil, err
+		}
+		return nil, nil, fmt.Errorf("%s has no records", path)
+	}
+	period := ""
+	if m := datePattern.FindStringSubmatch(filepath.Base(path)); m != nil {
+		period = m[1] + "-" + m[2]
+	} else if p, ok := periodFromDateRange(out.Records[0].DateRange); ok {
+		period = p
+	}
+	return []timeRecord{{date: period, stats: normalizeCounties(out.Records, filepath.Base(path))}}, out.Provenance, nil
+}
+
+// Convert implements the "convert" subcommand: rewrite one parsed output
+// file in another representation, without the source PDF.
+func Convert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	to := fs.String("to", "", "output format: "+strings.Join(convertFormats, ", "))
+	out := fs.String("out", "", "output file (default stdout; required for parquet)")
+	cleanNumbers := fs.Bool("clean-numbers", false, "with a table format: write values as plain numbers: no commas, percentages as decimals, \"- -\" as empty")
+	sections := fs.String("sections", "", "with a table format: comma-separated sections to include: "+strings.Join(tableSections, ", ")+" (default all)")
+	rows := fs.String("rows", "", "with a table format: comma-separated rows to include per section: prior, current, change (default all)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt convert <input.json | dataset.json[.gz]> --to %s [--out path] [--clean-numbers] [--sections list] [--rows list]\n\n"+
+			"Convert a parsed output file, or a merged dataset, to another representation.\n\nFlags:\n", strings.Join(convertFormats, "|"))
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	input := fs.Arg(0)
+	if !contains(convertFormats, *to) {
+		fmt.Fprintf(os.Stderr, "invalid --to %q; valid options: %s\n", *to, strings.Join(convertFormats, ", "))
+		os.Exit(1)
+	}
+	opts, err := parseTableOptions(*cleanNumbers, false, *sections, *rows)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if *to == "typed-json" && (*cleanNumbers || opts.sections != nil || opts.rows != nil) {
+		fmt.Fprintf(os.Stderr, "--clean-numbers, --sections and --rows don't apply to --to typed-json\n")
+		os.Exit(1)
+	}
+	f := exportFormats[*to]
+	if *out == "" && f.needsFile {
+		fmt.Fprintf(os.Stderr, "--out is required for --to %s\n", *to)
+		os.Exit(1)
+	}
+
+	records, prov, err := loadConvertInput(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading %s: %v\n", input, err)
+		os.Exit(1)
+	}
+
+	if *to == "typed-json" {
+		typed, bad := typedOutputFrom(records, prov)
+		if err := writeTypedJSON(*out, typed); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing typed-json: %v\n", err)
+			os.Exit(1)
+		}
+		if bad > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %d values aren't numbers and were written as null (see the records' warnings)\n", bad)
+		}
+		if *out != "" {
+			fmt.Fprintf(os.Stderr, "wrote %d records to %s\n", len(typed.Records), *out)
+		}
+		return
+	}
+
+	w, err := f.open(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating output: %v\n", err)
+		os.Exit(1)
+	}
+	n, err := exportRecords(w, records, opts)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", *to, err)
+		os.Exit(1)
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "wrote %d rows to %s\n", n, *out)
+	}
+}
+
+// writeTypedJSON writes t, indented, to path or stdout.
+func writeTypedJSON(path string, t typedOutput) error {
+	f, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestTypedRecordFrom(t *testing.T) {
+	s := rateStat("ATLANTIC", "ABSECON", "1,749", "1,700", "97.2%")
+	s.Filings.PctChange.GrandTotal = "- -"
+	s.Backlog.CurrentPeriod.Parking = "12 3"
+	s.Filings.CurrentPeriod.Recovered[8] = parser.RecoveryKerning
+	s.DateRange, s.Backlog.CurrentPeriod.Label = "JULY 2023 - JUNE 2024", "Jun 2024"
+	s.ParsePeriods()
+	got := typedRecordFrom(s, "2024-06")
+
+	if v := got.Filings.CurrentPeriod.GrandTotal; v == nil || *v != 1749 {
+		t.Errorf("filings = %v, want 1749", v)
+	}
+	if v := got.ClearancePct.CurrentPeriod.GrandTotal; v == nil || *v != 0.972 {
+		t.Errorf("clearance %% = %v, want 0.972", v)
+	}
+	if c := got.Filings.CurrentPeriod.Confidence; c["grandTotal"] != 0.9 || c["parking"] != 1 || len(c) != 9 {
+		t.Errorf("confidence = %v", c)
+	}
+	if got.Filings.PctChange == nil || got.Filings.PctChange.GrandTotal != nil {
+		t.Errorf("no-data change = %+v, want null", got.Filings.PctChange)
+	}
+	if got.Start != "2023-07-01" || got.End != "2024-06-30" || got.Backlog.CurrentPeriod.Start != "2024-06-01" {
+		t.Errorf("periods = %s–%s, backlog row from %s", got.Start, got.End, got.Backlog.CurrentPeriod.Start)
+	}
+	if got.Clearance.PctChange != nil {
+		t.Errorf("two-row section has a pctChange row")
+	}
+	if got.Backlog.CurrentPeriod.Parking != nil || len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "Backlog_Current_Parking") {
+		t.Errorf("bad value: parking %v, warnings %q", got.Backlog.CurrentPeriod.Parking, got.Warnings)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"pctChange":{"label":"","indictables":null`) {
+		t.Errorf("missing values aren't null: %s", data)
+	}
+}
+
+func TestLoadConvertInput(t *testing.T) {
+	dir := t.TempDir()
+	prov := &parser.Provenance{SourceFile: "munm2406.pdf"}
+	records := []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "100", "90", "90%")}
+
+	path := filepath.Join(dir, "municipal-courts-2024-06.json")
+	if err := writeOutputJSON(path, prov, records); err != nil {
+		t.Fatal(err)
+	}
+	got, gotProv, err := loadConvertInput(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].date != "2024-06" || gotProv == nil || gotProv.SourceFile != "munm2406.pdf" {
+		t.Errorf("output file: records %+v, provenance %+v", got, gotProv)
+	}
+
+	ds, _ := mergeOutputs(map[string]string{"municipal-courts-2024-06.json": "2024-06"},
+		map[string]parser.Output{"municipal-courts-2024-06.json": {Provenance: prov, Records: records}})
+	dsPath := filepath.Join(dir, "dataset.json")
+	if err := writeDataset(dsPath, ds); err != nil {
+		t.Fatal(err)
+	}
+	got, gotProv, err = loadConvertInput(dsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].date != "2024-06" || gotProv != nil {
+		t.Errorf("dataset: records %+v, provenance %+v", got, gotProv)
+	}
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// correlation is the Pearson correlation of x with y over N pairs, for one
+// entity over time or for one period across entities.
+type correlation struct {
+	Name string // entity, or period when across entities
+	R    float64
+	N    int
+}
+
+// correlationReport is the output of the correlate subcommand.
+type correlationReport struct {
+	X, Y, Type, Level string
+	Across            bool
+	Lag               int
+	PooledR           float64 // over every pair, including rows left out
+	PooledN           int
+	Rows              []correlation
+}
+
+// MarshalJSON writes r rounded to three places and NaN as null, which
+// encoding/json can't write.
+func (r correlationReport) MarshalJSON() ([]byte, error) {
+	rows := make([]map[string]any, len(r.Rows))
+	for i, c := range r.Rows {
+		rows[i] = map[string]any{"name": c.Name, "r": jsonFloat(c.R), "n": c.N}
+	}
+	return json.Marshal(map[string]any{
+		"x": r.X, "y": r.Y, "type": r.Type, "level": r.Level, "across": r.Across, "lag": r.Lag,
+		"pooledR": jsonFloat(r.PooledR), "pooledN": r.PooledN, "rows": rows,
+	})
+}
+
+func jsonFloat(v float64) any {
+	if math.IsNaN(v) {
+		return nil
+	}
+	return math.Round(v*1000) / 1000
+}
+
+// pearson returns the correlation coefficient of xs and ys, NaN with fewer
+// than three pairs or when either side is constant.
+func pearson(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	if len(xs) < 3 {
+		return math.NaN()
+	}
+	var sx, sy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+	}
+	mx, my := sx/n, sy/n
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// correlateSeries pairs each entity's x in a period with its y lag periods
+// later (periods counted in sortedDates). Within an entity (across false)
+// each entity gets a row, ranked by r; across entities each period gets a
+// row, in order. Rows with fewer than minPairs pairs are dropped.
+func correlateSeries(xSeries, ySeries map[string][]dataPoint, sortedDates []string, lag int, across bool, minPairs int) (rows []correlation, pooledR float64, pooledN int) {
+	var names []string
+	for name := range xSeries {
+		if _, ok := ySeries[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	xv := make(map[string][]float64, len(names))
+	yv := make(map[string][]float64, len(names))
+	for _, name := range names {
+		xv[name] = alignValues(xSeries[name], sortedDates)
+		yv[name] = alignValues(ySeries[name], sortedDates)
+	}
+
+	type pairs struct{ xs, ys []float64 }
+	groups := make(map[string]*pairs)
+	var order []string
+	var all pairs
+	for i := 0; i+lag < len(sortedDates); i++ {
+		for _, name := range names {
+			x, y := xv[name][i], yv[name][i+lag]
+			if math.IsNaN(x) || math.IsNaN(y) {
+				continue
+			}
+			key := name
+			if across {
+				key = sortedDates[i]
+			}
+			g, ok := groups[key]
+			if !ok {
+				g = &pairs{}
+				groups[key] = g
+				order = append(order, key)
+			}
+			g.xs, g.ys = append(g.xs, x), append(g.ys, y)
+			all.xs, all.ys = append(all.xs, x), append(all.ys, y)
+		}
+	}
+
+	for _, key := range order {
+		g := groups[key]
+		if len(g.xs) < minPairs {
+			continue
+		}
+		rows = append(rows, correlation{Name: key, R: pearson(g.xs, g.ys), N: len(g.xs)})
+	}
+	if !across {
+		sort.SliceStable(rows, func(i, j int) bool {
+			ri, rj := rows[i].R, rows[j].R
+			if math.IsNaN(ri) != math.IsNaN(rj) {
+				return !math.IsNaN(ri)
+			}
+			return ri > rj
+		})
+	}
+	return rows, pearson(all.xs, all.ys), len(all.xs)
+}
+
+// Correlate implements the "correlate" subcommand: how closely two metrics
+// move together, within each entity over time or across entities in each
+// period, optionally with y lagged behind x.
+func Correlate(args []string) {
+	fs := flag.NewFlagSet("correlate", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	x := fs.String("x", "filings", "first metric")
+	y := fs.String("y", "backlog", "second metric")
+	caseType := fs.String("type", "grand-total", "case type column")
+	level := fs.String("level", "municipality", "aggregation level: state, county, municipality")
+	county := fs.String("county", "", "county filter")
+	lag := fs.Int("lag", 0, "pair x with y this many periods later")
+	across := fs.Bool("across", false, "correlate across entities within each period instead of within each entity over time")
+	minPairs := fs.Int("min-pairs", 5, "skip rows with fewer pairs of values")
+	values := fs.String("values", "cumulative", "filings, resolutions and clearance as reported or per month: "+strings.Join(validValues, ", "))
+	weighted := fs.Bool("weighted", false, "compute rate metrics as ratios of summed components instead of averaging")
+	top := fs.Int("top", 0, "show only the first N rows (0 for all)")
+	format := fs.String("format", "table", "output format: table, csv, json")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt correlate [dir] --x filings --y backlog [--level municipality] [--lag 2] [--across] [--values monthly] [--format table|csv|json]\n\nCorrelate two metrics within each entity over time, or across entities in each period.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	for _, m := range []struct{ flag, value string }{{"--x", *x}, {"--y", *y}} {
+		if !contains(validMetrics, m.value) {
+			fmt.Fprintf(os.Stderr, "invalid %s %q; valid options: %s\n", m.flag, m.value, strings.Join(validMetrics, ", "))
+			os.Exit(1)
+		}
+	}
+	if !contains(validTypes, *caseType) {
+		fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+		os.Exit(1)
+	}
+	if *level != "state" && *level != "county" && *level != "municipality" {
+		fmt.Fprintf(os.Stderr, "invalid --level %q; valid options: state, county, municipality\n", *level)
+		os.Exit(1)
+	}
+	if !contains(validValues, *values) {
+		fmt.Fprintf(os.Stderr, "invalid --values %q; valid options: %s\n", *values, strings.Join(validValues, ", "))
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, csv, json\n", *format)
+		os.Exit(1)
+	}
+	if *lag < 0 {
+		fmt.Fprintf(os.Stderr, "--lag can't be negative; swap --x and --y instead\n")
+		os.Exit(1)
+	}
+	*county = strings.ToUpper(*county)
+
+	records, err := loadMetricRecords(*dir, *x, *y)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+	if *values == "monthly" {
+		records = monthlyRecords(records)
+	}
+
+	xSeries, dates := aggregateSeries(records, *x, *caseType, *level, *county, "", *weighted)
+	ySeries, _ := aggregateSeries(records, *y, *caseType, *level, *county, "", *weighted)
+	rep := correlationReport{X: *x, Y: *y, Type: *caseType, Level: *level, Across: *across, Lag: *lag}
+	rep.Rows, rep.PooledR, rep.PooledN = correlateSeries(xSeries, ySeries, sortDates(dates), *lag, *across, *minPairs)
+	if *top > 0 && len(rep.Rows) > *top {
+		rep.Rows = rep.Rows[:*top]
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rep)
+	case "csv":
+		err = writeCorrelationCSV(os.Stdout, rep)
+	default:
+		renderCorrelation(rep)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func formatR(r float64) string {
+	if math.IsNaN(r) {
+		return "- -"
+	}
+	return fmt.Sprintf("%+.3f", r)
+}
+
+func renderCorrelation(rep correlationReport) {
+	later := ""
+	if rep.Lag > 0 {
+		later = fmt.Sprintf(" %d period(s) later", rep.Lag)
+	}
+	by := "within each " + rep.Level + " over time"
+	if rep.Across {
+		by = "across " + rep.Level + " entities in each period"
+	}
+	fmt.Printf("%s vs %s%s (%s), %s\n", metricLabel(rep.X), metricLabel(rep.Y), later, typeLabel(rep.Type), by)
+	fmt.Printf("Pooled: r = %s over %d pairs\n\n", formatR(rep.PooledR), rep.PooledN)
+	if len(rep.Rows) == 0 {
+		fmt.Println("  (no rows with enough pairs)")
+		return
+	}
+	for i, c := range rep.Rows {
+		rank := fmt.Sprintf("%4d.", i+1)
+		if rep.Across {
+			rank = "    " // periods are listed in order, not ranked
+		}
+		fmt.Printf("%s %-32s %7s  n=%d\n", rank, c.Name, formatR(c.R), c.N)
+	}
+}
+
+func writeCorrelationCSV(out io.Writer, rep correlationReport) error {
+	w := csv.NewWriter(out)
+	if rep.Across {
+		w.Write([]string{"Period", "R", "N"})
+	} else {
+		w.Write([]string{"Rank", "Entity", "R", "N"})
+	}
+	for i, c := range rep.Rows {
+		r := ""
+		if !math.IsNaN(c.R) {
+			r = strconv.FormatFloat(c.R, 'f', 4, 64)
+		}
+		row := []string{c.Name, r, strconv.Itoa(c.N)}
+		if !rep.Across {
+			row = append([]string{strconv.Itoa(i + 1)}, row...)
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
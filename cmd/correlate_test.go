@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPearson(t *testing.T) {
+	if got := pearson([]float64{1, 2, 3, 4}, []float64{2, 4, 6, 8}); math.Abs(got-1) > 1e-12 {
+		t.Errorf("perfect correlation = %v, want 1", got)
+	}
+	if got := pearson([]float64{1, 2, 3}, []float64{3, 2, 1}); math.Abs(got+1) > 1e-12 {
+		t.Errorf("perfect anticorrelation = %v, want -1", got)
+	}
+	if got := pearson([]float64{1, 2, 3}, []float64{5, 5, 5}); !math.IsNaN(got) {
+		t.Errorf("constant y = %v, want NaN", got)
+	}
+}
+
+func TestCorrelateSeries(t *testing.T) {
+	dates := []string{"2024-01", "2024-02", "2024-03", "2024-04", "2024-05"}
+	pts := func(vals ...float64) []dataPoint {
+		var out []dataPoint
+		for i, v := range vals {
+			out = append(out, dataPoint{date: dates[i], value: v})
+		}
+		return out
+	}
+	// A's y follows its x one period later; B's moves against it.
+	x := map[string][]dataPoint{"A": pts(1, 5, 2, 8, 3), "B": pts(1, 2, 3, 4, 5)}
+	y := map[string][]dataPoint{"A": pts(0, 1, 5, 2, 8), "B": pts(9, 8, 7, 6, 5)}
+
+	rows, _, n := correlateSeries(x, y, dates, 1, false, 3)
+	if len(rows) != 2 || rows[0].Name != "A" || math.Abs(rows[0].R-1) > 1e-12 || rows[0].N != 4 {
+		t.Fatalf("within rows = %+v, want A first with r=1 over 4 pairs", rows)
+	}
+	if rows[1].Name != "B" || math.Abs(rows[1].R+1) > 1e-12 {
+		t.Errorf("B = %+v, want r=-1", rows[1])
+	}
+	if n != 8 {
+		t.Errorf("pooled n = %d, want 8", n)
+	}
+
+	// Across entities each period has only two pairs, below pearson's minimum.
+	rows, _, _ = correlateSeries(x, y, dates, 0, true, 1)
+	if len(rows) != 5 || rows[0].Name != "2024-01" || !math.IsNaN(rows[0].R) {
+		t.Errorf("across rows = %+v, want one per period in order with NaN r", rows)
+	}
+}
@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// Coverage cell states.
+const (
+	cellPresent = '█'
+	cellMissing = '·'
+	cellFailed  = '×'
+)
+
+// coverageRow is one entity's presence across every period.
+type coverageRow struct {
+	county       string
+	municipality string
+	cells        []rune // aligned to coverage.periods
+}
+
+// coverageTotals counts cell states for a single period.
+type coverageTotals struct {
+	present, missing, failed int
+}
+
+type coverage struct {
+	periods  []string
+	unparsed map[string]bool // periods with a PDF but no parsed JSON
+	rows     []coverageRow
+	totals   []coverageTotals // aligned to periods
+}
+
+// buildCoverage lays out every county/municipality seen in records against
+// every period. unparsedPeriods are periods whose PDF is present but never
+// produced JSON; every entity counts as failed there.
+func buildCoverage(records []timeRecord, unparsedPeriods []string) coverage {
+	cov := coverage{unparsed: make(map[string]bool)}
+
+	periodSet := make(map[string]bool)
+	for _, rec := range records {
+		periodSet[rec.date] = true
+	}
+	for _, p := range unparsedPeriods {
+		if !periodSet[p] {
+			periodSet[p] = true
+			cov.unparsed[p] = true
+		}
+	}
+	cov.periods = sortDates(periodSet)
+	periodIdx := make(map[string]int, len(cov.periods))
+	for i, p := range cov.periods {
+		periodIdx[p] = i
+	}
+
+	rows := make(map[string]*coverageRow)
+	for _, rec := range records {
+		col := periodIdx[rec.date]
+		for _, s := range rec.stats {
+			k := entityID(s)
+			r, ok := rows[k]
+			if !ok {
+				r = &coverageRow{county: strings.ToUpper(s.County), municipality: entityLabel(s), cells: make([]rune, len(cov.periods))}
+				for i := range r.cells {
+					r.cells[i] = cellMissing
+				}
+				rows[k] = r
+			}
+			// A parsed page with values wins over a failed duplicate.
+			if hasValues(s) {
+				r.cells[col] = cellPresent
+			} else if r.cells[col] != cellPresent {
+				r.cells[col] = cellFailed
+			}
+		}
+	}
+
+	for _, r := range rows {
+		for p := range cov.unparsed {
+			r.cells[periodIdx[p]] = cellFailed
+		}
+		cov.rows = append(cov.rows, *r)
+	}
+	sort.Slice(cov.rows, func(i, j int) bool {
+		if cov.rows[i].county != cov.rows[j].county {
+			return cov.rows[i].county < cov.rows[j].county
+		}
+		return cov.rows[i].municipality < cov.rows[j].municipality
+	})
+
+	cov.totals = make([]coverageTotals, len(cov.periods))
+	for _, r := range cov.rows {
+		for i, c := range r.cells {
+			switch c {
+			case cellPresent:
+				cov.totals[i].present++
+			case cellFailed:
+				cov.totals[i].failed++
+			default:
+				cov.totals[i].missing++
+			}
+		}
+	}
+	return cov
+}
+
+// hasValues reports whether any of the headline current-period totals
+// parsed as a number. A record without any is a page whose layout the
+// parser didn't understand.
+func hasValues(s parser.MunicipalityStats) bool {
+	for _, row := range []parser.RowData{
+		s.Filings.CurrentPeriod,
+		s.Resolutions.CurrentPeriod,
+		s.Backlog.CurrentPeriod,
+		s.ActivePending.CurrentPeriod,
+	} {
+		if !math.IsNaN(parseNumber(row.GrandTotal)) {
+			return true
+		}
+	}
+	return false
+}
+
+// unparsedPeriods returns the periods of dated PDFs in dir that have no
+// matching JSON file.
+func unparsedPeriods(dir string) ([]string, error) {
+	pdfs, err := filepath.Glob(filepath.Join(dir, "*.pdf"))
+	if err != nil {
+		return nil, err
+	}
+	var periods []string
+	for _, path := range pdfs {
+		m := datePattern.FindStringSubmatch(filepath.Base(path))
+		if m == nil {
+			continue
+		}
+		jsonPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".json"
+		if _, err := os.Stat(jsonPath); err == nil {
+			continue
+		}
+		periods = append(periods, m[1]+"-"+m[2])
+	}
+	return periods, nil
+}
+
+// Coverage implements the "coverage" subcommand.
+func Coverage(args []string) {
+	fs := flag.NewFlagSet("coverage", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	county := fs.String("county", "", "county filter")
+	gapsOnly := fs.Bool("gaps", false, "only list entities with missing or failed periods")
+	missing := fs.Bool("missing", false, "list calendar months with no parsed file and the PDFs to fetch")
+	checkSite := fs.Bool("check-site", false, "with --missing, resolve download URLs from the statistics page")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt coverage [dir] [--county NAME] [--gaps] [--missing [--check-site]]\n\nShow which municipalities have data in which periods.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+
+	records, err := loadRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	unparsed, err := unparsedPeriods(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error globbing directory: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 && len(unparsed) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	if *missing {
+		periods := make(map[string]bool)
+		for _, rec := range records {
+			periods[rec.date] = true
+		}
+		for _, p := range unparsed {
+			periods[p] = true
+		}
+		renderBackfill(*dir, missingPeriods(sortDates(periods)), *checkSite)
+		return
+	}
+
+	if *county != "" {
+		upper := strings.ToUpper(*county)
+		for i, rec := range records {
+			var kept []parser.MunicipalityStats
+			for _, s := range rec.stats {
+				if strings.ToUpper(s.County) == upper {
+					kept = append(kept, s)
+				}
+			}
+			records[i].stats = kept
+		}
+	}
+
+	renderCoverage(buildCoverage(records, unparsed), *gapsOnly)
+}
+
+// coverageCells draws cell states with the current glyph set.
+func coverageCells(cells []rune) string {
+	var sb strings.Builder
+	for _, c := range cells {
+		switch c {
+		case cellPresent:
+			sb.WriteRune(glyphs.present)
+		case cellMissing:
+			sb.WriteString(paint(colorStdout, styleDim, string(glyphs.missing)))
+		case cellFailed:
+			sb.WriteString(paint(colorStdout, styleRed, string(glyphs.failed)))
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func renderCoverage(cov coverage, gapsOnly bool) {
+	labels := make([]string, len(cov.rows))
+	maxName := len("Entity")
+	for i, r := range cov.rows {
+		labels[i] = r.county + " / " + r.municipality
+		if len(labels[i]) > maxName {
+			maxName = len(labels[i])
+		}
+	}
+
+	n := len(cov.periods)
+	if n > 0 {
+		fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("Coverage: %s to %s (%d periods)", cov.periods[0], cov.periods[n-1], n)))
+	}
+	fmt.Printf("%c present   %s missing   %s failed to parse\n\n", glyphs.present,
+		paint(colorStdout, styleDim, string(glyphs.missing)), paint(colorStdout, styleRed, string(glyphs.failed)))
+
+	rowFmt := fmt.Sprintf("%%-%ds  %%s  %%s\n", maxName)
+	fmt.Printf(rowFmt, "", yearAxis(cov.periods), "")
+	fmt.Println(strings.Repeat(glyphs.hrule, maxName+2+n+2+7))
+
+	shown := 0
+	for i, r := range cov.rows {
+		have := strings.Count(string(r.cells), string(cellPresent))
+		if gapsOnly && have == n {
+			continue
+		}
+		fmt.Printf(rowFmt, labels[i], coverageCells(r.cells), fmt.Sprintf("%d/%d", have, n))
+		shown++
+	}
+	if gapsOnly && shown == 0 {
+		fmt.Println("(no gaps)")
+	}
+
+	fmt.Println()
+	fmt.Printf("%-8s  %8s  %8s  %8s\n", "Period", "Present", "Missing", "Failed")
+	for i, p := range cov.periods {
+		t := cov.totals[i]
+		note := ""
+		if cov.unparsed[p] {
+			note = "  (PDF not parsed)"
+		}
+		fmt.Printf("%-8s  %8d  %8d  %8d%s\n", p, t.present, t.missing, t.failed, note)
+	}
+}
+
+// yearAxis labels the matrix columns with the last two digits of the year
+// at the first period of each year, leaving room between labels.
+func yearAxis(periods []string) string {
+	axis := []byte(strings.Repeat(" ", len(periods)))
+	lastYear := ""
+	next := 0
+	for i, p := range periods {
+		year := p[:4]
+		if year == lastYear {
+			continue
+		}
+		lastYear = year
+		if i < next || i+2 > len(axis) {
+			continue
+		}
+		copy(axis[i:], year[2:])
+		next = i + 3
+	}
+	return string(axis)
+}
+
+// missingPeriods returns the calendar months strictly between the first and
+// last of periods (sorted YYYY-MM) that aren't in periods.
+func missingPeriods(periods []string) []string {
+	if len(periods) < 2 {
+		return nil
+	}
+	have := make(map[string]bool, len(periods))
+	for _, p := range periods {
+		have[p] = true
+	}
+	first, err := time.Parse("2006-01", periods[0])
+	if err != nil {
+		return nil
+	}
+	last, err := time.Parse("2006-01", periods[len(periods)-1])
+	if err != nil {
+		return nil
+	}
+	var gaps []string
+	for t := first.AddDate(0, 1, 0); t.Before(last); t = t.AddDate(0, 1, 0) {
+		if p := t.Format("2006-01"); !have[p] {
+			gaps = append(gaps, p)
+		}
+	}
+	return gaps
+}
+
+// renderBackfill lists the missing periods with the PDF each would come
+// from. With checkSite, the statistics page is scraped so periods it links
+// get an exact URL.
+func renderBackfill(dir string, gaps []string, checkSite bool) {
+	if len(gaps) == 0 {
+		fmt.Println("No missing periods.")
+		return
+	}
+
+	var urls map[string]string
+	if checkSite {
+		links, err := fetchPDFLinks(defaultPatterns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		urls = make(map[string]string, len(links))
+		for _, l := range links {
+			urls[l.period] = l.url
+		}
+	}
+
+	fmt.Printf("%d missing periods\n\n", len(gaps))
+	available := 0
+	for _, p := range gaps {
+		source := ""
+		switch {
+		case !checkSite:
+		case urls[p] != "":
+			source = urls[p]
+			available++
+		default:
+			source = "(not linked on statistics page)"
+		}
+		fmt.Println(strings.TrimRight(fmt.Sprintf("%s  %-13s %s", p, reportFileName(p), source), " "))
+	}
+
+	fmt.Println()
+	switch {
+	case !checkSite:
+		fmt.Println("Run with --check-site to look up download URLs.")
+	case available > 0:
+		fmt.Printf("%d of %d are available; fetch them with: municourt download -dir %s\n", available, len(gaps), dir)
+	default:
+		fmt.Println("None of the missing periods are linked on the statistics page.")
+	}
+}
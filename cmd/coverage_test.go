@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestBuildCoverage(t *testing.T) {
+	records := []timeRecord{
+		{date: "2023-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			rateStat("ATLANTIC", "BRIGANTINE", "100", "50", "50%"),
+		}},
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			stat("ATLANTIC", "BRIGANTINE"), // no values parsed
+		}},
+	}
+
+	cov := buildCoverage(records, []string{"2025-06"})
+
+	if len(cov.periods) != 3 || !cov.unparsed["2025-06"] {
+		t.Fatalf("periods = %v, unparsed = %v", cov.periods, cov.unparsed)
+	}
+	want := map[string]string{
+		"ABSECON":    "██×",
+		"BRIGANTINE": "█××",
+	}
+	for _, r := range cov.rows {
+		if got := string(r.cells); got != want[r.municipality] {
+			t.Errorf("%s cells = %q, want %q", r.municipality, got, want[r.municipality])
+		}
+	}
+	if got := cov.totals[1]; got != (coverageTotals{present: 1, failed: 1}) {
+		t.Errorf("2024-06 totals = %+v", got)
+	}
+}
+
+func TestMissingPeriods(t *testing.T) {
+	got := missingPeriods([]string{"2023-11", "2024-01", "2024-03"})
+	want := []string{"2023-12", "2024-02"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("missingPeriods = %v, want %v", got, want)
+	}
+	if got := missingPeriods([]string{"2024-06"}); got != nil {
+		t.Errorf("single period: got %v", got)
+	}
+	if got := reportFileName("2024-02"); got != "munm2402.pdf" {
+		t.Errorf("reportFileName = %q", got)
+	}
+}
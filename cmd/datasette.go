@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// datasetteMetadataPath returns where the metadata for the database at
+// dbPath is written: stats.db gets stats.metadata.json.
+func datasetteMetadataPath(dbPath string) string {
+	return strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + ".metadata.json"
+}
+
+// Friendly names for the recordRows prefixes and caseTypeColumns, used in
+// column descriptions.
+var (
+	sectionDescriptions = map[string]string{
+		"Filings":       "Filings",
+		"Resolutions":   "Resolutions",
+		"Clearance":     "Clearance (resolutions minus filings)",
+		"ClearancePct":  "Clearance rate",
+		"Backlog":       "Backlog (cases pending beyond time goals)",
+		"BacklogPer100": "Backlog per 100 monthly filings",
+		"BacklogPct":    "Backlog as a percentage of active pending",
+		"ActivePending": "Active pending cases",
+	}
+	rowDescriptions = map[string]string{
+		"Prior":     "same months a year earlier",
+		"Current":   "current period",
+		"PctChange": "% change from the prior period",
+	}
+	caseTypeDescriptions = map[string]string{
+		"Indictables":   "indictables",
+		"DPAndPDP":      "disorderly and petty disorderly persons",
+		"OtherCriminal": "other criminal",
+		"CriminalTotal": "criminal total",
+		"DWI":           "DWI",
+		"TrafficMoving": "traffic moving",
+		"Parking":       "parking",
+		"TrafficTotal":  "traffic total",
+		"GrandTotal":    "grand total",
+	}
+)
+
+// describeColumn returns a human description of an export column such as
+// "Backlog_Current_GrandTotal".
+func describeColumn(col string) string {
+	switch col {
+	case "Period":
+		return "Report period, YYYY-MM (the month the report's 12-month window ends)"
+	case "County":
+		return "County"
+	case "Municipality":
+		return "Municipality (or joint court) name as printed in the report"
+	case "EntityID":
+		return "Stable ID of the court, the same under every name it has been printed as"
+	case "DateRange":
+		return "The report's date range as printed"
+	case "Population":
+		return "Census population, interpolated between vintages to the period"
+	}
+	parts := strings.Split(col, "_")
+	if len(parts) != 3 {
+		return ""
+	}
+	section, row := sectionDescriptions[parts[0]], rowDescriptions[parts[1]]
+	if section == "" || row == "" {
+		return ""
+	}
+	if parts[2] == "Label" {
+		return fmt.Sprintf("%s, %s: row label as printed", section, row)
+	}
+	if ct := caseTypeDescriptions[parts[2]]; ct != "" {
+		return fmt.Sprintf("%s, %s: %s", section, row, ct)
+	}
+	return ""
+}
+
+// sqlNumber is an SQL expression reading a text column as a number, for
+// values either as printed ("1,749", "- -") or cleaned.
+func sqlNumber(col string) string {
+	return fmt.Sprintf(`CAST(NULLIF(NULLIF(REPLACE("%s", ',', ''), '- -'), '') AS INTEGER)`, col)
+}
+
+// datasetteQueries returns the canned queries that the exported columns
+// support. They need the current filings, resolutions and backlog totals.
+func datasetteQueries(columns []string) map[string]any {
+	filings, resolutions, backlog := "Filings_Current_GrandTotal", "Resolutions_Current_GrandTotal", "Backlog_Current_GrandTotal"
+	if !contains(columns, filings) || !contains(columns, resolutions) || !contains(columns, backlog) {
+		return nil
+	}
+	totals := fmt.Sprintf("SUM(%s) AS filings, SUM(%s) AS resolutions, SUM(%s) AS backlog",
+		sqlNumber(filings), sqlNumber(resolutions), sqlNumber(backlog))
+	return map[string]any{
+		"statewide_by_period": map[string]string{
+			"title":       "Statewide totals by period",
+			"description": "Grand-total filings, resolutions and backlog summed over every municipality.",
+			"sql":         "SELECT Period, " + totals + " FROM records GROUP BY Period ORDER BY Period",
+		},
+		"county_totals": map[string]string{
+			"title":       "County totals for a period",
+			"description": "Grand totals per county for one period (YYYY-MM).",
+			"sql":         "SELECT County, COUNT(*) AS municipalities, " + totals + " FROM records WHERE Period = :period GROUP BY County ORDER BY County",
+		},
+		"municipality_history": map[string]string{
+			"title":       "One municipality over time",
+			"description": "Grand totals for every period of municipalities whose name contains the search text.",
+			"sql": fmt.Sprintf("SELECT Period, County, Municipality, %s AS filings, %s AS resolutions, %s AS backlog FROM records WHERE Municipality LIKE '%%' || upper(:name) || '%%' ORDER BY Municipality, Period",
+				sqlNumber(filings), sqlNumber(resolutions), sqlNumber(backlog)),
+		},
+		"largest_backlogs": map[string]string{
+			"title":       "Largest backlogs in the latest period",
+			"description": "The 25 municipalities with the most cases beyond time goals in the newest report.",
+			"sql": fmt.Sprintf("SELECT County, Municipality, %s AS backlog, %s AS filings FROM records WHERE Period = (SELECT MAX(Period) FROM records) ORDER BY backlog DESC LIMIT 25",
+				sqlNumber(backlog), sqlNumber(filings)),
+		},
+	}
+}
+
+// writeDatasette adds Datasette conventions to an exported database: a
+// municipalities table, full-text search over municipality names, indexes
+// for the common facets, and a metadata file with descriptions, facets and
+// canned queries. It returns the metadata path.
+func writeDatasette(dbPath string, columns []string) (string, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	stmts := []string{
+		`CREATE INDEX records_period ON records (Period)`,
+		`CREATE INDEX records_place ON records (County, Municipality)`,
+		`CREATE INDEX records_entity ON records (EntityID)`,
+		`CREATE TABLE municipalities AS
+			SELECT County, Municipality, EntityID, MIN(Period) AS FirstPeriod, MAX(Period) AS LastPeriod, COUNT(*) AS Periods
+			FROM records GROUP BY County, Municipality, EntityID ORDER BY County, Municipality`,
+		// External-content FTS tables, named <table>_fts as Datasette
+		// expects, so its search box works on both tables.
+		`CREATE VIRTUAL TABLE records_fts USING fts5(County, Municipality, content="records")`,
+		`INSERT INTO records_fts (rowid, County, Municipality) SELECT rowid, County, Municipality FROM records`,
+		`CREATE VIRTUAL TABLE municipalities_fts USING fts5(County, Municipality, content="municipalities")`,
+		`INSERT INTO municipalities_fts (rowid, County, Municipality) SELECT rowid, County, Municipality FROM municipalities`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return "", fmt.Errorf("adding Datasette tables: %w", err)
+		}
+	}
+
+	recordColumns := make(map[string]string)
+	for _, c := range columns {
+		if d := describeColumn(c); d != "" {
+			recordColumns[c] = d
+		}
+	}
+	name := strings.TrimSuffix(filepath.Base(dbPath), filepath.Ext(dbPath))
+	database := map[string]any{
+		"tables": map[string]any{
+			"records": map[string]any{
+				"description": "One row per municipality per report period. Values are text as printed in the report unless exported with --clean-numbers.",
+				"facets":      []string{"Period", "County"},
+				"sort_desc":   "Period",
+				"fts_table":   "records_fts",
+				"fts_pk":      "rowid",
+				"columns":     recordColumns,
+			},
+			"municipalities": map[string]any{
+				"description": "Every county and municipality with the periods it appears in.",
+				"facets":      []string{"County"},
+				"fts_table":   "municipalities_fts",
+				"fts_pk":      "rowid",
+			},
+			"records_fts":        map[string]any{"hidden": true},
+			"municipalities_fts": map[string]any{"hidden": true},
+		},
+	}
+	if q := datasetteQueries(columns); q != nil {
+		database["queries"] = q
+	}
+	meta := map[string]any{
+		"title":       "New Jersey Municipal Court Statistics",
+		"description": "Caseload statistics parsed from the New Jersey Courts' municipal court reports by municourt " + version() + ".",
+		"source":      "New Jersey Courts",
+		"source_url":  statisticsPageURL,
+		"databases":   map[string]any{name: database},
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := datasetteMetadataPath(dbPath)
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
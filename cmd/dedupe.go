@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -165,8 +170,8 @@ func deduplicateMunicipalities(parsed []parseResult) {
 	acceptAll := false
 	for _, c := range candidates {
 		if acceptAll {
-			fmt.Fprintf(os.Stderr, "  %s → %s: %s (%d) + %s (%d)\n",
-				c.county, c.nameA, c.nameB, len(c.datesB), c.nameA, len(c.datesA))
+			fmt.Fprintf(os.Stderr, "  %s %s %s: %s (%d) + %s (%d)\n",
+				c.county, glyphs.arrow, c.nameA, c.nameB, len(c.datesB), c.nameA, len(c.datesA))
 			merges[muniKey{c.county, c.nameB}] = c.nameA
 			continue
 		}
@@ -174,7 +179,7 @@ func deduplicateMunicipalities(parsed []parseResult) {
 		fmt.Fprintf(os.Stderr, "\nPotential duplicate in %s county:\n", c.county)
 		fmt.Fprintf(os.Stderr, "  %-30s %s\n", c.nameA, formatDateRange(c.datesA))
 		fmt.Fprintf(os.Stderr, "  %-30s %s\n", c.nameB, formatDateRange(c.datesB))
-		fmt.Fprintf(os.Stderr, "Merge %q → %q? [y/N/a(ll)]: ", c.nameB, c.nameA)
+		fmt.Fprintf(os.Stderr, "Merge %q %s %q? [y/N/a(ll)]: ", c.nameB, glyphs.arrow, c.nameA)
 
 		if !scanner.Scan() {
 			break
@@ -206,3 +211,110 @@ func deduplicateMunicipalities(parsed []parseResult) {
 	}
 	fmt.Fprintf(os.Stderr, "dedupe: renamed %d entries\n", applied)
 }
+
+// candidateReport is the flat, spreadsheet-friendly form of a
+// duplicateCandidate written by "dedupe --report".
+type candidateReport struct {
+	County           string `json:"county"`
+	Keeper           string `json:"keeper"`
+	Duplicate        string `json:"duplicate"`
+	KeeperFirst      string `json:"keeperFirst"`
+	KeeperLast       string `json:"keeperLast"`
+	KeeperPeriods    int    `json:"keeperPeriods"`
+	DuplicateFirst   string `json:"duplicateFirst"`
+	DuplicateLast    string `json:"duplicateLast"`
+	DuplicatePeriods int    `json:"duplicatePeriods"`
+}
+
+func candidateReports(candidates []duplicateCandidate) []candidateReport {
+	reports := make([]candidateReport, len(candidates))
+	for i, c := range candidates {
+		reports[i] = candidateReport{
+			County:           c.county,
+			Keeper:           c.nameA,
+			Duplicate:        c.nameB,
+			KeeperFirst:      c.datesA[0],
+			KeeperLast:       c.datesA[len(c.datesA)-1],
+			KeeperPeriods:    len(c.datesA),
+			DuplicateFirst:   c.datesB[0],
+			DuplicateLast:    c.datesB[len(c.datesB)-1],
+			DuplicatePeriods: len(c.datesB),
+		}
+	}
+	return reports
+}
+
+// Dedupe implements the "dedupe" subcommand: list municipality name variants
+// in already-parsed JSON files that likely refer to the same entity, without
+// modifying anything.
+func Dedupe(args []string) {
+	fs := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	reportOut := fs.String("report", "", "write candidates to this file (.json or .csv) instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt dedupe [dir] [--report candidates.json]\n\nList likely duplicate municipality names without modifying any files.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+
+	records, err := loadRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	parsed := make([]parseResult, len(records))
+	for i, rec := range records {
+		parsed[i] = parseResult{date: rec.date, results: rec.stats}
+	}
+	reports := candidateReports(findDuplicates(parsed))
+
+	if *reportOut == "" {
+		for _, r := range reports {
+			fmt.Printf("%-12s %-30s %s to %s (%d)\n", r.County, r.Keeper, r.KeeperFirst, r.KeeperLast, r.KeeperPeriods)
+			fmt.Printf("%-12s %-30s %s to %s (%d)\n\n", "", r.Duplicate, r.DuplicateFirst, r.DuplicateLast, r.DuplicatePeriods)
+		}
+		fmt.Fprintf(os.Stderr, "%d candidates\n", len(reports))
+		return
+	}
+
+	if err := writeCandidateReport(*reportOut, reports); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing report: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d candidates to %s\n", len(reports), *reportOut)
+}
+
+func writeCandidateReport(path string, reports []candidateReport) error {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w := csv.NewWriter(f)
+		w.Write([]string{"County", "Keeper", "Duplicate", "KeeperFirst", "KeeperLast", "KeeperPeriods",
+			"DuplicateFirst", "DuplicateLast", "DuplicatePeriods"})
+		for _, r := range reports {
+			w.Write([]string{r.County, r.Keeper, r.Duplicate, r.KeeperFirst, r.KeeperLast, strconv.Itoa(r.KeeperPeriods),
+				r.DuplicateFirst, r.DuplicateLast, strconv.Itoa(r.DuplicatePeriods)})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
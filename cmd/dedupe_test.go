@@ -145,3 +145,28 @@ func TestFindDuplicates_KeeperIsMoreRecent(t *testing.T) {
 		t.Errorf("nameA = %q, want CLIFTON CITY (more recent)", candidates[0].nameA)
 	}
 }
+
+func TestCandidateReports(t *testing.T) {
+	candidates := []duplicateCandidate{{
+		county: "HUDSON",
+		nameA:  "GUTTENBERG",
+		nameB:  "GUTTENBERG TOWN",
+		datesA: []string{"2010-07", "2011-07", "2012-07"},
+		datesB: []string{"2005-07"},
+	}}
+	got := candidateReports(candidates)
+	want := candidateReport{
+		County:           "HUDSON",
+		Keeper:           "GUTTENBERG",
+		Duplicate:        "GUTTENBERG TOWN",
+		KeeperFirst:      "2010-07",
+		KeeperLast:       "2012-07",
+		KeeperPeriods:    3,
+		DuplicateFirst:   "2005-07",
+		DuplicateLast:    "2005-07",
+		DuplicatePeriods: 1,
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("candidateReports = %+v, want %+v", got, want)
+	}
+}
@@ -1,119 +1,513 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
 )
 
-var hrefPattern = regexp.MustCompile(`href="([^"]*munm(\d{4})\.pdf)"`)
+// builtinPatterns are the report file names the courts have used, newest
+// first. {yyyy}, {yy} and {mm} stand for the period's year and month.
+var builtinPatterns = []string{
+	"munm{yy}{mm}.pdf",
+	"munm{yyyy}{mm}.pdf",
+	"mun{yy}{mm}.pdf",
+	"munm-{yyyy}-{mm}.pdf",
+}
+
+// urlPattern is a compiled report file name pattern.
+type urlPattern struct {
+	source string
+	name   *regexp.Regexp // matches the file name alone
+	href   *regexp.Regexp // matches an href attribute linking to it
+}
+
+var patternFields = map[string]string{
+	"{yyyy}": `(?P<yyyy>\d{4})`,
+	"{yy}":   `(?P<yy>\d{2})`,
+	"{mm}":   `(?P<mm>\d{2})`,
+}
+
+var patternFieldRe = regexp.MustCompile(`\{yyyy\}|\{yy\}|\{mm\}`)
+
+func compilePattern(p string) (urlPattern, error) {
+	if !strings.Contains(p, "{mm}") || !(strings.Contains(p, "{yy}") || strings.Contains(p, "{yyyy}")) {
+		return urlPattern{}, fmt.Errorf("pattern %q must contain {mm} and {yy} or {yyyy}", p)
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range patternFieldRe.FindAllStringIndex(p, -1) {
+		b.WriteString(regexp.QuoteMeta(p[last:loc[0]]))
+		b.WriteString(patternFields[p[loc[0]:loc[1]]])
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(p[last:]))
+	expr := b.String()
+
+	name, err := regexp.Compile(`(?i)` + expr)
+	if err != nil {
+		return urlPattern{}, fmt.Errorf("pattern %q: %w", p, err)
+	}
+	href := regexp.MustCompile(`(?i)href="([^"]*` + expr + `)"`)
+	return urlPattern{source: p, name: name, href: href}, nil
+}
+
+// matchPeriod returns the YYYY-MM encoded in a match of a compiled pattern.
+func matchPeriod(re *regexp.Regexp, m []string) string {
+	var year, month string
+	for i, g := range re.SubexpNames() {
+		switch g {
+		case "yyyy":
+			year = m[i]
+		case "yy":
+			year = "20" + m[i]
+		case "mm":
+			month = m[i]
+		}
+	}
+	return year + "-" + month
+}
+
+// compilePatterns compiles the user's patterns followed by the built-in ones.
+func compilePatterns(extra []string) ([]urlPattern, error) {
+	var patterns []urlPattern
+	for _, p := range append(append([]string(nil), extra...), builtinPatterns...) {
+		up, err := compilePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, up)
+	}
+	return patterns, nil
+}
+
+// defaultPatterns is the compiled built-in list.
+var defaultPatterns = func() []urlPattern {
+	p, err := compilePatterns(nil)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}()
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
 
 // Download implements the "download" subcommand: scrape the NJ Courts
 // statistics page for municipal court PDFs and download them.
 func Download(args []string) {
 	fs := flag.NewFlagSet("download", flag.ExitOnError)
 	dir := fs.String("dir", ".", "output directory for downloaded PDFs")
+	var extraPatterns stringList
+	fs.Var(&extraPatterns, "pattern", "report file name pattern using {yyyy}, {yy}, {mm} (repeatable; tried before the built-ins)")
+	verify := fs.Bool("verify-existing", false, "HEAD existing files and flag ones the server has replaced")
+	refresh := fs.Bool("refresh", false, "with -verify-existing, re-download replaced files")
+	noCache := fs.Bool("no-cache", false, "always re-scrape the statistics page, even if unchanged since the last run")
+	arch := addArchiveFlags(fs)
+	netFlags := addHTTPFlags(fs)
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: municourt download [-dir path]\n")
+		fmt.Fprintf(os.Stderr, "Usage: municourt download [-dir path] [-pattern munm{yy}{mm}.pdf ...] [-verify-existing [-refresh]] [-archive] [-archive-page URL ...] [-wayback [-wayback-limit N] [-wayback-delay 1s]] [-proxy URL] [-timeout 60s] [-insecure]\n")
 		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nBuilt-in patterns: %s\n", strings.Join(builtinPatterns, ", "))
 	}
 	fs.Parse(args)
 
+	patterns, err := compilePatterns(extraPatterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --pattern: %v\n", err)
+		os.Exit(1)
+	}
+	if err := netFlags.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := arch.check(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
 	if err := os.MkdirAll(*dir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "error creating output directory: %v\n", err)
 		os.Exit(1)
 	}
 
-	const pageURL = "https://www.njcourts.gov/public/statistics"
-	fmt.Fprintf(os.Stderr, "Fetching %s\n", pageURL)
+	cachePath := filepath.Join(*dir, pageCacheName)
+	var cache pageCache
+	if !*noCache {
+		cache = loadPageCache(cachePath)
+	}
 
-	req, err := http.NewRequest("GET", pageURL, nil)
+	fmt.Fprintf(os.Stderr, "Fetching %s\n", statisticsPageURL)
+	page, unchanged, err := fetchStatisticsPage(statisticsPageURL, cache)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error creating request: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; municourt/1.0)")
+	// An unchanged page still lists what to check for on disk, with the
+	// current patterns: a deleted PDF or a new --pattern isn't its news.
+	if unchanged {
+		fmt.Fprintf(os.Stderr, "Statistics page unchanged since last run; using the cached copy\n")
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	base, _ := url.Parse(statisticsPageURL)
+	links := extractPDFLinks(page.Body, base, patterns)
+	if arch.enabled() {
+		links = mergeLinks(links, arch.discover(patterns))
+	}
+	if len(links) == 0 {
+		fmt.Fprintf(os.Stderr, "no municipal court PDF links found on page\n")
+		os.Exit(1)
+	}
+
+	m, err := loadManifest(*dir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error fetching statistics page: %v\n", err)
+		fmt.Fprintf(os.Stderr, "error loading manifest: %v\n", err)
 		os.Exit(1)
 	}
+
+	var downloaded, skipped, changed, failed int
+	dirty := false // m has entries to save
+	for _, link := range links {
+		outName := "municipal-courts-" + link.period + ".pdf"
+		outPath := filepath.Join(*dir, outName)
+
+		if info, err := os.Stat(outPath); err == nil {
+			if !*verify {
+				fmt.Fprintf(os.Stderr, "skip %s (already exists)\n", outName)
+				skipped++
+				continue
+			}
+			recorded, ok := m[outName]
+			if !ok {
+				// Downloaded before the manifest existed: all we know is the size.
+				recorded = manifestEntry{URL: link.url, ContentLength: info.Size()}
+			}
+			remote, err := headFile(link.url)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error checking %s: %v\n", link.url, err)
+				failed++
+				continue
+			}
+			reason, replaced := remoteChanged(recorded, remote)
+			if !replaced {
+				fmt.Fprintf(os.Stderr, "ok %s\n", outName)
+				skipped++
+				// Keep what the HEAD told us, so later checks of a file
+				// from before the manifest needn't rely on its size alone.
+				if e, update := verifiedEntry(recorded, ok, remote, link.url, info); update {
+					m[outName] = e
+					dirty = true
+				}
+				continue
+			}
+			changed++
+			fmt.Fprintf(os.Stderr, "changed %s (%s)\n", outName, reason)
+			if !*refresh {
+				continue
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "downloading %s -> %s\n", link.url, outName)
+
+		src := link.url
+		remote, err := downloadReport(src, *dir, outName)
+		if err != nil && link.fallback != "" {
+			fmt.Fprintf(os.Stderr, "error downloading %s: %v; trying the Wayback copy\n", link.url, err)
+			src = link.fallback
+			remote, err = downloadReport(src, *dir, outName)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error downloading %s: %v\n", src, err)
+			failed++
+			continue
+		}
+		m[outName] = remote.entry(src)
+		downloaded++
+		dirty = true
+	}
+
+	if dirty {
+		if err := m.save(*dir); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing manifest: %v\n", err)
+		}
+	}
+	// Only remember the page once everything it links is on disk, so a
+	// failed download is retried next time even if the page hasn't changed.
+	if failed == 0 && (page.ETag != "" || page.LastModified != "") {
+		if err := page.save(cachePath); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing page cache: %v\n", err)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d files failed and will be retried on the next run\n", failed)
+	}
+	if *verify {
+		fmt.Fprintf(os.Stderr, "Done: %d downloaded, %d skipped, %d changed on server\n", downloaded, skipped, changed)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Done: %d downloaded, %d skipped\n", downloaded, skipped)
+}
+
+const statisticsPageURL = "https://www.njcourts.gov/public/statistics"
+
+// pdfLink is a municipal court PDF linked from the statistics page.
+type pdfLink struct {
+	period   string // YYYY-MM
+	url      string
+	fallback string // Wayback copy to try if url fails; see archiveFlags
+}
+
+// pageCacheName is the file, inside the download directory, holding the
+// last statistics page body and its validators.
+const pageCacheName = ".statistics-page.json"
+
+// pageCache is a fetched copy of the statistics page.
+type pageCache struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Body         string `json:"body"`
+}
+
+// loadPageCache reads a cached page; any error yields an empty cache.
+func loadPageCache(path string) pageCache {
+	var c pageCache
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &c)
+	}
+	return c
+}
+
+func (c pageCache) save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// fetchStatisticsPage fetches pageURL, revalidating against cache when it
+// has an ETag or Last-Modified. If the server answers 304 Not Modified, the
+// cached copy is returned with unchanged set.
+func fetchStatisticsPage(pageURL string, cache pageCache) (page pageCache, unchanged bool, err error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return pageCache{}, false, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; municourt/1.0)")
+	if cache.Body != "" {
+		if cache.ETag != "" {
+			req.Header.Set("If-None-Match", cache.ETag)
+		}
+		if cache.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cache.LastModified)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return pageCache{}, false, fmt.Errorf("error fetching statistics page: %w", err)
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cache.Body != "" {
+		return cache, true, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		fmt.Fprintf(os.Stderr, "unexpected status %d fetching statistics page\n", resp.StatusCode)
-		os.Exit(1)
+		return pageCache{}, false, fmt.Errorf("unexpected status %d fetching statistics page", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error reading response body: %v\n", err)
-		os.Exit(1)
+		return pageCache{}, false, fmt.Errorf("error reading response body: %w", err)
 	}
+	return pageCache{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         string(body),
+	}, false, nil
+}
 
-	matches := hrefPattern.FindAllSubmatch(body, -1)
-	if len(matches) == 0 {
-		fmt.Fprintf(os.Stderr, "no municipal court PDF links found on page\n")
-		os.Exit(1)
+// fetchPDFLinks scrapes the statistics page for municipal court PDF links
+// matching any of patterns.
+func fetchPDFLinks(patterns []urlPattern) ([]pdfLink, error) {
+	page, _, err := fetchStatisticsPage(statisticsPageURL, pageCache{})
+	if err != nil {
+		return nil, err
 	}
+	base, _ := url.Parse(statisticsPageURL)
+	return extractPDFLinks(page.Body, base, patterns), nil
+}
 
-	var downloaded, skipped int
-	for _, m := range matches {
-		href := string(m[1])
-		yymm := string(m[2])
-		year := "20" + yymm[:2]
-		month := yymm[2:]
+// extractPDFLinks finds links in page matching any of patterns, resolved
+// against base. A period linked under several patterns keeps the first.
+func extractPDFLinks(page string, base *url.URL, patterns []urlPattern) []pdfLink {
+	var links []pdfLink
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		for _, m := range p.href.FindAllStringSubmatch(page, -1) {
+			period := matchPeriod(p.href, m)
+			if seen[period] {
+				continue
+			}
+			ref, err := url.Parse(m[1])
+			if err != nil {
+				continue
+			}
+			seen[period] = true
+			links = append(links, pdfLink{period: period, url: base.ResolveReference(ref).String()})
+		}
+	}
+	return links
+}
 
-		outName := fmt.Sprintf("municipal-courts-%s-%s.pdf", year, month)
-		outPath := filepath.Join(*dir, outName)
+// reportFileName returns the name the courts publish the PDF for period
+// (YYYY-MM) under, e.g. munm2406.pdf.
+func reportFileName(period string) string {
+	return "munm" + period[2:4] + period[5:7] + ".pdf"
+}
 
-		if _, err := os.Stat(outPath); err == nil {
-			fmt.Fprintf(os.Stderr, "skip %s (already exists)\n", outName)
-			skipped++
-			continue
+// downloadReport downloads url to outName in dir, validating it first. The
+// download goes beside the target so a failed refresh leaves the old copy,
+// and a file failing validation is quarantined.
+func downloadReport(url, dir, outName string) (remoteInfo, error) {
+	tmpPath := filepath.Join(dir, outName+".part")
+	remote, err := downloadFile(url, tmpPath)
+	if err == nil {
+		if verr := validatePDF(tmpPath); verr != nil {
+			if dest, qerr := quarantine(tmpPath, dir, outName); qerr == nil {
+				return remoteInfo{}, fmt.Errorf("invalid PDF: %v; moved to %s", verr, dest)
+			}
+			err = fmt.Errorf("invalid PDF: %w", verr)
 		}
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, filepath.Join(dir, outName))
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return remoteInfo{}, err
+	}
+	return remote, nil
+}
 
-		fullURL := "https://www.njcourts.gov" + href
-		fmt.Fprintf(os.Stderr, "downloading %s -> %s\n", fullURL, outName)
+// quarantineDir is the subdirectory of the download directory that files
+// failing validatePDF are moved to, for inspection rather than parsing.
+const quarantineDir = "failed"
 
-		if err := downloadFile(fullURL, outPath); err != nil {
-			fmt.Fprintf(os.Stderr, "error downloading %s: %v\n", fullURL, err)
-			continue
+// errFoundDataPage stops validatePDF's page scan at the first data page.
+var errFoundDataPage = errors.New("found a data page")
+
+// validatePDF checks that a downloaded file is a whole report: it starts
+// with a PDF header, ends with a trailer pdfcpu can read, and has at least
+// one municipality data page. Truncated downloads and HTML error pages
+// served as PDFs fail here instead of weeks later in parse.
+func validatePDF(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	head := make([]byte, 5)
+	_, err = io.ReadFull(f, head)
+	var tail []byte
+	if err == nil {
+		tail, err = readTail(f, 1024)
+	}
+	f.Close()
+	if err != nil || string(head) != "%PDF-" {
+		return fmt.Errorf("not a PDF (missing %%PDF header)")
+	}
+	// A truncated download loses the trailer, which always ends the file.
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return fmt.Errorf("no trailer (truncated download?)")
+	}
+
+	err = parser.ForEachPage(path, func(page parser.PageData) error {
+		if parser.ContainsFilings(parser.ExtractTextItems(page)) {
+			return errFoundDataPage
 		}
-		downloaded++
+		return nil
+	})
+	switch {
+	case err == errFoundDataPage:
+		return nil
+	case err != nil:
+		return fmt.Errorf("unreadable PDF: %w", err)
 	}
+	return fmt.Errorf("no municipal court data pages")
+}
 
-	fmt.Fprintf(os.Stderr, "Done: %d downloaded, %d skipped\n", downloaded, skipped)
+// readTail returns up to the last n bytes of f.
+func readTail(f *os.File, n int64) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	off := max(info.Size()-n, 0)
+	buf := make([]byte, info.Size()-off)
+	_, err = f.ReadAt(buf, off)
+	return buf, err
+}
+
+// quarantine moves the file at path to name in the quarantine directory
+// under dir, replacing any earlier failure of the same name, and returns
+// the new path.
+func quarantine(path, dir, name string) (string, error) {
+	qdir := filepath.Join(dir, quarantineDir)
+	if err := os.MkdirAll(qdir, 0755); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(qdir, name)
+	if err := os.Rename(path, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
 }
 
-func downloadFile(url, dest string) error {
+// downloadFile saves url to dest, returning the size written and the
+// server's Last-Modified for the manifest.
+func downloadFile(url, dest string) (remoteInfo, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return err
+		return remoteInfo{}, err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; municourt/1.0)")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return err
+		return remoteInfo{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("status %d", resp.StatusCode)
+		return remoteInfo{}, fmt.Errorf("status %d", resp.StatusCode)
 	}
 
 	f, err := os.Create(dest)
 	if err != nil {
-		return err
+		return remoteInfo{}, err
 	}
-	defer f.Close()
-
-	_, err = io.Copy(f, resp.Body)
-	return err
+	n, err := io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return remoteInfo{contentLength: n, lastModified: resp.Header.Get("Last-Modified")}, err
 }
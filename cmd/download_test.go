@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExtractPDFLinks(t *testing.T) {
+	page := `
+<a href="/sites/default/files/munm2406.pdf">June 2024</a>
+<a href="https://archive.example.com/old/MUNM-2009-06.pdf">June 2009</a>
+<a href="/sites/default/files/report-2401.pdf">custom</a>
+<a href="/sites/default/files/munm2406.pdf">duplicate</a>
+<a href="/sites/default/files/other.pdf">unrelated</a>`
+
+	patterns, err := compilePatterns([]string{"report-{yy}{mm}.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse(statisticsPageURL)
+	links := extractPDFLinks(page, base, patterns)
+
+	want := map[string]string{
+		"2024-01": "https://www.njcourts.gov/sites/default/files/report-2401.pdf",
+		"2024-06": "https://www.njcourts.gov/sites/default/files/munm2406.pdf",
+		"2009-06": "https://archive.example.com/old/MUNM-2009-06.pdf",
+	}
+	if len(links) != len(want) {
+		t.Fatalf("got %d links, want %d: %v", len(links), len(want), links)
+	}
+	for _, l := range links {
+		if want[l.period] != l.url {
+			t.Errorf("%s: url = %q, want %q", l.period, l.url, want[l.period])
+		}
+	}
+}
+
+func TestCompilePattern_RequiresPeriodFields(t *testing.T) {
+	if _, err := compilePattern("munm.pdf"); err == nil {
+		t.Error("expected error for pattern without period fields")
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	if _, err := newHTTPClient("not a url", time.Second, false); err == nil {
+		t.Error("expected error for invalid proxy")
+	}
+
+	// A server that never answers must not hang the client.
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	c, err := newHTTPClient("", 50*time.Millisecond, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(srv.URL); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestFetchStatisticsPage_ETag(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`<a href="/munm2406.pdf">June</a>`))
+	}))
+	defer srv.Close()
+
+	page, unchanged, err := fetchStatisticsPage(srv.URL, pageCache{})
+	if err != nil || unchanged || page.ETag != `"v1"` {
+		t.Fatalf("first fetch = %+v, %v, %v", page, unchanged, err)
+	}
+
+	again, unchanged, err := fetchStatisticsPage(srv.URL, page)
+	if err != nil || !unchanged || again.Body != page.Body {
+		t.Fatalf("revalidation = %+v, %v, %v", again, unchanged, err)
+	}
+	if hits != 2 {
+		t.Errorf("hits = %d, want 2", hits)
+	}
+}
+
+func TestValidatePDF(t *testing.T) {
+	if err := validatePDF("../parser/testdata/page.pdf"); err != nil {
+		t.Errorf("page.pdf: %v", err)
+	}
+	if err := validatePDF("../parser/testdata/cover.pdf"); err == nil {
+		t.Error("cover.pdf: expected an error for a report without data pages")
+	}
+
+	data, err := os.ReadFile("../parser/testdata/page.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{
+		"truncated.pdf": data[:len(data)/2],
+		"html.pdf":      []byte("<html><body>Service Unavailable</body></html>"),
+		"empty.pdf":     nil,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := validatePDF(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	part := filepath.Join(dir, "municipal-courts-2024-06.pdf.part")
+	if err := os.WriteFile(part, []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest, err := quarantine(part, dir, "municipal-courts-2024-06.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "failed", "municipal-courts-2024-06.pdf"); dest != want {
+		t.Errorf("dest = %s, want %s", dest, want)
+	}
+	if _, err := os.Stat(part); !os.IsNotExist(err) {
+		t.Error("original file still present")
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// sparkBuckets groups sorted periods (YYYY-MM) for a sparkline that has
+// room for at most limit values. It returns each period's bucket index and
+// a label for the bucketing ("" when every period keeps its own value).
+//
+// mode is "none", "quarterly", "yearly", a number of periods per bucket, or
+// "auto", which leaves the periods alone if they fit and otherwise tries
+// quarterly, then yearly, then as many periods per bucket as it takes.
+// With courtYear, quarters and years follow the July–June court year.
+func sparkBuckets(dates []string, mode string, limit int, courtYear bool) ([]int, string) {
+	byKey := func(key func(string) string) []int {
+		idx := make([]int, len(dates))
+		for i, d := range dates {
+			if i > 0 {
+				idx[i] = idx[i-1]
+				if key(d) != key(dates[i-1]) {
+					idx[i]++
+				}
+			}
+		}
+		return idx
+	}
+	quarterly := func() []int {
+		return byKey(func(d string) string { return intervalKey(d, withCourtYear("quarter", courtYear)) })
+	}
+	yearly := func() []int {
+		return byKey(func(d string) string { return intervalKey(d, withCourtYear("year", courtYear)) })
+	}
+	quarterLabel, yearLabel := "quarterly", "yearly"
+	if courtYear {
+		quarterLabel, yearLabel = "by court quarter", "by court year"
+	}
+	every := func(n int) []int {
+		idx := make([]int, len(dates))
+		for i := range idx {
+			idx[i] = i / n
+		}
+		return idx
+	}
+	buckets := func(idx []int) int {
+		if len(idx) == 0 {
+			return 0
+		}
+		return idx[len(idx)-1] + 1
+	}
+
+	switch mode {
+	case "none":
+		return every(1), ""
+	case "quarterly":
+		return quarterly(), quarterLabel
+	case "yearly":
+		return yearly(), yearLabel
+	case "auto":
+		if len(dates) <= limit {
+			return every(1), ""
+		}
+		if idx := quarterly(); buckets(idx) <= limit {
+			return idx, quarterLabel
+		}
+		if idx := yearly(); buckets(idx) <= limit {
+			return idx, yearLabel
+		}
+		n := (len(dates) + limit - 1) / max(limit, 1)
+		return every(n), fmt.Sprintf("every %d periods", n)
+	}
+	n, _ := strconv.Atoi(mode)
+	if n <= 1 {
+		return every(1), ""
+	}
+	return every(n), fmt.Sprintf("every %d periods", n)
+}
+
+// validDownsample reports whether mode is accepted by sparkBuckets.
+func validDownsample(mode string) bool {
+	switch mode {
+	case "auto", "none", "quarterly", "yearly":
+		return true
+	}
+	n, err := strconv.Atoi(mode)
+	return err == nil && n >= 1
+}
+
+// bucketValues averages vals within each bucket from sparkBuckets, skipping
+// NaN; a bucket with no values is NaN.
+func bucketValues(vals []float64, idx []int) []float64 {
+	if len(idx) == 0 {
+		return nil
+	}
+	out := make([]float64, idx[len(idx)-1]+1)
+	counts := make([]int, len(out))
+	for i, v := range vals {
+		if !math.IsNaN(v) {
+			out[idx[i]] += v
+			counts[idx[i]]++
+		}
+	}
+	for b := range out {
+		if counts[b] == 0 {
+			out[b] = math.NaN()
+		} else {
+			out[b] /= float64(counts[b])
+		}
+	}
+	return out
+}
+
+// bucketPoints fits sorted points into limit columns of a line chart: if
+// there are more, they are averaged into buckets as sparkBuckets does in
+// "auto" mode, each dated by its first period and marked if any of its
+// periods is. label describes the bucketing, "" if the points fit.
+func bucketPoints(points []dataPoint, marked []bool, limit int) ([]dataPoint, []bool, string) {
+	if len(points) <= limit {
+		return points, marked, ""
+	}
+	dates := make([]string, len(points))
+	vals := make([]float64, len(points))
+	for i, p := range points {
+		dates[i], vals[i] = p.date, p.value
+	}
+	idx, label := sparkBuckets(dates, "auto", limit, false)
+	avg := bucketValues(vals, idx)
+	bucketed := make([]dataPoint, len(avg))
+	bucketMarked := make([]bool, len(avg))
+	for i := len(idx) - 1; i >= 0; i-- {
+		bucketed[idx[i]] = dataPoint{date: dates[i], value: avg[idx[i]]}
+		bucketMarked[idx[i]] = bucketMarked[idx[i]] || marked[i]
+	}
+	return bucketed, bucketMarked, label
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSparkBuckets(t *testing.T) {
+	dates := []string{"2020-01", "2020-02", "2020-04", "2020-12", "2021-01", "2021-02"}
+	tests := []struct {
+		mode      string
+		limit     int
+		wantIdx   []int
+		wantLabel string
+	}{
+		{"auto", 6, []int{0, 1, 2, 3, 4, 5}, ""},
+		{"auto", 5, []int{0, 0, 1, 2, 3, 3}, "quarterly"},
+		{"auto", 2, []int{0, 0, 0, 0, 1, 1}, "yearly"},
+		{"auto", 1, []int{0, 0, 0, 0, 0, 0}, "every 6 periods"},
+		{"none", 1, []int{0, 1, 2, 3, 4, 5}, ""},
+		{"yearly", 100, []int{0, 0, 0, 0, 1, 1}, "yearly"},
+		{"4", 100, []int{0, 0, 0, 0, 1, 1}, "every 4 periods"},
+	}
+	for _, tt := range tests {
+		idx, label := sparkBuckets(dates, tt.mode, tt.limit, false)
+		if !reflect.DeepEqual(idx, tt.wantIdx) || label != tt.wantLabel {
+			t.Errorf("sparkBuckets(%s, %d) = %v %q, want %v %q", tt.mode, tt.limit, idx, label, tt.wantIdx, tt.wantLabel)
+		}
+	}
+}
+
+func TestBucketValues(t *testing.T) {
+	got := bucketValues([]float64{1, 3, math.NaN(), math.NaN(), 10}, []int{0, 0, 1, 1, 2})
+	if len(got) != 3 || got[0] != 2 || !math.IsNaN(got[1]) || got[2] != 10 {
+		t.Errorf("bucketValues = %v", got)
+	}
+}
+
+func TestBucketPoints(t *testing.T) {
+	var points []dataPoint
+	for i, d := range []string{"2020-01", "2020-02", "2020-03", "2020-04", "2020-05", "2020-06"} {
+		points = append(points, dataPoint{date: d, value: float64(i + 1)})
+	}
+	marked := []bool{false, false, false, false, true, false}
+
+	got, gotMarked, label := bucketPoints(points, marked, 6)
+	if label != "" || len(got) != 6 {
+		t.Errorf("fitting points: %d points, label %q", len(got), label)
+	}
+
+	got, gotMarked, label = bucketPoints(points, marked, 3)
+	want := []dataPoint{{"2020-01", 2}, {"2020-04", 5}}
+	if label != "quarterly" || !reflect.DeepEqual(got, want) || !reflect.DeepEqual(gotMarked, []bool{false, true}) {
+		t.Errorf("bucketPoints(3) = %v %v %q, want %v [false true] quarterly", got, gotMarked, label, want)
+	}
+}
+
+func TestValidDownsample(t *testing.T) {
+	for _, m := range []string{"auto", "none", "quarterly", "yearly", "1", "12"} {
+		if !validDownsample(m) {
+			t.Errorf("validDownsample(%q) = false", m)
+		}
+	}
+	for _, m := range []string{"", "monthly", "0", "-3"} {
+		if validDownsample(m) {
+			t.Errorf("validDownsample(%q) = true", m)
+		}
+	}
+}
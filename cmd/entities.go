@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// entityID returns the stable ID of s's court, which joins its records
+// across periods whatever each report calls it. Records built without going
+// through the loader have theirs looked up.
+func entityID(s parser.MunicipalityStats) string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return parser.EntityID(s.County, s.Municipality)
+}
+
+// entityLabel returns the name to show for s's court: its registry name, or
+// the name as printed for a court the registry doesn't know.
+func entityLabel(s parser.MunicipalityStats) string {
+	if e, ok := parser.EntityByID(entityID(s)); ok {
+		return e.Name
+	}
+	return strings.ToUpper(s.Municipality)
+}
+
+// nameLabel is entityLabel for a court given by county and any of its
+// names.
+func nameLabel(county, name string) string {
+	if e, ok := parser.LookupEntity(county, name); ok {
+		return e.Name
+	}
+	return strings.ToUpper(name)
+}
+
+// matchesEntity reports whether s is the court named name, under any of the
+// names it has appeared as.
+func matchesEntity(s parser.MunicipalityStats, name string) bool {
+	return strings.ToUpper(s.Municipality) == name || entityID(s) == parser.EntityID(s.County, name)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+func TestEntityJoin(t *testing.T) {
+	old := stat("OCEAN", "Dover Twp")
+	cur := stat("OCEAN", "TOMS RIVER")
+	if entityID(old) != entityID(cur) {
+		t.Errorf("IDs differ: %q, %q", entityID(old), entityID(cur))
+	}
+	if got := entityLabel(old); got != "TOMS RIVER" {
+		t.Errorf("entityLabel = %q, want TOMS RIVER", got)
+	}
+	if !matchesEntity(old, "TOMS RIVER") || !matchesEntity(cur, "DOVER TWP") {
+		t.Error("names of one court don't match each other")
+	}
+	if matchesEntity(cur, "SOUTH TOMS RIVER") {
+		t.Error("TOMS RIVER matches SOUTH TOMS RIVER")
+	}
+
+	unknown := stat("SALEM", "New Court")
+	if got := entityLabel(unknown); got != "NEW COURT" {
+		t.Errorf("entityLabel(unknown) = %q", got)
+	}
+}
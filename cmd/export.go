@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// exportWriter writes one table in a particular file format. WriteHeader is
+// called once before any rows; Close finishes the file.
+type exportWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []string) error
+	Close() error
+}
+
+// exportFormat describes one --format choice. Formats that can't stream to
+// stdout set needsFile.
+type exportFormat struct {
+	open      func(path string) (exportWriter, error)
+	needsFile bool
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":     {open: newCSVExport},
+	"json":    {open: newJSONExport},
+	"jsonl":   {open: newJSONLExport},
+	"sqlite":  {open: newSQLiteExport, needsFile: true},
+	"parquet": {open: newParquetExport, needsFile: true},
+	"xlsx":    {open: newXLSXExport, needsFile: true},
+}
+
+func exportFormatNames() []string {
+	names := make([]string, 0, len(exportFormats)+1)
+	for name := range exportFormats {
+		names = append(names, name)
+	}
+	// geojson isn't a table: it joins one metric onto boundary features.
+	names = append(names, "geojson")
+	sort.Strings(names)
+	return names
+}
+
+// Export implements the "export" subcommand: write every record in a parsed
+// directory as one table in the chosen format.
+func Export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	format := fs.String("format", "csv", "output format: "+strings.Join(exportFormatNames(), ", "))
+	out := fs.String("out", "", "output file (csv, json and jsonl default to stdout)")
+	cleanNumbers := fs.Bool("clean-numbers", false, "write values as plain numbers: no commas, percentages as decimals, \"- -\" as empty")
+	sections := fs.String("sections", "", "comma-separated sections to include: "+strings.Join(tableSections, ", ")+" (default all)")
+	rows := fs.String("rows", "", "comma-separated rows to include per section: prior, current, change (default all)")
+	split := fs.Bool("split-sections", false, "write one <section>.<format> per section into the --out directory")
+	datasette := fs.Bool("datasette", false, "with --format sqlite: add full-text search and write <out>.metadata.json with facets and canned queries for Datasette")
+	boundaries := fs.String("boundaries", "", "with --format geojson: municipal boundary GeoJSON to join values onto")
+	metric := fs.String("metric", "filings", "with --format geojson: metric to join: "+strings.Join(validMetrics, ", "))
+	caseType := fs.String("type", "grand-total", "with --format geojson: case type to join: "+strings.Join(validTypes, ", "))
+	date := fs.String("date", "", "with --format geojson: period to join, YYYY-MM (default newest)")
+	nameProp := fs.String("name-property", "", "with --format geojson: boundary property holding the municipality name (default: detected)")
+	countyProp := fs.String("county-property", "", "with --format geojson: boundary property holding the county name (default: detected)")
+	values := fs.String("values", "cumulative", "filings, resolutions and clearance as reported (cumulative from July) or per month: "+strings.Join(validValues, ", "))
+	withPopulation := fs.Bool("population", false, "add Census population (fetched once and cached; see municourt population), and per-1,000 values to geojson")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt export <parsed-dir> --format %s [--out path] [--clean-numbers] [--sections list] [--rows list] [--split-sections] [--datasette] [--population] [--values monthly]\n"+
+			"       municourt export <parsed-dir> --format geojson --boundaries file.geojson [--metric m] [--type t] [--date YYYY-MM] [--population] [--out path]\n\n"+
+			"Export all parsed periods as a single table, or one period joined onto municipal boundaries.\n\nFlags:\n", strings.Join(exportFormatNames(), "|"))
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	f, ok := exportFormats[*format]
+	if !ok && *format != "geojson" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: %s\n", *format, strings.Join(exportFormatNames(), ", "))
+		os.Exit(1)
+	}
+	opts, err := parseTableOptions(*cleanNumbers, *split, *sections, *rows)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if !contains(validValues, *values) {
+		fmt.Fprintf(os.Stderr, "invalid --values %q; valid options: %s\n", *values, strings.Join(validValues, ", "))
+		os.Exit(1)
+	}
+	if *datasette && (*format != "sqlite" || opts.split) {
+		fmt.Fprintf(os.Stderr, "--datasette requires --format sqlite and can't be combined with --split-sections\n")
+		os.Exit(1)
+	}
+	if *withPopulation && opts.split {
+		fmt.Fprintf(os.Stderr, "--population can't be combined with --split-sections\n")
+		os.Exit(1)
+	}
+	if (*format == "geojson") != (*boundaries != "") {
+		fmt.Fprintf(os.Stderr, "--format geojson and --boundaries must be given together\n")
+		os.Exit(1)
+	}
+	if *format == "geojson" {
+		if opts.split {
+			fmt.Fprintf(os.Stderr, "--split-sections can't be used with --format geojson\n")
+			os.Exit(1)
+		}
+		if !contains(validMetrics, *metric) {
+			fmt.Fprintf(os.Stderr, "invalid --metric %q; valid options: %s\n", *metric, strings.Join(validMetrics, ", "))
+			os.Exit(1)
+		}
+		if !contains(validTypes, *caseType) {
+			fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+			os.Exit(1)
+		}
+	}
+	if *out == "" && (f.needsFile || opts.split) {
+		if opts.split {
+			fmt.Fprintf(os.Stderr, "--out is required with --split-sections\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "--out is required for --format %s\n", *format)
+		}
+		os.Exit(1)
+	}
+
+	records, err := loadRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+	if *values == "monthly" {
+		records = monthlyRecords(records)
+	}
+
+	if *withPopulation {
+		if opts.population, err = loadPopulation(populationCachePath(), false, os.Getenv("CENSUS_API_KEY")); err != nil {
+			fmt.Fprintf(os.Stderr, "error loading population: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *format == "geojson" {
+		if err := exportGeoJSON(records, *boundaries, *nameProp, *countyProp, *metric, *caseType, *date, opts.population, *out); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing geojson: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if opts.split {
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "error creating output directory: %v\n", err)
+			os.Exit(1)
+		}
+		pathFor := func(section string) string { return filepath.Join(*out, section+"."+*format) }
+		written, err := writeSectionTables(f.open, pathFor, records, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error writing %s: %v\n", *format, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "wrote %d section files to %s\n", len(written), *out)
+		return
+	}
+
+	w, err := f.open(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating output: %v\n", err)
+		os.Exit(1)
+	}
+	n, err := exportRecords(w, records, opts)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", *format, err)
+		os.Exit(1)
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "wrote %d rows to %s\n", n, *out)
+	}
+	if *datasette {
+		meta, err := writeDatasette(*out, exportColumns(opts))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error writing Datasette metadata: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "wrote %s; browse with: datasette %s -m %s\n", meta, *out, meta)
+	}
+}
+
+// exportRecords writes every record as a row of recordColumns prefixed
+// with its period, and followed by its population when opts has one,
+// returning the number of rows written.
+func exportRecords(w exportWriter, records []timeRecord, opts tableOptions) (int, error) {
+	if err := w.WriteHeader(exportColumns(opts)); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, rec := range records {
+		var bases map[place]int
+		if opts.population != nil {
+			bases = courtBaseCounts(rec.stats)
+		}
+		for _, s := range rec.stats {
+			row := append([]string{rec.date}, recordValues(s, opts)...)
+			if opts.population != nil {
+				row = append(row, formatPopulation(opts.population.lookup(s.County, s.Municipality, rec.date, bases)))
+			}
+			if err := w.WriteRow(row); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// exportColumns returns the header exportRecords writes.
+func exportColumns(opts tableOptions) []string {
+	columns := append([]string{"Period"}, recordColumns(opts)...)
+	if opts.population != nil {
+		columns = append(columns, "Population")
+	}
+	return columns
+}
+
+// createOutput opens path for writing, or stdout if path is empty.
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+type csvExport struct {
+	f io.WriteCloser
+	w *csv.Writer
+}
+
+func newCSVExport(path string) (exportWriter, error) {
+	f, err := createOutput(path)
+	if err != nil {
+		return nil, err
+	}
+	return &csvExport{f: f, w: csv.NewWriter(f)}, nil
+}
+
+func (e *csvExport) WriteHeader(columns []string) error { return e.w.Write(columns) }
+func (e *csvExport) WriteRow(values []string) error     { return e.w.Write(values) }
+
+func (e *csvExport) Close() error {
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
+
+// jsonExport writes rows as objects keyed by column name, either as one
+// array or, with lines set, one object per line.
+type jsonExport struct {
+	f       io.WriteCloser
+	w       *bufio.Writer
+	lines   bool
+	columns []string
+	rows    int
+}
+
+func newJSONExport(path string) (exportWriter, error)  { return openJSONExport(path, false) }
+func newJSONLExport(path string) (exportWriter, error) { return openJSONExport(path, true) }
+
+func openJSONExport(path string, lines bool) (exportWriter, error) {
+	f, err := createOutput(path)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonExport{f: f, w: bufio.NewWriter(f), lines: lines}, nil
+}
+
+func (e *jsonExport) WriteHeader(columns []string) error {
+	e.columns = columns
+	if !e.lines {
+		_, err := e.w.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+// WriteRow builds the object by hand so keys keep column order.
+func (e *jsonExport) WriteRow(values []string) error {
+	if !e.lines {
+		if e.rows > 0 {
+			e.w.WriteString(",")
+		}
+		e.w.WriteString("\n  ")
+	}
+	e.w.WriteString("{")
+	for i, col := range e.columns {
+		if i > 0 {
+			e.w.WriteString(",")
+		}
+		k, _ := json.Marshal(col)
+		v, _ := json.Marshal(values[i])
+		e.w.Write(k)
+		e.w.WriteString(":")
+		e.w.Write(v)
+	}
+	e.w.WriteString("}")
+	if e.lines {
+		e.w.WriteString("\n")
+	}
+	e.rows++
+	return nil
+}
+
+func (e *jsonExport) Close() error {
+	if !e.lines {
+		e.w.WriteString("\n]\n")
+	}
+	if err := e.w.Flush(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
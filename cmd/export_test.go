@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func exportTestRecords() []timeRecord {
+	return []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "1,749", "1,700", ""),
+		}},
+		{date: "2025-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "1,800", "1,750", ""),
+			rateStat("ATLANTIC", "BRIGANTINE", "900", "950", ""),
+		}},
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	w, err := newJSONLExport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := exportRecords(w, exportTestRecords(), tableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("rows = %d, want 3", n)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	// Keys keep column order rather than being sorted.
+	if !strings.HasPrefix(lines[0], `{"Period":"2024-06","County":"ATLANTIC","Municipality":"ABSECON"`) {
+		t.Errorf("first line = %.80s", lines[0])
+	}
+	var row map[string]string
+	if err := json.Unmarshal([]byte(lines[2]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row["Municipality"] != "BRIGANTINE" || row["Filings_Current_GrandTotal"] != "900" {
+		t.Errorf("last row = %v, %v", row["Municipality"], row["Filings_Current_GrandTotal"])
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	w, _ := newJSONExport(path)
+	exportRecords(w, exportTestRecords(), tableOptions{})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	var rows []map[string]string
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(rows) != 3 || rows[1]["Period"] != "2025-06" {
+		t.Errorf("rows = %d, second period %q", len(rows), rows[1]["Period"])
+	}
+}
+
+func TestExportSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.db")
+	for range 2 { // a second export replaces the first
+		w, err := newSQLiteExport(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := exportRecords(w, exportTestRecords(), tableOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var n int
+	var filings string
+	if err := db.QueryRow(`SELECT COUNT(*), MAX("Filings_Current_GrandTotal") FROM records`).Scan(&n, &filings); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || filings != "900" {
+		t.Errorf("count = %d, max filings = %q", n, filings)
+	}
+}
+
+func TestWriteDatasette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.db")
+	w, _ := newSQLiteExport(path)
+	exportRecords(w, exportTestRecords(), tableOptions{})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	metaPath, err := writeDatasette(path, exportColumns(tableOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(filepath.Dir(path), "stats.metadata.json"); metaPath != want {
+		t.Errorf("metadata path = %q, want %q", metaPath, want)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM records WHERE rowid IN (SELECT rowid FROM records_fts WHERE records_fts MATCH 'absecon')`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("records matching absecon = %d, want 2", n)
+	}
+	var first, last string
+	if err := db.QueryRow(`SELECT FirstPeriod, LastPeriod, Periods FROM municipalities WHERE Municipality = 'ABSECON'`).Scan(&first, &last, &n); err != nil {
+		t.Fatal(err)
+	}
+	if first != "2024-06" || last != "2025-06" || n != 2 {
+		t.Errorf("ABSECON = %s..%s (%d periods)", first, last, n)
+	}
+
+	data, _ := os.ReadFile(metaPath)
+	var meta struct {
+		Databases map[string]struct {
+			Tables map[string]struct {
+				Facets   []string          `json:"facets"`
+				FTSTable string            `json:"fts_table"`
+				Columns  map[string]string `json:"columns"`
+			} `json:"tables"`
+			Queries map[string]struct {
+				SQL string `json:"sql"`
+			} `json:"queries"`
+		} `json:"databases"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatal(err)
+	}
+	stats := meta.Databases["stats"]
+	records := stats.Tables["records"]
+	if records.FTSTable != "records_fts" || !contains(records.Facets, "County") {
+		t.Errorf("records metadata = %+v", records)
+	}
+	if d := records.Columns["Backlog_Current_GrandTotal"]; !strings.Contains(d, "grand total") {
+		t.Errorf("Backlog_Current_GrandTotal description = %q", d)
+	}
+
+	// Canned queries run against the exported data.
+	var filings int
+	if err := db.QueryRow(stats.Queries["county_totals"].SQL, sql.Named("period", "2025-06")).Scan(new(string), &n, &filings, new(sql.NullInt64), new(sql.NullInt64)); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || filings != 2700 {
+		t.Errorf("county_totals = %d municipalities, %d filings; want 2, 2700", n, filings)
+	}
+	for name, q := range stats.Queries {
+		rows, err := db.Query(q.SQL, sql.Named("period", "2025-06"), sql.Named("name", "absecon"))
+		if err != nil {
+			t.Errorf("query %s: %v", name, err)
+			continue
+		}
+		rows.Close()
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	w, _ := newParquetExport(path)
+	exportRecords(w, exportTestRecords(), tableOptions{})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("missing PAR1 magic")
+	}
+	footer := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if footer <= 0 || footer > len(data)-12 {
+		t.Fatalf("footer length %d out of range", footer)
+	}
+	meta := data[len(data)-8-footer : len(data)-8]
+	// The footer names every column; the first page holds the Period values.
+	if !bytes.Contains(meta, []byte("Filings_Current_GrandTotal")) {
+		t.Error("footer is missing column names")
+	}
+	if !bytes.Contains(data[:len(data)-8-footer], []byte("\x07\x00\x00\x002024-06")) {
+		t.Error("Period values not PLAIN-encoded")
+	}
+}
+
+func TestThriftWriter(t *testing.T) {
+	var w thriftWriter
+	w.i32(1, 1)    // short form: delta 1, type i32
+	w.i64(20, -1)  // long form: delta > 15
+	w.str(21, "a") // short form again
+	w.stop()
+	want := []byte{0x15, 0x02, 0x06, 0x28, 0x01, 0x18, 0x01, 'a', 0x00}
+	if !bytes.Equal(w.buf.Bytes(), want) {
+		t.Errorf("encoded % x, want % x", w.buf.Bytes(), want)
+	}
+}
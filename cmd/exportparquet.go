@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+)
+
+// parquetExport buffers the table and writes it on Close as one row group
+// of uncompressed, PLAIN-encoded, required UTF-8 columns. That small subset
+// of the format is readable by every Parquet implementation and keeps the
+// writer dependency-free.
+type parquetExport struct {
+	path    string
+	columns []string
+	values  [][]string // values[col][row]
+	rows    int
+}
+
+func newParquetExport(path string) (exportWriter, error) {
+	return &parquetExport{path: path}, nil
+}
+
+func (e *parquetExport) WriteHeader(columns []string) error {
+	e.columns = columns
+	e.values = make([][]string, len(columns))
+	return nil
+}
+
+func (e *parquetExport) WriteRow(values []string) error {
+	for i := range e.columns {
+		e.values[i] = append(e.values[i], values[i])
+	}
+	e.rows++
+	return nil
+}
+
+// Parquet enum values used below.
+const (
+	parquetByteArray    = 6 // Type.BYTE_ARRAY
+	parquetRequired     = 0 // FieldRepetitionType.REQUIRED
+	parquetUTF8         = 0 // ConvertedType.UTF8
+	parquetPlain        = 0 // Encoding.PLAIN
+	parquetRLE          = 3 // Encoding.RLE
+	parquetUncompressed = 0 // CompressionCodec.UNCOMPRESSED
+	parquetDataPage     = 0 // PageType.DATA_PAGE
+)
+
+func (e *parquetExport) Close() error {
+	var buf bytes.Buffer
+	buf.WriteString("PAR1")
+
+	type chunk struct{ offset, size int64 }
+	chunks := make([]chunk, len(e.columns))
+	var total int64
+	for i, vals := range e.values {
+		// PLAIN byte arrays: a 4-byte little-endian length, then the bytes.
+		// Required columns have no repetition or definition levels.
+		var data bytes.Buffer
+		for _, v := range vals {
+			binary.Write(&data, binary.LittleEndian, uint32(len(v)))
+			data.WriteString(v)
+		}
+
+		var h thriftWriter
+		h.i32(1, parquetDataPage)
+		h.i32(2, int64(data.Len()))
+		h.i32(3, int64(data.Len()))
+		h.beginStruct(5) // DataPageHeader
+		h.i32(1, int64(e.rows))
+		h.i32(2, parquetPlain)
+		h.i32(3, parquetRLE)
+		h.i32(4, parquetRLE)
+		h.endStruct()
+		h.stop()
+
+		chunks[i] = chunk{offset: int64(buf.Len()), size: int64(h.buf.Len() + data.Len())}
+		total += chunks[i].size
+		buf.Write(h.buf.Bytes())
+		buf.Write(data.Bytes())
+	}
+
+	var m thriftWriter // FileMetaData
+	m.i32(1, 1)
+	m.listBegin(2, thriftStruct, len(e.columns)+1)
+	m.elemBegin() // root SchemaElement
+	m.str(4, "schema")
+	m.i32(5, int64(len(e.columns)))
+	m.elemEnd()
+	for _, c := range e.columns {
+		m.elemBegin()
+		m.i32(1, parquetByteArray)
+		m.i32(3, parquetRequired)
+		m.str(4, c)
+		m.i32(6, parquetUTF8)
+		m.elemEnd()
+	}
+	m.i64(3, int64(e.rows))
+	m.listBegin(4, thriftStruct, 1)
+	m.elemBegin() // RowGroup
+	m.listBegin(1, thriftStruct, len(e.columns))
+	for i, c := range e.columns {
+		m.elemBegin() // ColumnChunk
+		m.i64(2, chunks[i].offset)
+		m.beginStruct(3) // ColumnMetaData
+		m.i32(1, parquetByteArray)
+		m.listBegin(2, thriftI32, 1)
+		m.varint(zigzag(parquetPlain))
+		m.listBegin(3, thriftBinary, 1)
+		m.binary(c)
+		m.i32(4, parquetUncompressed)
+		m.i64(5, int64(e.rows))
+		m.i64(6, chunks[i].size)
+		m.i64(7, chunks[i].size)
+		m.i64(9, chunks[i].offset)
+		m.endStruct()
+		m.elemEnd()
+	}
+	m.i64(2, total)
+	m.i64(3, int64(e.rows))
+	m.elemEnd()
+	m.str(6, "municourt "+version())
+	m.stop()
+
+	buf.Write(m.buf.Bytes())
+	binary.Write(&buf, binary.LittleEndian, uint32(m.buf.Len()))
+	buf.WriteString("PAR1")
+	return os.WriteFile(e.path, buf.Bytes(), 0644)
+}
+
+// Thrift compact protocol type codes.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Thrift compact protocol, which Parquet uses for
+// its page headers and footer. Only what those structures need is here.
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID int16
+	stack  []int16 // lastID of each enclosing struct
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func zigzag(v int64) uint64 { return uint64((v << 1) ^ (v >> 63)) }
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if d := id - w.lastID; d > 0 && d <= 15 {
+		w.buf.WriteByte(byte(d)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(zigzag(int64(id)))
+	}
+	w.lastID = id
+}
+
+func (w *thriftWriter) i32(id int16, v int64) { w.field(id, thriftI32); w.varint(zigzag(v)) }
+func (w *thriftWriter) i64(id int16, v int64) { w.field(id, thriftI64); w.varint(zigzag(v)) }
+func (w *thriftWriter) str(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.binary(s)
+}
+
+func (w *thriftWriter) binary(s string) {
+	w.varint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *thriftWriter) listBegin(id int16, elem byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		w.buf.WriteByte(0xf0 | elem)
+		w.varint(uint64(n))
+	}
+}
+
+// elemBegin and elemEnd bracket a struct that is a list element and so has
+// no field header of its own.
+func (w *thriftWriter) elemBegin() {
+	w.stack = append(w.stack, w.lastID)
+	w.lastID = 0
+}
+
+func (w *thriftWriter) elemEnd() {
+	w.stop()
+	w.lastID = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+func (w *thriftWriter) beginStruct(id int16) {
+	w.field(id, thriftStruct)
+	w.elemBegin()
+}
+
+func (w *thriftWriter) endStruct() { w.elemEnd() }
+
+func (w *thriftWriter) stop() { w.buf.WriteByte(0) }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteExport writes the table to a "records" table in a new SQLite
+// database, in a single transaction.
+type sqliteExport struct {
+	db   *sql.DB
+	tx   *sql.Tx
+	stmt *sql.Stmt
+}
+
+func newSQLiteExport(path string) (exportWriter, error) {
+	// Replace rather than append to an earlier export.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteExport{db: db}, nil
+}
+
+func (e *sqliteExport) WriteHeader(columns []string) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = `"` + strings.ReplaceAll(c, `"`, `""`) + `"`
+	}
+	if _, err := e.db.Exec(fmt.Sprintf("CREATE TABLE records (%s TEXT)", strings.Join(quoted, " TEXT, "))); err != nil {
+		return err
+	}
+
+	tx, err := e.db.Begin()
+	if err != nil {
+		return err
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO records (%s) VALUES (%s)", strings.Join(quoted, ", "), placeholders))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	e.tx, e.stmt = tx, stmt
+	return nil
+}
+
+func (e *sqliteExport) WriteRow(values []string) error {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	_, err := e.stmt.Exec(args...)
+	return err
+}
+
+func (e *sqliteExport) Close() error {
+	var err error
+	if e.tx != nil {
+		e.stmt.Close()
+		err = e.tx.Commit()
+	}
+	if cerr := e.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
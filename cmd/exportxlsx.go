@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSheet is the name of the worksheet holding the exported table.
+const xlsxSheet = "Records"
+
+// xlsxExport streams the table into a single worksheet.
+type xlsxExport struct {
+	path string
+	f    *excelize.File
+	sw   *excelize.StreamWriter
+	row  int
+}
+
+func newXLSXExport(path string) (exportWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxExport{path: path, f: f, sw: sw}, nil
+}
+
+func (e *xlsxExport) WriteHeader(columns []string) error {
+	// Panes must be set before the first row is written.
+	if err := e.sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	return e.WriteRow(columns)
+}
+
+func (e *xlsxExport) WriteRow(values []string) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	row := make([]any, len(values))
+	for i, v := range values {
+		row[i] = v
+	}
+	return e.sw.SetRow(cell, row)
+}
+
+func (e *xlsxExport) Close() error {
+	defer e.f.Close()
+	if err := e.sw.Flush(); err != nil {
+		return err
+	}
+	return e.f.SaveAs(e.path)
+}
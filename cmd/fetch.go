@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// Fetch implements the "fetch" subcommand: download a single report PDF
+// from an arbitrary URL, name it by its period, and optionally parse it.
+func Fetch(args []string) {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	dir := fs.String("dir", ".", "output directory for the downloaded PDF")
+	parse := fs.Bool("parse", false, "parse the PDF into JSON + CSV after downloading")
+	force := fs.Bool("force", false, "overwrite an existing PDF for the same period")
+	postFlags := addPostParseFlags(fs)
+	netFlags := addHTTPFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt fetch <url> [--dir path] [--parse] [--force] [--duplicates policy] [--rules file] [--strict]\n\nDownload one report PDF and name it by its detected period.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	url := fs.Arg(0)
+	if err := netFlags.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	post, err := postFlags.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "downloading %s\n", url)
+	outPath, err := fetchReport(url, *dir, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "saved %s\n", outPath)
+
+	if *parse {
+		r := parsePDFFile(outPath)
+		if !post.process(&r, filepath.Dir(outPath)) {
+			os.Exit(1)
+		}
+		if err := writeResults(r, "", "", tableOptions{}); err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// fetchReport downloads url into dir and renames it after the period it
+// covers, returning the final path.
+func fetchReport(url, dir string, force bool) (string, error) {
+	tmp, err := os.CreateTemp(dir, "fetch-*.pdf")
+	if err != nil {
+		return "", fmt.Errorf("error creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	remote, err := downloadFile(url, tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("error downloading %s: %w", url, err)
+	}
+	if verr := validatePDF(tmpPath); verr != nil {
+		name := filepath.Base(url)
+		if period, ok := periodFromURL(url); ok {
+			name = "municipal-courts-" + period + ".pdf"
+		}
+		if dest, err := quarantine(tmpPath, dir, name); err == nil {
+			return "", fmt.Errorf("invalid download: %v; moved to %s", verr, dest)
+		}
+		return "", fmt.Errorf("invalid download: %w", verr)
+	}
+
+	period, ok := periodFromURL(url)
+	if !ok {
+		// Non-standard URL: read the period from the report itself.
+		r := parsePDFFile(tmpPath)
+		if !r.failed && len(r.results) > 0 {
+			period, ok = periodFromDateRange(r.results[0].DateRange)
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("could not detect the report period from the URL or PDF contents")
+	}
+
+	outPath := filepath.Join(dir, "municipal-courts-"+period+".pdf")
+	if _, err := os.Stat(outPath); err == nil && !force {
+		return "", fmt.Errorf("%s already exists (use --force to replace it)", filepath.Base(outPath))
+	}
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		return "", fmt.Errorf("error saving %s: %w", filepath.Base(outPath), err)
+	}
+
+	m, err := loadManifest(dir)
+	if err == nil {
+		m[filepath.Base(outPath)] = remote.entry(url)
+		err = m.save(dir)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not update %s: %v\n", manifestName, err)
+	}
+	return outPath, nil
+}
+
+// periodFromURL extracts YYYY-MM from one of the courts' file name patterns
+// or from a YYYY-MM anywhere in the URL.
+func periodFromURL(url string) (string, bool) {
+	for _, p := range defaultPatterns {
+		if m := p.name.FindStringSubmatch(url); m != nil {
+			return matchPeriod(p.name, m), true
+		}
+	}
+	if m := datePattern.FindStringSubmatch(url); m != nil {
+		return m[1] + "-" + m[2], true
+	}
+	return "", false
+}
+
+// periodFromDateRange returns the closing month of a report date range such
+// as "JULY 2023 - JUNE 2024" as YYYY-MM.
+func periodFromDateRange(s string) (string, bool) {
+	_, end, ok := parser.ParsePeriod(s)
+	if !ok {
+		return "", false
+	}
+	return end.Format("2006-01"), true
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import "testing"
+
+func TestPeriodDetection(t *testing.T) {
+	urls := map[string]string{
+		"https://www.njcourts.gov/sites/default/files/munm2406.pdf": "2024-06",
+		"https://example.com/reports/corrected-2023-12.pdf":         "2023-12",
+		"https://example.com/reports/corrected.pdf":                 "",
+	}
+	for url, want := range urls {
+		got, ok := periodFromURL(url)
+		if got != want || ok != (want != "") {
+			t.Errorf("periodFromURL(%q) = %q, %v; want %q", url, got, ok, want)
+		}
+	}
+
+	ranges := map[string]string{
+		"JULY 2023 - JUNE 2024":          "2024-06",
+		"JULY 2025  - DECEMBER 2025":     "2025-12",
+		"july 2004 - june 2005":          "2005-06",
+		"":                               "",
+		"FISCAL YEAR 2024 - SMARCH 2024": "",
+	}
+	for in, want := range ranges {
+		got, ok := periodFromDateRange(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("periodFromDateRange(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// geoFeatureCollection is a GeoJSON FeatureCollection. Geometry and any
+// members we don't touch are kept as raw JSON so they round-trip exactly.
+type geoFeatureCollection struct {
+	Type     string                     `json:"type"`
+	Features []geoFeature               `json:"features"`
+	Extra    map[string]json.RawMessage `json:"-"`
+}
+
+type geoFeature struct {
+	Type       string          `json:"type"`
+	ID         json.RawMessage `json:"id,omitempty"`
+	Properties map[string]any  `json:"properties"`
+	Geometry   json.RawMessage `json:"geometry"`
+}
+
+// Property names tried, in order, when --name-property or --county-property
+// isn't given. They cover the NJGIN municipal boundary layers and common
+// hand-made files.
+var (
+	boundaryNameProperties   = []string{"MUN", "MUN_LABEL", "NAME", "MUN_NAME", "MUNICIPALITY", "name", "municipality"}
+	boundaryCountyProperties = []string{"COUNTY", "CO_NAME", "COUNTY_NAME", "county"}
+)
+
+func readBoundaries(path string) (*geoFeatureCollection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fc geoFeatureCollection
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep codes such as MUN_CODE exactly as written
+	if err := dec.Decode(&fc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%s: not a GeoJSON FeatureCollection", path)
+	}
+	var members map[string]json.RawMessage
+	json.Unmarshal(data, &members)
+	delete(members, "type")
+	delete(members, "features")
+	fc.Extra = members
+	return &fc, nil
+}
+
+func (fc *geoFeatureCollection) write(w io.Writer) error {
+	out := map[string]any{"type": fc.Type, "features": fc.Features}
+	for k, v := range fc.Extra {
+		out[k] = v
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+// boundaryProperty returns the property to read names from: want if given,
+// otherwise the first of candidates present on the first feature.
+func boundaryProperty(fc *geoFeatureCollection, want string, candidates []string, flagName string) (string, error) {
+	if len(fc.Features) == 0 {
+		return want, nil
+	}
+	props := fc.Features[0].Properties
+	if want != "" {
+		if _, ok := props[want]; !ok {
+			return "", fmt.Errorf("boundary features have no %q property", want)
+		}
+		return want, nil
+	}
+	for _, c := range candidates {
+		if _, ok := props[c]; ok {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("can't tell which boundary property to use; set %s", flagName)
+}
+
+// joinBoundaries sets properties on every feature from the court in stats
+// that matches it by county and name (see placeIndex): municourt_court,
+// municourt_entity_id, municourt_period and municourt_<metric> (null when
+// unmatched or not reported). With pop it also sets municourt_population and
+// municourt_<metric>_per_1000. It returns the courts that matched no
+// feature, such as joint and central courts.
+func joinBoundaries(fc *geoFeatureCollection, nameProp, countyProp string, stats []parser.MunicipalityStats, period, metric, caseType string, pop *populationTable) []string {
+	ix := newPlaceIndex()
+	for i, f := range fc.Features {
+		county, _ := parser.NormalizeCounty(fmt.Sprint(f.Properties[countyProp]))
+		ix.add(county, fmt.Sprint(f.Properties[nameProp]), i)
+	}
+	courtBases := courtBaseCounts(stats)
+
+	valueKey := "municourt_" + strings.ReplaceAll(metric, "-", "_")
+	for i := range fc.Features {
+		if fc.Features[i].Properties == nil {
+			fc.Features[i].Properties = make(map[string]any)
+		}
+		props := fc.Features[i].Properties
+		props["municourt_court"] = nil
+		props["municourt_entity_id"] = nil
+		props["municourt_period"] = period
+		props[valueKey] = nil
+		if pop != nil {
+			props["municourt_population"] = nil
+			props[valueKey+"_per_1000"] = nil
+		}
+	}
+
+	var unmatched []string
+	for _, s := range stats {
+		i := ix.match(s.County, s.Municipality, courtBases)
+		if i < 0 || fc.Features[i].Properties["municourt_court"] != nil {
+			unmatched = append(unmatched, s.County+" / "+s.Municipality)
+			continue
+		}
+		props := fc.Features[i].Properties
+		props["municourt_court"] = s.Municipality
+		props["municourt_entity_id"] = entityID(s)
+		v := getField(getRow(s, metric), caseType)
+		if !math.IsNaN(v) {
+			props[valueKey] = v
+		}
+		if pop == nil {
+			continue
+		}
+		if n := math.Round(pop.lookup(s.County, s.Municipality, period, courtBases)); n > 0 {
+			props["municourt_population"] = n
+			if !math.IsNaN(v) {
+				props[valueKey+"_per_1000"] = math.Round(v/n*1000*100) / 100
+			}
+		}
+	}
+	return unmatched
+}
+
+// exportGeoJSON writes the boundaries in boundariesPath with the metric for
+// one period joined on, to out (stdout if empty). An empty date means the
+// newest period; pop may be nil.
+func exportGeoJSON(records []timeRecord, boundariesPath, nameProp, countyProp, metric, caseType, date string, pop *populationTable, out string) error {
+	var rec *timeRecord
+	for i := range records {
+		if records[i].date == date || (date == "" && i == len(records)-1) {
+			rec = &records[i]
+		}
+	}
+	if rec == nil {
+		return fmt.Errorf("no data for period %s", date)
+	}
+	fc, err := readBoundaries(boundariesPath)
+	if err != nil {
+		return err
+	}
+	if nameProp, err = boundaryProperty(fc, nameProp, boundaryNameProperties, "--name-property"); err != nil {
+		return err
+	}
+	if countyProp, err = boundaryProperty(fc, countyProp, boundaryCountyProperties, "--county-property"); err != nil {
+		return err
+	}
+
+	unmatched := joinBoundaries(fc, nameProp, countyProp, rec.stats, rec.date, metric, caseType, pop)
+	if len(unmatched) > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d courts in %s matched no boundary (joint and central courts never do):\n", len(unmatched), len(rec.stats), rec.date)
+		for _, u := range unmatched {
+			fmt.Fprintf(os.Stderr, "  %s\n", u)
+		}
+	}
+
+	w, err := createOutput(out)
+	if err != nil {
+		return err
+	}
+	if err := fc.write(w); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if out != "" {
+		fmt.Fprintf(os.Stderr, "wrote %d features for %s to %s\n", len(fc.Features), rec.date, out)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+const testBoundaries = `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"EPSG:4326"}},"features":[
+{"type":"Feature","properties":{"MUN":"EGG HARBOR CITY","COUNTY":"ATLANTIC","MUN_CODE":"0107"},"geometry":{"type":"Point","coordinates":[-74.6,39.5]}},
+{"type":"Feature","properties":{"MUN":"EGG HARBOR TOWNSHIP","COUNTY":"ATLANTIC","MUN_CODE":"0108"},"geometry":{"type":"Point","coordinates":[-74.6,39.4]}},
+{"type":"Feature","properties":{"MUN":"MARGATE CITY CITY","COUNTY":"ATLANTIC","MUN_CODE":"0116"},"geometry":{"type":"Point","coordinates":[-74.5,39.3]}},
+{"type":"Feature","properties":{"MUN":"HAMILTON TOWNSHIP","COUNTY":"MERCER","MUN_CODE":"1103"},"geometry":{"type":"Point","coordinates":[-74.6,40.2]}}
+]}`
+
+func TestJoinBoundaries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nj.geojson")
+	os.WriteFile(path, []byte(testBoundaries), 0644)
+	fc, err := readBoundaries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := []parser.MunicipalityStats{
+		rateStat("ATLANTIC", "EGG HARBOR CITY", "100", "", ""),
+		rateStat("ATLANTIC", "EGG HARBOR TWP", "200", "", ""),
+		rateStat("ATLANTIC", "MARGATE", "- -", "", ""),
+		rateStat("ATLANTIC", "HAMILTON TWP", "300", "", ""), // Mercer's Hamilton is a different town
+		rateStat("ATLANTIC", "ATLANTIC CNTY CENTRAL MC", "5", "", ""),
+	}
+	unmatched := joinBoundaries(fc, "MUN", "COUNTY", stats, "2025-06", "filings", "grand-total", nil)
+	if strings.Join(unmatched, ";") != "ATLANTIC / HAMILTON TWP;ATLANTIC / ATLANTIC CNTY CENTRAL MC" {
+		t.Errorf("unmatched = %q", unmatched)
+	}
+
+	var buf bytes.Buffer
+	if err := fc.write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		CRS      json.RawMessage `json:"crs"`
+		Features []struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.CRS) == 0 {
+		t.Error("crs member dropped")
+	}
+	want := []struct {
+		court string
+		value any
+	}{
+		{"EGG HARBOR CITY", 100.0},
+		{"EGG HARBOR TWP", 200.0},
+		{"MARGATE", nil},
+		{"", nil},
+	}
+	for i, w := range want {
+		p := got.Features[i].Properties
+		court, _ := p["municourt_court"].(string)
+		if court != w.court || p["municourt_filings"] != w.value || p["municourt_period"] != "2025-06" {
+			t.Errorf("feature %d properties = %v", i, p)
+		}
+	}
+	if code := got.Features[0].Properties["MUN_CODE"]; code != "0107" {
+		t.Errorf("MUN_CODE = %v", code)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// glyphSet holds the non-ASCII characters used in terminal output, so they
+// can be swapped for ASCII where a terminal or file can't show them.
+type glyphSet struct {
+	spark  []rune // sparkline levels, lowest first
+	point  rune   // chart data point
+	trail  rune   // chart line between points
+	hrule  string // horizontal rule and x axis
+	vaxis  string // chart y axis
+	corner string // chart axis corner
+	arrow  string // "from → to"
+	dash   string // title separator, with surrounding spaces
+	mark   rune   // chart marker under an annotated period
+
+	boxFill, boxMedian, whiskerEnd, outlier rune // box plot parts
+	bar                                     rune // histogram bar
+
+	present, missing, failed rune // coverage cells
+}
+
+var (
+	unicodeGlyphs = glyphSet{
+		spark: []rune("▁▂▃▄▅▆▇█"), point: '●', trail: '·',
+		hrule: "─", vaxis: "│", corner: "└", arrow: "→", dash: " — ", mark: '▲',
+		boxFill: '▒', boxMedian: '┃', whiskerEnd: '│', outlier: '•', bar: '█',
+		present: '█', missing: '·', failed: '×',
+	}
+	asciiGlyphs = glyphSet{
+		spark: []rune("_.-~=+*#"), point: '*', trail: '.',
+		hrule: "-", vaxis: "|", corner: "+", arrow: "->", dash: " - ", mark: '^',
+		boxFill: '=', boxMedian: '#', whiskerEnd: '|', outlier: 'o', bar: '#',
+		present: '#', missing: '.', failed: 'x',
+	}
+)
+
+// glyphs is the set in use; addASCIIFlag's caller switches it.
+var glyphs = unicodeGlyphs
+
+// localeIsUTF8 reports whether the locale environment (LC_ALL, LC_CTYPE,
+// then LANG, as setlocale reads them) names a UTF-8 character set. An
+// unset locale is taken as UTF-8, which nearly every terminal now is.
+func localeIsUTF8() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := strings.ToLower(os.Getenv(name)); v != "" {
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return true
+}
+
+// addASCIIFlag registers --ascii, which defaults to on under a non-UTF-8
+// locale. Pass the parsed value to useASCII.
+func addASCIIFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("ascii", !localeIsUTF8(), "draw with ASCII instead of block and box-drawing characters (default on for non-UTF-8 locales; --ascii=false to force Unicode)")
+}
+
+// useASCII selects the glyph set for terminal output.
+func useASCII(ascii bool) {
+	if ascii {
+		glyphs = asciiGlyphs
+	} else {
+		glyphs = unicodeGlyphs
+	}
+}
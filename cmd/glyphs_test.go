@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocaleIsUTF8(t *testing.T) {
+	tests := []struct {
+		lcAll, lcCtype, lang string
+		want                 bool
+	}{
+		{"", "", "", true},
+		{"", "", "en_US.UTF-8", true},
+		{"", "", "de_DE.utf8", true},
+		{"", "", "C", false},
+		{"", "en_US.ISO-8859-1", "en_US.UTF-8", false}, // LC_CTYPE wins over LANG
+		{"POSIX", "en_US.UTF-8", "", false},            // LC_ALL wins over both
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_CTYPE", tt.lcCtype)
+		t.Setenv("LANG", tt.lang)
+		if got := localeIsUTF8(); got != tt.want {
+			t.Errorf("LC_ALL=%q LC_CTYPE=%q LANG=%q: got %v, want %v", tt.lcAll, tt.lcCtype, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestUseASCII(t *testing.T) {
+	defer useASCII(false)
+	useASCII(true)
+	if got := sparkline([]float64{0, math.NaN(), 7}); got != "_ #" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := coverageCells([]rune{cellPresent, cellMissing, cellFailed}); got != "#.x" {
+		t.Errorf("coverageCells = %q", got)
+	}
+	useASCII(false)
+	if got := sparkline([]float64{0, 7}); got != "▁█" {
+		t.Errorf("sparkline = %q", got)
+	}
+}
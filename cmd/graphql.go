@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/zalepa/municourt/parser"
+)
+
+// gqlCounty and gqlMunicipality are the sources of the County and
+// Municipality types; their other fields are resolved from the dataset.
+type gqlCounty struct {
+	Name string `json:"name"`
+}
+
+type gqlMunicipality struct {
+	County string `json:"county"`
+	Name   string `json:"name"`
+	ID     string `json:"id"`
+}
+
+type gqlSeries struct {
+	Name   string     `json:"name"`
+	ID     string     `json:"id,omitempty"`
+	Metric string     `json:"metric"`
+	Type   string     `json:"type"`
+	Points []gqlPoint `json:"points"`
+}
+
+type gqlPoint struct {
+	Period string   `json:"period"`
+	Value  *float64 `json:"value"`
+}
+
+// gqlDatasetKey carries the request's dataset to the resolvers.
+type gqlDatasetKey struct{}
+
+func gqlDataset(p graphql.ResolveParams) *dataset {
+	return p.Context.Value(gqlDatasetKey{}).(*dataset)
+}
+
+// graphqlSchema is built once; resolvers find the dataset in the context.
+var graphqlSchema = func() graphql.Schema {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}()
+
+func newGraphQLSchema() (graphql.Schema, error) {
+	option := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Option",
+		Fields: graphql.Fields{
+			"value": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"label": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	point := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Point",
+		Description: "One period's value; null where the report has no data.",
+		Fields: graphql.Fields{
+			"period": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"value":  &graphql.Field{Type: graphql.Float},
+		},
+	})
+	series := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Series",
+		Fields: graphql.Fields{
+			"name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"id":     &graphql.Field{Type: graphql.String, Description: "Stable entity ID of a municipality series within a county."},
+			"metric": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"type":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"points": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(point)))},
+		},
+	})
+	historyEvent := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HistoryEvent",
+		Fields: graphql.Fields{
+			"date":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"kind":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"county": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"from":   &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"to":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"note":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	// seriesArgs are the selection and period range shared by every series
+	// field.
+	seriesArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"metric": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "filings", Description: strings.Join(validMetrics, ", ")},
+			"type":   &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "grand-total", Description: strings.Join(validTypes, ", ")},
+			"from":   &graphql.ArgumentConfig{Type: graphql.String, Description: "first period, YYYY-MM"},
+			"to":     &graphql.ArgumentConfig{Type: graphql.String, Description: "last period, YYYY-MM"},
+		}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+	weightedArg := &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: true, Description: "recompute rate metrics from summed counts rather than averaging"}
+
+	municipality := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Municipality",
+		Fields: graphql.Fields{
+			"county": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Stable entity ID, the same under every name the court has had."},
+			"periods": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Description: "Periods in which the municipality reported.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					m := p.Source.(gqlMunicipality)
+					var periods []string
+					for _, rec := range gqlDataset(p).records {
+						for _, s := range rec.stats {
+							if strings.ToUpper(s.County) == m.County && matchesEntity(s, m.Name) {
+								periods = append(periods, rec.date)
+								break
+							}
+						}
+					}
+					return periods, nil
+				},
+			},
+			"history": &graphql.Field{
+				Type: graphql.NewList(graphql.NewNonNull(historyEvent)),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					m := p.Source.(gqlMunicipality)
+					return parser.HistoryFor(m.County, m.Name), nil
+				},
+			},
+			"series": &graphql.Field{
+				Type: series,
+				Args: seriesArgs(nil),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					m := p.Source.(gqlMunicipality)
+					return gqlResolveSeries(p, "municipality", m.County, m.Name, false)
+				},
+			},
+			"value": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "The value for one period (default the latest).",
+				Args: graphql.FieldConfigArgument{
+					"metric": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "filings"},
+					"type":   &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "grand-total"},
+					"period": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					m := p.Source.(gqlMunicipality)
+					metric, caseType, err := gqlSelection(p)
+					if err != nil {
+						return nil, err
+					}
+					records := gqlDataset(p).records
+					period, _ := p.Args["period"].(string)
+					if period == "" && len(records) > 0 {
+						period = records[len(records)-1].date
+					}
+					for _, rec := range records {
+						if rec.date != period {
+							continue
+						}
+						for _, s := range rec.stats {
+							if strings.ToUpper(s.County) == m.County && matchesEntity(s, m.Name) {
+								if v := getField(getRow(s, metric), caseType); !math.IsNaN(v) {
+									return v, nil
+								}
+							}
+						}
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	county := graphql.NewObject(graphql.ObjectConfig{
+		Name: "County",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"municipalities": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(municipality))),
+				Args: graphql.FieldConfigArgument{
+					"search": &graphql.ArgumentConfig{Type: graphql.String, Description: "case-insensitive substring of the name"},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					search, _ := p.Args["search"].(string)
+					return gqlMunicipalities(gqlDataset(p), p.Source.(gqlCounty).Name, search), nil
+				},
+			},
+			"series": &graphql.Field{
+				Type: series,
+				Args: seriesArgs(graphql.FieldConfigArgument{"weighted": weightedArg}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					weighted, _ := p.Args["weighted"].(bool)
+					return gqlResolveSeries(p, "county", p.Source.(gqlCounty).Name, "", weighted)
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"periods": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					records := gqlDataset(p).records
+					periods := make([]string, len(records))
+					for i, rec := range records {
+						periods[i] = rec.date
+					}
+					return periods, nil
+				},
+			},
+			"metrics": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(option))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return buildMetadata(nil).Metrics, nil
+				},
+			},
+			"types": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(option))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return buildMetadata(nil).Types, nil
+				},
+			},
+			"counties": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(county))),
+				Args: graphql.FieldConfigArgument{
+					"names": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					var want map[string]bool
+					if names, ok := p.Args["names"].([]any); ok {
+						want = make(map[string]bool)
+						for _, n := range names {
+							c, _ := parser.NormalizeCounty(n.(string))
+							want[c] = true
+						}
+					}
+					var counties []gqlCounty
+					for _, c := range buildMetadata(gqlDataset(p).records).Counties {
+						if want == nil || want[c] {
+							counties = append(counties, gqlCounty{Name: c})
+						}
+					}
+					return counties, nil
+				},
+			},
+			"county": &graphql.Field{
+				Type: county,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					name, _ := parser.NormalizeCounty(p.Args["name"].(string))
+					if !contains(buildMetadata(gqlDataset(p).records).Counties, name) {
+						return nil, nil
+					}
+					return gqlCounty{Name: name}, nil
+				},
+			},
+			"municipalities": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(municipality))),
+				Args: graphql.FieldConfigArgument{
+					"county": &graphql.ArgumentConfig{Type: graphql.String},
+					"search": &graphql.ArgumentConfig{Type: graphql.String, Description: "case-insensitive substring of the name"},
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					county, _ := p.Args["county"].(string)
+					if county != "" {
+						county, _ = parser.NormalizeCounty(county)
+					}
+					search, _ := p.Args["search"].(string)
+					munis := gqlMunicipalities(gqlDataset(p), county, search)
+					if limit, ok := p.Args["limit"].(int); ok && limit >= 0 && limit < len(munis) {
+						munis = munis[:limit]
+					}
+					return munis, nil
+				},
+			},
+			"municipality": &graphql.Field{
+				Type: municipality,
+				Args: graphql.FieldConfigArgument{
+					"county": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"name":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					county, _ := parser.NormalizeCounty(p.Args["county"].(string))
+					id := parser.EntityID(county, p.Args["name"].(string))
+					for _, m := range gqlMunicipalities(gqlDataset(p), county, "") {
+						if m.ID == id {
+							return m, nil
+						}
+					}
+					return nil, nil
+				},
+			},
+			"series": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(series))),
+				Description: "Series at one level, as from /api/series, sorted by name.",
+				Args: seriesArgs(graphql.FieldConfigArgument{
+					"level":        &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "county", Description: "state, county or municipality"},
+					"county":       &graphql.ArgumentConfig{Type: graphql.String},
+					"municipality": &graphql.ArgumentConfig{Type: graphql.String},
+					"weighted":     &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false, Description: "recompute rate metrics from summed counts rather than averaging"},
+				}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					level, _ := p.Args["level"].(string)
+					if level != "state" && level != "county" && level != "municipality" {
+						return nil, fmt.Errorf("invalid level %q; valid options: state, county, municipality", level)
+					}
+					county, _ := p.Args["county"].(string)
+					muni, _ := p.Args["municipality"].(string)
+					weighted, _ := p.Args["weighted"].(bool)
+					return gqlBuildSeries(p, level, strings.ToUpper(county), strings.ToUpper(muni), weighted)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// gqlSelection validates the metric and type arguments.
+func gqlSelection(p graphql.ResolveParams) (metric, caseType string, err error) {
+	metric, _ = p.Args["metric"].(string)
+	caseType, _ = p.Args["type"].(string)
+	if !contains(validMetrics, metric) {
+		return "", "", fmt.Errorf("invalid metric %q; valid options: %s", metric, strings.Join(validMetrics, ", "))
+	}
+	if !contains(validTypes, caseType) {
+		return "", "", fmt.Errorf("invalid type %q; valid options: %s", caseType, strings.Join(validTypes, ", "))
+	}
+	return metric, caseType, nil
+}
+
+// gqlBuildSeries returns every series at level matching the filters,
+// limited to the from/to period range.
+func gqlBuildSeries(p graphql.ResolveParams, level, county, municipality string, weighted bool) ([]gqlSeries, error) {
+	metric, caseType, err := gqlSelection(p)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := p.Args["from"].(string)
+	to, _ := p.Args["to"].(string)
+
+	byName, dates := aggregateSeries(gqlDataset(p).records, metric, caseType, level, county, municipality, weighted)
+	var sortedDates []string
+	for _, d := range sortDates(dates) {
+		if (from == "" || d >= from) && (to == "" || d <= to) {
+			sortedDates = append(sortedDates, d)
+		}
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]gqlSeries, 0, len(names))
+	for _, name := range names {
+		values := nullableValues(alignValues(byName[name], sortedDates))
+		points := make([]gqlPoint, len(sortedDates))
+		for i, d := range sortedDates {
+			points[i] = gqlPoint{Period: d, Value: values[i]}
+		}
+		sr := gqlSeries{Name: name, Metric: metric, Type: caseType, Points: points}
+		if level == "municipality" && county != "" {
+			sr.ID = parser.EntityID(county, name)
+		}
+		out = append(out, sr)
+	}
+	return out, nil
+}
+
+// gqlResolveSeries returns the single series for one county or
+// municipality, or nil if it has no data.
+func gqlResolveSeries(p graphql.ResolveParams, level, county, municipality string, weighted bool) (any, error) {
+	all, err := gqlBuildSeries(p, level, county, municipality, weighted)
+	if err != nil || len(all) == 0 {
+		return nil, err
+	}
+	return all[0], nil
+}
+
+// gqlMunicipalities lists the dataset's municipalities, optionally limited
+// to one county and to names containing search, sorted by county and name.
+func gqlMunicipalities(ds *dataset, county, search string) []gqlMunicipality {
+	meta := buildMetadata(ds.records)
+	search = strings.ToUpper(search)
+	var out []gqlMunicipality
+	for _, c := range meta.Counties {
+		if county != "" && c != county {
+			continue
+		}
+		for _, m := range meta.Municipalities[c] {
+			if strings.Contains(m, search) {
+				out = append(out, gqlMunicipality{County: c, Name: m, ID: meta.EntityIDs[c][m]})
+			}
+		}
+	}
+	return out
+}
+
+// graphqlRequest is the standard GraphQL-over-HTTP request body.
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// serveGraphQL serves GraphQL queries sent as a JSON POST body or, for simple
+// queries, as GET ?query=.
+func (s *apiServer) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				http.Error(w, "invalid variables: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+	// Only now, so ?dataset= is read from the URL without consuming the
+	// JSON body as a form.
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         graphqlSchema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        context.WithValue(r.Context(), gqlDatasetKey{}, ds),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
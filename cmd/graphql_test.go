@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestGraphQL(t *testing.T) {
+	ds := &dataset{name: "default", records: []timeRecord{
+		{date: "2023-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			rateStat("ATLANTIC", "BRIGANTINE", "900", "900", "100%"),
+			rateStat("BERGEN", "ALLENDALE", "10", "10", "100%"),
+		}},
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "120", "120", "100%"),
+		}},
+	}}
+	query := func(q string) (string, []string) {
+		t.Helper()
+		res := graphql.Do(graphql.Params{
+			Schema:        graphqlSchema,
+			RequestString: q,
+			Context:       context.WithValue(context.Background(), gqlDatasetKey{}, ds),
+		})
+		var errs []string
+		for _, e := range res.Errors {
+			errs = append(errs, e.Message)
+		}
+		data, _ := json.Marshal(res.Data)
+		return string(data), errs
+	}
+
+	tests := []struct {
+		query, want string
+	}{
+		{`{ periods }`, `{"periods":["2023-06","2024-06"]}`},
+		{`{ counties(names: ["bergen"]) { name municipalities { name } } }`,
+			`{"counties":[{"municipalities":[{"name":"ALLENDALE"}],"name":"BERGEN"}]}`},
+		{`{ municipalities(search: "brig") { county name periods } }`,
+			`{"municipalities":[{"county":"ATLANTIC","name":"BRIGANTINE","periods":["2023-06"]}]}`},
+		{`{ municipality(county: "Atlantic", name: "absecon") { latest: value older: value(period: "2023-06") series(from: "2024-01") { points { period value } } } }`,
+			`{"municipality":{"latest":120,"older":100,"series":{"points":[{"period":"2024-06","value":120}]}}}`},
+		// County rates are weighted by default; BRIGANTINE's gap is null.
+		{`{ county(name: "ATLANTIC") { series(metric: "clearance-pct") { points { value } } } }`,
+			`{"county":{"series":{"points":[{"value":95},{"value":100}]}}}`},
+		{`{ series(level: "municipality", county: "ATLANTIC") { name points { value } } }`,
+			`{"series":[{"name":"ABSECON","points":[{"value":100},{"value":120}]},{"name":"BRIGANTINE","points":[{"value":900},{"value":null}]}]}`},
+		{`{ county(name: "NOWHERE") { name } }`, `{"county":null}`},
+	}
+	for _, tt := range tests {
+		got, errs := query(tt.query)
+		if len(errs) > 0 {
+			t.Errorf("%s: errors %v", tt.query, errs)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.query, got, tt.want)
+		}
+	}
+
+	if _, errs := query(`{ series(metric: "bogus") { name } }`); len(errs) != 1 {
+		t.Errorf("invalid metric: errors = %v", errs)
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/zalepa/municourt/municourtpb"
+	"github.com/zalepa/municourt/parser"
+)
+
+// GRPC implements the "grpc" subcommand: serve the parsed records and
+// series over gRPC, as defined in municourtpb/municourt.proto.
+func GRPC(args []string) {
+	fs := flag.NewFlagSet("grpc", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	addr := fs.String("addr", ":9090", "address to listen on")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt grpc <parsed-dir> [--addr :9090]\n\nServe parsed data over gRPC (service municourt.v1.Municourt).\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	records, err := loadRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "warning: no JSON files found in %s, starting with empty data\n", *dir)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error listening on %s: %v\n", *addr, err)
+		os.Exit(1)
+	}
+	srv := grpc.NewServer()
+	municourtpb.RegisterMunicourtServer(srv, &grpcServer{records: records})
+	// Reflection lets generic clients such as grpcurl list and call the
+	// service without the .proto file.
+	reflection.Register(srv)
+	fmt.Printf("serving gRPC on %s\n", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// grpcServer implements municourtpb.MunicourtServer over loaded records.
+type grpcServer struct {
+	municourtpb.UnimplementedMunicourtServer
+	records []timeRecord
+}
+
+func (g *grpcServer) ListPeriods(ctx context.Context, req *municourtpb.ListPeriodsRequest) (*municourtpb.ListPeriodsResponse, error) {
+	resp := &municourtpb.ListPeriodsResponse{}
+	for _, rec := range g.records {
+		resp.Periods = append(resp.Periods, rec.date)
+	}
+	return resp, nil
+}
+
+func (g *grpcServer) ListMunicipalities(ctx context.Context, req *municourtpb.ListMunicipalitiesRequest) (*municourtpb.ListMunicipalitiesResponse, error) {
+	county := ""
+	if req.County != "" {
+		county, _ = parser.NormalizeCounty(req.County)
+	}
+	meta := buildMetadata(g.records)
+	resp := &municourtpb.ListMunicipalitiesResponse{}
+	for _, c := range meta.Counties {
+		if county != "" && c != county {
+			continue
+		}
+		for _, m := range meta.Municipalities[c] {
+			resp.Municipalities = append(resp.Municipalities, &municourtpb.Municipality{County: c, Name: m, Id: meta.EntityIDs[c][m]})
+		}
+	}
+	return resp, nil
+}
+
+func (g *grpcServer) GetRecords(req *municourtpb.GetRecordsRequest, stream grpc.ServerStreamingServer[municourtpb.Record]) error {
+	county := ""
+	if req.County != "" {
+		county, _ = parser.NormalizeCounty(req.County)
+	}
+	muni := strings.ToUpper(req.Municipality)
+	for _, rec := range g.records {
+		if (req.From != "" && rec.date < req.From) || (req.To != "" && rec.date > req.To) {
+			continue
+		}
+		for _, s := range rec.stats {
+			if county != "" && strings.ToUpper(s.County) != county {
+				continue
+			}
+			if muni != "" && !matchesEntity(s, muni) {
+				continue
+			}
+			if err := stream.Send(&municourtpb.Record{Period: rec.date, Stats: statsToProto(s)}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *grpcServer) GetSeries(ctx context.Context, req *municourtpb.GetSeriesRequest) (*municourtpb.GetSeriesResponse, error) {
+	level, metric, caseType := req.Level, req.Metric, req.Type
+	if level == "" {
+		level = "county"
+	}
+	if metric == "" {
+		metric = "filings"
+	}
+	if caseType == "" {
+		caseType = "grand-total"
+	}
+	if level != "state" && level != "county" && level != "municipality" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid level %q; valid options: state, county, municipality", level)
+	}
+	if !contains(validMetrics, metric) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metric %q; valid options: %s", metric, strings.Join(validMetrics, ", "))
+	}
+	if !contains(validTypes, caseType) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid type %q; valid options: %s", caseType, strings.Join(validTypes, ", "))
+	}
+
+	series, dates := aggregateSeries(g.records, metric, caseType, level, strings.ToUpper(req.County), strings.ToUpper(req.Municipality), req.Weighted)
+	resp := &municourtpb.GetSeriesResponse{Title: metricLabel(metric) + " — " + typeLabel(caseType)}
+	for _, d := range sortDates(dates) {
+		if (req.From == "" || d >= req.From) && (req.To == "" || d <= req.To) {
+			resp.Periods = append(resp.Periods, d)
+		}
+	}
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out := &municourtpb.Series{Name: name}
+		if level == "municipality" && req.County != "" {
+			out.Id = parser.EntityID(req.County, name)
+		}
+		for _, v := range nullableValues(alignValues(series[name], resp.Periods)) {
+			out.Points = append(out.Points, &municourtpb.Point{Value: v})
+		}
+		resp.Series = append(resp.Series, out)
+	}
+	return resp, nil
+}
+
+func statsToProto(s parser.MunicipalityStats) *municourtpb.MunicipalityStats {
+	return &municourtpb.MunicipalityStats{
+		County:                    s.County,
+		Municipality:              s.Municipality,
+		DateRange:                 s.DateRange,
+		Filings:                   sectionWithChangeToProto(s.Filings),
+		Resolutions:               sectionWithChangeToProto(s.Resolutions),
+		Clearance:                 sectionTwoRowToProto(s.Clearance),
+		ClearancePercent:          sectionTwoRowToProto(s.ClearancePct),
+		Backlog:                   sectionWithChangeToProto(s.Backlog),
+		BacklogPer100MthlyFilings: sectionWithChangeToProto(s.BacklogPer100),
+		BacklogPercent:            sectionTwoRowToProto(s.BacklogPct),
+		ActivePending:             sectionWithChangeToProto(s.ActivePending),
+		Predecessors:              s.Predecessors,
+		Successor:                 s.Successor,
+		SourceFile:                s.SourceFile,
+		PageNumber:                int32(s.PageNumber),
+		Warnings:                  s.Warnings,
+		Id:                        entityID(s),
+	}
+}
+
+func sectionWithChangeToProto(s parser.SectionWithChange) *municourtpb.SectionWithChange {
+	return &municourtpb.SectionWithChange{
+		PriorPeriod:   rowToProto(s.PriorPeriod),
+		CurrentPeriod: rowToProto(s.CurrentPeriod),
+		PctChange:     rowToProto(s.PctChange),
+	}
+}
+
+func sectionTwoRowToProto(s parser.SectionTwoRow) *municourtpb.SectionTwoRow {
+	return &municourtpb.SectionTwoRow{
+		PriorPeriod:   rowToProto(s.PriorPeriod),
+		CurrentPeriod: rowToProto(s.CurrentPeriod),
+	}
+}
+
+func rowToProto(r parser.RowData) *municourtpb.RowData {
+	return &municourtpb.RowData{
+		Label:         r.Label,
+		Indictables:   r.Indictables,
+		DpAndPdp:      r.DPAndPDP,
+		OtherCriminal: r.OtherCriminal,
+		CriminalTotal: r.CriminalTotal,
+		Dwi:           r.DWI,
+		TrafficMoving: r.TrafficMoving,
+		Parking:       r.Parking,
+		TrafficTotal:  r.TrafficTotal,
+		GrandTotal:    r.GrandTotal,
+	}
+}
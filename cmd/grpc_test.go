@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/zalepa/municourt/municourtpb"
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestGRPCServer(t *testing.T) {
+	records := []timeRecord{
+		{date: "2023-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			rateStat("ATLANTIC", "BRIGANTINE", "900", "900", "100%"),
+		}},
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "1,200", "120", "10%"),
+		}},
+	}
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	municourtpb.RegisterMunicourtServer(srv, &grpcServer{records: records})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := municourtpb.NewMunicourtClient(conn)
+	ctx := context.Background()
+
+	periods, err := client.ListPeriods(ctx, &municourtpb.ListPeriodsRequest{})
+	if err != nil || len(periods.Periods) != 2 || periods.Periods[1] != "2024-06" {
+		t.Errorf("ListPeriods = %v, %v", periods, err)
+	}
+
+	munis, err := client.ListMunicipalities(ctx, &municourtpb.ListMunicipalitiesRequest{County: "atlantic"})
+	if err != nil || len(munis.Municipalities) != 2 || munis.Municipalities[1].Name != "BRIGANTINE" {
+		t.Errorf("ListMunicipalities = %v, %v", munis, err)
+	}
+
+	stream, err := client.GetRecords(ctx, &municourtpb.GetRecordsRequest{Municipality: "absecon", From: "2024-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*municourtpb.Record
+	for {
+		rec, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, rec)
+	}
+	if len(got) != 1 || got[0].Period != "2024-06" || got[0].Stats.Filings.CurrentPeriod.GrandTotal != "1,200" {
+		t.Errorf("GetRecords = %v", got)
+	}
+
+	series, err := client.GetSeries(ctx, &municourtpb.GetSeriesRequest{Level: "municipality", County: "ATLANTIC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Series) != 2 || len(series.Periods) != 2 {
+		t.Fatalf("GetSeries = %v", series)
+	}
+	// BRIGANTINE has no 2024-06 record, so its second point is unset.
+	b := series.Series[1]
+	if b.Name != "BRIGANTINE" || b.Points[0].GetValue() != 900 || b.Points[1].Value != nil {
+		t.Errorf("BRIGANTINE = %v", b)
+	}
+
+	_, err = client.GetSeries(ctx, &municourtpb.GetSeriesRequest{Metric: "bogus"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid metric: err = %v", err)
+	}
+}
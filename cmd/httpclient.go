@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultHTTPTimeout = 60 * time.Second
+
+// httpClient is used for every request to the courts' site. Commands that
+// download replace it according to their -proxy, -timeout and -insecure flags.
+var httpClient = &http.Client{Timeout: defaultHTTPTimeout}
+
+// newHTTPClient builds a client with an overall request timeout, an optional
+// proxy (otherwise the environment's HTTP_PROXY/HTTPS_PROXY apply), and
+// optionally without TLS certificate verification.
+func newHTTPClient(proxy string, timeout time.Duration, insecure bool) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// httpFlags holds the network flags shared by download and fetch.
+type httpFlags struct {
+	proxy    *string
+	timeout  *time.Duration
+	insecure *bool
+}
+
+func addHTTPFlags(fs *flag.FlagSet) httpFlags {
+	return httpFlags{
+		proxy:    fs.String("proxy", "", "HTTP(S) proxy URL (default: from HTTP_PROXY/HTTPS_PROXY)"),
+		timeout:  fs.Duration("timeout", defaultHTTPTimeout, "per-request timeout, including reading the body (0 for none)"),
+		insecure: fs.Bool("insecure", false, "skip TLS certificate verification"),
+	}
+}
+
+// apply installs a client configured from the parsed flags.
+func (h httpFlags) apply() error {
+	c, err := newHTTPClient(*h.proxy, *h.timeout, *h.insecure)
+	if err != nil {
+		return err
+	}
+	httpClient = c
+	return nil
+}
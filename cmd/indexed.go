@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+)
+
+// indexSeries rescales each series so its value at the base period is 100,
+// letting entities of very different sizes be compared by the shape of
+// their trends. Series with no value or a zero at base can't be indexed;
+// they are left out and their names returned.
+func indexSeries(series map[string][]dataPoint, base string) (map[string][]dataPoint, []string) {
+	out := make(map[string][]dataPoint, len(series))
+	var dropped []string
+	for key, pts := range series {
+		indexed, ok := indexPoints(pts, base)
+		if !ok {
+			dropped = append(dropped, key)
+			continue
+		}
+		out[key] = indexed
+	}
+	return out, dropped
+}
+
+// indexPoints rescales pts to 100 at base, reporting false if it has no
+// non-zero value there.
+func indexPoints(pts []dataPoint, base string) ([]dataPoint, bool) {
+	var b float64
+	for _, p := range pts {
+		if p.date == base {
+			b = p.value
+		}
+	}
+	if b == 0 || math.IsNaN(b) {
+		return nil, false
+	}
+	out := make([]dataPoint, len(pts))
+	for i, p := range pts {
+		out[i] = dataPoint{date: p.date, value: p.value / b * 100}
+	}
+	return out, true
+}
+
+// checkIndexBase reports an error if base isn't one of the charted periods,
+// which after --interval are labels like 2019-Q3 or 2019.
+func checkIndexBase(base string, dates map[string]bool) error {
+	if dates[base] {
+		return nil
+	}
+	sorted := sortDates(dates)
+	if len(sorted) == 0 {
+		return fmt.Errorf("--index %s: no periods to index", base)
+	}
+	return fmt.Errorf("--index %s isn't one of the charted periods (%s to %s)", base, sorted[0], sorted[len(sorted)-1])
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestIndexSeries(t *testing.T) {
+	series := map[string][]dataPoint{
+		"BIG":   {{"2018-06", 5000}, {"2019-06", 4000}, {"2020-06", 2000}},
+		"SMALL": {{"2018-06", 40}, {"2019-06", 50}, {"2020-06", 75}},
+		"NEW":   {{"2020-06", 10}},
+		"ZERO":  {{"2019-06", 0}, {"2020-06", 3}},
+		"GAP":   {{"2019-06", math.NaN()}, {"2020-06", 3}},
+	}
+	got, dropped := indexSeries(series, "2019-06")
+	if want := []float64{125, 100, 50}; !equalValues(got["BIG"], want) {
+		t.Errorf("BIG = %v, want %v", got["BIG"], want)
+	}
+	if want := []float64{80, 100, 150}; !equalValues(got["SMALL"], want) {
+		t.Errorf("SMALL = %v, want %v", got["SMALL"], want)
+	}
+	if len(got) != 2 || len(dropped) != 3 {
+		t.Errorf("kept %d, dropped %v", len(got), dropped)
+	}
+
+	dates := map[string]bool{"2018-06": true, "2019-06": true}
+	if err := checkIndexBase("2019-06", dates); err != nil {
+		t.Error(err)
+	}
+	if err := checkIndexBase("2019-07", dates); err == nil || !strings.Contains(err.Error(), "2018-06 to 2019-06") {
+		t.Errorf("missing base: %v", err)
+	}
+}
+
+func equalValues(pts []dataPoint, want []float64) bool {
+	if len(pts) != len(want) {
+		return false
+	}
+	for i, p := range pts {
+		if math.Abs(p.value-want[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
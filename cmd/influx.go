@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxClient pushes line protocol to InfluxDB. Like webhookClient it
+// doesn't share httpClient's settings, which are for the courts' site.
+var influxClient = &http.Client{Timeout: 60 * time.Second}
+
+// Influx implements the "influx" subcommand: write every municipality's
+// values as InfluxDB line protocol, to a file or stdout or straight to an
+// InfluxDB server, so Grafana can chart them.
+func Influx(args []string) {
+	fs := flag.NewFlagSet("influx", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	out := fs.String("out", "", "output file (default stdout; ignored with --url)")
+	metrics := fs.String("metrics", "", "comma-separated metrics to write: "+strings.Join(validMetrics, ", ")+" (default all)")
+	types := fs.String("types", "", "comma-separated case types to write: "+strings.Join(validTypes, ", ")+" (default all)")
+	prefix := fs.String("measurement-prefix", "", "prefix for measurement names, e.g. municourt_")
+	serverURL := fs.String("url", "", "InfluxDB base URL to write to, e.g. http://localhost:8086")
+	bucket := fs.String("bucket", "", "InfluxDB 2.x bucket (uses /api/v2/write)")
+	org := fs.String("org", "", "InfluxDB 2.x organization")
+	token := fs.String("token", os.Getenv("INFLUX_TOKEN"), "InfluxDB API token (default $INFLUX_TOKEN)")
+	db := fs.String("db", "", "InfluxDB 1.x database (uses /write)")
+	batch := fs.Int("batch", 5000, "lines per write request with --url")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt influx <parsed-dir> [--out file] [--metrics list] [--types list] [--measurement-prefix p]\n"+
+			"                       [--url http://host:8086 (--bucket b --org o [--token t] | --db name)]\n\n"+
+			"Write parsed data as InfluxDB line protocol, or push it to InfluxDB.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	opts := influxOptions{prefix: *prefix}
+	var err error
+	if opts.metrics, err = splitChoices("--metrics", *metrics, validMetrics); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if opts.types, err = splitChoices("--types", *types, validTypes); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if opts.metrics == nil {
+		opts.metrics = validMetrics
+	}
+	if opts.types == nil {
+		opts.types = validTypes
+	}
+	var writeURL string
+	if *serverURL != "" {
+		if writeURL, err = influxWriteURL(*serverURL, *bucket, *org, *db); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if *batch < 1 {
+			fmt.Fprintf(os.Stderr, "--batch must be at least 1\n")
+			os.Exit(1)
+		}
+	}
+
+	records, err := loadMetricRecords(*dir, opts.metrics...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	if writeURL != "" {
+		w := &influxBatchWriter{url: writeURL, token: *token, size: *batch}
+		n, err := writeLineProtocol(w, records, opts)
+		if err == nil {
+			err = w.flush()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error writing to InfluxDB: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "wrote %d points to %s in %d requests\n", n, *serverURL, w.requests)
+		return
+	}
+
+	f, err := createOutput(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating output: %v\n", err)
+		os.Exit(1)
+	}
+	bw := bufio.NewWriter(f)
+	n, err := writeLineProtocol(bw, records, opts)
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing line protocol: %v\n", err)
+		os.Exit(1)
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "wrote %d points to %s\n", n, *out)
+	}
+}
+
+type influxOptions struct {
+	metrics []string
+	types   []string
+	prefix  string
+}
+
+// influxWriteURL returns the write endpoint for an InfluxDB 2.x bucket or a
+// 1.x database. Timestamps are always written in seconds.
+func influxWriteURL(base, bucket, org, db string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid --url %q", base)
+	}
+	q := url.Values{"precision": {"s"}}
+	switch {
+	case bucket != "" && db != "":
+		return "", fmt.Errorf("use either --bucket (InfluxDB 2.x) or --db (InfluxDB 1.x), not both")
+	case bucket != "":
+		if org == "" {
+			return "", fmt.Errorf("--org is required with --bucket")
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v2/write"
+		q.Set("bucket", bucket)
+		q.Set("org", org)
+	case db != "":
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/write"
+		q.Set("db", db)
+	default:
+		return "", fmt.Errorf("--url needs --bucket and --org (InfluxDB 2.x) or --db (InfluxDB 1.x)")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// writeLineProtocol writes one point per municipality, period, metric and
+// case type with a value, returning the number of points written. Points
+// look like:
+//
+//	filings,case_type=grand-total,county=ATLANTIC,entity_id=atlantic.absecon,municipality=ABSECON value=1749 1717200000
+//
+// The measurement is the metric with dashes as underscores, and the
+// timestamp is midnight UTC on the first day of the period's month.
+func writeLineProtocol(w io.Writer, records []timeRecord, opts influxOptions) (int, error) {
+	n := 0
+	for _, rec := range records {
+		t, err := time.Parse("2006-01", rec.date)
+		if err != nil {
+			continue
+		}
+		ts := strconv.FormatInt(t.Unix(), 10)
+		for _, s := range rec.stats {
+			place := ",county=" + escapeInfluxTag(s.County) + ",entity_id=" + escapeInfluxTag(entityID(s)) + ",municipality=" + escapeInfluxTag(s.Municipality)
+			for _, metric := range opts.metrics {
+				measurement := escapeInfluxMeasurement(opts.prefix + strings.ReplaceAll(metric, "-", "_"))
+				row := getRow(s, metric)
+				for _, caseType := range opts.types {
+					v := getField(row, caseType)
+					if math.IsNaN(v) {
+						continue
+					}
+					line := measurement + ",case_type=" + caseType + place +
+						" value=" + strconv.FormatFloat(v, 'f', -1, 64) + " " + ts + "\n"
+					if _, err := io.WriteString(w, line); err != nil {
+						return n, err
+					}
+					n++
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
+var (
+	influxTagEscaper         = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `)
+	influxMeasurementEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, " ", `\ `)
+)
+
+func escapeInfluxTag(s string) string         { return influxTagEscaper.Replace(s) }
+func escapeInfluxMeasurement(s string) string { return influxMeasurementEscaper.Replace(s) }
+
+// influxBatchWriter collects lines and POSTs them to an InfluxDB write
+// endpoint size lines at a time. Call flush after the last write.
+type influxBatchWriter struct {
+	url      string
+	token    string
+	size     int
+	buf      bytes.Buffer
+	lines    int
+	requests int
+}
+
+func (b *influxBatchWriter) Write(p []byte) (int, error) {
+	b.buf.Write(p)
+	b.lines += bytes.Count(p, []byte("\n"))
+	if b.lines >= b.size {
+		if err := b.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (b *influxBatchWriter) flush() error {
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodPost, b.url, bytes.NewReader(b.buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "municourt/"+version())
+	if b.token != "" {
+		req.Header.Set("Authorization", "Token "+b.token)
+	}
+	resp, err := influxClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	b.buf.Reset()
+	b.lines = 0
+	b.requests++
+	return nil
+}
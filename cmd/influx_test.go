@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestWriteLineProtocol(t *testing.T) {
+	records := []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ATLANTIC CITY", "1,749", "1,700", "97.2%"),
+			rateStat("ATLANTIC", "ABSECON", "- -", "10", ""),
+		}},
+	}
+	var sb strings.Builder
+	n, err := writeLineProtocol(&sb, records, influxOptions{
+		metrics: []string{"filings", "clearance-pct"},
+		types:   []string{"grand-total"},
+		prefix:  "mc_",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "mc_filings,case_type=grand-total,county=ATLANTIC,entity_id=atlantic.atlantic-city,municipality=ATLANTIC\\ CITY value=1749 1717200000\n" +
+		"mc_clearance_pct,case_type=grand-total,county=ATLANTIC,entity_id=atlantic.atlantic-city,municipality=ATLANTIC\\ CITY value=97.2 1717200000\n"
+	if got := sb.String(); got != want || n != 2 {
+		t.Errorf("got %d points:\n%s\nwant:\n%s", n, got, want)
+	}
+}
+
+func TestInfluxWriteURL(t *testing.T) {
+	got, err := influxWriteURL("http://localhost:8086/", "courts", "civic", "")
+	if err != nil || got != "http://localhost:8086/api/v2/write?bucket=courts&org=civic&precision=s" {
+		t.Errorf("v2 URL = %q, %v", got, err)
+	}
+	got, err = influxWriteURL("http://localhost:8086", "", "", "courts")
+	if err != nil || got != "http://localhost:8086/write?db=courts&precision=s" {
+		t.Errorf("v1 URL = %q, %v", got, err)
+	}
+	for _, args := range [][4]string{
+		{"localhost:8086", "", "", "courts"},
+		{"http://localhost:8086", "courts", "", ""},
+		{"http://localhost:8086", "courts", "civic", "courts"},
+		{"http://localhost:8086", "", "", ""},
+	} {
+		if _, err := influxWriteURL(args[0], args[1], args[2], args[3]); err == nil {
+			t.Errorf("influxWriteURL%q: want error", args)
+		}
+	}
+}
+
+func TestInfluxBatchWriter(t *testing.T) {
+	var bodies []string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	b := &influxBatchWriter{url: srv.URL, token: "secret", size: 2}
+	for _, line := range []string{"a value=1 1\n", "b value=2 1\n", "c value=3 1\n"} {
+		if _, err := io.WriteString(b, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || bodies[0] != "a value=1 1\nb value=2 1\n" || bodies[1] != "c value=3 1\n" {
+		t.Errorf("bodies = %q", bodies)
+	}
+	if auth != "Token secret" || b.requests != 2 {
+		t.Errorf("auth = %q, requests = %d", auth, b.requests)
+	}
+
+	fail := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer fail.Close()
+	b = &influxBatchWriter{url: fail.URL, size: 10}
+	io.WriteString(b, "a value=1 1\n")
+	if err := b.flush(); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("flush error = %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// validIntervals are the periods --interval can roll report periods up to.
+// With --court-year, quarter and year become "court-quarter" and
+// "court-year" (see withCourtYear).
+var validIntervals = []string{"month", "quarter", "year"}
+
+// stockMetrics are counts taken at a point in time rather than accumulated
+// over the period, so a rolled-up interval keeps its last value instead of
+// the sum.
+var stockMetrics = map[string]bool{
+	"backlog":        true,
+	"active-pending": true,
+}
+
+// withCourtYear maps an --interval value to its court-year form when
+// courtYear is set.
+func withCourtYear(interval string, courtYear bool) string {
+	if courtYear && (interval == "quarter" || interval == "year") {
+		return "court-" + interval
+	}
+	return interval
+}
+
+// intervalKey returns the label of the interval a YYYY-MM period falls in:
+// the period itself for "month", "2024-Q3" for "quarter", "2024" for
+// "year", and for the July–June court year "CY2024-25 Q1" for
+// "court-quarter" and "CY2024-25" for "court-year". Anything that isn't a
+// YYYY-MM period is returned as is.
+func intervalKey(date, interval string) string {
+	t, err := time.Parse("2006-01", date)
+	if err != nil {
+		return date
+	}
+	quarter := (int(t.Month()) + 2) / 3
+	switch interval {
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", t.Year(), quarter)
+	case "year":
+		return fmt.Sprint(t.Year())
+	case "court-quarter":
+		return fmt.Sprintf("%s Q%d", courtYearLabel(t), (quarter+1)%4+1)
+	case "court-year":
+		return courtYearLabel(t)
+	}
+	return date
+}
+
+// courtYearLabel names the court year, July to June, that t falls in, e.g.
+// "CY2023-24" for 2024-06 and "CY2024-25" for 2024-07.
+func courtYearLabel(t time.Time) string {
+	start := t.Year()
+	if t.Month() < time.July {
+		start--
+	}
+	return fmt.Sprintf("CY%d-%02d", start, (start+1)%100)
+}
+
+// intervalSeries builds series like aggregateSeries, then rolls each
+// entity's periods up to interval: counts are summed (stock counts keep the
+// interval's last value) and rates averaged, or with weighted recomputed
+// from the interval's summed components. The dates returned are interval
+// labels.
+func intervalSeries(records []timeRecord, metric, caseType, level, county, municipality string, weighted bool, interval string) (map[string][]dataPoint, map[string]bool) {
+	rc, isRatio := rateComponents[metric]
+	if interval == "month" || interval == "" {
+		return aggregateSeries(records, metric, caseType, level, county, municipality, weighted)
+	}
+	if weighted && isRatio {
+		num, den, dates := componentSeries(records, rc, caseType, level, county, municipality)
+		series := make(map[string][]dataPoint)
+		for key := range num {
+			n, d := rollup(num[key], interval, sumValues), rollup(den[key], interval, sumValues)
+			for i := range n {
+				if d[i].value != 0 {
+					series[key] = append(series[key], dataPoint{date: n[i].date, value: n[i].value / d[i].value * rc.scale})
+				}
+			}
+		}
+		return series, intervalDates(dates, interval)
+	}
+
+	series, dates := aggregateSeries(records, metric, caseType, level, county, municipality, weighted)
+	for key, pts := range series {
+		series[key] = rollup(pts, interval, rollupCombine(metric))
+	}
+	return series, intervalDates(dates, interval)
+}
+
+// rollupCombine returns how metric's values within an interval combine:
+// the sum for counts, the last value for stock counts, and the mean for
+// rates.
+func rollupCombine(metric string) func([]float64) float64 {
+	switch {
+	case rateMetrics[metric]:
+		return meanValues
+	case stockMetrics[metric]:
+		return lastValue
+	}
+	return sumValues
+}
+
+// rollup combines the non-NaN values of each interval in pts. Intervals
+// with none are left out.
+func rollup(pts []dataPoint, interval string, combine func([]float64) float64) []dataPoint {
+	sorted := append([]dataPoint(nil), pts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].date < sorted[j].date })
+
+	var out []dataPoint
+	var vals []float64
+	flush := func(key string) {
+		if len(vals) > 0 {
+			out = append(out, dataPoint{date: key, value: combine(vals)})
+		}
+		vals = vals[:0]
+	}
+	for i, p := range sorted {
+		key := intervalKey(p.date, interval)
+		if i > 0 && key != intervalKey(sorted[i-1].date, interval) {
+			flush(intervalKey(sorted[i-1].date, interval))
+		}
+		if !math.IsNaN(p.value) {
+			vals = append(vals, p.value)
+		}
+	}
+	if len(sorted) > 0 {
+		flush(intervalKey(sorted[len(sorted)-1].date, interval))
+	}
+	return out
+}
+
+func intervalDates(dates map[string]bool, interval string) map[string]bool {
+	out := make(map[string]bool, len(dates))
+	for d := range dates {
+		out[intervalKey(d, interval)] = true
+	}
+	return out
+}
+
+func sumValues(vals []float64) float64 {
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	return sum
+}
+
+func meanValues(vals []float64) float64 { return sumValues(vals) / float64(len(vals)) }
+
+func lastValue(vals []float64) float64 { return vals[len(vals)-1] }
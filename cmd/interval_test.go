@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestIntervalKey(t *testing.T) {
+	for _, tt := range []struct{ date, interval, want string }{
+		{"2024-03", "month", "2024-03"},
+		{"2024-03", "quarter", "2024-Q1"},
+		{"2024-04", "quarter", "2024-Q2"},
+		{"2024-12", "quarter", "2024-Q4"},
+		{"2024-12", "year", "2024"},
+		{"2024-06", "court-year", "CY2023-24"},
+		{"2024-07", "court-year", "CY2024-25"},
+		{"1999-07", "court-year", "CY1999-00"},
+		{"2024-07", "court-quarter", "CY2024-25 Q1"},
+		{"2024-12", "court-quarter", "CY2024-25 Q2"},
+		{"2025-03", "court-quarter", "CY2024-25 Q3"},
+		{"2025-06", "court-quarter", "CY2024-25 Q4"},
+		{"CY2024-25", "year", "CY2024-25"},
+	} {
+		if got := intervalKey(tt.date, tt.interval); got != tt.want {
+			t.Errorf("intervalKey(%s, %s) = %s, want %s", tt.date, tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestIntervalSeries(t *testing.T) {
+	withBacklog := func(s parser.MunicipalityStats, backlog string) parser.MunicipalityStats {
+		s.Backlog.CurrentPeriod.GrandTotal = backlog
+		return s
+	}
+	records := []timeRecord{
+		{date: "2024-01", stats: []parser.MunicipalityStats{withBacklog(rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"), "10")}},
+		{date: "2024-02", stats: []parser.MunicipalityStats{withBacklog(rateStat("ATLANTIC", "ABSECON", "300", "300", "100%"), "20")}},
+		{date: "2024-04", stats: []parser.MunicipalityStats{withBacklog(rateStat("ATLANTIC", "ABSECON", "50", "50", "100%"), "5")}},
+	}
+	value := func(metric string, weighted bool) []dataPoint {
+		series, _ := intervalSeries(records, metric, "grand-total", "municipality", "ATLANTIC", "ABSECON", weighted, "quarter")
+		return series["ABSECON"]
+	}
+
+	if got := value("filings", false); len(got) != 2 || got[0] != (dataPoint{"2024-Q1", 400}) || got[1] != (dataPoint{"2024-Q2", 50}) {
+		t.Errorf("filings = %v, want Q1 400 and Q2 50", got)
+	}
+	if got := value("backlog", false); got[0].value != 20 {
+		t.Errorf("backlog Q1 = %v, want the last month's 20", got[0].value)
+	}
+	if got := value("clearance-pct", false); got[0].value != 75 {
+		t.Errorf("clearance-pct Q1 = %v, want the mean 75", got[0].value)
+	}
+	if got := value("clearance-pct", true); got[0].value != 87.5 {
+		t.Errorf("weighted clearance-pct Q1 = %v, want 350/400 = 87.5", got[0].value)
+	}
+
+	_, dates := intervalSeries(records, "filings", "grand-total", "state", "", "", false, "year")
+	if len(dates) != 1 || !dates["2024"] {
+		t.Errorf("dates = %v, want just 2024", dates)
+	}
+}
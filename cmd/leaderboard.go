@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mover is one municipality's change in a metric between two periods.
+type mover struct {
+	County       string   `json:"county"`
+	Municipality string   `json:"municipality"`
+	From         float64  `json:"from"`
+	To           float64  `json:"to"`
+	Change       float64  `json:"change"`
+	PctChange    *float64 `json:"pctChange"` // nil when From is zero
+}
+
+// leaderboard ranks municipalities by how much a metric moved between
+// FromDate and ToDate.
+type leaderboard struct {
+	Metric       string  `json:"metric"`
+	Type         string  `json:"type"`
+	FromDate     string  `json:"fromDate"`
+	ToDate       string  `json:"toDate"`
+	CourtYears   bool    `json:"courtYears,omitempty"` // FromDate and ToDate end court years
+	Increases    []mover `json:"increases"`
+	Decreases    []mover `json:"decreases"`
+	PctIncreases []mover `json:"pctIncreases"`
+	PctDecreases []mover `json:"pctDecreases"`
+}
+
+// windowStart returns the latest of dates (sorted YYYY-MM) that is at least
+// months before the last one. Reports aren't published every month, so the
+// exact month is often missing.
+func windowStart(dates []string, months int) (string, bool) {
+	if len(dates) == 0 {
+		return "", false
+	}
+	last, err := time.Parse("2006-01", dates[len(dates)-1])
+	if err != nil {
+		return "", false
+	}
+	target := last.AddDate(0, -months, 0).Format("2006-01")
+	for i := len(dates) - 1; i >= 0; i-- {
+		if dates[i] <= target {
+			return dates[i], true
+		}
+	}
+	return "", false
+}
+
+// courtYearEnd returns the index of the last period of the newest court
+// year (July–June) in dates that has ended: a June period, or the last
+// period before one in a later court year.
+func courtYearEnd(dates []string) (int, bool) {
+	for i := len(dates) - 1; i >= 0; i-- {
+		if strings.HasSuffix(dates[i], "-06") {
+			return i, true
+		}
+		if i+1 < len(dates) && intervalKey(dates[i], "court-year") != intervalKey(dates[i+1], "court-year") {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// buildLeaderboard compares every municipality with a value in both periods
+// and keeps the top n of each ranking. Percentage rankings skip entities
+// whose starting value is below minBase, so tiny courts don't crowd them.
+func buildLeaderboard(records []timeRecord, metric, caseType, from, to string, n int, minBase float64) leaderboard {
+	lb := leaderboard{Metric: metric, Type: caseType, FromDate: from, ToDate: to}
+
+	start := make(map[string]float64)
+	for _, v := range periodValues(records, metric, caseType, from) {
+		start[v.ID] = v.Value
+	}
+	var movers []mover
+	for _, v := range periodValues(records, metric, caseType, to) {
+		base, ok := start[v.ID]
+		if !ok {
+			continue
+		}
+		m := mover{County: v.County, Municipality: v.Municipality, From: base, To: v.Value, Change: v.Value - base}
+		if base != 0 {
+			pct := m.Change / math.Abs(base) * 100
+			m.PctChange = &pct
+		}
+		movers = append(movers, m)
+	}
+
+	top := func(keep func(mover) bool, less func(a, b mover) bool) []mover {
+		var out []mover
+		for _, m := range movers {
+			if keep(m) {
+				out = append(out, m)
+			}
+		}
+		sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+		if len(out) > n {
+			out = out[:n]
+		}
+		return out
+	}
+	hasPct := func(m mover) bool { return m.PctChange != nil && math.Abs(m.From) >= minBase }
+
+	lb.Increases = top(func(m mover) bool { return m.Change > 0 },
+		func(a, b mover) bool { return a.Change > b.Change })
+	lb.Decreases = top(func(m mover) bool { return m.Change < 0 },
+		func(a, b mover) bool { return a.Change < b.Change })
+	lb.PctIncreases = top(func(m mover) bool { return hasPct(m) && *m.PctChange > 0 },
+		func(a, b mover) bool { return *a.PctChange > *b.PctChange })
+	lb.PctDecreases = top(func(m mover) bool { return hasPct(m) && *m.PctChange < 0 },
+		func(a, b mover) bool { return *a.PctChange < *b.PctChange })
+	return lb
+}
+
+// Leaderboard implements the "leaderboard" subcommand: the municipalities
+// whose metric moved the most over a window.
+func Leaderboard(args []string) {
+	fs := flag.NewFlagSet("leaderboard", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	metric := fs.String("metric", "backlog", "metric to compare")
+	caseType := fs.String("type", "grand-total", "case type column")
+	window := fs.Int("window", 12, "months between the compared periods")
+	n := fs.Int("top", 10, "entries per ranking")
+	minBase := fs.Float64("min-base", 0, "skip starting values below this in the percentage rankings")
+	format := fs.String("format", "table", "output format: table, csv, json")
+	courtYear := fs.Bool("court-year", false, "compare the ends of court years (July–June) instead of the newest period")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt leaderboard [dir] [--metric backlog] [--window 12] [--court-year] [--top 10] [--format table|csv|json]\n\nList the municipalities with the largest increases and decreases over a window.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if !contains(validMetrics, *metric) {
+		fmt.Fprintf(os.Stderr, "invalid --metric %q; valid options: %s\n", *metric, strings.Join(validMetrics, ", "))
+		os.Exit(1)
+	}
+	if !contains(validTypes, *caseType) {
+		fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, csv, json\n", *format)
+		os.Exit(1)
+	}
+	if *window < 1 {
+		fmt.Fprintf(os.Stderr, "--window must be at least 1\n")
+		os.Exit(1)
+	}
+
+	records, err := loadMetricRecords(*dir, *metric)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	dates := make([]string, len(records))
+	for i, rec := range records {
+		dates[i] = rec.date
+	}
+	if *courtYear {
+		end, ok := courtYearEnd(dates)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "no completed court year in %s\n", *dir)
+			os.Exit(1)
+		}
+		dates = dates[:end+1]
+	}
+	to := dates[len(dates)-1]
+	from, ok := windowStart(dates, *window)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "no period at least %d months before %s\n", *window, to)
+		os.Exit(1)
+	}
+
+	lb := buildLeaderboard(records, *metric, *caseType, from, to, *n, *minBase)
+	lb.CourtYears = *courtYear
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(lb)
+	case "csv":
+		err = writeLeaderboardCSV(os.Stdout, lb)
+	default:
+		renderLeaderboard(lb)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// ranking is one of a leaderboard's lists, named as in the CSV output.
+type ranking struct {
+	name   string
+	movers []mover
+}
+
+func (lb leaderboard) rankings() []ranking {
+	return []ranking{
+		{"increase", lb.Increases},
+		{"decrease", lb.Decreases},
+		{"pct-increase", lb.PctIncreases},
+		{"pct-decrease", lb.PctDecreases},
+	}
+}
+
+func renderLeaderboard(lb leaderboard) {
+	if lb.CourtYears {
+		fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("%s (%s): end of %s %s end of %s (%s %s %s)", metricLabel(lb.Metric), typeLabel(lb.Type),
+			intervalKey(lb.FromDate, "court-year"), glyphs.arrow, intervalKey(lb.ToDate, "court-year"), lb.FromDate, glyphs.arrow, lb.ToDate)))
+	} else {
+		fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("%s (%s): %s %s %s", metricLabel(lb.Metric), typeLabel(lb.Type), lb.FromDate, glyphs.arrow, lb.ToDate)))
+	}
+	titles := map[string]string{
+		"increase":     "Largest increases",
+		"decrease":     "Largest decreases",
+		"pct-increase": "Largest % increases",
+		"pct-decrease": "Largest % decreases",
+	}
+	for _, r := range lb.rankings() {
+		fmt.Printf("\n%s\n", paint(colorStdout, styleBold, titles[r.name]))
+		if len(r.movers) == 0 {
+			fmt.Println("  (none)")
+			continue
+		}
+		for i, m := range r.movers {
+			fmt.Printf("%3d. %-12s %-30s %12s %s %-12s %12s %9s\n", i+1, m.County, m.Municipality,
+				formatNum(m.From), glyphs.arrow, formatNum(m.To), signed(m.Change), formatChangePct(m.PctChange))
+		}
+	}
+}
+
+func writeLeaderboardCSV(out io.Writer, lb leaderboard) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"Ranking", "Rank", "County", "Municipality", "From", "To", "FromDate", "ToDate", "Change", "PctChange"})
+	for _, r := range lb.rankings() {
+		for i, m := range r.movers {
+			pct := ""
+			if m.PctChange != nil {
+				pct = strconv.FormatFloat(*m.PctChange, 'f', 2, 64)
+			}
+			w.Write([]string{r.name, strconv.Itoa(i + 1), m.County, m.Municipality,
+				strconv.FormatFloat(m.From, 'f', -1, 64), strconv.FormatFloat(m.To, 'f', -1, 64),
+				lb.FromDate, lb.ToDate, strconv.FormatFloat(m.Change, 'f', -1, 64), pct})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// signed formats v like formatNum with an explicit sign.
+func signed(v float64) string {
+	if v > 0 {
+		return "+" + formatNum(v)
+	}
+	return formatNum(v)
+}
+
+func formatChangePct(p *float64) string {
+	if p == nil {
+		return "- -"
+	}
+	return fmt.Sprintf("%+.1f%%", *p)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestWindowStart(t *testing.T) {
+	dates := []string{"2023-06", "2024-06", "2025-06", "2025-12"}
+	tests := []struct {
+		months int
+		want   string
+		ok     bool
+	}{
+		{6, "2025-06", true},
+		{12, "2024-06", true}, // 2024-12 isn't on disk
+		{18, "2024-06", true},
+		{36, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := windowStart(dates, tt.months)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("windowStart(%d) = %q, %v; want %q, %v", tt.months, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBuildLeaderboard(t *testing.T) {
+	records := []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "1000", "", ""),
+			rateStat("ATLANTIC", "BRIGANTINE", "10", "", ""),
+			rateStat("ATLANTIC", "CORBIN CITY", "0", "", ""),
+			rateStat("ATLANTIC", "EGG HARBOR CITY", "500", "", ""),
+		}},
+		{date: "2025-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "1500", "", ""),
+			rateStat("ATLANTIC", "BRIGANTINE", "40", "", ""),
+			rateStat("ATLANTIC", "CORBIN CITY", "5", "", ""),
+			rateStat("ATLANTIC", "EGG HARBOR CITY", "400", "", ""),
+			rateStat("ATLANTIC", "ESTELL MANOR", "900", "", ""), // no starting value
+		}},
+	}
+
+	lb := buildLeaderboard(records, "filings", "grand-total", "2024-06", "2025-06", 2, 0)
+	if len(lb.Increases) != 2 || lb.Increases[0].Municipality != "ABSECON" || lb.Increases[1].Municipality != "BRIGANTINE" {
+		t.Errorf("increases = %+v", lb.Increases)
+	}
+	if len(lb.Decreases) != 1 || lb.Decreases[0].Change != -100 {
+		t.Errorf("decreases = %+v", lb.Decreases)
+	}
+	// CORBIN CITY started at zero, so it has no percentage change.
+	if len(lb.PctIncreases) != 2 || lb.PctIncreases[0].Municipality != "BRIGANTINE" || *lb.PctIncreases[0].PctChange != 300 {
+		t.Errorf("pct increases = %+v", lb.PctIncreases)
+	}
+
+	lb = buildLeaderboard(records, "filings", "grand-total", "2024-06", "2025-06", 2, 100)
+	if len(lb.PctIncreases) != 1 || lb.PctIncreases[0].Municipality != "ABSECON" {
+		t.Errorf("pct increases with min base = %+v", lb.PctIncreases)
+	}
+}
+
+func TestCourtYearEnd(t *testing.T) {
+	tests := []struct {
+		dates []string
+		want  int
+		ok    bool
+	}{
+		{[]string{"2023-06", "2024-06", "2024-12"}, 1, true},
+		{[]string{"2023-05", "2024-05", "2024-08"}, 1, true}, // no June report
+		{[]string{"2024-08", "2024-12"}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := courtYearEnd(tt.dates)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("courtYearEnd(%v) = %d, %v; want %d, %v", tt.dates, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,623 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// defaultLedgerName is the ledger file in a parsed directory.
+const defaultLedgerName = "ledger.jsonl"
+
+// ledgerEntry is one line of the append-only ledger. A "parse" entry
+// records the values of one court's report that were new or differed from
+// those earlier parses recorded when parsed output was ingested; a "manual"
+// entry records a hand correction; a "reject" entry withdraws an earlier
+// entry, so the values before it apply again. Entries are never rewritten.
+type ledgerEntry struct {
+	Seq          int               `json:"seq"`
+	RecordedAt   time.Time         `json:"recordedAt"`
+	Kind         string            `json:"kind"`
+	Period       string            `json:"period,omitempty"`
+	County       string            `json:"county,omitempty"`
+	Municipality string            `json:"municipality,omitempty"`
+	Values       map[string]string `json:"values,omitempty"` // by CSV column name
+	Source       *ledgerSource     `json:"source,omitempty"`
+	Rejects      int               `json:"rejects,omitempty"` // seq withdrawn by a reject entry
+	Note         string            `json:"note,omitempty"`
+}
+
+// ledgerSource is where a parse entry's values came from.
+type ledgerSource struct {
+	File     string    `json:"file"`
+	SHA256   string    `json:"sha256,omitempty"`
+	Page     int       `json:"page,omitempty"`
+	ParsedAt time.Time `json:"parsedAt,omitempty"`
+	Version  string    `json:"municourtVersion,omitempty"`
+}
+
+// ledgerValue is a value as the ledger accepts it, with the entry that set
+// it.
+type ledgerValue struct {
+	value string
+	entry *ledgerEntry
+}
+
+// ledgerRecord is one court's report as the ledger accepts it.
+type ledgerRecord struct {
+	period, county, municipality string
+	values                       map[string]ledgerValue
+	first                        int // seq of the first entry, for ordering
+}
+
+func ledgerKey(period, county, municipality string) string {
+	return period + "|" + strings.ToUpper(county) + "|" + strings.ToUpper(municipality)
+}
+
+// ledgerFields returns the column names (as in the CSV output) of the
+// values the ledger tracks, with pointers to them in s.
+func ledgerFields(s *parser.MunicipalityStats) ([]string, []*string) {
+	names := []string{"DateRange"}
+	ptrs := []*string{&s.DateRange}
+	for _, rr := range recordRows {
+		r := rr.get(s)
+		names = append(names, rr.prefix+"_Label")
+		ptrs = append(ptrs, &r.Label)
+		for i, p := range []*string{&r.Indictables, &r.DPAndPDP, &r.OtherCriminal, &r.CriminalTotal,
+			&r.DWI, &r.TrafficMoving, &r.Parking, &r.TrafficTotal, &r.GrandTotal} {
+			names = append(names, rr.prefix+"_"+caseTypeColumns[i])
+			ptrs = append(ptrs, p)
+		}
+	}
+	return names, ptrs
+}
+
+// readLedger reads every entry of the ledger at path, which may not exist
+// yet.
+func readLedger(path string) ([]ledgerEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []ledgerEntry
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var e ledgerEntry
+		if err := dec.Decode(&e); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: entry %d: %w", path, len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// appendLedger writes entries to the end of the ledger at path, numbering
+// them after the last of existing.
+func appendLedger(path string, existing, entries []ledgerEntry) error {
+	next := 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Seq + 1
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i := range entries {
+		entries[i].Seq = next + i
+		if err := enc.Encode(entries[i]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// replayLedger returns the records as the ledger accepted them at asOf
+// (every entry when asOf is zero): entries recorded later are ignored, as
+// are entries withdrawn by a reject recorded by then.
+func replayLedger(entries []ledgerEntry, asOf time.Time) map[string]*ledgerRecord {
+	visible := func(e ledgerEntry) bool { return asOf.IsZero() || !e.RecordedAt.After(asOf) }
+	rejected := make(map[int]bool)
+	for _, e := range entries {
+		if e.Kind == "reject" && visible(e) {
+			rejected[e.Rejects] = true
+		}
+	}
+	records := make(map[string]*ledgerRecord)
+	for i := range entries {
+		e := &entries[i]
+		if e.Kind == "reject" || rejected[e.Seq] || !visible(*e) {
+			continue
+		}
+		key := ledgerKey(e.Period, e.County, e.Municipality)
+		rec, ok := records[key]
+		if !ok {
+			rec = &ledgerRecord{period: e.Period, county: e.County, municipality: e.Municipality, values: make(map[string]ledgerValue), first: e.Seq}
+			records[key] = rec
+		}
+		for field, v := range e.Values {
+			rec.values[field] = ledgerValue{value: v, entry: e}
+		}
+	}
+	return records
+}
+
+// parsedValues returns, by ledgerKey and then field, the newest value
+// each field was given by a parse entry, whether or not it was since
+// withdrawn or corrected. Ingest compares against these, so re-reading an
+// unchanged report doesn't undo a manual correction or bring back a
+// withdrawn value.
+func parsedValues(entries []ledgerEntry) map[string]map[string]string {
+	parsed := make(map[string]map[string]string)
+	for _, e := range entries {
+		if e.Kind != "parse" {
+			continue
+		}
+		key := ledgerKey(e.Period, e.County, e.Municipality)
+		if parsed[key] == nil {
+			parsed[key] = make(map[string]string)
+		}
+		for field, v := range e.Values {
+			parsed[key][field] = v
+		}
+	}
+	return parsed
+}
+
+// ingestEntries compares the records of a parsed output file, named file,
+// with the values earlier parses recorded, as parsedValues returns them,
+// and returns a parse entry for each record with new or changed values,
+// holding just those values.
+func ingestEntries(parsed map[string]map[string]string, file, date string, out parser.Output, now time.Time) []ledgerEntry {
+	var entries []ledgerEntry
+	for _, s := range out.Records {
+		names, ptrs := ledgerFields(&s)
+		prev := parsed[ledgerKey(date, s.County, s.Municipality)]
+		changed := make(map[string]string)
+		for i, name := range names {
+			if old, ok := prev[name]; !ok || old != *ptrs[i] {
+				changed[name] = *ptrs[i]
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		// Output from before provenance headers names only itself.
+		src := &ledgerSource{File: s.SourceFile, Page: s.PageNumber}
+		if p := out.Provenance; p != nil {
+			src.File, src.SHA256, src.ParsedAt, src.Version = p.SourceFile, p.SHA256, p.ParsedAt, p.Version
+		}
+		if src.File == "" {
+			src.File = file
+		}
+		entries = append(entries, ledgerEntry{
+			RecordedAt: now, Kind: "parse", Period: date,
+			County: s.County, Municipality: s.Municipality, Values: changed, Source: src,
+		})
+	}
+	return entries
+}
+
+// materializeLedger rebuilds parse output, one file's records per period,
+// from accepted ledger records.
+func materializeLedger(records map[string]*ledgerRecord) map[string][]parser.MunicipalityStats {
+	var recs []*ledgerRecord
+	for _, r := range records {
+		recs = append(recs, r)
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].first < recs[j].first })
+
+	out := make(map[string][]parser.MunicipalityStats)
+	for _, r := range recs {
+		s := parser.MunicipalityStats{County: r.county, Municipality: r.municipality}
+		names, ptrs := ledgerFields(&s)
+		latest := 0
+		for i, name := range names {
+			v, ok := r.values[name]
+			if !ok {
+				continue
+			}
+			*ptrs[i] = v.value
+			// The record's source is that of its newest parsed value.
+			if v.entry.Source != nil && v.entry.Seq > latest {
+				latest = v.entry.Seq
+				s.SourceFile, s.PageNumber = v.entry.Source.File, v.entry.Source.Page
+			}
+		}
+		parser.AnnotateHistory(&s)
+		s.ParsePeriods()
+		out[r.period] = append(out[r.period], s)
+	}
+	return out
+}
+
+// Ledger implements the "ledger" subcommand: an append-only record of
+// every parsed value with its source, plus corrections, from which the
+// data can be rebuilt as it stood at any time.
+func Ledger(args []string) {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, `Usage: municourt ledger <command> [flags]
+
+Keep an append-only ledger of parsed values and corrections.
+
+Commands:
+  ingest <parsed-dir>     record new and changed values from parsed output
+  correct                 record a manual correction to one value
+  reject                  withdraw an earlier entry
+  history                 list the entries for a court's report
+  materialize --out dir   write parsed output as accepted at --as-of
+
+Run "municourt ledger <command> -h" for a command's flags.
+`)
+	}
+	if len(args) < 1 {
+		usage()
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "ingest":
+		ledgerIngest(args[1:])
+	case "correct":
+		ledgerCorrect(args[1:])
+	case "reject":
+		ledgerReject(args[1:])
+	case "history":
+		ledgerHistory(args[1:])
+	case "materialize":
+		ledgerMaterialize(args[1:])
+	case "-h", "-help", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown ledger command: %s\n\n", args[0])
+		usage()
+		os.Exit(1)
+	}
+}
+
+// loadLedgerOrExit reads the ledger at path, exiting on error.
+func loadLedgerOrExit(path string) []ledgerEntry {
+	entries, err := readLedger(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading ledger: %v\n", err)
+		os.Exit(1)
+	}
+	return entries
+}
+
+// ledgerFlags locate the ledger, the same way for every ledger command.
+type ledgerFlags struct {
+	dir, path *string
+}
+
+func addLedgerFlags(fs *flag.FlagSet) ledgerFlags {
+	return ledgerFlags{
+		dir:  fs.String("dir", ".", "directory containing parsed JSON files"),
+		path: fs.String("ledger", "", "ledger file (default "+defaultLedgerName+" in the parsed directory)"),
+	}
+}
+
+// resolve reads the parsed directory from the first argument, if given,
+// and returns it and the ledger's path.
+func (f ledgerFlags) resolve(fs *flag.FlagSet) (dir, path string) {
+	if fs.NArg() > 0 {
+		*f.dir = fs.Arg(0)
+	}
+	if *f.path == "" {
+		*f.path = filepath.Join(*f.dir, defaultLedgerName)
+	}
+	return *f.dir, *f.path
+}
+
+func ledgerIngest(args []string) {
+	fs := flag.NewFlagSet("ledger ingest", flag.ExitOnError)
+	loc := addLedgerFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "report what would be recorded without writing")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt ledger ingest <parsed-dir> [--ledger path] [--dry-run]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	dir, ledgerPath := loc.resolve(fs)
+
+	entries := loadLedgerOrExit(ledgerPath)
+	parsed := parsedValues(entries)
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	var added []ledgerEntry
+	files := 0
+	for _, path := range matches {
+		m := datePattern.FindStringSubmatch(filepath.Base(path))
+		if m == nil || !isOutputJSON(path) {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		out, err := parser.ReadOutput(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		files++
+		added = append(added, ingestEntries(parsed, filepath.Base(path), m[1]+"-"+m[2], out, now)...)
+	}
+	if files == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", dir)
+		os.Exit(1)
+	}
+
+	newRecords, changed := 0, 0
+	for _, e := range added {
+		prev, ok := parsed[ledgerKey(e.Period, e.County, e.Municipality)]
+		if !ok {
+			newRecords++
+			continue
+		}
+		for field := range e.Values {
+			if _, had := prev[field]; had {
+				changed++
+			}
+		}
+	}
+	verb := "recorded"
+	if *dryRun {
+		verb = "would record"
+	} else if len(added) > 0 {
+		if err := appendLedger(ledgerPath, entries, added); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing ledger: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d files: %s %d new records and %d changed values in %s\n", files, verb, newRecords, changed, ledgerPath)
+}
+
+func ledgerCorrect(args []string) {
+	fs := flag.NewFlagSet("ledger correct", flag.ExitOnError)
+	loc := addLedgerFlags(fs)
+	period := fs.String("period", "", "report period (YYYY-MM)")
+	county := fs.String("county", "", "county")
+	municipality := fs.String("municipality", "", "municipality")
+	field := fs.String("field", "", "value to correct, by CSV column name (e.g. Filings_Current_GrandTotal)")
+	value := fs.String("value", "", "corrected value, as the report would print it")
+	note := fs.String("note", "", "why the value was corrected")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt ledger correct --period 2024-06 --county ATLANTIC --municipality ABSECON --field Filings_Current_GrandTotal --value 1,234 --note \"reason\" [--dir parsed-dir] [--ledger path]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	_, ledgerPath := loc.resolve(fs)
+
+	if *period == "" || *county == "" || *municipality == "" || *field == "" || *note == "" {
+		fmt.Fprintf(os.Stderr, "--period, --county, --municipality, --field and --note are required\n")
+		os.Exit(1)
+	}
+	names, _ := ledgerFields(&parser.MunicipalityStats{})
+	if !contains(names, *field) {
+		fmt.Fprintf(os.Stderr, "unknown --field %q; fields are the per-file CSV columns after Municipality, e.g. Filings_Current_GrandTotal\n", *field)
+		os.Exit(1)
+	}
+	entries := loadLedgerOrExit(ledgerPath)
+	rec, ok := replayLedger(entries, time.Time{})[ledgerKey(*period, *county, *municipality)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s/%s has no record for %s in %s\n", *county, *municipality, *period, ledgerPath)
+		os.Exit(1)
+	}
+	e := ledgerEntry{
+		RecordedAt: time.Now().UTC().Truncate(time.Second), Kind: "manual",
+		Period: rec.period, County: rec.county, Municipality: rec.municipality,
+		Values: map[string]string{*field: *value}, Note: *note,
+	}
+	if err := appendLedger(ledgerPath, entries, []ledgerEntry{e}); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing ledger: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%s/%s %s %s: %q %s %q\n", rec.county, rec.municipality, rec.period, *field, rec.values[*field].value, glyphs.arrow, *value)
+}
+
+func ledgerReject(args []string) {
+	fs := flag.NewFlagSet("ledger reject", flag.ExitOnError)
+	loc := addLedgerFlags(fs)
+	seq := fs.Int("seq", 0, "entry to withdraw")
+	note := fs.String("note", "", "why the entry is withdrawn")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt ledger reject --seq N --note \"reason\" [--dir parsed-dir] [--ledger path]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	_, ledgerPath := loc.resolve(fs)
+
+	if *seq <= 0 || *note == "" {
+		fmt.Fprintf(os.Stderr, "--seq and --note are required\n")
+		os.Exit(1)
+	}
+	entries := loadLedgerOrExit(ledgerPath)
+	var target *ledgerEntry
+	for i := range entries {
+		if entries[i].Seq == *seq {
+			target = &entries[i]
+		}
+		if entries[i].Kind == "reject" && entries[i].Rejects == *seq {
+			fmt.Fprintf(os.Stderr, "entry %d was already withdrawn by entry %d\n", *seq, entries[i].Seq)
+			os.Exit(1)
+		}
+	}
+	if target == nil || target.Kind == "reject" {
+		fmt.Fprintf(os.Stderr, "no parse or manual entry %d in %s\n", *seq, ledgerPath)
+		os.Exit(1)
+	}
+	e := ledgerEntry{
+		RecordedAt: time.Now().UTC().Truncate(time.Second), Kind: "reject",
+		Period: target.Period, County: target.County, Municipality: target.Municipality,
+		Rejects: *seq, Note: *note,
+	}
+	if err := appendLedger(ledgerPath, entries, []ledgerEntry{e}); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing ledger: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "withdrew entry %d (%s/%s %s)\n", *seq, target.County, target.Municipality, target.Period)
+}
+
+func ledgerHistory(args []string) {
+	fs := flag.NewFlagSet("ledger history", flag.ExitOnError)
+	loc := addLedgerFlags(fs)
+	period := fs.String("period", "", "report period (YYYY-MM)")
+	county := fs.String("county", "", "county")
+	municipality := fs.String("municipality", "", "municipality")
+	field := fs.String("field", "", "only entries that set this value")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt ledger history --period 2024-06 --county ATLANTIC --municipality ABSECON [--field Filings_Current_GrandTotal] [--dir parsed-dir] [--ledger path]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	_, ledgerPath := loc.resolve(fs)
+
+	if *period == "" || *county == "" || *municipality == "" {
+		fmt.Fprintf(os.Stderr, "--period, --county and --municipality are required\n")
+		os.Exit(1)
+	}
+	key := ledgerKey(*period, *county, *municipality)
+	entries := loadLedgerOrExit(ledgerPath)
+	rejected := make(map[int]int)
+	for _, e := range entries {
+		if e.Kind == "reject" {
+			rejected[e.Rejects] = e.Seq
+		}
+	}
+	found := false
+	for _, e := range entries {
+		if ledgerKey(e.Period, e.County, e.Municipality) != key {
+			continue
+		}
+		if _, ok := e.Values[*field]; *field != "" && !ok && e.Kind != "reject" {
+			continue
+		}
+		found = true
+		line := fmt.Sprintf("#%-6d %s  %-6s", e.Seq, e.RecordedAt.Format(time.RFC3339), e.Kind)
+		switch {
+		case e.Kind == "reject":
+			line += fmt.Sprintf(" withdraws #%d", e.Rejects)
+		case e.Source != nil:
+			line += " " + e.Source.File
+			if e.Source.Page > 0 {
+				line += fmt.Sprintf(" page %d", e.Source.Page)
+			}
+			if !e.Source.ParsedAt.IsZero() {
+				line += " parsed " + e.Source.ParsedAt.Format(time.RFC3339)
+			}
+		}
+		if by, ok := rejected[e.Seq]; ok {
+			line += fmt.Sprintf(" (withdrawn by #%d)", by)
+		}
+		if e.Note != "" {
+			line += fmt.Sprintf(" %q", e.Note)
+		}
+		fmt.Println(line)
+		if *field != "" {
+			if v, ok := e.Values[*field]; ok {
+				fmt.Printf("        %s = %q\n", *field, v)
+			}
+		} else if e.Kind != "reject" {
+			fmt.Printf("        %d values\n", len(e.Values))
+		}
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "no entries for %s/%s %s in %s\n", *county, *municipality, *period, ledgerPath)
+		os.Exit(1)
+	}
+}
+
+func ledgerMaterialize(args []string) {
+	fs := flag.NewFlagSet("ledger materialize", flag.ExitOnError)
+	loc := addLedgerFlags(fs)
+	outDir := fs.String("out", "", "directory to write parsed JSON files to")
+	asOfStr := fs.String("as-of", "", "rebuild the data as accepted at this time: YYYY-MM-DD (end of day, UTC) or RFC 3339 (default now)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt ledger materialize --out dir [--as-of 2025-01-31] [--dir parsed-dir] [--ledger path]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	_, ledgerPath := loc.resolve(fs)
+
+	if *outDir == "" {
+		fmt.Fprintf(os.Stderr, "--out is required\n")
+		os.Exit(1)
+	}
+	var asOf time.Time
+	if *asOfStr != "" {
+		var err error
+		asOf, err = parseAsOf(*asOfStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --as-of %q: use YYYY-MM-DD or RFC 3339\n", *asOfStr)
+			os.Exit(1)
+		}
+	}
+	entries := loadLedgerOrExit(ledgerPath)
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "%s is empty or missing\n", ledgerPath)
+		os.Exit(1)
+	}
+	byPeriod := materializeLedger(replayLedger(entries, asOf))
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+	prov := &parser.Provenance{
+		SourceFile: filepath.Base(ledgerPath),
+		ParsedAt:   time.Now().UTC().Truncate(time.Second),
+		Version:    version(),
+	}
+	periods := make([]string, 0, len(byPeriod))
+	for p := range byPeriod {
+		periods = append(periods, p)
+	}
+	sort.Strings(periods)
+	for _, p := range periods {
+		path := filepath.Join(*outDir, "municipal-courts-"+p+".json")
+		if err := writeOutputJSON(path, prov, byPeriod[p]); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+	as := "now"
+	if !asOf.IsZero() {
+		as = asOf.Format(time.RFC3339)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d periods to %s as accepted %s\n", len(periods), *outDir, as)
+}
+
+// parseAsOf reads --as-of: a date means the end of that day, UTC.
+func parseAsOf(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 12, 0, 0, 0, time.UTC) }
+	ingest := func(filings string, at time.Time) []ledgerEntry {
+		t.Helper()
+		entries, err := readLedger(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := rateStat("ATLANTIC", "ABSECON", filings, "90", "90%")
+		s.PageNumber = 3
+		out := parser.Output{Provenance: &parser.Provenance{SourceFile: "munm2406.pdf", SHA256: "abc"}, Records: []parser.MunicipalityStats{s}}
+		added := ingestEntries(parsedValues(entries), "municipal-courts-2024-06.json", "2024-06", out, at)
+		if err := appendLedger(path, entries, added); err != nil {
+			t.Fatal(err)
+		}
+		return added
+	}
+	filingsAt := func(asOf time.Time) string {
+		t.Helper()
+		entries, err := readLedger(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs := materializeLedger(replayLedger(entries, asOf))["2024-06"]
+		if len(recs) != 1 {
+			t.Fatalf("as of %v: %d records", asOf, len(recs))
+		}
+		return recs[0].Filings.CurrentPeriod.GrandTotal
+	}
+
+	first := ingest("100", day(1))
+	if len(first) != 1 || first[0].Seq != 1 || first[0].Source.File != "munm2406.pdf" || first[0].Source.Page != 3 {
+		t.Fatalf("first ingest = %+v", first)
+	}
+	if again := ingest("100", day(2)); len(again) != 0 {
+		t.Errorf("re-ingesting unchanged output added %+v", again)
+	}
+	// A re-published report changes one value; only it is recorded.
+	second := ingest("120", day(3))
+	if len(second) != 1 || len(second[0].Values) != 1 || second[0].Values["Filings_Current_GrandTotal"] != "120" || second[0].Seq != 2 {
+		t.Fatalf("second ingest = %+v", second)
+	}
+
+	entries, _ := readLedger(path)
+	fix := []ledgerEntry{{RecordedAt: day(4), Kind: "manual", Period: "2024-06", County: "ATLANTIC", Municipality: "ABSECON",
+		Values: map[string]string{"Filings_Current_GrandTotal": "125"}, Note: "typo in the report"}}
+	if err := appendLedger(path, entries, fix); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = readLedger(path)
+	reject := []ledgerEntry{{RecordedAt: day(5), Kind: "reject", Rejects: 2, Period: "2024-06", County: "ATLANTIC", Municipality: "ABSECON"}}
+	if err := appendLedger(path, entries, reject); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		asOf time.Time
+		want string
+	}{
+		{day(1), "100"},
+		{day(3), "120"},
+		{day(4), "125"},
+		{day(5), "125"}, // the withdrawn re-publication was overridden anyway
+		{time.Time{}, "125"},
+	} {
+		if got := filingsAt(tt.asOf); got != tt.want {
+			t.Errorf("as of %v: filings = %q, want %q", tt.asOf, got, tt.want)
+		}
+	}
+
+	// Ingesting the same output again leaves the correction and the
+	// withdrawal in place.
+	if again := ingest("120", day(6)); len(again) != 0 {
+		t.Errorf("re-ingesting after a correction added %+v", again)
+	}
+	if got := filingsAt(time.Time{}); got != "125" {
+		t.Errorf("after re-ingesting: filings = %q, want the correction, 125", got)
+	}
+
+	// Withdrawing the manual fix too falls back to the first parse.
+	entries, _ = readLedger(path)
+	reject[0].Rejects = 3
+	reject[0].RecordedAt = day(7)
+	if err := appendLedger(path, entries, reject); err != nil {
+		t.Fatal(err)
+	}
+	if got := filingsAt(time.Time{}); got != "100" {
+		t.Errorf("after withdrawing both corrections: filings = %q, want 100", got)
+	}
+	if got := filingsAt(day(5)); got != "125" {
+		t.Errorf("as of day 5: filings = %q, want 125", got)
+	}
+}
+
+func TestParseAsOf(t *testing.T) {
+	got, err := parseAsOf("2025-01-31")
+	if err != nil || !got.Equal(time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("parseAsOf(date) = %v, %v", got, err)
+	}
+	if _, err := parseAsOf("last week"); err == nil {
+		t.Error("parseAsOf accepted nonsense")
+	}
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serverLimits are the protections web and api put in front of their
+// handlers, so a public deployment can't be trivially overwhelmed by a
+// client requesting every municipality's series in a loop. The zero value
+// limits nothing.
+type serverLimits struct {
+	rate        float64       // requests per second per client IP; 0 for no limit
+	burst       int           // requests a client may make at once
+	timeout     time.Duration // longest a handler may run
+	maxBody     int64         // largest request body, in bytes
+	maxResponse int64         // largest response body, in bytes
+	trustProxy  bool          // take the client IP from X-Forwarded-For
+	read, write time.Duration // longest the server spends reading a request or writing a response
+}
+
+// limitFlags holds the flags configuring serverLimits.
+type limitFlags struct {
+	rate        *float64
+	burst       *int
+	timeout     *time.Duration
+	maxBody     *int64
+	maxResponse *int64
+	trustProxy  *bool
+	read, write *time.Duration
+}
+
+// addLimitFlags registers the rate, timeout and size limit flags and the
+// server's read and write timeouts. Call
+// limits after parsing.
+func addLimitFlags(fs *flag.FlagSet) limitFlags {
+	return limitFlags{
+		rate:        fs.Float64("rate-limit", 10, "requests per second allowed from each client IP (0 for no limit)"),
+		burst:       fs.Int("rate-burst", 40, "requests a client may make at once before --rate-limit applies"),
+		timeout:     fs.Duration("request-timeout", 60*time.Second, "longest a request may take before it is answered with 503 (0 for none)"),
+		maxBody:     fs.Int64("max-body", 1<<20, "largest request body accepted, in bytes (0 for no limit)"),
+		maxResponse: fs.Int64("max-response", 32<<20, "largest response sent, in bytes; longer ones are cut off (0 for no limit)"),
+		trustProxy:  fs.Bool("trust-proxy", false, "rate-limit by the client IP in X-Forwarded-For, when behind a reverse proxy"),
+		read:        fs.Duration("read-timeout", 10*time.Second, "maximum time to read a request"),
+		write:       fs.Duration("write-timeout", 60*time.Second, "maximum time to write a response (PDF reports can be slow)"),
+	}
+}
+
+// limits checks the parsed flags.
+func (f limitFlags) limits() (serverLimits, error) {
+	l := serverLimits{
+		rate:        *f.rate,
+		burst:       *f.burst,
+		timeout:     *f.timeout,
+		maxBody:     *f.maxBody,
+		maxResponse: *f.maxResponse,
+		trustProxy:  *f.trustProxy,
+		read:        *f.read,
+		write:       *f.write,
+	}
+	switch {
+	case l.rate < 0 || math.IsNaN(l.rate) || math.IsInf(l.rate, 0):
+		return l, fmt.Errorf("--rate-limit must be a positive number, or 0 for no limit")
+	case l.rate > 0 && l.burst < 1:
+		return l, fmt.Errorf("--rate-burst must be at least 1")
+	case l.timeout < 0 || l.maxBody < 0 || l.maxResponse < 0:
+		return l, fmt.Errorf("--request-timeout, --max-body and --max-response can't be negative")
+	}
+	return l, nil
+}
+
+// wrap puts the limits in front of h. Clients over their rate get 429
+// before any work is done; a handler still running at the timeout is
+// answered with 503.
+func (l serverLimits) wrap(h http.Handler) http.Handler {
+	if l.maxResponse > 0 {
+		h = withResponseCap(h, l.maxResponse)
+	}
+	if l.maxBody > 0 {
+		h = withBodyCap(h, l.maxBody)
+	}
+	if l.timeout > 0 {
+		h = http.TimeoutHandler(h, l.timeout, `{"error": "request timed out"}`)
+	}
+	if l.rate > 0 {
+		h = withRateLimit(h, newRateLimiter(l.rate, l.burst, time.Now), l.trustProxy)
+	}
+	return h
+}
+
+// withBodyCap fails reads of a request body past max bytes, which handlers
+// report as a bad request.
+func withBodyCap(next http.Handler, max int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, max)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withResponseCap aborts a response once it passes max bytes. The status
+// is already sent by then, so the connection is closed rather than leaving
+// the client a truncated body that looks complete.
+func withResponseCap(next http.Handler, max int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&cappedWriter{ResponseWriter: w, max: max, left: max, uri: r.URL.RequestURI()}, r)
+	})
+}
+
+type cappedWriter struct {
+	http.ResponseWriter
+	max, left int64
+	uri       string
+}
+
+func (c *cappedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > c.left {
+		c.ResponseWriter.Write(p[:c.left])
+		fmt.Fprintf(os.Stderr, "%s: response cut off at %d bytes (--max-response)\n", c.uri, c.max)
+		panic(http.ErrAbortHandler)
+	}
+	c.left -= int64(len(p))
+	return c.ResponseWriter.Write(p)
+}
+
+// withRateLimit answers clients over their rate with 429 and a Retry-After
+// header.
+func withRateLimit(next http.Handler, rl *rateLimiter, trustProxy bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wait := rl.allow(clientIP(r, trustProxy)); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP is the address a request came from: the last X-Forwarded-For
+// entry, the one the proxy appended, if that proxy is trusted, else the
+// connection's peer. Entries before it come from the client, which can
+// write anything there.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+			last := fwd[len(fwd)-1]
+			if i := strings.LastIndexByte(last, ','); i >= 0 {
+				last = last[i+1:]
+			}
+			if last = strings.TrimSpace(last); last != "" {
+				return last
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter is a token bucket per client: each holds up to burst
+// requests and refills at rate per second.
+type rateLimiter struct {
+	rate      float64
+	burst     float64
+	now       func() time.Time
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int, now func() time.Time) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), now: now, buckets: make(map[string]*bucket), lastSweep: now()}
+}
+
+// allow takes a request from client's bucket, returning 0 if it may go
+// ahead or how long until it may.
+func (rl *rateLimiter) allow(client string) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := rl.now()
+	rl.sweep(now)
+	b, ok := rl.buckets[client]
+	if !ok {
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[client] = b
+	}
+	b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep forgets, once a minute, clients whose buckets have refilled, so
+// the map doesn't grow with every address ever seen.
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < time.Minute {
+		return
+	}
+	rl.lastSweep = now
+	for client, b := range rl.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, client)
+		}
+	}
+}
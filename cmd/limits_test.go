@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := newRateLimiter(2, 3, func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		if wait := rl.allow("a"); wait != 0 {
+			t.Fatalf("request %d of the burst waited %v", i+1, wait)
+		}
+	}
+	if wait := rl.allow("a"); wait != 500*time.Millisecond {
+		t.Errorf("past the burst: wait %v, want 500ms", wait)
+	}
+	if wait := rl.allow("b"); wait != 0 {
+		t.Errorf("another client waited %v", wait)
+	}
+	now = now.Add(time.Second) // two more requests at 2/s
+	if rl.allow("a") != 0 || rl.allow("a") != 0 || rl.allow("a") == 0 {
+		t.Error("bucket didn't refill at the rate")
+	}
+
+	now = now.Add(time.Hour)
+	rl.allow("c")
+	if _, ok := rl.buckets["a"]; ok {
+		t.Error("idle client not swept")
+	}
+}
+
+func TestServerLimits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, "ok")
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 200)))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	limits := serverLimits{rate: 1, burst: 4, timeout: 50 * time.Millisecond, maxBody: 10, maxResponse: 100}
+	srv := httptest.NewServer(limits.wrap(mux))
+	defer srv.Close()
+
+	post := func(path, body string) (*http.Response, error) {
+		return http.Post(srv.URL+path, "text/plain", strings.NewReader(body))
+	}
+	if resp, err := post("/echo", "short"); err != nil || resp.StatusCode != 200 {
+		t.Errorf("small body: %v %v", resp, err)
+	}
+	if resp, err := post("/echo", strings.Repeat("x", 11)); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("large body: %v %v", resp, err)
+	}
+	if resp, err := post("/big", ""); err == nil {
+		_, err = io.ReadAll(resp.Body)
+		if err == nil {
+			t.Error("large response sent whole")
+		}
+	}
+	if resp, err := post("/slow", ""); err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("slow handler: %v %v", resp, err)
+	}
+	resp, err := post("/echo", "")
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("past the burst: %v %v", resp, err)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	if got := clientIP(r, false); got != "10.0.0.1" {
+		t.Errorf("untrusted proxy: %q", got)
+	}
+	if got := clientIP(r, true); got != "203.0.113.7" {
+		t.Errorf("trusted proxy: %q", got)
+	}
+
+	// A client making up earlier hops, in one header or several, still
+	// gets the address the proxy appended.
+	r.Header.Set("X-Forwarded-For", "198.51.100.1, 198.51.100.2, 203.0.113.7")
+	if got := clientIP(r, true); got != "203.0.113.7" {
+		t.Errorf("spoofed hops: %q", got)
+	}
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.Header.Add("X-Forwarded-For", "203.0.113.7")
+	if got := clientIP(r, true); got != "203.0.113.7" {
+		t.Errorf("spoofed header: %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// remoteReport is one report linked from the statistics page, with what
+// of it is already in the local directory.
+type remoteReport struct {
+	Period     string `json:"period"` // YYYY-MM
+	URL        string `json:"url"`
+	Archived   string `json:"archived,omitempty"` // Wayback copy, for links found in a capture
+	File       string `json:"file"`               // local PDF name
+	Downloaded bool   `json:"downloaded"`         // the PDF is in the directory
+	Parsed     bool   `json:"parsed"`             // its JSON output is too
+}
+
+// remoteReports pairs links with the local PDFs and parsed output in dir,
+// sorted by period.
+func remoteReports(links []pdfLink, dir string) []remoteReport {
+	reports := make([]remoteReport, 0, len(links))
+	for _, l := range links {
+		name := "municipal-courts-" + l.period
+		r := remoteReport{Period: l.period, URL: l.url, Archived: l.fallback, File: name + ".pdf"}
+		if _, err := os.Stat(filepath.Join(dir, name+".pdf")); err == nil {
+			r.Downloaded = true
+		}
+		if _, err := os.Stat(filepath.Join(dir, name+".json")); err == nil {
+			r.Parsed = true
+		}
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Period < reports[j].Period })
+	return reports
+}
+
+// ListRemote implements the "list-remote" subcommand: list every report the
+// statistics page links to, without downloading anything.
+func ListRemote(args []string) {
+	fs := flag.NewFlagSet("list-remote", flag.ExitOnError)
+	dir := fs.String("dir", ".", "local directory to compare against (downloaded PDFs and parsed JSON)")
+	asJSON := fs.Bool("json", false, "print a JSON array instead of a table")
+	var extraPatterns stringList
+	fs.Var(&extraPatterns, "pattern", "report file name pattern using {yyyy}, {yy}, {mm} (repeatable; tried before the built-ins)")
+	arch := addArchiveFlags(fs)
+	netFlags := addHTTPFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt list-remote [dir] [--json] [--pattern munm{yy}{mm}.pdf ...] [--archive] [--archive-page URL ...] [--wayback [--wayback-limit N] [--wayback-delay 1s]] [--proxy URL] [--timeout 60s] [--insecure]\n\nList every municipal report linked from the statistics page and whether it is already in dir.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	patterns, err := compilePatterns(extraPatterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --pattern: %v\n", err)
+		os.Exit(1)
+	}
+	if err := netFlags.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := arch.check(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	// The page cache is read but never written: download only saves it
+	// after a run in which every linked report was fetched.
+	page, _, err := fetchStatisticsPage(statisticsPageURL, loadPageCache(filepath.Join(*dir, pageCacheName)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	base, _ := url.Parse(statisticsPageURL)
+	links := extractPDFLinks(page.Body, base, patterns)
+	if arch.enabled() {
+		links = mergeLinks(links, arch.discover(patterns))
+	}
+	reports := remoteReports(links, *dir)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(reports) == 0 {
+		fmt.Fprintf(os.Stderr, "no municipal court PDF links found on page\n")
+		os.Exit(1)
+	}
+	missing := 0
+	for _, r := range reports {
+		var local []string
+		if r.Downloaded {
+			local = append(local, "downloaded")
+		} else {
+			missing++
+		}
+		if r.Parsed {
+			local = append(local, "parsed")
+		}
+		if len(local) == 0 {
+			local = append(local, "-")
+		}
+		fmt.Printf("%s  %-18s  %s\n", r.Period, strings.Join(local, ","), r.URL)
+	}
+	fmt.Fprintf(os.Stderr, "%d reports, %d not downloaded\n", len(reports), missing)
+}
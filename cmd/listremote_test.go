@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoteReports(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"municipal-courts-2024-06.pdf", "municipal-courts-2024-06.json", "municipal-courts-2024-12.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := []pdfLink{
+		{period: "2025-06", url: "https://example.org/munm2506.pdf"},
+		{period: "2024-06", url: "https://example.org/munm2406.pdf"},
+		{period: "2024-12", url: "https://example.org/munm2412.pdf"},
+	}
+	got := remoteReports(links, dir)
+	want := []remoteReport{
+		{Period: "2024-06", URL: "https://example.org/munm2406.pdf", File: "municipal-courts-2024-06.pdf", Downloaded: true, Parsed: true},
+		{Period: "2024-12", URL: "https://example.org/munm2412.pdf", File: "municipal-courts-2024-12.pdf", Downloaded: true},
+		{Period: "2025-06", URL: "https://example.org/munm2506.pdf", File: "municipal-courts-2025-06.pdf"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d reports, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("report %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// recordCacheFormat is bumped whenever the cached encoding or the meaning of
+// a decoded parser.Output changes, so old entries are ignored. Changes to
+// its fields are caught by recordCacheSchema without a bump.
+const recordCacheFormat = 2
+
+// recordCacheSchema fingerprints the shape of parser.Output, so entries
+// written before a field was added or changed are ignored even by builds
+// whose version() doesn't change, such as go run.
+var recordCacheSchema = typeFingerprint(reflect.TypeOf(parser.Output{}))
+
+// typeFingerprint hashes the names and types of the fields gob encodes in
+// t, recursively.
+func typeFingerprint(t reflect.Type) string {
+	var b strings.Builder
+	seen := make(map[reflect.Type]bool)
+	var describe func(t reflect.Type)
+	describe = func(t reflect.Type) {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			fmt.Fprintf(&b, "%s[%s]", t.Kind(), t.String())
+			describe(t.Elem())
+		case reflect.Map:
+			b.WriteString("map[")
+			describe(t.Key())
+			b.WriteString("]")
+			describe(t.Elem())
+		case reflect.Struct:
+			b.WriteString(t.String())
+			if seen[t] {
+				return
+			}
+			seen[t] = true
+			b.WriteString("{")
+			for i := 0; i < t.NumField(); i++ {
+				if f := t.Field(i); f.IsExported() {
+					b.WriteString(f.Name + " ")
+					describe(f.Type)
+					b.WriteString(";")
+				}
+			}
+			b.WriteString("}")
+		default:
+			b.WriteString(t.String())
+		}
+	}
+	describe(t)
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// recordCacheDir returns the directory holding decoded output files, or ""
+// if caching is disabled. MUNICOURT_CACHE_DIR overrides the location; set it
+// to "off" to disable the cache.
+func recordCacheDir() string {
+	if dir := os.Getenv("MUNICOURT_CACHE_DIR"); dir != "" {
+		if dir == "off" {
+			return ""
+		}
+		return dir
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "municourt", "records")
+}
+
+// recordCacheKey identifies the exact file a cache entry was decoded from.
+type recordCacheKey struct {
+	Format  int
+	Schema  string // recordCacheSchema
+	Version string
+	Path    string
+	ModTime int64
+	Size    int64
+}
+
+func newRecordCacheKey(path string, info os.FileInfo) recordCacheKey {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return recordCacheKey{
+		Format:  recordCacheFormat,
+		Schema:  recordCacheSchema,
+		Version: version(),
+		Path:    path,
+		ModTime: info.ModTime().UnixNano(),
+		Size:    info.Size(),
+	}
+}
+
+func (k recordCacheKey) file(dir string) string {
+	sum := sha256.Sum256([]byte(k.Path))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".gob")
+}
+
+// readRecordCache returns the cached decoding of the file described by key.
+// Any problem with the entry is treated as a miss.
+func readRecordCache(dir string, key recordCacheKey) (parser.Output, bool) {
+	f, err := os.Open(key.file(dir))
+	if err != nil {
+		return parser.Output{}, false
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+	var stored recordCacheKey
+	if err := dec.Decode(&stored); err != nil || stored != key {
+		return parser.Output{}, false
+	}
+	var out parser.Output
+	if err := dec.Decode(&out); err != nil {
+		return parser.Output{}, false
+	}
+	return out, true
+}
+
+// writeRecordCache stores out under key. The cache is only an optimization,
+// so failures are ignored.
+func writeRecordCache(dir string, key recordCacheKey, out parser.Output) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	enc := gob.NewEncoder(f)
+	err = enc.Encode(key)
+	if err == nil {
+		err = enc.Encode(out)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	// Rename so concurrent readers never see a partial entry.
+	if err == nil {
+		err = os.Rename(f.Name(), key.file(dir))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// decodeOutputFile reads and decodes one output file, going through the
+// record cache when it is enabled.
+func decodeOutputFile(path, cacheDir string) (parser.Output, error) {
+	var key recordCacheKey
+	if cacheDir != "" {
+		if info, err := os.Stat(path); err == nil {
+			key = newRecordCacheKey(path, info)
+			if out, ok := readRecordCache(cacheDir, key); ok {
+				return out, nil
+			}
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return parser.Output{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+	out, err := parser.ReadOutput(f)
+	if err != nil {
+		return parser.Output{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if key.Path != "" {
+		writeRecordCache(cacheDir, key, out)
+	}
+	return out, nil
+}
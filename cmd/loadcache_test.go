@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestMain keeps the tests' record cache out of the user's cache directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "municourt-cache-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("MUNICOURT_CACHE_DIR", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestLoadRecords_Cache(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("MUNICOURT_CACHE_DIR", cacheDir)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "municipal-courts-2024-06.json")
+	write := func(muni string, mtime time.Time) {
+		data := `{"schemaVersion": 2, "records": [{"county": "ATLANTIC", "municipality": "` + muni + `"}]}`
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func() string {
+		t.Helper()
+		records, err := loadRecords(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || len(records[0].stats) != 1 {
+			t.Fatalf("got %d records", len(records))
+		}
+		return records[0].stats[0].Municipality
+	}
+
+	first := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	write("ABSECON", first)
+	if got := load(); got != "ABSECON" {
+		t.Fatalf("municipality = %q", got)
+	}
+	entries, _ := filepath.Glob(filepath.Join(cacheDir, "*.gob"))
+	if len(entries) != 1 {
+		t.Fatalf("cache has %d entries, want 1", len(entries))
+	}
+
+	// A changed file must not be served from the cache.
+	write("BRIGANTINE", first.Add(time.Hour))
+	if got := load(); got != "BRIGANTINE" {
+		t.Errorf("after edit, municipality = %q", got)
+	}
+
+	// An unreadable cache entry falls back to the file.
+	if err := os.WriteFile(entries[0], []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := load(); got != "BRIGANTINE" {
+		t.Errorf("with corrupt cache, municipality = %q", got)
+	}
+}
+
+func TestRecordCacheDir_Off(t *testing.T) {
+	t.Setenv("MUNICOURT_CACHE_DIR", "off")
+	if dir := recordCacheDir(); dir != "" {
+		t.Errorf("recordCacheDir = %q, want disabled", dir)
+	}
+}
+
+func TestTypeFingerprint(t *testing.T) {
+	// Each closure's types print the same, as one type would before and
+	// after an edit.
+	v1 := func() reflect.Type {
+		type record struct{ A string }
+		type output struct {
+			Records []record
+			Next    *output
+		}
+		return reflect.TypeOf(output{})
+	}()
+	v1Again := func() reflect.Type {
+		type record struct{ A string }
+		type output struct {
+			Records []record
+			Next    *output
+		}
+		return reflect.TypeOf(output{})
+	}()
+	v2 := func() reflect.Type {
+		type record struct {
+			A string
+			B int
+		}
+		type output struct {
+			Records []record
+			Next    *output
+		}
+		return reflect.TypeOf(output{})
+	}()
+	if typeFingerprint(v1) != typeFingerprint(v1Again) {
+		t.Error("the same fields gave different fingerprints")
+	}
+	if typeFingerprint(v1) == typeFingerprint(v2) {
+		t.Error("adding a field to a nested type didn't change the fingerprint")
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// manifestName is the file, inside the download directory, recording where
+// each PDF came from and what the server reported for it.
+const manifestName = "manifest.json"
+
+// manifestEntry describes one downloaded PDF.
+type manifestEntry struct {
+	URL           string    `json:"url"`
+	ContentLength int64     `json:"contentLength"`
+	LastModified  string    `json:"lastModified,omitempty"`
+	DownloadedAt  time.Time `json:"downloadedAt"`
+}
+
+// manifest maps a local file name to its entry.
+type manifest map[string]manifestEntry
+
+// loadManifest reads dir's manifest; a missing file yields an empty one.
+func loadManifest(dir string) (manifest, error) {
+	m := make(manifest)
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", manifestName, err)
+	}
+	return m, nil
+}
+
+func (m manifest) save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, manifestName), data, 0644)
+}
+
+// remoteInfo is what the server reports about a file.
+type remoteInfo struct {
+	contentLength int64 // -1 if unknown
+	lastModified  string
+}
+
+func (ri remoteInfo) entry(url string) manifestEntry {
+	return manifestEntry{
+		URL:           url,
+		ContentLength: ri.contentLength,
+		LastModified:  ri.lastModified,
+		DownloadedAt:  time.Now().UTC(),
+	}
+}
+
+// verifiedEntry returns the manifest entry for a file on disk, described
+// by info, that a HEAD of url found unchanged, and whether it differs from
+// recorded: a file missing from the manifest (had is false) is added, and
+// a recorded one gains a Last-Modified it lacked.
+func verifiedEntry(recorded manifestEntry, had bool, remote remoteInfo, url string, info os.FileInfo) (manifestEntry, bool) {
+	if !had {
+		return manifestEntry{
+			URL:           url,
+			ContentLength: info.Size(),
+			LastModified:  remote.lastModified,
+			DownloadedAt:  info.ModTime().UTC(),
+		}, true
+	}
+	if recorded.LastModified == "" && remote.lastModified != "" {
+		recorded.LastModified = remote.lastModified
+		return recorded, true
+	}
+	return recorded, false
+}
+
+// headFile issues a HEAD request for url.
+func headFile(url string) (remoteInfo, error) {
+	req, err := http.NewRequest("HEAD", url, nil)
+	if err != nil {
+		return remoteInfo{}, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; municourt/1.0)")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return remoteInfo{}, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return remoteInfo{}, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	ri := remoteInfo{contentLength: -1, lastModified: resp.Header.Get("Last-Modified")}
+	if n, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		ri.contentLength = n
+	}
+	return ri, nil
+}
+
+// remoteChanged compares the server's current view of a file with what was
+// recorded when it was downloaded, returning why it looks replaced.
+func remoteChanged(recorded manifestEntry, remote remoteInfo) (string, bool) {
+	if remote.contentLength >= 0 && remote.contentLength != recorded.ContentLength {
+		return fmt.Sprintf("size %d -> %d", recorded.ContentLength, remote.contentLength), true
+	}
+	if recorded.LastModified != "" && remote.lastModified != "" && recorded.LastModified != remote.lastModified {
+		return fmt.Sprintf("last modified %s -> %s", recorded.LastModified, remote.lastModified), true
+	}
+	return "", false
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoteChanged(t *testing.T) {
+	recorded := manifestEntry{ContentLength: 1000, LastModified: "Mon, 01 Jul 2024 00:00:00 GMT"}
+
+	tests := []struct {
+		name   string
+		remote remoteInfo
+		want   bool
+	}{
+		{"same", remoteInfo{contentLength: 1000, lastModified: recorded.LastModified}, false},
+		{"size changed", remoteInfo{contentLength: 1200, lastModified: recorded.LastModified}, true},
+		{"re-posted", remoteInfo{contentLength: 1000, lastModified: "Tue, 02 Jul 2024 00:00:00 GMT"}, true},
+		{"no headers", remoteInfo{contentLength: -1}, false},
+	}
+	for _, tt := range tests {
+		if _, got := remoteChanged(recorded, tt.remote); got != tt.want {
+			t.Errorf("%s: changed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeadFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" {
+			t.Errorf("method = %s, want HEAD", r.Method)
+		}
+		w.Header().Set("Content-Length", "4242")
+		w.Header().Set("Last-Modified", "Mon, 01 Jul 2024 00:00:00 GMT")
+	}))
+	defer srv.Close()
+
+	ri, err := headFile(srv.URL + "/munm2406.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ri.contentLength != 4242 || ri.lastModified != "Mon, 01 Jul 2024 00:00:00 GMT" {
+		t.Errorf("headFile = %+v", ri)
+	}
+}
+
+func TestVerifiedEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "municipal-courts-2024-06.pdf")
+	if err := os.WriteFile(path, make([]byte, 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const lm = "Mon, 01 Jul 2024 00:00:00 GMT"
+	remote := remoteInfo{contentLength: 1000, lastModified: lm}
+
+	// A file from before the manifest is added with what the HEAD said.
+	e, update := verifiedEntry(manifestEntry{}, false, remote, "https://example.org/munm2406.pdf", info)
+	if !update || e.URL != "https://example.org/munm2406.pdf" || e.ContentLength != 1000 || e.LastModified != lm || !e.DownloadedAt.Equal(info.ModTime()) {
+		t.Errorf("unrecorded file: %+v, %v", e, update)
+	}
+	// A recorded entry gains a missing Last-Modified and is otherwise kept.
+	recorded := manifestEntry{URL: "u", ContentLength: 1000}
+	if e, update := verifiedEntry(recorded, true, remote, "u", info); !update || e.LastModified != lm {
+		t.Errorf("entry without Last-Modified: %+v, %v", e, update)
+	}
+	recorded.LastModified = lm
+	if _, update := verifiedEntry(recorded, true, remote, "u", info); update {
+		t.Error("complete entry was rewritten")
+	}
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// mergedDataset is every period of a parsed directory in one file, as
+// written by the merge subcommand. Any command that reads a parsed
+// directory also reads one of these in its place.
+type mergedDataset struct {
+	SchemaVersion int                      `json:"schemaVersion"`
+	MergedAt      time.Time                `json:"mergedAt"`
+	Version       string                   `json:"municourtVersion"`
+	Periods       map[string]*mergedPeriod `json:"periods"` // keyed by YYYY-MM
+}
+
+// mergedPeriod is one period's records and the output files they came from.
+type mergedPeriod struct {
+	Sources []mergedSource             `json:"sources"`
+	Records []parser.MunicipalityStats `json:"records"`
+}
+
+type mergedSource struct {
+	File       string             `json:"file"` // output file name
+	Provenance *parser.Provenance `json:"provenance,omitempty"`
+}
+
+// isDatasetFile reports whether path names a merged dataset (by its
+// extension) rather than a directory.
+func isDatasetFile(path string) bool {
+	return strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".json.gz")
+}
+
+// readDataset reads a merged dataset, gzipped if its name ends in .gz.
+func readDataset(path string) (mergedDataset, error) {
+	var ds mergedDataset
+	f, err := os.Open(path)
+	if err != nil {
+		return ds, err
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReader(f)
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return ds, fmt.Errorf("reading %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	if err := json.NewDecoder(r).Decode(&ds); err != nil {
+		return ds, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if ds.Periods == nil {
+		return ds, fmt.Errorf("%s is not a merged dataset (no \"periods\")", path)
+	}
+	return ds, nil
+}
+
+// loadDatasetRecords is loadRecordsWith for a merged dataset file.
+func loadDatasetRecords(path string, prune func(parser.MunicipalityStats) parser.MunicipalityStats) ([]timeRecord, error) {
+	ds, err := readDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]timeRecord, 0, len(ds.Periods))
+	for date, p := range ds.Periods {
+		stats := p.Records
+		if prune != nil {
+			for i, s := range stats {
+				stats[i] = prune(s)
+			}
+		}
+		records = append(records, timeRecord{date: date, stats: normalizeCounties(stats, filepath.Base(path)+" "+date)})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].date < records[j].date })
+	return records, nil
+}
+
+// mergeDuplicate is an (entity, period) pair found more than once.
+type mergeDuplicate struct {
+	period, county, municipality string
+	where                        []string // "file page N" of each copy
+}
+
+// mergeOutputs combines output files, keyed by period, into a dataset and
+// lists every (county, municipality, period) that appears more than once.
+func mergeOutputs(files map[string]string, outputs map[string]parser.Output) (mergedDataset, []mergeDuplicate) {
+	ds := mergedDataset{SchemaVersion: parser.SchemaVersion, Periods: make(map[string]*mergedPeriod)}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type key struct{ period, county, muni string }
+	seen := make(map[key]*mergeDuplicate)
+	var order []key
+	for _, name := range names {
+		period, out := files[name], outputs[name]
+		p, ok := ds.Periods[period]
+		if !ok {
+			p = &mergedPeriod{}
+			ds.Periods[period] = p
+		}
+		p.Sources = append(p.Sources, mergedSource{File: name, Provenance: out.Provenance})
+		p.Records = append(p.Records, out.Records...)
+		for _, s := range out.Records {
+			k := key{period, strings.ToUpper(s.County), strings.ToUpper(s.Municipality)}
+			where := name
+			if s.PageNumber > 0 {
+				where = fmt.Sprintf("%s page %d", name, s.PageNumber)
+			}
+			d, ok := seen[k]
+			if !ok {
+				d = &mergeDuplicate{period: period, county: s.County, municipality: s.Municipality}
+				seen[k] = d
+				order = append(order, k)
+			}
+			d.where = append(d.where, where)
+		}
+	}
+
+	var dups []mergeDuplicate
+	for _, k := range order {
+		if d := seen[k]; len(d.where) > 1 {
+			dups = append(dups, *d)
+		}
+	}
+	return ds, dups
+}
+
+// Merge implements the "merge" subcommand: combine every period file in a
+// parsed directory into one dataset file, refusing duplicate (entity,
+// period) pairs. Of several files for one period, only the newest report
+// is merged.
+func Merge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	out := fs.String("out", "", "dataset file to write; a name ending in .gz is gzipped")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt merge <parsed-dir> --out dataset.json[.gz]\n\nCombine every period file in a parsed directory into one dataset file.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if *out == "" || !isDatasetFile(*out) {
+		fmt.Fprintf(os.Stderr, "--out is required and must end in .json or .json.gz\n")
+		os.Exit(1)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	files := make(map[string]string)
+	outputs := make(map[string]parser.Output)
+	cacheDir := recordCacheDir()
+	for _, path := range matches {
+		m := datePattern.FindStringSubmatch(filepath.Base(path))
+		if m == nil || !isOutputJSON(path) {
+			continue
+		}
+		o, err := decodeOutputFile(path, cacheDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		files[filepath.Base(path)] = m[1] + "-" + m[2]
+		outputs[filepath.Base(path)] = o
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	for _, note := range dropSuperseded(files, outputs) {
+		fmt.Fprintf(os.Stderr, "%s\n", note)
+	}
+	ds, dups := mergeOutputs(files, outputs)
+	if len(dups) > 0 {
+		for _, d := range dups {
+			fmt.Fprintf(os.Stderr, "%s %s/%s appears %d times: %s\n", d.period, d.county, d.municipality, len(d.where), strings.Join(d.where, ", "))
+		}
+		fmt.Fprintf(os.Stderr, "%d duplicate entity/period pairs; nothing written (see parse --duplicates)\n", len(dups))
+		os.Exit(1)
+	}
+	ds.MergedAt = time.Now().UTC().Truncate(time.Second)
+	ds.Version = version()
+
+	if err := writeDataset(*out, ds); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", *out, err)
+		os.Exit(1)
+	}
+	n := 0
+	for _, p := range ds.Periods {
+		n += len(p.Records)
+	}
+	fmt.Fprintf(os.Stderr, "merged %d files, %d periods, %d records %s %s\n", len(files), len(ds.Periods), n, glyphs.arrow, *out)
+}
+
+// writeDataset writes ds to path through a temporary file, so a failed
+// write never leaves half a dataset behind.
+func writeDataset(path string, ds mergedDataset) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	bw := bufio.NewWriter(tmp)
+	var w io.Writer = bw
+	var gz *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(bw)
+		w = gz
+	}
+	err = json.NewEncoder(w).Encode(ds)
+	if gz != nil && err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
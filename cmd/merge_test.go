@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestMergeOutputsDuplicates(t *testing.T) {
+	files := map[string]string{
+		"municipal-courts-2024-06.json":      "2024-06",
+		"municipal-courts-2024-06-copy.json": "2024-06",
+		"municipal-courts-2024-12.json":      "2024-12",
+	}
+	outputs := map[string]parser.Output{
+		"municipal-courts-2024-06.json":      {Records: []parser.MunicipalityStats{stat("ATLANTIC", "ABSECON"), stat("ATLANTIC", "BRIGANTINE")}},
+		"municipal-courts-2024-06-copy.json": {Records: []parser.MunicipalityStats{stat("Atlantic", "Absecon")}},
+		"municipal-courts-2024-12.json":      {Records: []parser.MunicipalityStats{stat("ATLANTIC", "ABSECON")}},
+	}
+	ds, dups := mergeOutputs(files, outputs)
+	if len(ds.Periods) != 2 || len(ds.Periods["2024-06"].Records) != 3 || len(ds.Periods["2024-06"].Sources) != 2 {
+		t.Fatalf("periods = %+v", ds.Periods)
+	}
+	if len(dups) != 1 || dups[0].period != "2024-06" || len(dups[0].where) != 2 {
+		t.Fatalf("dups = %+v", dups)
+	}
+
+	// The same court in different periods is not a duplicate.
+	delete(files, "municipal-courts-2024-06-copy.json")
+	if _, dups := mergeOutputs(files, outputs); len(dups) != 0 {
+		t.Errorf("dups = %+v", dups)
+	}
+}
+
+func TestDatasetRoundTrip(t *testing.T) {
+	files := map[string]string{
+		"municipal-courts-2024-12.json": "2024-12",
+		"municipal-courts-2024-06.json": "2024-06",
+	}
+	outputs := map[string]parser.Output{
+		"municipal-courts-2024-06.json": {Provenance: &parser.Provenance{SourceFile: "munm2406.pdf"}, Records: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "100", "90", "90%")}},
+		"municipal-courts-2024-12.json": {Records: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "120", "90", "75%")}},
+	}
+	ds, _ := mergeOutputs(files, outputs)
+
+	for _, name := range []string{"dataset.json", "dataset.json.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := writeDataset(path, ds); err != nil {
+			t.Fatal(err)
+		}
+		back, err := readDataset(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src := back.Periods["2024-06"].Sources; len(src) != 1 || src[0].Provenance == nil || src[0].Provenance.SourceFile != "munm2406.pdf" {
+			t.Errorf("%s: sources = %+v", name, src)
+		}
+		records, err := loadRecordsWith(path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 || records[0].date != "2024-06" || records[1].date != "2024-12" {
+			t.Fatalf("%s: records = %+v", name, records)
+		}
+		if got := records[1].stats[0].Filings.CurrentPeriod.GrandTotal; got != "120" {
+			t.Errorf("%s: 2024-12 filings = %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// migrations upgrade an output document from the keyed schema version to
+// the next one. path is the JSON file being migrated.
+var migrations = map[int]func(out *parser.Output, path string) error{
+	1: migrateV1,
+}
+
+// migrateV1 wraps a bare record array with a provenance header. The source
+// PDF's hash is only recorded if the PDF is still next to the JSON; the
+// parse time is approximated by the JSON file's modification time.
+func migrateV1(out *parser.Output, path string) error {
+	prov := &parser.Provenance{Version: "unknown"}
+	if info, err := os.Stat(path); err == nil {
+		prov.ParsedAt = info.ModTime().UTC().Truncate(time.Second)
+	}
+	pdfPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".pdf"
+	if p, err := fileProvenance(pdfPath); err == nil {
+		prov.SourceFile = p.SourceFile
+		prov.SHA256 = p.SHA256
+	}
+	out.Provenance = prov
+
+	for i := range out.Records {
+		if out.Records[i].SourceFile == "" {
+			out.Records[i].SourceFile = prov.SourceFile
+		}
+		parser.AnnotateHistory(&out.Records[i])
+	}
+	return nil
+}
+
+// migrateOutput upgrades out in place to parser.SchemaVersion, returning
+// the version it started from.
+func migrateOutput(out *parser.Output, path string) (int, error) {
+	from := out.SchemaVersion
+	for out.SchemaVersion < parser.SchemaVersion {
+		step, ok := migrations[out.SchemaVersion]
+		if !ok {
+			return from, fmt.Errorf("no migration from schema version %d", out.SchemaVersion)
+		}
+		if err := step(out, path); err != nil {
+			return from, err
+		}
+		out.SchemaVersion++
+	}
+	return from, nil
+}
+
+// Migrate implements the "migrate" subcommand: upgrade parsed JSON files in
+// place to the current output schema.
+func Migrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing files")
+	backup := fs.Bool("backup", false, "keep each original as <name>.json.bak")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt migrate <parsed-dir> [--dry-run] [--backup]\n\nUpgrade parsed JSON files to schema version %d.\n\nFlags:\n", parser.SchemaVersion)
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(fs.Arg(0), "*.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error globbing directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	var migrated, current, failed int
+	for _, path := range matches {
+		if !isOutputJSON(path) {
+			continue
+		}
+		name := filepath.Base(path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed++
+			continue
+		}
+		out, err := parser.DecodeOutput(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed++
+			continue
+		}
+		if out.SchemaVersion == parser.SchemaVersion {
+			current++
+			continue
+		}
+
+		from, err := migrateOutput(&out, path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: schema %d -> %d\n", name, from, out.SchemaVersion)
+		migrated++
+		if *dryRun {
+			continue
+		}
+
+		if *backup {
+			if err := os.WriteFile(path+".bak", data, 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: error writing backup: %v\n", name, err)
+				failed++
+				continue
+			}
+		}
+		if err := writeOutputJSON(path, out.Provenance, out.Records); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error writing JSON: %v\n", name, err)
+			failed++
+			continue
+		}
+		csvPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".csv"
+		if _, err := os.Stat(csvPath); err == nil {
+			if _, err := os.Stat(metaPath(csvPath)); os.IsNotExist(err) {
+				if err := writeMeta(csvPath, out.Provenance); err != nil {
+					fmt.Fprintf(os.Stderr, "%s: error writing %s: %v\n", name, filepath.Base(metaPath(csvPath)), err)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Done: %d migrated, %d already current, %d failed\n", migrated, current, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestMigrateOutput_V1(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "municipal-courts-2013-06.json")
+	if err := os.WriteFile(jsonPath, []byte(`[]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "municipal-courts-2013-06.pdf"), []byte("%PDF-1.4"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := parser.Output{SchemaVersion: 1, Records: []parser.MunicipalityStats{
+		stat("MERCER", "PRINCETON MUNICIPAL COUR"),
+	}}
+	from, err := migrateOutput(&out, jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != 1 || out.SchemaVersion != parser.SchemaVersion {
+		t.Errorf("migrated %d -> %d", from, out.SchemaVersion)
+	}
+	if out.Provenance == nil || out.Provenance.SourceFile != "municipal-courts-2013-06.pdf" || len(out.Provenance.SHA256) != 64 {
+		t.Errorf("provenance = %+v", out.Provenance)
+	}
+	rec := out.Records[0]
+	if rec.SourceFile != "municipal-courts-2013-06.pdf" || len(rec.Predecessors) != 2 {
+		t.Errorf("record = %q %v", rec.SourceFile, rec.Predecessors)
+	}
+}
+
+func TestWriteOutputJSON_ReplacesInPlace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "municipal-courts-2013-06.json")
+	if err := os.WriteFile(path, []byte(`[]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutputJSON(path, nil, []parser.MunicipalityStats{stat("MERCER", "PRINCETON")}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := parser.DecodeOutput(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.SchemaVersion != parser.SchemaVersion || len(out.Records) != 1 {
+		t.Errorf("rewritten file: schema %d, %d records", out.SchemaVersion, len(out.Records))
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, %v", info.Mode(), err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("left %d files behind, want just the output", len(entries))
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// validValues are the choices for --values.
+var validValues = []string{"cumulative", "monthly"}
+
+// flowRows lists the rows of a report that accumulate from July through the
+// report month, with getters for the current and prior-year rows.
+var flowRows = []func(*parser.MunicipalityStats) (cur, prior *parser.RowData){
+	func(s *parser.MunicipalityStats) (*parser.RowData, *parser.RowData) {
+		return &s.Filings.CurrentPeriod, &s.Filings.PriorPeriod
+	},
+	func(s *parser.MunicipalityStats) (*parser.RowData, *parser.RowData) {
+		return &s.Resolutions.CurrentPeriod, &s.Resolutions.PriorPeriod
+	},
+	func(s *parser.MunicipalityStats) (*parser.RowData, *parser.RowData) {
+		return &s.Clearance.CurrentPeriod, &s.Clearance.PriorPeriod
+	},
+}
+
+// rowFields returns pointers to the value fields of a row, in the order of
+// validTypes.
+func rowFields(r *parser.RowData) []*string {
+	return []*string{&r.GrandTotal, &r.Indictables, &r.DPAndPDP, &r.OtherCriminal, &r.CriminalTotal,
+		&r.DWI, &r.TrafficMoving, &r.Parking, &r.TrafficTotal}
+}
+
+// courtYearMonth numbers t's month within its court year, July as 1
+// through June as 12.
+func courtYearMonth(t time.Time) int {
+	return (int(t.Month())+5)%12 + 1
+}
+
+// monthlyRecords turns the reports' year-to-date counts into per-month
+// counts. Filings, resolutions and clearance accumulate from July through
+// each report's month, so each court's value is differenced against its
+// previous report in the same court year (or taken whole for the first
+// report after the July reset). When reports are months apart, the
+// difference is spread evenly over the months it covers, which get records
+// of their own holding just those rows. Clearance % is recomputed from the
+// monthly filings and resolutions; point-in-time rows (backlog, active
+// pending) and the report's own % change rows are left as reported, and
+// are blank in the filled-in months.
+func monthlyRecords(records []timeRecord) []timeRecord {
+	// cumulative is a court's last report: its month and its year-to-date
+	// values for each of flowRows.
+	type cumulative struct {
+		month      int // courtYearMonth of the report
+		courtYear  string
+		cur, prior [][]float64
+	}
+	last := make(map[string]cumulative)
+	byDate := make(map[string][]parser.MunicipalityStats)
+
+	read := func(r *parser.RowData) []float64 {
+		var out []float64
+		for _, f := range rowFields(r) {
+			out = append(out, parseNumber(*f))
+		}
+		return out
+	}
+	write := func(r *parser.RowData, vals []float64) {
+		for i, f := range rowFields(r) {
+			*f = formatMonthly(vals[i])
+		}
+	}
+	diff := func(cum, prev []float64, months int) []float64 {
+		out := make([]float64, len(cum))
+		for i, v := range cum {
+			base := 0.0
+			if prev != nil {
+				base = prev[i]
+			}
+			out[i] = (v - base) / float64(months)
+		}
+		return out
+	}
+
+	for _, rec := range records {
+		t, err := time.Parse("2006-01", rec.date)
+		if err != nil {
+			byDate[rec.date] = append(byDate[rec.date], rec.stats...)
+			continue
+		}
+		month, cy := courtYearMonth(t), courtYearLabel(t)
+
+		for _, s := range rec.stats {
+			key := entityID(s)
+			prev, seen := last[key]
+			if seen && (prev.courtYear != cy || prev.month >= month) {
+				seen = false
+			}
+			months := month
+			if seen {
+				months = month - prev.month
+			}
+
+			// One copy of the court per month covered; the last is the
+			// report month and keeps the rest of the report.
+			filled := make([]parser.MunicipalityStats, months)
+			for m := range filled {
+				filled[m] = parser.MunicipalityStats{County: s.County, Municipality: s.Municipality, ID: s.ID, DateRange: s.DateRange}
+			}
+			filled[months-1] = s
+
+			next := cumulative{month: month, courtYear: cy}
+			for i, rows := range flowRows {
+				cur, prior := rows(&s)
+				cumCur, cumPrior := read(cur), read(prior)
+				var prevCur, prevPrior []float64
+				if seen {
+					prevCur, prevPrior = prev.cur[i], prev.prior[i]
+				}
+				perCur, perPrior := diff(cumCur, prevCur, months), diff(cumPrior, prevPrior, months)
+				for m := range filled {
+					c, p := rows(&filled[m])
+					c.Label, p.Label = cur.Label, prior.Label
+					write(c, perCur)
+					write(p, perPrior)
+				}
+				next.cur = append(next.cur, cumCur)
+				next.prior = append(next.prior, cumPrior)
+			}
+			last[key] = next
+
+			for m := range filled {
+				f := &filled[m]
+				f.ClearancePct.CurrentPeriod = clearancePctRow(f.Resolutions.CurrentPeriod, f.Filings.CurrentPeriod, s.ClearancePct.CurrentPeriod.Label)
+				f.ClearancePct.PriorPeriod = clearancePctRow(f.Resolutions.PriorPeriod, f.Filings.PriorPeriod, s.ClearancePct.PriorPeriod.Label)
+				date := t.AddDate(0, m-months+1, 0).Format("2006-01")
+				byDate[date] = append(byDate[date], *f)
+			}
+		}
+	}
+
+	out := make([]timeRecord, 0, len(byDate))
+	for date, stats := range byDate {
+		out = append(out, timeRecord{date: date, stats: stats})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].date < out[j].date })
+	return out
+}
+
+// clearancePctRow computes resolutions as a percentage of filings for each
+// column, "- -" where there were no filings.
+func clearancePctRow(res, fil parser.RowData, label string) parser.RowData {
+	row := parser.RowData{Label: label}
+	r, f, out := rowFields(&res), rowFields(&fil), rowFields(&row)
+	for i := range out {
+		rv, fv := parseNumber(*r[i]), parseNumber(*f[i])
+		if math.IsNaN(rv) || math.IsNaN(fv) || fv == 0 {
+			*out[i] = "- -"
+			continue
+		}
+		*out[i] = strconv.FormatFloat(math.Round(rv/fv*100), 'f', 0, 64) + "%"
+	}
+	return row
+}
+
+// formatMonthly writes a per-month count to one decimal place, since spread
+// differences aren't whole; NaN is "- -".
+func formatMonthly(v float64) string {
+	if math.IsNaN(v) {
+		return "- -"
+	}
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
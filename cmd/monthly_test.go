@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestMonthlyRecords(t *testing.T) {
+	report := func(date, filings, resolutions, backlog string) timeRecord {
+		s := rateStat("ATLANTIC", "ABSECON", filings, resolutions, "")
+		s.Backlog.CurrentPeriod.GrandTotal = backlog
+		return timeRecord{date: date, stats: []parser.MunicipalityStats{s}}
+	}
+	records := []timeRecord{
+		report("2024-09", "300", "150", "40"), // July–September after the reset
+		report("2024-10", "420", "270", "41"),
+		report("2025-01", "600", "450", "42"), // three months since October
+		report("2025-07", "50", "100", "43"),  // a new court year
+	}
+
+	got := monthlyRecords(records)
+	want := []struct {
+		date, filings, clearancePct, backlog string
+	}{
+		{"2024-07", "100", "50%", ""},
+		{"2024-08", "100", "50%", ""},
+		{"2024-09", "100", "50%", "40"},
+		{"2024-10", "120", "100%", "41"},
+		{"2024-11", "60", "100%", ""},
+		{"2024-12", "60", "100%", ""},
+		{"2025-01", "60", "100%", "42"},
+		{"2025-07", "50", "200%", "43"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		s := got[i].stats[0]
+		if got[i].date != w.date || s.Filings.CurrentPeriod.GrandTotal != w.filings ||
+			s.ClearancePct.CurrentPeriod.GrandTotal != w.clearancePct || s.Backlog.CurrentPeriod.GrandTotal != w.backlog {
+			t.Errorf("record %d = %s filings %s clearance %s backlog %q, want %s %s %s %q", i, got[i].date,
+				s.Filings.CurrentPeriod.GrandTotal, s.ClearancePct.CurrentPeriod.GrandTotal, s.Backlog.CurrentPeriod.GrandTotal,
+				w.date, w.filings, w.clearancePct, w.backlog)
+		}
+	}
+}
+
+func TestCourtYearMonth(t *testing.T) {
+	for month, want := range map[int]int{7: 1, 12: 6, 1: 7, 6: 12} {
+		if got := courtYearMonth(time.Date(2024, time.Month(month), 1, 0, 0, 0, 0, time.UTC)); got != want {
+			t.Errorf("courtYearMonth(%d) = %d, want %d", month, got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// muniEntry is one court in the data and the periods it appears in.
+type muniEntry struct {
+	County       string   `json:"county"`
+	Municipality string   `json:"municipality"`
+	ID           string   `json:"id"`
+	Names        []string `json:"names"` // as printed in the reports, sorted
+	FirstPeriod  string   `json:"firstPeriod"`
+	LastPeriod   string   `json:"lastPeriod"`
+	Periods      int      `json:"periods"`
+}
+
+// buildMunis lists every court in records, by entity ID so a renamed
+// court is one entry, sorted by county and name.
+func buildMunis(records []timeRecord) []muniEntry {
+	byID := make(map[string]*muniEntry)
+	names := make(map[string]map[string]bool)
+	for _, rec := range records {
+		seen := make(map[string]bool)
+		for _, s := range rec.stats {
+			id := entityID(s)
+			e, ok := byID[id]
+			if !ok {
+				e = &muniEntry{County: strings.ToUpper(s.County), Municipality: entityLabel(s), ID: id, FirstPeriod: rec.date}
+				byID[id] = e
+				names[id] = make(map[string]bool)
+			}
+			names[id][strings.ToUpper(s.Municipality)] = true
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if rec.date < e.FirstPeriod {
+				e.FirstPeriod = rec.date
+			}
+			if rec.date > e.LastPeriod {
+				e.LastPeriod = rec.date
+			}
+			e.Periods++
+		}
+	}
+
+	out := make([]muniEntry, 0, len(byID))
+	for id, e := range byID {
+		for n := range names[id] {
+			e.Names = append(e.Names, n)
+		}
+		sort.Strings(e.Names)
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].County != out[j].County {
+			return out[i].County < out[j].County
+		}
+		return out[i].Municipality < out[j].Municipality
+	})
+	return out
+}
+
+// Munis implements the "munis" subcommand: the counties and municipalities
+// in a parsed directory, with the periods each appears in.
+func Munis(args []string) {
+	fs := flag.NewFlagSet("munis", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	county := fs.String("county", "", "county filter")
+	asJSON := fs.Bool("json", false, "write JSON instead of a table")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt munis [dir] [--county NAME] [--json]\n\nList the counties and municipalities in the data with the periods each appears in.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if *county != "" {
+		c, ok := parser.NormalizeCounty(*county)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown --county %q\n", *county)
+			os.Exit(1)
+		}
+		*county = c
+	}
+
+	// Only the names are needed, so no sections are kept.
+	records, err := loadMetricRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	munis := buildMunis(records)
+	if *county != "" {
+		kept := munis[:0]
+		for _, m := range munis {
+			if m.County == *county {
+				kept = append(kept, m)
+			}
+		}
+		munis = kept
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(munis); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	renderMunis(munis, len(records))
+}
+
+func renderMunis(munis []muniEntry, periods int) {
+	width := len("Municipality")
+	for _, m := range munis {
+		width = max(width, len(m.Municipality))
+	}
+	row := func(name, first, last, periods, also string) string {
+		return strings.TrimRight(fmt.Sprintf("  %-*s  %-8s  %-8s  %7s  %s", width, name, first, last, periods, also), " ")
+	}
+	for i, m := range munis {
+		if i == 0 || m.County != munis[i-1].County {
+			n := 0
+			for _, o := range munis[i:] {
+				if o.County != m.County {
+					break
+				}
+				n++
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("%s (%d)", m.County, n)))
+			fmt.Println(paint(colorStdout, styleDim, row("Municipality", "First", "Last", "Periods", "Also reported as")))
+		}
+		var also []string
+		for _, n := range m.Names {
+			if n != m.Municipality {
+				also = append(also, n)
+			}
+		}
+		fmt.Println(row(m.Municipality, m.FirstPeriod, m.LastPeriod, fmt.Sprintf("%d/%d", m.Periods, periods), strings.Join(also, ", ")))
+	}
+	if len(munis) == 0 {
+		fmt.Println("(none)")
+	}
+}
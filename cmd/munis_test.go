@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestBuildMunis(t *testing.T) {
+	records := []timeRecord{
+		{date: "2022-06", stats: []parser.MunicipalityStats{stat("OCEAN", "DOVER TWP"), stat("ATLANTIC", "ABSECON")}},
+		{date: "2023-06", stats: []parser.MunicipalityStats{stat("OCEAN", "TOMS RIVER"), stat("ATLANTIC", "ABSECON")}},
+		{date: "2024-06", stats: []parser.MunicipalityStats{stat("OCEAN", "TOMS RIVER")}},
+	}
+	got := buildMunis(records)
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(got), got)
+	}
+	if a := got[0]; a.ID != "atlantic.absecon" || a.FirstPeriod != "2022-06" || a.LastPeriod != "2023-06" || a.Periods != 2 {
+		t.Errorf("absecon = %+v", a)
+	}
+	// The rename is one court, listed under its registry name.
+	if o := got[1]; o.Municipality != "TOMS RIVER" || o.Periods != 3 || len(o.Names) != 2 || o.Names[0] != "DOVER TWP" {
+		t.Errorf("toms river = %+v", o)
+	}
+}
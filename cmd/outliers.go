@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// outlierMetrics are the counts checked against their history at parse
+// time. Rates are left out, since a small court's clearance % can swing
+// wildly without anything being wrong, and so is clearance, the signed
+// difference of resolutions and filings, which is often near zero.
+var outlierMetrics = []string{"filings", "resolutions", "backlog", "active-pending"}
+
+const (
+	// defaultOutlierFactor is the jump, in either direction, from a
+	// value's recent history that parse flags; a column shift usually
+	// moves a value by far more.
+	defaultOutlierFactor = 50
+	// outlierWindow is how many of the latest earlier reports make up a
+	// value's recent history (their median is compared).
+	outlierWindow = 3
+	// outlierMinValue keeps small counts, where 1 → 60 is plausible, from
+	// being flagged: the larger of the two values must reach it.
+	outlierMinValue = 100
+)
+
+// findOutliers compares each count in stats, for a report of the given
+// period, with the median of the same court's values in the latest
+// outlierWindow earlier periods of history, and describes every value that
+// differs from it by factor or more. Courts with no earlier values are
+// skipped. The returned slice is indexed like stats.
+func findOutliers(stats []parser.MunicipalityStats, period string, history []timeRecord, factor float64) [][]string {
+	earlier := make(map[string][]parser.MunicipalityStats)
+	for i := len(history) - 1; i >= 0; i-- {
+		rec := history[i]
+		if period != "" && rec.date >= period {
+			continue
+		}
+		for _, s := range rec.stats {
+			k := entityID(s)
+			if len(earlier[k]) < outlierWindow {
+				earlier[k] = append(earlier[k], s)
+			}
+		}
+	}
+
+	found := make([][]string, len(stats))
+	for i, s := range stats {
+		prev := earlier[entityID(s)]
+		if len(prev) == 0 {
+			continue
+		}
+		for _, metric := range outlierMetrics {
+			for _, caseType := range validTypes {
+				v := getField(getRow(s, metric), caseType)
+				if math.IsNaN(v) || v < 0 {
+					continue
+				}
+				var past []float64
+				for _, p := range prev {
+					if pv := getField(getRow(p, metric), caseType); !math.IsNaN(pv) && pv >= 0 {
+						past = append(past, pv)
+					}
+				}
+				if len(past) == 0 {
+					continue
+				}
+				base := median(past)
+				hi, lo := math.Max(v, base), math.Min(v, base)
+				if hi < outlierMinValue || hi/math.Max(lo, 1) < factor {
+					continue
+				}
+				found[i] = append(found[i], fmt.Sprintf("%s %s is %s against a recent median of %s (%.0fx)",
+					metricLabel(metric), strings.ToLower(typeLabel(caseType)), formatNum(v), formatNum(base), hi/math.Max(lo, 1)))
+			}
+		}
+	}
+	return found
+}
+
+// flagOutliers runs findOutliers on r, adding each finding to the record's
+// warnings and, with its page and court, to r.outliers for the summary.
+func flagOutliers(r *parseResult, history []timeRecord, factor float64) {
+	period := r.date
+	if period == "" && len(r.results) > 0 {
+		period, _ = periodFromDateRange(r.results[0].DateRange)
+	}
+	for i, msgs := range findOutliers(r.results, period, history, factor) {
+		s := &r.results[i]
+		for _, m := range msgs {
+			s.Warnings = append(s.Warnings, "possible outlier: "+m)
+			r.outliers = append(r.outliers, fmt.Sprintf("page %d: %s/%s %s", s.PageNumber, s.County, s.Municipality, m))
+		}
+	}
+}
+
+// outlierHistory loads the parsed output in dir as the history for
+// flagOutliers, with the reports in parsed (from the same run) taking the
+// place of any older output for their periods. A directory that can't be
+// read contributes nothing, with a warning.
+func outlierHistory(dir string, parsed []parseResult) []timeRecord {
+	records, err := loadMetricRecords(dir, outlierMetrics...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: reading %s for the outlier check: %v\n", filepath.Clean(dir), err)
+	}
+	fresh := make(map[string]bool)
+	var out []timeRecord
+	for _, r := range parsed {
+		if r.failed || r.date == "" {
+			continue
+		}
+		fresh[r.date] = true
+		out = append(out, timeRecord{date: r.date, stats: r.results})
+	}
+	for _, rec := range records {
+		if !fresh[rec.date] {
+			out = append(out, rec)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].date < out[j].date })
+	return out
+}
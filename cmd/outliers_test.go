@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestFindOutliers(t *testing.T) {
+	history := []timeRecord{
+		{date: "2021-06", stats: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "1,000", "900", "90%")}},
+		{date: "2022-06", stats: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "1,100", "5", "0%")}},
+		{date: "2023-06", stats: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "1,050", "950", "90%")}},
+		// Later periods aren't history for a 2024-06 report.
+		{date: "2025-06", stats: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "20", "950", "90%")}},
+	}
+	stats := []parser.MunicipalityStats{
+		rateStat("ATLANTIC", "ABSECON", "60,000", "1,000", "2%"), // filings shifted up 57×
+		rateStat("ATLANTIC", "BRIGANTINE", "60,000", "5", "0%"),  // no history
+	}
+
+	found := findOutliers(stats, "2024-06", history, 50)
+	if len(found[0]) != 1 || !strings.Contains(found[0][0], "Filings grand total is 60,000 against a recent median of 1,050") {
+		t.Errorf("ABSECON = %q, want one filings finding (the one low resolutions value is outvoted by the median)", found[0])
+	}
+	if len(found[1]) != 0 {
+		t.Errorf("BRIGANTINE = %q, want nothing without history", found[1])
+	}
+
+	// Small counts aren't flagged however far they move.
+	small := []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "99", "950", "90%")}
+	history[2].stats[0].Filings.CurrentPeriod.GrandTotal = "1"
+	history[1].stats[0].Filings.CurrentPeriod.GrandTotal = "1"
+	if found := findOutliers(small, "2024-06", history, 50); len(found[0]) != 0 {
+		t.Errorf("small counts flagged: %q", found[0])
+	}
+}
+
+func TestFlagOutliers(t *testing.T) {
+	history := []timeRecord{{date: "2023-06", stats: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "1,000", "900", "90%")}}}
+	s := rateStat("ATLANTIC", "ABSECON", "5", "900", "90%")
+	s.PageNumber = 7
+	r := parseResult{date: "2024-06", results: []parser.MunicipalityStats{s}}
+	flagOutliers(&r, history, 50)
+	if len(r.outliers) != 1 || !strings.HasPrefix(r.outliers[0], "page 7: ATLANTIC/ABSECON Filings") {
+		t.Errorf("outliers = %q", r.outliers)
+	}
+	if w := r.results[0].Warnings; len(w) != 1 || !strings.HasPrefix(w[0], "possible outlier: ") {
+		t.Errorf("warnings = %q", w)
+	}
+}
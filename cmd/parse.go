@@ -1,132 +1,735 @@
 package cmd
 
 import (
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/zalepa/municourt/parser"
 )
 
 // parseResult holds the output of parsing a single PDF file.
 type parseResult struct {
-	inputPath string
-	date      string // YYYY-MM extracted from filename
-	results   []parser.MunicipalityStats
-	errors    []string
-	nPages    int
-	failed    bool
+	inputPath  string
+	date       string // YYYY-MM extracted from filename
+	provenance *parser.Provenance
+	results    []parser.MunicipalityStats
+	errors     []string
+	duplicates []string // pages dropped as repeats of another page
+	shortfalls []string // counties with fewer municipalities than expected
+	outliers   []string // values far from the court's recent history
+	violations []string // values breaking --rules
+	failures   []failedPage
+	nPages     int
+	failed     bool
 }
 
-// Parse implements the "parse" subcommand: read a PDF (or directory of PDFs),
-// extract municipal court statistics, and write JSON + CSV output files.
+// failedPage is the text of a page that failed to parse, kept for
+// --dump-failed.
+type failedPage struct {
+	page  int
+	err   string
+	lines [][]string
+}
+
+// Parse implements the "parse" subcommand: read one or more PDFs (files,
+// directories, or globs), extract municipal court statistics, and write
+// JSON + CSV output files.
 func Parse(args []string) {
 	fs := flag.NewFlagSet("parse", flag.ExitOnError)
 	jsonOut := fs.String("json", "", "output JSON file path (single file mode only)")
 	csvOut := fs.String("csv", "", "output CSV file path (single file mode only)")
+	outDir := fs.String("out-dir", "", "write outputs here instead of alongside each PDF")
+	recursive := fs.Bool("recursive", false, "also parse PDFs in subdirectories of directory arguments")
+	nameTemplate := fs.String("name-template", "", "output file name template, e.g. \"{{.Year}}-{{.Month}}-municipal.json\" (fields: Year, Month, Period, Base)")
+	cleanNumbers := fs.Bool("clean-numbers", false, "write CSV values as plain numbers: no commas, percentages as decimals, \"- -\" as empty")
+	sections := fs.String("sections", "", "comma-separated sections to include: "+strings.Join(tableSections, ", ")+" (default all)")
+	rows := fs.String("rows", "", "comma-separated rows to include per section: prior, current, change (default all)")
+	split := fs.Bool("split-sections", false, "write <name>-<section>.csv per section in place of the combined CSV")
+	postFlags := addPostParseFlags(fs)
+	watch := fs.String("watch", "", "keep running, parsing PDFs as they appear or change in this directory")
+	poll := fs.Duration("poll", 2*time.Second, "how often --watch checks the directory")
+	dumpFailed := fs.Bool("dump-failed", false, "write the text lines of each page that fails to parse to <name>.failed/page-NNN.txt for review")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: municourt parse <input.pdf | directory> [--json output.json] [--csv output.csv]\n\n")
-		fmt.Fprintf(os.Stderr, "If a directory is given, all *.pdf files in it are parsed and output\nfiles are written alongside each PDF.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: municourt parse <input.pdf | directory | glob>... [--recursive] [--out-dir dir] [--name-template tmpl] [--json output.json] [--csv output.csv] [--clean-numbers] [--sections list] [--rows list] [--split-sections] [--duplicates policy] [--dump-failed] [--strict]\n")
+		fmt.Fprintf(os.Stderr, "       municourt parse --watch <directory> [--poll 2s] [--recursive] [--out-dir dir] [--name-template tmpl] ...\n\n")
+		fmt.Fprintf(os.Stderr, "Directories contribute every *.pdf inside them (and their subdirectories\nwith --recursive); globs are expanded if the shell didn't. Output files are written alongside each PDF unless --out-dir\nis given.\n\n")
 		fs.PrintDefaults()
 	}
+	args = reorderArgs(args)
 	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
 
-	if fs.NArg() < 1 {
+	if *watch != "" {
+		if fs.NArg() > 0 || *jsonOut != "" || *csvOut != "" {
+			fmt.Fprintf(os.Stderr, "--watch takes the directory to watch in place of inputs, and can't be combined with --json or --csv\n")
+			os.Exit(1)
+		}
+		if info, err := os.Stat(*watch); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "--watch %s: not a directory\n", *watch)
+			os.Exit(1)
+		}
+		if *poll <= 0 {
+			fmt.Fprintf(os.Stderr, "--poll must be positive\n")
+			os.Exit(1)
+		}
+	} else if fs.NArg() < 1 {
 		fs.Usage()
 		os.Exit(1)
 	}
 
-	inputPath := fs.Arg(0)
-
-	info, err := os.Stat(inputPath)
+	var pdfs []string
+	var err error
+	if *watch == "" {
+		pdfs, err = expandInputs(fs.Args(), *recursive)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(pdfs) == 0 {
+			fmt.Fprintf(os.Stderr, "no PDF files found in %s\n", strings.Join(fs.Args(), " "))
+			os.Exit(1)
+		}
+	}
+	opts, err := parseTableOptions(*cleanNumbers, *split, *sections, *rows)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-
-	if info.IsDir() {
-		pdfs, err := filepath.Glob(filepath.Join(inputPath, "*.pdf"))
+	post, err := postFlags.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if (*jsonOut != "" || *csvOut != "") && len(pdfs) > 1 {
+		fmt.Fprintf(os.Stderr, "--json and --csv require a single input PDF (got %d)\n", len(pdfs))
+		os.Exit(1)
+	}
+	var tmpl *template.Template
+	if *nameTemplate != "" {
+		tmpl, err = template.New("name").Option("missingkey=error").Parse(*nameTemplate)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error globbing directory: %v\n", err)
+			fmt.Fprintf(os.Stderr, "invalid --name-template: %v\n", err)
 			os.Exit(1)
 		}
-		if len(pdfs) == 0 {
-			fmt.Fprintf(os.Stderr, "no PDF files found in %s\n", inputPath)
+	}
+	if *outDir != "" && tmpl == nil {
+		if err := checkOutputCollisions(pdfs); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(*outDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "error creating output directory: %v\n", err)
 			os.Exit(1)
 		}
+	}
 
-		var parsed []parseResult
-		for _, pdf := range pdfs {
-			parsed = append(parsed, parsePDFFile(pdf))
+	if *watch != "" {
+		if *outDir != "" {
+			if err := os.MkdirAll(*outDir, 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "error creating output directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		upToDate := func(pdf string, stamp fileStamp) bool {
+			j, _, err := outputPaths(parseResult{inputPath: pdf, date: periodFromName(pdf)}, *outDir, tmpl)
+			if err != nil {
+				return false
+			}
+			info, err := os.Stat(j)
+			return err == nil && !info.ModTime().Before(stamp.modTime)
 		}
+		parse := func(pdf string) {
+			r := parsePDFFile(pdf)
+			if *dumpFailed {
+				dumpFailedPages(r, *outDir)
+			}
+			if !post.process(&r, historyDir(pdf, *outDir)) {
+				return
+			}
+			j, c, err := outputPaths(r, *outDir, tmpl)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(pdf), err)
+				return
+			}
+			if err := os.MkdirAll(filepath.Dir(j), 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: error creating output directory: %v\n", filepath.Base(pdf), err)
+				return
+			}
+			writeResults(r, j, c, opts)
+		}
+		if err := watchPDFs(*watch, *recursive, *poll, upToDate, parse); err != nil {
+			fmt.Fprintf(os.Stderr, "error watching %s: %v\n", *watch, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-		deduplicateMunicipalities(parsed)
+	var parsed []parseResult
+	for _, pdf := range pdfs {
+		r := parsePDFFile(pdf)
+		if *dumpFailed {
+			dumpFailedPages(r, *outDir)
+		}
+		post.check(&r)
+		parsed = append(parsed, r)
+	}
 
-		for _, r := range parsed {
-			if !r.failed {
-				writeResults(r, "", "")
+	if len(parsed) > 1 {
+		deduplicateMunicipalities(parsed)
+	}
+	if post.outlierFactor > 0 {
+		histories := make(map[string][]timeRecord)
+		for i := range parsed {
+			if parsed[i].failed {
+				continue
+			}
+			dir := historyDir(parsed[i].inputPath, *outDir)
+			if _, ok := histories[dir]; !ok {
+				histories[dir] = outlierHistory(dir, parsed)
 			}
+			flagOutliers(&parsed[i], histories[dir], post.outlierFactor)
+		}
+	}
+
+	written := make(map[string]string)
+	rejected := false
+	for _, r := range parsed {
+		if post.strict && rejectStrict(r) {
+			rejected = true
+			continue
 		}
-	} else {
-		// Default output paths: same directory and base name as input.
-		dir := filepath.Dir(inputPath)
-		base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
-		if *jsonOut == "" {
-			*jsonOut = filepath.Join(dir, base+".json")
+		if r.failed {
+			continue
+		}
+		j, c, err := outputPaths(r, *outDir, tmpl)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(r.inputPath), err)
+			continue
 		}
-		if *csvOut == "" {
-			*csvOut = filepath.Join(dir, base+".csv")
+		if prev, ok := written[j]; ok {
+			fmt.Fprintf(os.Stderr, "%s: output %s already written for %s; skipping\n", filepath.Base(r.inputPath), j, filepath.Base(prev))
+			continue
+		}
+		written[j] = r.inputPath
+		if *jsonOut != "" {
+			j = *jsonOut
 		}
-		r := parsePDFFile(inputPath)
-		if !r.failed {
-			writeResults(r, *jsonOut, *csvOut)
+		if *csvOut != "" {
+			c = *csvOut
+		}
+		if err := os.MkdirAll(filepath.Dir(j), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error creating output directory: %v\n", filepath.Base(r.inputPath), err)
+			continue
 		}
+		writeResults(r, j, c, opts)
+	}
+	if rejected {
+		os.Exit(1)
 	}
 }
 
-func parsePDFFile(inputPath string) parseResult {
-	baseName := filepath.Base(inputPath)
-	date := ""
-	if m := datePattern.FindStringSubmatch(baseName); m != nil {
-		date = m[1] + "-" + m[2]
+// postParse is what happens to a parsed PDF before its outputs are
+// written, the same for parse, parse --watch, fetch --parse and sync.
+type postParse struct {
+	duplicates    string               // which repeated page to keep; see dropDuplicatePages
+	counts        []parser.CountChange // expected municipalities per county, nil to skip the check
+	outlierFactor float64              // 0 to skip the outlier check
+	rules         []rule               // nil to skip the rules check
+	strict        bool                 // don't write a PDF with page errors
+}
+
+// postParseFlags are the flags that set up a postParse.
+type postParseFlags struct {
+	duplicates, counts, rules *string
+	outlierFactor             *float64
+	strict                    *bool
+}
+
+func addPostParseFlags(fs *flag.FlagSet) postParseFlags {
+	return postParseFlags{
+		duplicates:    fs.String("duplicates", "later", "which page to keep when a municipality appears twice in one PDF: "+strings.Join(duplicatePolicies, ", ")),
+		counts:        fs.String("expected-counts", "", "JSON table of expected municipalities per county, replacing the built-in one (\"off\" to skip the check)"),
+		rules:         fs.String("rules", "", "JSON sanity-check rules file (see validate) to check each record against"),
+		outlierFactor: fs.Float64("outlier-factor", defaultOutlierFactor, "flag counts this many times above or below the court's recent history in the output directory (0 to skip)"),
+		strict:        fs.Bool("strict", false, "don't write a PDF's outputs if any of its pages failed, and exit 1"),
 	}
+}
+
+// load checks the parsed flags and reads the files they name.
+func (f postParseFlags) load() (postParse, error) {
+	p := postParse{duplicates: *f.duplicates, counts: parser.CountChanges, outlierFactor: *f.outlierFactor, strict: *f.strict}
+	if !contains(duplicatePolicies, p.duplicates) {
+		return p, fmt.Errorf("invalid --duplicates %q; valid options: %s", p.duplicates, strings.Join(duplicatePolicies, ", "))
+	}
+	if p.outlierFactor != 0 && p.outlierFactor <= 1 {
+		return p, fmt.Errorf("--outlier-factor must be greater than 1, or 0 to skip the check")
+	}
+	if *f.rules != "" {
+		rules, err := loadRules(*f.rules)
+		if err != nil {
+			return p, fmt.Errorf("error reading --rules: %w", err)
+		}
+		p.rules = rules
+	}
+	switch *f.counts {
+	case "":
+	case "off":
+		p.counts = nil
+	default:
+		data, err := os.ReadFile(*f.counts)
+		if err == nil {
+			p.counts, err = parser.DecodeCountChanges(data)
+		}
+		if err != nil {
+			return p, fmt.Errorf("error reading --expected-counts: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// check drops r's repeated pages and runs the count and rules checks on
+// what's left. The outlier check is separate, since parse compares a
+// batch of PDFs only after merging their name variants.
+func (p postParse) check(r *parseResult) {
+	r.results, r.duplicates = dropDuplicatePages(r.results, p.duplicates)
+	r.shortfalls = checkCounts(*r, p.counts)
+	if p.rules != nil {
+		flagRuleViolations(r, p.rules)
+	}
+}
+
+// process readies r, a PDF parsed on its own, for writing: check, then the
+// outlier check against the court history in dir. It reports false if r
+// shouldn't be written because it couldn't be read or, with strict, had
+// page errors, which strictFailure describes.
+func (p postParse) process(r *parseResult, dir string) bool {
+	if p.strict && rejectStrict(*r) || r.failed {
+		return false
+	}
+	p.check(r)
+	if p.outlierFactor > 0 {
+		flagOutliers(r, outlierHistory(dir, nil), p.outlierFactor)
+	}
+	return true
+}
+
+// strictFailure says why --strict won't write a PDF's outputs, or returns
+// "" if it will: the PDF couldn't be read, or some of its pages failed.
+func strictFailure(r parseResult) string {
+	switch {
+	case r.failed:
+		return "couldn't be read"
+	case len(r.errors) == 1:
+		return "1 page error"
+	case len(r.errors) > 1:
+		return fmt.Sprintf("%d page errors", len(r.errors))
+	}
+	return ""
+}
+
+// rejectStrict reports whether --strict keeps r from being written, listing
+// its errors if so.
+func rejectStrict(r parseResult) bool {
+	why := strictFailure(r)
+	if why == "" {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s; not writing outputs (--strict)\n", paint(colorStderr, styleBold, filepath.Base(r.inputPath)), why)
+	for _, e := range r.errors {
+		fmt.Fprintf(os.Stderr, "  %s\n", paint(colorStderr, styleRed, e))
+	}
+	return true
+}
+
+// failedDir is where --dump-failed writes the pages of a PDF that failed:
+// <name>.failed alongside the PDF, or in outDir if given.
+func failedDir(inputPath, outDir string) string {
+	dir := outDir
+	if dir == "" {
+		dir = filepath.Dir(inputPath)
+	}
+	base := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	return filepath.Join(dir, base+".failed")
+}
+
+// writeFailedPages writes each failed page of r to dir as page-NNN.txt: the
+// error, then the page's text a line at a time with its items separated by
+// tabs. dir is cleared first, so pages that parse on a later run don't
+// linger, and removed if no page failed. It returns the number of pages
+// written.
+func writeFailedPages(r parseResult, dir string) (int, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, err
+	}
+	if len(r.failures) == 0 {
+		return 0, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	for i, f := range r.failures {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# page %d: %s\n", f.page, f.err)
+		for _, line := range f.lines {
+			b.WriteString(strings.Join(line, "\t"))
+			b.WriteByte('\n')
+		}
+		name := filepath.Join(dir, fmt.Sprintf("page-%03d.txt", f.page))
+		if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+			return i, err
+		}
+	}
+	return len(r.failures), nil
+}
 
-	pages, err := parser.ExtractContentStreams(inputPath)
+// dumpFailedPages runs writeFailedPages for --dump-failed, reporting what
+// it wrote.
+func dumpFailedPages(r parseResult, outDir string) {
+	if r.failed {
+		return
+	}
+	dir := failedDir(r.inputPath, outDir)
+	n, err := writeFailedPages(r, dir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: error extracting PDF streams: %v\n", baseName, err)
-		return parseResult{inputPath: inputPath, date: date, failed: true}
+		fmt.Fprintf(os.Stderr, "%s: error writing failed pages: %v\n", filepath.Base(r.inputPath), err)
 	}
+	if n > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d failed pages written to %s\n", filepath.Base(r.inputPath), n, dir)
+	}
+}
 
-	var results []parser.MunicipalityStats
-	var errors []string
+// historyDir is where the outlier check looks for earlier output of the
+// court: --out-dir if given, otherwise alongside the PDF.
+func historyDir(inputPath, outDir string) string {
+	if outDir != "" {
+		return outDir
+	}
+	return filepath.Dir(inputPath)
+}
 
-	for i, page := range pages {
-		items := parser.ExtractTextItems(page)
-		if !parser.ContainsFilings(items) {
-			continue
+// outputName holds the fields available to --name-template.
+type outputName struct {
+	Year   string // e.g. 2024
+	Month  string // e.g. 06
+	Period string // e.g. 2024-06
+	Base   string // input file name without extension
+}
+
+// outputPaths returns where r's JSON and CSV go: alongside the input (or in
+// outDir), named after the input, or after tmpl if given. The template's
+// extension is replaced by .json and .csv respectively, and it may include
+// subdirectories such as "{{.Year}}/{{.Period}}.json".
+func outputPaths(r parseResult, outDir string, tmpl *template.Template) (string, string, error) {
+	dir := filepath.Dir(r.inputPath)
+	if outDir != "" {
+		dir = outDir
+	}
+	base := strings.TrimSuffix(filepath.Base(r.inputPath), filepath.Ext(r.inputPath))
+	if tmpl == nil {
+		return filepath.Join(dir, base+".json"), filepath.Join(dir, base+".csv"), nil
+	}
+
+	period := r.date
+	if period == "" && len(r.results) > 0 {
+		period, _ = periodFromDateRange(r.results[0].DateRange)
+	}
+	if period == "" {
+		return "", "", fmt.Errorf("no period in file name or report date range for --name-template")
+	}
+
+	var b strings.Builder
+	err := tmpl.Execute(&b, outputName{Year: period[:4], Month: period[5:], Period: period, Base: base})
+	if err != nil {
+		return "", "", fmt.Errorf("--name-template: %w", err)
+	}
+	name := filepath.FromSlash(b.String())
+	stem := filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name)))
+	return stem + ".json", stem + ".csv", nil
+}
+
+// expandInputs turns the positional arguments into a list of PDF paths.
+// Directories contribute their *.pdf files (walking subdirectories too when
+// recursive is set, in lexical order), arguments containing glob
+// metacharacters are expanded (for shells that pass them through quoted),
+// and anything else is taken as a file. Duplicates are dropped, keeping the
+// first occurrence.
+func expandInputs(args []string, recursive bool) ([]string, error) {
+	var pdfs []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		clean := filepath.Clean(path)
+		if !seen[clean] {
+			seen[clean] = true
+			pdfs = append(pdfs, path)
 		}
-		stats, err := parser.ParsePage(items)
+	}
+
+	for _, arg := range args {
+		var paths []string
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("bad pattern %q: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s: no matches", arg)
+			}
+			paths = matches
+		} else {
+			paths = []string{arg}
+		}
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(path)
+				continue
+			}
+			if recursive {
+				err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+					if err != nil {
+						return err
+					}
+					if !d.IsDir() && filepath.Ext(p) == ".pdf" {
+						add(p)
+					}
+					return nil
+				})
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			inDir, err := filepath.Glob(filepath.Join(path, "*.pdf"))
+			if err != nil {
+				return nil, fmt.Errorf("error globbing directory: %w", err)
+			}
+			for _, p := range inDir {
+				add(p)
+			}
+		}
+	}
+	return pdfs, nil
+}
+
+// checkOutputCollisions reports PDFs from different directories that would
+// write the same output file name into a shared --out-dir.
+func checkOutputCollisions(pdfs []string) error {
+	byName := make(map[string]string)
+	for _, p := range pdfs {
+		name := strings.ToLower(filepath.Base(p))
+		if prev, ok := byName[name]; ok {
+			return fmt.Errorf("%s and %s would both write %s to --out-dir", prev, p, filepath.Base(p))
+		}
+		byName[name] = p
+	}
+	return nil
+}
+
+// periodFromName returns the YYYY-MM in a report's file name, or "".
+func periodFromName(path string) string {
+	if m := datePattern.FindStringSubmatch(filepath.Base(path)); m != nil {
+		return m[1] + "-" + m[2]
+	}
+	return ""
+}
+
+func parsePDFFile(inputPath string) parseResult {
+	baseName := filepath.Base(inputPath)
+	date := periodFromName(inputPath)
+
+	var results []parser.MunicipalityStats
+	var errs []string
+	var failures []failedPage
+
+	// Pages are decoded, parsed and released one at a time so combined
+	// annual reports don't need every page in memory at once.
+	nPages := 0
+	var docDate time.Time
+	record := func(stats parser.MunicipalityStats, items []parser.TextItem, err, contentErr error, n int) {
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("page %d: %v", i+1, err))
-			continue
+			if contentErr != nil {
+				err = fmt.Errorf("%w (%v)", err, contentErr)
+			}
+			errs = append(errs, fmt.Sprintf("page %d: %v", n, err))
+			failures = append(failures, failedPage{page: n, err: err.Error(), lines: parser.Lines(items)})
+			return
 		}
+		if contentErr != nil {
+			stats.Warnings = append(stats.Warnings, contentErr.Error())
+		}
+		// Unknown counties are kept but reported, so a new variant can be
+		// added to the normalizer rather than silently becoming an entity.
+		county, ok := parser.NormalizeCounty(stats.County)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("page %d: unknown county %q (%s)", n, stats.County, stats.Municipality))
+			stats.Warnings = append(stats.Warnings, fmt.Sprintf("unknown county %q", stats.County))
+		}
+		stats.County = county
+		stats.SourceFile = baseName
+		parser.AnnotateHistory(&stats)
 		results = append(results, stats)
 	}
 
+	// A page that fails part way through its table is held back in case
+	// the next page continues it (see parser.IsContinuation).
+	type fragment struct {
+		items      []parser.TextItem
+		page       int
+		err        error
+		contentErr error
+	}
+	var pending *fragment
+	flush := func() {
+		if pending != nil {
+			record(parser.MunicipalityStats{}, pending.items, pending.err, pending.contentErr, pending.page)
+			pending = nil
+		}
+	}
+
+	err := parser.ForEachPage(inputPath, func(page parser.PageData) error {
+		nPages++
+		n := page.Number
+		docDate = page.DocDate
+		items, contentErr := parser.ExtractPlacedItems(page)
+		if pending != nil && parser.IsContinuation(items) {
+			frag := pending
+			pending = nil
+			stitched := parser.StitchPages(frag.items, items)
+			stats, err := parser.ParsePageItems(stitched, frag.page)
+			if err != nil {
+				err = fmt.Errorf("continued on page %d: %w", n, err)
+			} else {
+				stats.Warnings = append(stats.Warnings, fmt.Sprintf("table continued on page %d", n))
+			}
+			record(stats, stitched, err, errors.Join(frag.contentErr, contentErr), frag.page)
+			return nil
+		}
+		flush()
+		if !parser.ContainsFilings(parser.Texts(items)) {
+			return nil
+		}
+		stats, err := parser.ParsePageItems(items, n)
+		if errors.Is(err, parser.ErrNotDataPage) {
+			return nil
+		}
+		if err != nil && parser.IsFragment(items) {
+			pending = &fragment{items: items, page: n, err: err, contentErr: contentErr}
+			return nil
+		}
+		record(stats, items, err, contentErr, n)
+		return nil
+	})
+	flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error extracting PDF streams: %v\n", baseName, err)
+		return parseResult{inputPath: inputPath, date: date, failed: true}
+	}
+
+	prov, err := fileProvenance(inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error hashing PDF: %v\n", baseName, err)
+	}
+	if prov != nil && !docDate.IsZero() {
+		prov.Generated = docDate.Format("2006-01-02")
+	}
+
 	return parseResult{
-		inputPath: inputPath,
-		date:      date,
-		results:   results,
-		errors:    errors,
-		nPages:    len(pages),
+		inputPath:  inputPath,
+		date:       date,
+		provenance: prov,
+		results:    results,
+		errors:     errs,
+		failures:   failures,
+		nPages:     nPages,
+	}
+}
+
+// fileProvenance describes the PDF at path for the output header.
+func fileProvenance(path string) (*parser.Provenance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return &parser.Provenance{
+		SourceFile: filepath.Base(path),
+		SHA256:     hex.EncodeToString(h.Sum(nil)),
+		ParsedAt:   time.Now().UTC().Truncate(time.Second),
+		Version:    version(),
+	}, nil
+}
+
+// writeOutputJSON writes records in the current output schema, through a
+// temporary file in the same directory so that a failed write, say while
+// migrate rewrites a file in place, leaves the old contents intact.
+func writeOutputJSON(path string, prov *parser.Provenance, records []parser.MunicipalityStats) error {
+	data, err := json.MarshalIndent(parser.Output{
+		SchemaVersion: parser.SchemaVersion,
+		Provenance:    prov,
+		Records:       records,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = tmp.Chmod(0644)
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
 	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// metaPath returns the provenance sidecar path for a CSV file.
+func metaPath(csvPath string) string {
+	return strings.TrimSuffix(csvPath, filepath.Ext(csvPath)) + ".meta.json"
+}
+
+// writeMeta writes the provenance sidecar that accompanies a CSV file.
+func writeMeta(csvPath string, prov *parser.Provenance) error {
+	if prov == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(prov, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath(csvPath), data, 0644)
 }
 
-func writeResults(r parseResult, jsonOut, csvOut string) {
+func writeResults(r parseResult, jsonOut, csvOut string, opts tableOptions) error {
 	dir := filepath.Dir(r.inputPath)
 	base := strings.TrimSuffix(filepath.Base(r.inputPath), filepath.Ext(r.inputPath))
 	if jsonOut == "" {
@@ -137,82 +740,177 @@ func writeResults(r parseResult, jsonOut, csvOut string) {
 	}
 
 	// Write JSON.
-	jsonData, err := json.MarshalIndent(r.results, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: error marshaling JSON: %v\n", filepath.Base(r.inputPath), err)
-		return
-	}
-	if err := os.WriteFile(jsonOut, jsonData, 0644); err != nil {
+	if err := writeOutputJSON(jsonOut, r.provenance, r.results); err != nil {
 		fmt.Fprintf(os.Stderr, "%s: error writing JSON: %v\n", filepath.Base(r.inputPath), err)
-		return
+		return err
 	}
 
-	// Write CSV.
-	if err := writeCSV(csvOut, r.results); err != nil {
+	// Write CSV, with its provenance in a sidecar.
+	if opts.split {
+		date := r.date
+		if date == "" && len(r.results) > 0 {
+			date, _ = periodFromDateRange(r.results[0].DateRange)
+		}
+		stem := strings.TrimSuffix(csvOut, filepath.Ext(csvOut))
+		pathFor := func(section string) string { return stem + "-" + section + ".csv" }
+		records := []timeRecord{{date: date, stats: r.results}}
+		if _, err := writeSectionTables(newCSVExport, pathFor, records, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error writing CSV: %v\n", filepath.Base(r.inputPath), err)
+			return err
+		}
+	} else if err := writeCSV(csvOut, r.results, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "%s: error writing CSV: %v\n", filepath.Base(r.inputPath), err)
-		return
+		return err
+	}
+	if err := writeMeta(csvOut, r.provenance); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error writing %s: %v\n", filepath.Base(r.inputPath), filepath.Base(metaPath(csvOut)), err)
 	}
 
 	// Summary.
-	fmt.Fprintf(os.Stderr, "%s: %d pages, %d successful, %d errors → %s\n",
-		filepath.Base(r.inputPath), r.nPages, len(r.results), len(r.errors), filepath.Base(jsonOut))
+	fmt.Fprintf(os.Stderr, "%s: %d pages, %d successful, %d errors %s %s\n",
+		paint(colorStderr, styleBold, filepath.Base(r.inputPath)), r.nPages, len(r.results), len(r.errors), glyphs.arrow, filepath.Base(jsonOut))
 	for _, e := range r.errors {
-		fmt.Fprintf(os.Stderr, "  %s\n", e)
+		fmt.Fprintf(os.Stderr, "  %s\n", paint(colorStderr, styleRed, e))
+	}
+	if line := confidenceLine(parser.SummarizeConfidence(r.results)); line != "" {
+		fmt.Fprintf(os.Stderr, "  %s\n", line)
+	}
+	for _, d := range r.duplicates {
+		fmt.Fprintf(os.Stderr, "  %s\n", d)
 	}
+	for _, w := range r.shortfalls {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", paint(colorStderr, styleYellow, "warning:"), w)
+	}
+	for _, o := range r.outliers {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", paint(colorStderr, styleYellow, "possible outlier:"), o)
+	}
+	for _, v := range r.violations {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", paint(colorStderr, styleYellow, "rule"), v)
+	}
+	return nil
 }
 
-func writeCSV(path string, stats []parser.MunicipalityStats) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+// confidenceLine summarizes the values that weren't read directly, e.g.
+// "12 of 1890 values recovered: 10 kerning, 2 comma-merge (mean confidence
+// 0.9989)", or returns "" if there are none.
+func confidenceLine(sum parser.ConfidenceSummary) string {
+	recovered := sum.Values - sum.ByRecovery[parser.RecoveryDirect]
+	if recovered == 0 {
+		return ""
 	}
-	defer f.Close()
+	var parts []string
+	for _, rec := range parser.Recoveries[1:] {
+		if n := sum.ByRecovery[rec]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, rec))
+		}
+	}
+	return fmt.Sprintf("%d of %d values recovered: %s (mean confidence %.4f)", recovered, sum.Values, strings.Join(parts, ", "), sum.Mean)
+}
 
-	w := csv.NewWriter(f)
-	defer w.Flush()
+// checkCounts compares the municipalities parsed from r with the number
+// each county should list in the report's period, returning a message for
+// every county (and the statewide total) that comes up short. A shortfall
+// usually means pages failed silently or the PDF is truncated.
+func checkCounts(r parseResult, changes []parser.CountChange) []string {
+	period := r.date
+	if period == "" && len(r.results) > 0 {
+		period, _ = periodFromDateRange(r.results[0].DateRange)
+	}
+	expected := parser.ExpectedCounts(changes, period)
+	if period == "" || expected == nil {
+		return nil
+	}
+
+	got := make(map[string]int)
+	for _, s := range r.results {
+		got[s.County]++
+	}
+	var msgs []string
+	wantTotal, gotTotal := 0, 0
+	for _, county := range parser.Counties {
+		want, ok := expected[county]
+		if !ok {
+			continue
+		}
+		wantTotal += want
+		gotTotal += got[county]
+		if got[county] < want {
+			msgs = append(msgs, fmt.Sprintf("%s: %d of %d expected municipalities", county, got[county], want))
+		}
+	}
+	// A table covering only some counties says nothing about the total.
+	if len(expected) == len(parser.Counties) && gotTotal < wantTotal {
+		msgs = append(msgs, fmt.Sprintf("statewide: %d of %d expected municipalities", gotTotal, wantTotal))
+	}
+	return msgs
+}
+
+// duplicatePolicies are the --duplicates choices: keep the later page, the
+// earlier one, the more complete one (more non-empty values, later on a
+// tie), or keep every page as before.
+var duplicatePolicies = []string{"later", "first", "complete", "keep"}
 
-	// Build header.
-	header := []string{"County", "Municipality", "DateRange"}
-	sections := []string{
-		"Filings_Prior", "Filings_Current", "Filings_PctChange",
-		"Resolutions_Prior", "Resolutions_Current", "Resolutions_PctChange",
-		"Clearance_Prior", "Clearance_Current",
-		"ClearancePct_Prior", "ClearancePct_Current",
-		"Backlog_Prior", "Backlog_Current", "Backlog_PctChange",
-		"BacklogPer100_Prior", "BacklogPer100_Current", "BacklogPer100_PctChange",
-		"BacklogPct_Prior", "BacklogPct_Current",
-		"ActivePending_Prior", "ActivePending_Current", "ActivePending_PctChange",
+// dropDuplicatePages keeps one record per (county, municipality) in a
+// single report, chosen by policy, so a reissued page doesn't double-count
+// in aggregates. It returns the kept records in report order and a note for
+// each page dropped; the kept record is also given a warning.
+func dropDuplicatePages(stats []parser.MunicipalityStats, policy string) ([]parser.MunicipalityStats, []string) {
+	if policy == "keep" {
+		return stats, nil
 	}
-	cols := []string{"Label", "Indictables", "DPAndPDP", "OtherCriminal", "CriminalTotal",
-		"DWI", "TrafficMoving", "Parking", "TrafficTotal", "GrandTotal"}
+	var kept []parser.MunicipalityStats
+	var notes []string
+	index := make(map[[2]string]int)
+	for _, s := range stats {
+		key := [2]string{strings.ToUpper(s.County), strings.ToUpper(s.Municipality)}
+		i, dup := index[key]
+		if !dup {
+			index[key] = len(kept)
+			kept = append(kept, s)
+			continue
+		}
+
+		prev := kept[i]
+		replace := policy == "later" ||
+			policy == "complete" && filledValues(s) >= filledValues(prev)
+		winner, loser := prev, s
+		if replace {
+			winner, loser = s, prev
+		}
+		winner.Warnings = append(winner.Warnings[:len(winner.Warnings):len(winner.Warnings)], fmt.Sprintf("duplicate of page %d, which was dropped", loser.PageNumber))
+		kept[i] = winner
+		notes = append(notes, fmt.Sprintf("page %d: %s/%s repeats page %d; kept page %d (--duplicates %s)",
+			s.PageNumber, s.County, s.Municipality, prev.PageNumber, winner.PageNumber, policy))
+	}
+	return kept, notes
+}
 
-	for _, sec := range sections {
-		for _, col := range cols {
-			header = append(header, sec+"_"+col)
+// filledValues counts the values in s that aren't blank or "- -".
+func filledValues(s parser.MunicipalityStats) int {
+	n := 0
+	for _, v := range recordValues(s, tableOptions{}) {
+		if cleanNumber(v) != "" {
+			n++
 		}
 	}
+	return n
+}
 
-	if err := w.Write(header); err != nil {
+func writeCSV(path string, stats []parser.MunicipalityStats, opts tableOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
 		return err
 	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
 
+	if err := w.Write(recordColumns(opts)); err != nil {
+		return err
+	}
 	for _, s := range stats {
-		row := []string{s.County, s.Municipality, s.DateRange}
-		allRows := []parser.RowData{
-			s.Filings.PriorPeriod, s.Filings.CurrentPeriod, s.Filings.PctChange,
-			s.Resolutions.PriorPeriod, s.Resolutions.CurrentPeriod, s.Resolutions.PctChange,
-			s.Clearance.PriorPeriod, s.Clearance.CurrentPeriod,
-			s.ClearancePct.PriorPeriod, s.ClearancePct.CurrentPeriod,
-			s.Backlog.PriorPeriod, s.Backlog.CurrentPeriod, s.Backlog.PctChange,
-			s.BacklogPer100.PriorPeriod, s.BacklogPer100.CurrentPeriod, s.BacklogPer100.PctChange,
-			s.BacklogPct.PriorPeriod, s.BacklogPct.CurrentPeriod,
-			s.ActivePending.PriorPeriod, s.ActivePending.CurrentPeriod, s.ActivePending.PctChange,
-		}
-		for _, r := range allRows {
-			row = append(row, r.Label, r.Indictables, r.DPAndPDP, r.OtherCriminal,
-				r.CriminalTotal, r.DWI, r.TrafficMoving, r.Parking, r.TrafficTotal, r.GrandTotal)
-		}
-		if err := w.Write(row); err != nil {
+		if err := w.Write(recordValues(s, opts)); err != nil {
 			return err
 		}
 	}
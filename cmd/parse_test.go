@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"text/template"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	touch := func(rel string) string {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	a1 := touch("2023/a.pdf")
+	a2 := touch("2023/b.pdf")
+	b1 := touch("2024/c.pdf")
+	touch("2024/notes.txt")
+	extra := touch("extra.pdf")
+
+	got, err := expandInputs([]string{
+		filepath.Join(dir, "2023", "*.pdf"),
+		filepath.Join(dir, "2024"),
+		extra,
+		a1, // already matched by the glob
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{a1, a2, b1, extra}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandInputs = %v, want %v", got, want)
+	}
+
+	if _, err := expandInputs([]string{filepath.Join(dir, "missing", "*.pdf")}, false); err == nil {
+		t.Error("expected error for glob with no matches")
+	}
+
+	// Only the top level without --recursive; every year directory with it.
+	got, err = expandInputs([]string{dir}, false)
+	if err != nil || !reflect.DeepEqual(got, []string{extra}) {
+		t.Errorf("non-recursive = %v, %v", got, err)
+	}
+	got, err = expandInputs([]string{dir}, true)
+	if err != nil || !reflect.DeepEqual(got, []string{a1, a2, b1, extra}) {
+		t.Errorf("recursive = %v, %v", got, err)
+	}
+}
+
+func TestCheckOutputCollisions(t *testing.T) {
+	if err := checkOutputCollisions([]string{"a/x.pdf", "b/y.pdf"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkOutputCollisions([]string{"a/x.pdf", "b/x.pdf"}); err == nil {
+		t.Error("expected collision error")
+	}
+}
+
+func TestOutputPaths_Template(t *testing.T) {
+	tmpl := template.Must(template.New("name").Parse("{{.Year}}/{{.Year}}-{{.Month}}-municipal.json"))
+
+	r := parseResult{inputPath: filepath.Join("in", "munm2406.pdf"), date: ""}
+	r.results = []parser.MunicipalityStats{{DateRange: "JULY 2023 - JUNE 2024"}}
+
+	j, c, err := outputPaths(r, "out", tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("out", "2024", "2024-06-municipal.json"); j != want {
+		t.Errorf("json = %q, want %q", j, want)
+	}
+	if want := filepath.Join("out", "2024", "2024-06-municipal.csv"); c != want {
+		t.Errorf("csv = %q, want %q", c, want)
+	}
+
+	// Without a template, outputs mirror the input name next to it.
+	j, _, _ = outputPaths(r, "", nil)
+	if want := filepath.Join("in", "munm2406.json"); j != want {
+		t.Errorf("default json = %q, want %q", j, want)
+	}
+
+	// No period anywhere is an error rather than a bogus name.
+	if _, _, err := outputPaths(parseResult{inputPath: "x.pdf"}, "", tmpl); err == nil {
+		t.Error("expected error without a period")
+	}
+}
+
+func TestDropDuplicatePages(t *testing.T) {
+	page := func(muni string, n int, filings string) parser.MunicipalityStats {
+		s := stat("ATLANTIC", muni)
+		s.PageNumber = n
+		s.Filings.CurrentPeriod.GrandTotal = filings
+		return s
+	}
+	stats := []parser.MunicipalityStats{
+		page("ABSECON", 1, "100"),
+		page("BRIGANTINE", 2, "200"),
+		page("Absecon", 3, ""),
+	}
+
+	tests := []struct {
+		policy   string
+		wantPage int // page kept for ABSECON
+	}{
+		{"later", 3},
+		{"first", 1},
+		{"complete", 1},
+	}
+	for _, tt := range tests {
+		kept, notes := dropDuplicatePages(stats, tt.policy)
+		if len(kept) != 2 || len(notes) != 1 {
+			t.Fatalf("%s: kept %d records with %d notes", tt.policy, len(kept), len(notes))
+		}
+		if kept[0].PageNumber != tt.wantPage || kept[1].Municipality != "BRIGANTINE" {
+			t.Errorf("%s: kept pages %d, %d", tt.policy, kept[0].PageNumber, kept[1].PageNumber)
+		}
+		if len(kept[0].Warnings) != 1 {
+			t.Errorf("%s: kept record warnings = %v", tt.policy, kept[0].Warnings)
+		}
+	}
+
+	if kept, notes := dropDuplicatePages(stats, "keep"); len(kept) != 3 || notes != nil {
+		t.Errorf("keep: %d records, notes %v", len(kept), notes)
+	}
+	if len(stats[0].Warnings) != 0 {
+		t.Error("input records were modified")
+	}
+}
+
+func TestCheckCounts(t *testing.T) {
+	changes := []parser.CountChange{
+		{From: "2020-06", Counts: map[string]int{"ATLANTIC": 2, "BERGEN": 1}},
+		{From: "2024-06", Counts: map[string]int{"ATLANTIC": 3}},
+	}
+	r := parseResult{date: "2024-06", results: []parser.MunicipalityStats{
+		stat("ATLANTIC", "ABSECON"), stat("ATLANTIC", "BRIGANTINE"), stat("BERGEN", "ALLENDALE"),
+	}}
+	got := checkCounts(r, changes)
+	want := []string{"ATLANTIC: 2 of 3 expected municipalities"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkCounts = %q, want %q", got, want)
+	}
+
+	r.date = "2021-06"
+	if got := checkCounts(r, changes); got != nil {
+		t.Errorf("2021-06: %q", got)
+	}
+	r.date = "2019-06"
+	if got := checkCounts(r, changes); got != nil {
+		t.Errorf("before the table: %q", got)
+	}
+}
+
+func TestPostParse_Process(t *testing.T) {
+	page := func(muni string, n int) parser.MunicipalityStats {
+		s := stat("ATLANTIC", muni)
+		s.PageNumber = n
+		s.Filings.CurrentPeriod.GrandTotal = "-5"
+		return s
+	}
+	zero := 0.0
+	post := postParse{
+		duplicates: "first",
+		counts:     []parser.CountChange{{From: "2020-06", Counts: map[string]int{"ATLANTIC": 3}}},
+		rules:      []rule{{Name: "no negative filings", Metrics: []string{"filings"}, Types: []string{"grand-total"}, Min: &zero}},
+	}
+	r := parseResult{date: "2024-06", errors: []string{"page 4: x"}, results: []parser.MunicipalityStats{
+		page("ABSECON", 1), page("BRIGANTINE", 2), page("ABSECON", 3),
+	}}
+
+	got := r
+	if !post.process(&got, t.TempDir()) {
+		t.Fatal("process rejected a readable PDF without --strict")
+	}
+	if len(got.results) != 2 || got.results[0].PageNumber != 1 || len(got.duplicates) != 1 {
+		t.Errorf("duplicates: kept %d records, notes %q", len(got.results), got.duplicates)
+	}
+	if len(got.shortfalls) != 1 {
+		t.Errorf("shortfalls = %q", got.shortfalls)
+	}
+	if len(got.violations) != 2 {
+		t.Errorf("violations = %q", got.violations)
+	}
+
+	post.strict = true
+	got = r
+	if post.process(&got, t.TempDir()) {
+		t.Error("--strict wrote a PDF with a page error")
+	}
+	if post.process(&parseResult{failed: true}, t.TempDir()) {
+		t.Error("process accepted an unreadable PDF")
+	}
+}
+
+func TestPostParseFlags_Load(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		wantErr bool
+	}{
+		{nil, false},
+		{[]string{"--duplicates", "complete", "--expected-counts", "off", "--outlier-factor", "0"}, false},
+		{[]string{"--duplicates", "last"}, true},
+		{[]string{"--outlier-factor", "1"}, true},
+		{[]string{"--rules", filepath.Join(t.TempDir(), "missing.json")}, true},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		f := addPostParseFlags(fs)
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		p, err := f.load()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q: err = %v", tc.args, err)
+		}
+		if tc.args == nil && (p.duplicates != "later" || p.counts == nil || p.outlierFactor != defaultOutlierFactor) {
+			t.Errorf("defaults = %+v", p)
+		}
+	}
+}
+
+func TestConfidenceLine(t *testing.T) {
+	s := stat("ATLANTIC", "ABSECON")
+	if got := confidenceLine(parser.SummarizeConfidence([]parser.MunicipalityStats{s})); got != "" {
+		t.Errorf("all direct: %q", got)
+	}
+	s.Filings.PriorPeriod.Recovered[4] = parser.RecoveryKerning
+	s.Filings.PriorPeriod.Recovered[6] = parser.RecoveryPadded
+	s.Backlog.CurrentPeriod.Recovered[4] = parser.RecoveryKerning
+	want := "3 of 189 values recovered: 2 kerning, 1 padded (mean confidence 0.9947)"
+	if got := confidenceLine(parser.SummarizeConfidence([]parser.MunicipalityStats{s})); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStrictFailure(t *testing.T) {
+	for _, tc := range []struct {
+		r    parseResult
+		want string
+	}{
+		{parseResult{results: []parser.MunicipalityStats{stat("ATLANTIC", "ABSECON")}}, ""},
+		{parseResult{failed: true}, "couldn't be read"},
+		{parseResult{errors: []string{"page 3: unexpected end of items"}}, "1 page error"},
+		{parseResult{errors: []string{"page 3: x", "page 9: y"}}, "2 page errors"},
+	} {
+		if got := strictFailure(tc.r); got != tc.want {
+			t.Errorf("strictFailure(%+v) = %q, want %q", tc.r, got, tc.want)
+		}
+	}
+}
+
+func TestWriteFailedPages(t *testing.T) {
+	dir := failedDir("reports/2024-06.pdf", t.TempDir())
+	r := parseResult{failures: []failedPage{{
+		page:  13,
+		err:   "unexpected end of items",
+		lines: [][]string{{"ATLANTIC", "ABSECON"}, {"Filings"}, {"Jul 2023 - Jun 2024", "1", "2"}},
+	}}}
+	if n, err := writeFailedPages(r, dir); err != nil || n != 1 {
+		t.Fatalf("writeFailedPages = %d, %v", n, err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "page-013.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# page 13: unexpected end of items\nATLANTIC\tABSECON\nFilings\nJul 2023 - Jun 2024\t1\t2\n"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A later run with no failures clears the directory away.
+	if n, err := writeFailedPages(parseResult{}, dir); err != nil || n != 0 {
+		t.Fatalf("writeFailedPages = %d, %v", n, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s left behind: %v", filepath.Base(dir), err)
+	}
+}
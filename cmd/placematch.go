@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// namePrefixes are designations written before the name, as in
+// "CITY OF ESTELL MANOR".
+var namePrefixes = []string{"CITY", "TOWN", "TOWNSHIP", "BOROUGH", "VILLAGE"}
+
+// joinName canonicalizes a court name or an outside dataset's municipality
+// name so the two can be compared: uppercase, punctuation and court words
+// dropped, designations spelled out and moved to the end ("CITY OF ESTELL
+// MANOR MUN" and "Estell Manor City" both become "ESTELL MANOR CITY").
+func joinName(name string) string {
+	name = strings.ToUpper(name)
+	name = strings.NewReplacer("-", " ", "/", " ", ".", "", "'", "", ",", "").Replace(name)
+	words := strings.Fields(name)
+	for i, w := range words {
+		switch w {
+		case "TWP":
+			words[i] = "TOWNSHIP"
+		case "BORO":
+			words[i] = "BOROUGH"
+		case "MT":
+			words[i] = "MOUNT"
+		}
+	}
+	for len(words) > 0 {
+		switch words[len(words)-1] {
+		case "MUN", "MUNI", "MUNICIPAL", "COURT", "CT", "CRT", "MC":
+			words = words[:len(words)-1]
+			continue
+		}
+		break
+	}
+	if len(words) > 2 && words[1] == "OF" && contains(namePrefixes, words[0]) {
+		words = append(words[2:], words[0])
+	}
+	// Some boundary layers repeat the type, as in "MARGATE CITY CITY".
+	if n := len(words); n > 2 && words[n-1] == words[n-2] {
+		words = words[:n-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// place is a canonical county and joinName (or base) name.
+type place struct{ county, name string }
+
+// placeIndex finds items from an outside dataset, such as boundary features
+// or census places, by a court's county and name. Names are compared with
+// joinName, falling back to the name without its designation ("MARGATE"
+// for "MARGATE CITY") when that is unambiguous on both sides.
+type placeIndex struct {
+	full map[place][]int
+	base map[place][]int
+}
+
+func newPlaceIndex() *placeIndex {
+	return &placeIndex{full: make(map[place][]int), base: make(map[place][]int)}
+}
+
+// add indexes item i under a canonical county name and a name as written.
+func (ix *placeIndex) add(county, name string, i int) {
+	full := place{county, joinName(name)}
+	base := place{county, stripMunicipalSuffix(full.name)}
+	ix.full[full] = append(ix.full[full], i)
+	ix.base[base] = append(ix.base[base], i)
+}
+
+// match returns the item matching a court, or -1. courtBases, from
+// courtBaseCounts, says how many courts in the same period share each base
+// name.
+func (ix *placeIndex) match(county, name string, courtBases map[place]int) int {
+	full := place{county, joinName(name)}
+	if idx := ix.full[full]; len(idx) == 1 {
+		return idx[0]
+	}
+	base := place{county, stripMunicipalSuffix(full.name)}
+	if idx := ix.base[base]; len(idx) == 1 && courtBases[base] == 1 {
+		return idx[0]
+	}
+	return -1
+}
+
+// courtBaseCounts counts the courts in stats by county and base name.
+func courtBaseCounts(stats []parser.MunicipalityStats) map[place]int {
+	counts := make(map[place]int)
+	for _, s := range stats {
+		counts[place{s.County, stripMunicipalSuffix(joinName(s.Municipality))}]++
+	}
+	return counts
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import "testing"
+
+func TestJoinName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"CITY OF ESTELL MANOR MUN", "ESTELL MANOR CITY"},
+		{"Estell Manor City", "ESTELL MANOR CITY"},
+		{"EGG HARBOR TWP", "EGG HARBOR TOWNSHIP"},
+		{"HO-HO-KUS BORO", "HO HO KUS BOROUGH"},
+		{"MARGATE CITY CITY", "MARGATE CITY"},
+		{"MT. LAUREL TWP", "MOUNT LAUREL TOWNSHIP"},
+		{"ELK JOINT MUNICIPAL CRT", "ELK JOINT"},
+	}
+	for _, tt := range tests {
+		if got := joinName(tt.in); got != tt.want {
+			t.Errorf("joinName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
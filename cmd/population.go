@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// censusAPIBase is the Census Data API root. Tests point it at a local
+// server.
+var censusAPIBase = "https://api.census.gov/data"
+
+// censusSource is one Census dataset with a total-population variable for
+// New Jersey's county subdivisions, which are its municipalities.
+type censusSource struct {
+	Year     int
+	Dataset  string // path under censusAPIBase, e.g. "2020/dec/pl"
+	Variable string
+}
+
+// censusSources returns the vintages population is built from: the 2000,
+// 2010 and 2020 decennial counts, and the ACS 5-year estimates for every
+// other year from 2009 that may be published by now (each appears in the
+// December after its year ends).
+func censusSources(now time.Time) []censusSource {
+	sources := []censusSource{
+		{2000, "2000/dec/sf1", "P001001"},
+		{2010, "2010/dec/sf1", "P001001"},
+		{2020, "2020/dec/pl", "P1_001N"},
+	}
+	for y := 2009; y < now.Year(); y++ {
+		if y%10 == 0 {
+			continue
+		}
+		sources = append(sources, censusSource{y, fmt.Sprintf("%d/acs/acs5", y), "B01003_001E"})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Year < sources[j].Year })
+	return sources
+}
+
+// populationCache is the local copy of every fetched vintage.
+type populationCache struct {
+	Fetched  time.Time           `json:"fetched"`
+	Vintages []populationVintage `json:"vintages"`
+}
+
+type populationVintage struct {
+	Year   int               `json:"year"`
+	Source string            `json:"source"`
+	Places []populationPlace `json:"places"`
+}
+
+type populationPlace struct {
+	County     string `json:"county"`
+	Name       string `json:"name"` // as the Census writes it, e.g. "Absecon city"
+	Population int    `json:"population"`
+}
+
+// populationCachePath returns where fetched population is kept:
+// $MUNICOURT_POPULATION if set, otherwise population.json in the user cache
+// directory.
+func populationCachePath() string {
+	if p := os.Getenv("MUNICOURT_POPULATION"); p != "" {
+		return p
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "population.json"
+	}
+	return filepath.Join(dir, "municourt", "population.json")
+}
+
+// fetchCensusVintage downloads one source's municipal populations.
+func fetchCensusVintage(src censusSource, key string) (populationVintage, error) {
+	v := populationVintage{Year: src.Year, Source: src.Dataset}
+	q := url.Values{"get": {"NAME," + src.Variable}, "for": {"county subdivision:*"}, "in": {"state:34"}}
+	if key != "" {
+		q.Set("key", key)
+	}
+	resp, err := httpClient.Get(censusAPIBase + "/" + src.Dataset + "?" + q.Encode())
+	if err != nil {
+		return v, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return v, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	// The API answers with a table: a header row, then one row per place,
+	// e.g. ["Absecon city, Atlantic County, New Jersey", "9137", ...].
+	var table [][]string
+	if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+		return v, fmt.Errorf("decoding response: %w", err)
+	}
+	for _, row := range table[min(1, len(table)):] {
+		if len(row) < 2 {
+			continue
+		}
+		parts := strings.Split(row[0], ", ")
+		n, err := strconv.Atoi(row[1])
+		if len(parts) < 2 || err != nil || n <= 0 {
+			continue // "County subdivisions not defined" and suppressed values
+		}
+		county, ok := parser.NormalizeCounty(parts[1])
+		if !ok {
+			continue
+		}
+		v.Places = append(v.Places, populationPlace{County: county, Name: parts[0], Population: n})
+	}
+	if len(v.Places) == 0 {
+		return v, fmt.Errorf("no municipalities in response")
+	}
+	return v, nil
+}
+
+// fetchPopulation downloads every vintage in sources. Vintages that fail
+// are skipped with a warning, since the newest ACS release may not be out
+// yet; it is an error only if none succeed.
+func fetchPopulation(sources []censusSource, key string) (*populationCache, error) {
+	c := &populationCache{Fetched: time.Now().UTC().Truncate(time.Second)}
+	for _, src := range sources {
+		fmt.Fprintf(os.Stderr, "Fetching %d population (%s)\n", src.Year, src.Dataset)
+		v, err := fetchCensusVintage(src, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  skipped: %v\n", err)
+			continue
+		}
+		c.Vintages = append(c.Vintages, v)
+	}
+	if len(c.Vintages) == 0 {
+		return nil, fmt.Errorf("no population data could be fetched from %s", censusAPIBase)
+	}
+	return c, nil
+}
+
+// loadPopulation reads the population cache at path, fetching it first if
+// it doesn't exist or refresh is set.
+func loadPopulation(path string, refresh bool, key string) (*populationTable, error) {
+	var c populationCache
+	data, err := os.ReadFile(path)
+	if err == nil && !refresh {
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w (delete it or use --refresh)", path, err)
+		}
+		return newPopulationTable(&c), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	fetched, err := fetchPopulation(censusSources(time.Now()), key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(fetched, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, err
+	}
+	return newPopulationTable(fetched), nil
+}
+
+// populationTable answers population lookups for courts and periods.
+type populationTable struct {
+	places []populationSeries
+	index  *placeIndex
+}
+
+// populationSeries is one Census place's counts, oldest first.
+type populationSeries struct {
+	county, name string
+	years        []int
+	counts       []float64
+}
+
+func newPopulationTable(c *populationCache) *populationTable {
+	t := &populationTable{index: newPlaceIndex()}
+	byPlace := make(map[[2]string]int)
+	vintages := append([]populationVintage(nil), c.Vintages...)
+	sort.Slice(vintages, func(i, j int) bool { return vintages[i].Year < vintages[j].Year })
+	for _, v := range vintages {
+		for _, p := range v.Places {
+			k := [2]string{p.County, strings.ToUpper(p.Name)}
+			i, ok := byPlace[k]
+			if !ok {
+				i = len(t.places)
+				byPlace[k] = i
+				t.places = append(t.places, populationSeries{county: p.County, name: p.Name})
+				t.index.add(p.County, p.Name, i)
+			}
+			s := &t.places[i]
+			if n := len(s.years); n > 0 && s.years[n-1] == v.Year {
+				continue
+			}
+			s.years = append(s.years, v.Year)
+			s.counts = append(s.counts, float64(p.Population))
+		}
+	}
+	return t
+}
+
+// at returns the population in period (YYYY-MM), counting each vintage as
+// of July 1 of its year and interpolating linearly between them. Periods
+// outside the vintages get the nearest one.
+func (s populationSeries) at(period string) float64 {
+	t, err := time.Parse("2006-01", period)
+	if err != nil || len(s.years) == 0 {
+		return math.NaN()
+	}
+	x := float64(t.Year()) + float64(t.Month()-1)/12
+	pos := func(i int) float64 { return float64(s.years[i]) + 0.5 }
+	if x <= pos(0) {
+		return s.counts[0]
+	}
+	for i := 1; i < len(s.years); i++ {
+		if x <= pos(i) {
+			f := (x - pos(i-1)) / (pos(i) - pos(i-1))
+			return s.counts[i-1] + f*(s.counts[i]-s.counts[i-1])
+		}
+	}
+	return s.counts[len(s.counts)-1]
+}
+
+// lookup returns the population of a court's municipality in period, or
+// NaN if no single Census place matches (joint and central courts never
+// do). courtBases comes from courtBaseCounts over the same period.
+func (t *populationTable) lookup(county, municipality, period string, courtBases map[place]int) float64 {
+	i := t.index.match(county, municipality, courtBases)
+	if i < 0 {
+		return math.NaN()
+	}
+	return t.places[i].at(period)
+}
+
+// formatPopulation rounds a population for table output; NaN is empty.
+func formatPopulation(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(math.Round(v), 'f', 0, 64)
+}
+
+// Population implements the "population" subcommand: fetch and cache
+// Census population for NJ municipalities and show it for each court in a
+// period.
+func Population(args []string) {
+	fs := flag.NewFlagSet("population", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	date := fs.String("date", "", "period to show, YYYY-MM (default newest)")
+	refresh := fs.Bool("refresh", false, "re-download population even if it is cached")
+	cachePath := fs.String("cache", populationCachePath(), "population cache file (default $MUNICOURT_POPULATION or the user cache directory)")
+	key := fs.String("census-key", os.Getenv("CENSUS_API_KEY"), "Census API key (default $CENSUS_API_KEY; optional)")
+	csvOut := fs.Bool("csv", false, "write CSV to stdout instead of a table")
+	hf := addHTTPFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt population <parsed-dir> [--date YYYY-MM] [--refresh] [--csv] [--cache file] [--census-key key]\n\nFetch and cache Census population and show it for each court.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if err := hf.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	pop, err := loadPopulation(*cachePath, *refresh, *key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading population: %v\n", err)
+		os.Exit(1)
+	}
+	records, err := loadMetricRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	var rec *timeRecord
+	for i := range records {
+		if records[i].date == *date || (*date == "" && i == len(records)-1) {
+			rec = &records[i]
+		}
+	}
+	if rec == nil {
+		fmt.Fprintf(os.Stderr, "no data for period %q in %s\n", *date, *dir)
+		os.Exit(1)
+	}
+
+	if err := writePopulation(os.Stdout, pop, *rec, *csvOut); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writePopulation lists each court in rec with its population, unmatched
+// courts last.
+func writePopulation(w io.Writer, pop *populationTable, rec timeRecord, asCSV bool) error {
+	bases := courtBaseCounts(rec.stats)
+	var matched, unmatched [][]string
+	for _, s := range rec.stats {
+		row := []string{s.County, s.Municipality, formatPopulation(pop.lookup(s.County, s.Municipality, rec.date, bases))}
+		if row[2] == "" {
+			unmatched = append(unmatched, row)
+		} else {
+			matched = append(matched, row)
+		}
+	}
+	if asCSV {
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"County", "Municipality", "Population"})
+		cw.WriteAll(append(matched, unmatched...))
+		return cw.Error()
+	}
+	fmt.Fprintf(w, "Population in %s (%d of %d courts matched)\n\n", rec.date, len(matched), len(rec.stats))
+	for _, row := range matched {
+		n, _ := strconv.ParseFloat(row[2], 64)
+		fmt.Fprintf(w, "  %-12s %-28s %10s\n", row[0], row[1], formatNum(n))
+	}
+	if len(unmatched) > 0 {
+		fmt.Fprintf(w, "\nNo Census place (joint, central and renamed courts):\n")
+		for _, row := range unmatched {
+			fmt.Fprintf(w, "  %-12s %s\n", row[0], row[1])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestCensusSources(t *testing.T) {
+	sources := censusSources(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	var years []int
+	for _, s := range sources {
+		years = append(years, s.Year)
+	}
+	if len(years) != 17 || years[0] != 2000 || years[len(years)-1] != 2024 {
+		t.Errorf("years = %v", years)
+	}
+	for _, s := range sources {
+		if s.Year == 2020 && s.Dataset != "2020/dec/pl" {
+			t.Errorf("2020 source = %+v, want the decennial count", s)
+		}
+	}
+}
+
+func TestFetchCensusVintage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2020/dec/pl" || r.URL.Query().Get("in") != "state:34" || r.URL.Query().Get("key") != "k" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[["NAME","P1_001N","state","county","county subdivision"],
+["Absecon city, Atlantic County, New Jersey","9137","34","001","00100"],
+["County subdivisions not defined, Atlantic County, New Jersey","0","34","001","00000"],
+["Egg Harbor township, Atlantic County, New Jersey","47842","34","001","20290"]]`))
+	}))
+	defer srv.Close()
+	defer func(old string) { censusAPIBase = old }(censusAPIBase)
+	censusAPIBase = srv.URL
+
+	v, err := fetchCensusVintage(censusSource{2020, "2020/dec/pl", "P1_001N"}, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []populationPlace{{"ATLANTIC", "Absecon city", 9137}, {"ATLANTIC", "Egg Harbor township", 47842}}
+	if len(v.Places) != 2 || v.Places[0] != want[0] || v.Places[1] != want[1] {
+		t.Errorf("places = %+v", v.Places)
+	}
+	if _, err := fetchCensusVintage(censusSource{2099, "2099/acs/acs5", "B01003_001E"}, "k"); err == nil {
+		t.Error("want error for a missing dataset")
+	}
+}
+
+func testPopulation() *populationTable {
+	return newPopulationTable(&populationCache{Vintages: []populationVintage{
+		{Year: 2020, Places: []populationPlace{{"ATLANTIC", "Absecon city", 9200}, {"ATLANTIC", "Egg Harbor township", 48000}}},
+		{Year: 2010, Places: []populationPlace{{"ATLANTIC", "Absecon city", 8200}, {"ATLANTIC", "Egg Harbor township", 43000}}},
+	}})
+}
+
+func TestPopulationLookup(t *testing.T) {
+	pop := testPopulation()
+	stats := []parser.MunicipalityStats{stat("ATLANTIC", "ABSECON"), stat("ATLANTIC", "EGG HARBOR TWP"), stat("ATLANTIC", "ATLANTIC CNTY CENTRAL MC")}
+	bases := courtBaseCounts(stats)
+	tests := []struct {
+		muni, period string
+		want         float64
+	}{
+		{"ABSECON", "2015-07", 8700}, // halfway between the vintages
+		{"ABSECON", "2005-06", 8200}, // before the first vintage
+		{"ABSECON", "2024-06", 9200}, // after the last
+		{"EGG HARBOR TWP", "2020-07", 48000},
+	}
+	for _, tt := range tests {
+		if got := pop.lookup("ATLANTIC", tt.muni, tt.period, bases); got != tt.want {
+			t.Errorf("lookup(%s, %s) = %v, want %v", tt.muni, tt.period, got, tt.want)
+		}
+	}
+	if got := pop.lookup("ATLANTIC", "ATLANTIC CNTY CENTRAL MC", "2020-07", bases); !math.IsNaN(got) {
+		t.Errorf("central court population = %v, want NaN", got)
+	}
+}
+
+func TestLoadPopulation_Cached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "population.json")
+	os.WriteFile(path, []byte(`{"vintages":[{"year":2020,"places":[{"county":"ATLANTIC","name":"Absecon city","population":9137}]}]}`), 0644)
+	defer func(old string) { censusAPIBase = old }(censusAPIBase)
+	censusAPIBase = "http://127.0.0.1:0" // a fetch would fail
+
+	pop, err := loadPopulation(path, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pop.lookup("ATLANTIC", "ABSECON", "2024-06", courtBaseCounts([]parser.MunicipalityStats{stat("ATLANTIC", "ABSECON")})); got != 9137 {
+		t.Errorf("population = %v", got)
+	}
+}
+
+func TestExportRecords_Population(t *testing.T) {
+	opts := tableOptions{sections: []string{"filings"}, rows: []string{"current"}, population: testPopulation()}
+	if got := exportColumns(opts); got[len(got)-1] != "Population" {
+		t.Fatalf("columns = %v", got)
+	}
+	records := []timeRecord{{date: "2015-07", stats: []parser.MunicipalityStats{stat("ATLANTIC", "ABSECON"), stat("ATLANTIC", "BRIGANTINE")}}}
+	rows := &memExport{}
+	if _, err := exportRecords(rows, records, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := rows.rows[0][len(rows.rows[0])-1]; got != "8700" {
+		t.Errorf("ABSECON population = %q", got)
+	}
+	if got := rows.rows[1][len(rows.rows[1])-1]; got != "" {
+		t.Errorf("BRIGANTINE population = %q, want empty", got)
+	}
+}
+
+// memExport collects rows in memory.
+type memExport struct {
+	header []string
+	rows   [][]string
+}
+
+func (m *memExport) WriteHeader(columns []string) error { m.header = columns; return nil }
+func (m *memExport) WriteRow(values []string) error     { m.rows = append(m.rows, values); return nil }
+func (m *memExport) Close() error                       { return nil }
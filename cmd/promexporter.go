@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PromExporter implements the "prom-exporter" subcommand: serve the newest
+// period's values as Prometheus gauges at /metrics, so alerting rules can
+// watch for threshold crossings such as backlog spikes.
+func PromExporter(args []string) {
+	fs := flag.NewFlagSet("prom-exporter", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	addr := fs.String("addr", ":9187", "address to listen on")
+	metrics := fs.String("metrics", "", "comma-separated metrics to export: "+strings.Join(validMetrics, ", ")+" (default all)")
+	types := fs.String("types", "", "comma-separated case types to export: "+strings.Join(validTypes, ", ")+" (default all)")
+	refresh := fs.Duration("refresh", 5*time.Minute, "how often to reload the parsed directory (at most once per scrape)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt prom-exporter <parsed-dir> [--addr :9187] [--metrics list] [--types list] [--refresh 5m]\n\nServe the newest period's values as Prometheus metrics at /metrics.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	e := &promExporter{dir: *dir, refresh: *refresh}
+	var err error
+	if e.metrics, err = splitChoices("--metrics", *metrics, validMetrics); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if e.types, err = splitChoices("--types", *types, validTypes); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if e.metrics == nil {
+		e.metrics = validMetrics
+	}
+	if e.types == nil {
+		e.types = validTypes
+	}
+	// Load once up front so a bad directory fails at startup rather than on
+	// the first scrape.
+	if err := e.reload(); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(e.page) == 0 {
+		fmt.Fprintf(os.Stderr, "warning: no JSON files found in %s, starting with empty data\n", *dir)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", e)
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>municourt exporter</title></head><body><h1>municourt exporter</h1><p><a href=\"metrics\">Metrics</a></p></body></html>\n")
+	})
+	fmt.Printf("serving Prometheus metrics on %s/metrics\n", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// promExporter renders the metrics page from the newest period in dir,
+// reloading the directory when the cached page is older than refresh.
+type promExporter struct {
+	dir     string
+	metrics []string
+	types   []string
+	refresh time.Duration
+
+	mu         sync.Mutex
+	page       []byte
+	loadedAt   time.Time
+	loadErrors int
+}
+
+func (e *promExporter) reload() error {
+	records, err := loadMetricRecords(e.dir, e.metrics...)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writePromMetrics(&buf, records, e.metrics, e.types)
+	e.page = buf.Bytes()
+	e.loadedAt = time.Now()
+	return nil
+}
+
+func (e *promExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	if time.Since(e.loadedAt) >= e.refresh {
+		// Keep serving the last good page if the reload fails; the error
+		// counter below lets an alert catch it.
+		if err := e.reload(); err != nil {
+			e.loadErrors++
+			fmt.Fprintf(os.Stderr, "error reloading %s: %v\n", e.dir, err)
+		}
+	}
+	page, loadedAt, loadErrors := e.page, e.loadedAt, e.loadErrors
+	e.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(page)
+	fmt.Fprintf(w, "# HELP municourt_last_reload_timestamp_seconds When the parsed directory was last loaded.\n")
+	fmt.Fprintf(w, "# TYPE municourt_last_reload_timestamp_seconds gauge\n")
+	fmt.Fprintf(w, "municourt_last_reload_timestamp_seconds %d\n", loadedAt.Unix())
+	fmt.Fprintf(w, "# HELP municourt_reload_errors_total Failed reloads of the parsed directory.\n")
+	fmt.Fprintf(w, "# TYPE municourt_reload_errors_total counter\n")
+	fmt.Fprintf(w, "municourt_reload_errors_total %d\n", loadErrors)
+}
+
+// writePromMetrics writes the newest period's values in the Prometheus text
+// format: one gauge family per metric, named municourt_<metric> with dashes
+// as underscores, labeled with county, municipality, entity_id and
+// case_type. Blank and "- -" values are left out.
+func writePromMetrics(w io.Writer, records []timeRecord, metrics, types []string) {
+	if len(records) == 0 {
+		return
+	}
+	latest := records[len(records)-1]
+	if t, err := time.Parse("2006-01", latest.date); err == nil {
+		fmt.Fprintf(w, "# HELP municourt_period_timestamp_seconds Start of the month of the newest report period.\n")
+		fmt.Fprintf(w, "# TYPE municourt_period_timestamp_seconds gauge\n")
+		fmt.Fprintf(w, "municourt_period_timestamp_seconds{period=\"%s\"} %d\n", latest.date, t.Unix())
+	}
+	fmt.Fprintf(w, "# HELP municourt_municipalities Municipalities in the newest report period.\n")
+	fmt.Fprintf(w, "# TYPE municourt_municipalities gauge\n")
+	fmt.Fprintf(w, "municourt_municipalities %d\n", len(latest.stats))
+
+	for _, metric := range metrics {
+		name := "municourt_" + strings.ReplaceAll(metric, "-", "_")
+		fmt.Fprintf(w, "# HELP %s %s in the newest report period.\n", name, metricLabel(metric))
+		fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		for _, s := range latest.stats {
+			row := getRow(s, metric)
+			labels := "county=\"" + escapePromLabel(s.County) + "\",municipality=\"" + escapePromLabel(s.Municipality) + "\",entity_id=\"" + escapePromLabel(entityID(s)) + "\",case_type=\""
+			for _, caseType := range types {
+				v := getField(row, caseType)
+				if math.IsNaN(v) {
+					continue
+				}
+				fmt.Fprintf(w, "%s{%s%s\"} %s\n", name, labels, strings.ReplaceAll(caseType, "-", "_"), strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		}
+	}
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapePromLabel(s string) string { return promLabelEscaper.Replace(s) }
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestWritePromMetrics(t *testing.T) {
+	newark := rateStat("ESSEX", `NEWARK "CENTRAL"`, "1,749", "1,700", "- -")
+	newark.Filings.CurrentPeriod.DWI = "12"
+	records := []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{rateStat("ESSEX", "NEWARK", "5", "5", "")}},
+		{date: "2025-06", stats: []parser.MunicipalityStats{newark}},
+	}
+	var sb strings.Builder
+	writePromMetrics(&sb, records, []string{"filings", "clearance-pct"}, []string{"grand-total", "dwi"})
+	got := sb.String()
+	for _, want := range []string{
+		"municourt_period_timestamp_seconds{period=\"2025-06\"} 1748736000\n",
+		"municourt_municipalities 1\n",
+		"# TYPE municourt_filings gauge\n",
+		`municourt_filings{county="ESSEX",municipality="NEWARK \"CENTRAL\"",entity_id="essex.newark-central",case_type="grand_total"} 1749` + "\n",
+		`municourt_filings{county="ESSEX",municipality="NEWARK \"CENTRAL\"",entity_id="essex.newark-central",case_type="dwi"} 12` + "\n",
+		"# TYPE municourt_clearance_pct gauge\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "municourt_clearance_pct{") || strings.Contains(got, "} 5\n") {
+		t.Errorf("unexpected sample in:\n%s", got)
+	}
+}
+
+func TestPromExporter_ServeHTTP(t *testing.T) {
+	e := &promExporter{dir: t.TempDir(), metrics: []string{"filings"}, types: []string{"grand-total"}}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "municourt_reload_errors_total 0\n") {
+		t.Errorf("body = %q", body)
+	}
+}
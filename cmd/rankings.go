@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rankHistory is one municipality's rank on a metric across a window of
+// periods, summarized.
+type rankHistory struct {
+	County       string  `json:"county"`
+	Municipality string  `json:"municipality"`
+	Periods      int     `json:"periods"`    // periods it was ranked in
+	MeanRank     float64 `json:"meanRank"`   // mean rank, 1 being the highest value (lowest with --order low)
+	RankStdDev   float64 `json:"rankStdDev"` // standard deviation of its percentile rank, in points
+	BestRank     int     `json:"bestRank"`
+	WorstRank    int     `json:"worstRank"`
+	LatestRank   int     `json:"latestRank"`  // 0 when it isn't ranked in the last period
+	TopShare     float64 `json:"topShare"`    // share of its periods spent in the top band
+	BottomShare  float64 `json:"bottomShare"` // share of its periods spent in the bottom band
+}
+
+// rankStability summarizes how municipalities' ranks on a metric moved over
+// the periods From through To: who stayed at the top or bottom, and whose
+// rank swung the most.
+type rankStability struct {
+	Metric    string        `json:"metric"`
+	Type      string        `json:"type"`
+	County    string        `json:"county,omitempty"`
+	Order     string        `json:"order"` // high: rank 1 is the highest value; low: the lowest
+	FromDate  string        `json:"fromDate"`
+	ToDate    string        `json:"toDate"`
+	Periods   int           `json:"periods"`
+	Band      float64       `json:"band"` // top and bottom bands, as a percentage of those ranked
+	Ranked    int           `json:"ranked"`
+	Top       []rankHistory `json:"top"`
+	Bottom    []rankHistory `json:"bottom"`
+	Volatile  []rankHistory `json:"volatile"`
+	Steadiest []rankHistory `json:"steadiest"`
+}
+
+// buildRankStability ranks the municipalities (in county, if set) by metric
+// in each of dates, and summarizes those with a rank in at least minPeriods
+// of them, keeping n of each list. Ties share the better rank. Volatility
+// compares percentile ranks, so a period with fewer courts reporting
+// doesn't read as movement.
+func buildRankStability(records []timeRecord, metric, caseType, county string, dates []string, low bool, band float64, minPeriods, n int) rankStability {
+	rs := rankStability{Metric: metric, Type: caseType, County: county, Order: "high", Periods: len(dates), Band: band}
+	if low {
+		rs.Order = "low"
+	}
+	if len(dates) > 0 {
+		rs.FromDate, rs.ToDate = dates[0], dates[len(dates)-1]
+	}
+
+	type history struct {
+		county, name string
+		ranks, pcts  []float64
+		top, bottom  int
+		latest       int
+	}
+	byEntity := make(map[string]*history)
+	for i, date := range dates {
+		var vals []entityValue
+		for _, v := range periodValues(records, metric, caseType, date) {
+			if county == "" || v.County == county {
+				vals = append(vals, v)
+			}
+		}
+		sort.SliceStable(vals, func(a, b int) bool {
+			if low {
+				return vals[a].Value < vals[b].Value
+			}
+			return vals[a].Value > vals[b].Value
+		})
+		cut := int(math.Ceil(float64(len(vals)) * band / 100))
+		rank := 0
+		for j, v := range vals {
+			if j == 0 || v.Value != vals[j-1].Value {
+				rank = j + 1
+			}
+			h, ok := byEntity[v.ID]
+			if !ok {
+				h = &history{county: v.County, name: v.Municipality}
+				byEntity[v.ID] = h
+			}
+			h.ranks = append(h.ranks, float64(rank))
+			if len(vals) > 1 {
+				h.pcts = append(h.pcts, float64(rank-1)/float64(len(vals)-1)*100)
+			} else {
+				h.pcts = append(h.pcts, 0)
+			}
+			if rank <= cut {
+				h.top++
+			}
+			if rank > len(vals)-cut {
+				h.bottom++
+			}
+			if i == len(dates)-1 {
+				h.latest = rank
+			}
+		}
+	}
+
+	var all []rankHistory
+	for _, h := range byEntity {
+		if len(h.ranks) < minPeriods {
+			continue
+		}
+		r := rankHistory{
+			County:       h.county,
+			Municipality: h.name,
+			Periods:      len(h.ranks),
+			MeanRank:     meanValues(h.ranks),
+			RankStdDev:   stdDevValues(h.pcts),
+			BestRank:     int(h.ranks[0]),
+			WorstRank:    int(h.ranks[0]),
+			LatestRank:   h.latest,
+			TopShare:     float64(h.top) / float64(len(h.ranks)),
+			BottomShare:  float64(h.bottom) / float64(len(h.ranks)),
+		}
+		for _, rank := range h.ranks {
+			r.BestRank = min(r.BestRank, int(rank))
+			r.WorstRank = max(r.WorstRank, int(rank))
+		}
+		all = append(all, r)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].County != all[j].County {
+			return all[i].County < all[j].County
+		}
+		return all[i].Municipality < all[j].Municipality
+	})
+	rs.Ranked = len(all)
+
+	top := func(keep func(rankHistory) bool, less func(a, b rankHistory) bool) []rankHistory {
+		var out []rankHistory
+		for _, r := range all {
+			if keep(r) {
+				out = append(out, r)
+			}
+		}
+		sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+		if len(out) > n {
+			out = out[:n]
+		}
+		return out
+	}
+	rs.Top = top(func(r rankHistory) bool { return r.TopShare > 0 }, func(a, b rankHistory) bool {
+		if a.TopShare != b.TopShare {
+			return a.TopShare > b.TopShare
+		}
+		return a.MeanRank < b.MeanRank
+	})
+	rs.Bottom = top(func(r rankHistory) bool { return r.BottomShare > 0 }, func(a, b rankHistory) bool {
+		if a.BottomShare != b.BottomShare {
+			return a.BottomShare > b.BottomShare
+		}
+		return a.MeanRank > b.MeanRank
+	})
+	many := func(r rankHistory) bool { return r.Periods > 1 }
+	rs.Volatile = top(many, func(a, b rankHistory) bool { return a.RankStdDev > b.RankStdDev })
+	rs.Steadiest = top(many, func(a, b rankHistory) bool { return a.RankStdDev < b.RankStdDev })
+	return rs
+}
+
+// stdDevValues returns the population standard deviation of vals, which
+// mustn't be empty.
+func stdDevValues(vals []float64) float64 {
+	m := meanValues(vals)
+	var ss float64
+	for _, v := range vals {
+		ss += (v - m) * (v - m)
+	}
+	return math.Sqrt(ss / float64(len(vals)))
+}
+
+// Rankings implements the "rankings" subcommand: how stable municipalities'
+// ranks on a metric were over a window.
+func Rankings(args []string) {
+	fs := flag.NewFlagSet("rankings", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	metric := fs.String("metric", "backlog", "metric to rank by")
+	caseType := fs.String("type", "grand-total", "case type column")
+	county := fs.String("county", "", "rank only the municipalities of this county")
+	window := fs.Int("window", 36, "months of periods to rank, ending at the newest (0 for every period)")
+	order := fs.String("order", "high", "rank 1 is the highest value (high) or the lowest (low)")
+	band := fs.Float64("band", 10, "size of the top and bottom bands, as a percentage of the municipalities ranked")
+	minPeriods := fs.Int("min-periods", 0, "leave out municipalities ranked in fewer periods than this (default every period in the window)")
+	n := fs.Int("top", 10, "entries per list")
+	format := fs.String("format", "table", "output format: table, csv, json")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt rankings [dir] [--metric backlog] [--window 36] [--order high|low] [--band 10] [--county name] [--top 10] [--format table|csv|json]\n\nRank municipalities on a metric in every period of a window and list the ones that stay at the top or bottom and the ones whose rank swings the most.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if !contains(validMetrics, *metric) {
+		fmt.Fprintf(os.Stderr, "invalid --metric %q; valid options: %s\n", *metric, strings.Join(validMetrics, ", "))
+		os.Exit(1)
+	}
+	if !contains(validTypes, *caseType) {
+		fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+		os.Exit(1)
+	}
+	if *order != "high" && *order != "low" {
+		fmt.Fprintf(os.Stderr, "invalid --order %q; valid options: high, low\n", *order)
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, csv, json\n", *format)
+		os.Exit(1)
+	}
+	if *window < 0 || *band <= 0 || *band > 50 {
+		fmt.Fprintf(os.Stderr, "--window can't be negative and --band must be above 0 and at most 50\n")
+		os.Exit(1)
+	}
+	*county = strings.ToUpper(*county)
+
+	records, err := loadMetricRecords(*dir, *metric)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	dates := make([]string, len(records))
+	for i, rec := range records {
+		dates[i] = rec.date
+	}
+	if *window > 0 {
+		from, ok := windowStart(dates, *window)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "no period at least %d months before %s\n", *window, dates[len(dates)-1])
+			os.Exit(1)
+		}
+		dates = dates[sort.SearchStrings(dates, from):]
+	}
+	if *minPeriods <= 0 {
+		*minPeriods = len(dates)
+	}
+
+	rs := buildRankStability(records, *metric, *caseType, *county, dates, *order == "low", *band, *minPeriods, *n)
+	if rs.Ranked == 0 {
+		fmt.Fprintf(os.Stderr, "no municipality was ranked in %d of the %d periods; lower --min-periods\n", *minPeriods, len(dates))
+		os.Exit(1)
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rs)
+	case "csv":
+		err = writeRankingsCSV(os.Stdout, rs)
+	default:
+		renderRankings(rs)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// rankList is one of a rankStability's lists, named as in the CSV output.
+type rankList struct {
+	name    string
+	entries []rankHistory
+}
+
+func (rs rankStability) lists() []rankList {
+	return []rankList{
+		{"top", rs.Top},
+		{"bottom", rs.Bottom},
+		{"volatile", rs.Volatile},
+		{"steadiest", rs.Steadiest},
+	}
+}
+
+func renderRankings(rs rankStability) {
+	scope := "municipalities"
+	if rs.County != "" {
+		scope = rs.County + " municipalities"
+	}
+	first := "highest"
+	if rs.Order == "low" {
+		first = "lowest"
+	}
+	fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("%s (%s): %s %s %s, %d periods", metricLabel(rs.Metric), typeLabel(rs.Type),
+		rs.FromDate, glyphs.arrow, rs.ToDate, rs.Periods)))
+	fmt.Printf("%d %s ranked, rank 1 the %s; bands are the top and bottom %g%%\n", rs.Ranked, scope, first, rs.Band)
+	titles := map[string]string{
+		"top":       "Most often in the top band",
+		"bottom":    "Most often in the bottom band",
+		"volatile":  "Most volatile rank",
+		"steadiest": "Steadiest rank",
+	}
+	for _, l := range rs.lists() {
+		fmt.Printf("\n%s\n", paint(colorStdout, styleBold, titles[l.name]))
+		if len(l.entries) == 0 {
+			fmt.Println("  (none)")
+			continue
+		}
+		fmt.Printf("     %-12s %-30s %6s %6s %6s %6s %6s %6s %6s\n", "County", "Municipality", "Top", "Bottom", "Mean", "Best", "Worst", "Latest", "Swing")
+		for i, r := range l.entries {
+			latest := "- -"
+			if r.LatestRank > 0 {
+				latest = strconv.Itoa(r.LatestRank)
+			}
+			fmt.Printf("%3d. %-12s %-30s %5.0f%% %5.0f%% %6.1f %6d %6d %6s %6.1f\n", i+1, r.County, r.Municipality,
+				r.TopShare*100, r.BottomShare*100, r.MeanRank, r.BestRank, r.WorstRank, latest, r.RankStdDev)
+		}
+	}
+}
+
+func writeRankingsCSV(out io.Writer, rs rankStability) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"List", "Rank", "County", "Municipality", "Periods", "TopShare", "BottomShare", "MeanRank", "BestRank", "WorstRank", "LatestRank", "RankStdDev", "FromDate", "ToDate"})
+	for _, l := range rs.lists() {
+		for i, r := range l.entries {
+			latest := ""
+			if r.LatestRank > 0 {
+				latest = strconv.Itoa(r.LatestRank)
+			}
+			w.Write([]string{l.name, strconv.Itoa(i + 1), r.County, r.Municipality, strconv.Itoa(r.Periods),
+				strconv.FormatFloat(r.TopShare, 'f', 4, 64), strconv.FormatFloat(r.BottomShare, 'f', 4, 64),
+				strconv.FormatFloat(r.MeanRank, 'f', 2, 64), strconv.Itoa(r.BestRank), strconv.Itoa(r.WorstRank), latest,
+				strconv.FormatFloat(r.RankStdDev, 'f', 2, 64), rs.FromDate, rs.ToDate})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
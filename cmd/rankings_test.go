@@ -0,0 +1,50 @@
+package cmd
+
+import "testing"
+
+func TestBuildRankStability(t *testing.T) {
+	period := func(date string, filings ...string) timeRecord {
+		names := []string{"ABSECON", "BRIGANTINE", "CORBIN CITY", "EGG HARBOR CITY", "ESTELL MANOR"}
+		rec := timeRecord{date: date}
+		for i, f := range filings {
+			if f != "" {
+				rec.stats = append(rec.stats, rateStat("ATLANTIC", names[i], f, "", ""))
+			}
+		}
+		return rec
+	}
+	records := []timeRecord{
+		period("2023-06", "900", "100", "50", "40", "10"),
+		period("2024-06", "950", "120", "60", "500", "10"),
+		period("2025-06", "990", "120", "60", "60", ""),
+	}
+	dates := []string{"2023-06", "2024-06", "2025-06"}
+
+	rs := buildRankStability(records, "filings", "grand-total", "", dates, false, 20, 3, 10)
+	if rs.Ranked != 4 || rs.FromDate != "2023-06" || rs.ToDate != "2025-06" {
+		t.Fatalf("ranked %d from %s to %s", rs.Ranked, rs.FromDate, rs.ToDate)
+	}
+	if rs.Top[0].Municipality != "ABSECON" || rs.Top[0].TopShare != 1 || rs.Top[0].MeanRank != 1 {
+		t.Errorf("top = %+v", rs.Top)
+	}
+	// EGG HARBOR CITY jumped to 2nd for one period; ESTELL MANOR missed one.
+	if rs.Volatile[0].Municipality != "EGG HARBOR CITY" || rs.Volatile[0].BestRank != 2 || rs.Volatile[0].WorstRank != 4 {
+		t.Errorf("volatile = %+v", rs.Volatile)
+	}
+	for _, r := range rs.Bottom {
+		if r.Municipality == "ESTELL MANOR" {
+			t.Errorf("entity missing a period was ranked: %+v", r)
+		}
+	}
+	// CORBIN CITY and EGG HARBOR CITY tie in 2025-06 and share 3rd.
+	for _, r := range rs.Steadiest {
+		if (r.Municipality == "CORBIN CITY" || r.Municipality == "EGG HARBOR CITY") && r.LatestRank != 3 {
+			t.Errorf("tied latest rank = %+v", r)
+		}
+	}
+
+	rs = buildRankStability(records, "filings", "grand-total", "", dates, true, 20, 2, 10)
+	if rs.Ranked != 5 || rs.Top[0].Municipality != "ESTELL MANOR" || rs.Top[0].Periods != 2 {
+		t.Errorf("ascending with min 2 periods: %+v", rs.Top)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// supersedes reports whether output file a, named nameA, should be read
+// instead of b, another file for the same period: its report was generated
+// later. Where either generation date is unknown, as for files parsed
+// before it was recorded, or the two are the same, the name sorting last
+// wins, so a re-download saved as "...-2.json" is preferred.
+func supersedes(a parser.Output, nameA string, b parser.Output, nameB string) bool {
+	ga, gb := reportGenerated(a), reportGenerated(b)
+	if ga != "" && gb != "" && ga != gb {
+		return ga > gb
+	}
+	return strings.TrimSuffix(nameA, filepath.Ext(nameA)) > strings.TrimSuffix(nameB, filepath.Ext(nameB))
+}
+
+// reportGenerated returns the generation date recorded for out's report,
+// or "".
+func reportGenerated(out parser.Output) string {
+	if out.Provenance == nil {
+		return ""
+	}
+	return out.Provenance.Generated
+}
+
+// dropSuperseded removes from files (output file name → period) every
+// file superseded by another for the same period, such as a report that
+// was later re-issued, and returns a note for each, sorted.
+func dropSuperseded(files map[string]string, outputs map[string]parser.Output) []string {
+	newest := make(map[string]string) // period → file name
+	for name, period := range files {
+		prev, ok := newest[period]
+		if !ok || supersedes(outputs[name], name, outputs[prev], prev) {
+			newest[period] = name
+		}
+	}
+	var notes []string
+	for name, period := range files {
+		keep := newest[period]
+		if name == keep {
+			continue
+		}
+		delete(files, name)
+		note := fmt.Sprintf("%s: skipping %s, superseded by %s", period, name, keep)
+		if g := reportGenerated(outputs[keep]); g != "" {
+			note += " (generated " + g + ")"
+		}
+		notes = append(notes, note)
+	}
+	sort.Strings(notes)
+	return notes
+}
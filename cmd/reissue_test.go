@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestDropSuperseded(t *testing.T) {
+	generated := func(date string) parser.Output {
+		return parser.Output{Provenance: &parser.Provenance{Generated: date}}
+	}
+	files := map[string]string{
+		"municipal-courts-2022-06.json":   "2022-06",
+		"municipal-courts-2022-06-2.json": "2022-06",
+		"municipal-courts-2023-06.json":   "2023-06",
+		"municipal-courts-2024-06.json":   "2024-06",
+		"municipal-courts-2024-06-2.json": "2024-06",
+	}
+	outputs := map[string]parser.Output{
+		// The re-issue was saved under the earlier-sorting name.
+		"municipal-courts-2022-06.json":   generated("2024-03-11"),
+		"municipal-courts-2022-06-2.json": generated("2022-07-20"),
+		"municipal-courts-2023-06.json":   generated("2023-07-18"),
+		// Without dates, the name sorting last wins.
+		"municipal-courts-2024-06.json":   {},
+		"municipal-courts-2024-06-2.json": {},
+	}
+
+	notes := dropSuperseded(files, outputs)
+	want := map[string]string{
+		"municipal-courts-2022-06.json":   "2022-06",
+		"municipal-courts-2023-06.json":   "2023-06",
+		"municipal-courts-2024-06-2.json": "2024-06",
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("kept %v, want %v", files, want)
+	}
+	if len(notes) != 2 || notes[0] != "2022-06: skipping municipal-courts-2022-06-2.json, superseded by municipal-courts-2022-06.json (generated 2024-03-11)" {
+		t.Errorf("notes = %q", notes)
+	}
+}
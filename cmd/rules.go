@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// ruleFile is a set of sanity checks on parsed values, for validate and
+// parse --rules. Each rule applies to the current-period row of its
+// metrics (every metric when empty) in its case types (every type when
+// empty) and bounds the value by min and max, or by another case type in
+// the same row.
+//
+//	{
+//	  "rules": [
+//	    {"name": "clearance % in range", "metrics": ["clearance-pct"], "min": 0, "max": 500},
+//	    {"metrics": ["filings", "resolutions"], "types": ["parking"], "min": 0},
+//	    {"metrics": ["filings"], "types": ["grand-total"], "atLeast": "criminal-total"},
+//	    {"metrics": ["backlog-pct"], "max": 100, "severity": "warning"}
+//	  ]
+//	}
+type ruleFile struct {
+	Rules []rule `json:"rules"`
+}
+
+type rule struct {
+	Name     string   `json:"name,omitempty"`
+	Metrics  []string `json:"metrics,omitempty"`
+	Types    []string `json:"types,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	AtLeast  string   `json:"atLeast,omitempty"`  // case type the value must be at least
+	AtMost   string   `json:"atMost,omitempty"`   // case type the value must be at most
+	Severity string   `json:"severity,omitempty"` // "error" (the default) or "warning"
+}
+
+// ruleViolation is one value that broke a rule.
+type ruleViolation struct {
+	Rule         string `json:"rule"`
+	Severity     string `json:"severity"`
+	Period       string `json:"period"`
+	County       string `json:"county"`
+	Municipality string `json:"municipality"`
+	Page         int    `json:"page,omitempty"`
+	Message      string `json:"message"`
+}
+
+// where names the record, with its page when known (output written before
+// page numbers were recorded has none).
+func (v ruleViolation) where() string {
+	if v.Page > 0 {
+		return fmt.Sprintf("%s/%s (page %d)", v.County, v.Municipality, v.Page)
+	}
+	return v.County + "/" + v.Municipality
+}
+
+// flagRuleViolations checks r's records against rules, adding each
+// violation to the record's warnings and to r.violations for the summary.
+func flagRuleViolations(r *parseResult, rules []rule) {
+	period := r.date
+	if period == "" && len(r.results) > 0 {
+		period, _ = periodFromDateRange(r.results[0].DateRange)
+	}
+	for i := range r.results {
+		s := &r.results[i]
+		for _, v := range checkRules(r.results[i:i+1], period, rules) {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("rule %q (%s): %s", v.Rule, v.Severity, v.Message))
+			r.violations = append(r.violations, fmt.Sprintf("%s: %s: %s: %s", v.Severity, v.where(), v.Rule, v.Message))
+		}
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+// defaultRules are checked when no rules file is given: counts can't be
+// negative, the totals cover their parts, and rates stay in a plausible
+// range. Clearance, the difference of resolutions and filings, may be
+// negative.
+var defaultRules = []rule{
+	{Name: "counts are not negative", Metrics: []string{"filings", "resolutions", "backlog", "active-pending"}, Min: ptr(0)},
+	{Name: "grand total covers criminal", Metrics: []string{"filings", "resolutions", "backlog", "active-pending"}, Types: []string{"grand-total"}, AtLeast: "criminal-total"},
+	{Name: "grand total covers traffic", Metrics: []string{"filings", "resolutions", "backlog", "active-pending"}, Types: []string{"grand-total"}, AtLeast: "traffic-total"},
+	{Name: "clearance % in range", Metrics: []string{"clearance-pct"}, Min: ptr(0), Max: ptr(500), Severity: "warning"},
+	{Name: "backlog % in range", Metrics: []string{"backlog-pct"}, Min: ptr(0), Max: ptr(100)},
+}
+
+// loadRules reads and checks a rules file.
+func loadRules(path string) ([]rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f ruleFile
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i := range f.Rules {
+		if err := checkRule(&f.Rules[i]); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %w", path, i+1, err)
+		}
+	}
+	return f.Rules, nil
+}
+
+// checkRule rejects unknown metrics, types and severities and a rule with
+// nothing to check, and fills in the defaults.
+func checkRule(r *rule) error {
+	for _, m := range r.Metrics {
+		if !contains(validMetrics, m) {
+			return fmt.Errorf("unknown metric %q; valid options: %s", m, strings.Join(validMetrics, ", "))
+		}
+	}
+	for _, t := range append(append([]string(nil), r.Types...), r.AtLeast, r.AtMost) {
+		if t != "" && !contains(validTypes, t) {
+			return fmt.Errorf("unknown type %q; valid options: %s", t, strings.Join(validTypes, ", "))
+		}
+	}
+	if r.Min == nil && r.Max == nil && r.AtLeast == "" && r.AtMost == "" {
+		return fmt.Errorf("needs min, max, atLeast or atMost")
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = "error"
+	case "error", "warning":
+	default:
+		return fmt.Errorf("invalid severity %q; valid options: error, warning", r.Severity)
+	}
+	if r.Name == "" {
+		r.Name = r.describe()
+	}
+	return nil
+}
+
+// describe names a rule without one from what it checks, e.g. "filings
+// grand-total >= criminal-total".
+func (r rule) describe() string {
+	subject := "all metrics"
+	if len(r.Metrics) > 0 {
+		subject = strings.Join(r.Metrics, ",")
+	}
+	if len(r.Types) > 0 {
+		subject += " " + strings.Join(r.Types, ",")
+	}
+	var conds []string
+	if r.Min != nil && r.Max != nil {
+		conds = append(conds, fmt.Sprintf("between %g and %g", *r.Min, *r.Max))
+	} else if r.Min != nil {
+		conds = append(conds, fmt.Sprintf(">= %g", *r.Min))
+	} else if r.Max != nil {
+		conds = append(conds, fmt.Sprintf("<= %g", *r.Max))
+	}
+	if r.AtLeast != "" {
+		conds = append(conds, ">= "+r.AtLeast)
+	}
+	if r.AtMost != "" {
+		conds = append(conds, "<= "+r.AtMost)
+	}
+	return subject + " " + strings.Join(conds, ", ")
+}
+
+// checkRules evaluates rules against the records of one report. Values
+// that are missing or "- -", on either side of a comparison, pass.
+func checkRules(stats []parser.MunicipalityStats, period string, rules []rule) []ruleViolation {
+	var out []ruleViolation
+	for _, s := range stats {
+		for _, r := range rules {
+			metrics, types := r.Metrics, r.Types
+			if len(metrics) == 0 {
+				metrics = validMetrics
+			}
+			if len(types) == 0 {
+				types = validTypes
+			}
+			for _, m := range metrics {
+				row := getRow(s, m)
+				for _, t := range types {
+					v := getField(row, t)
+					if math.IsNaN(v) {
+						continue
+					}
+					var problems []string
+					if r.Min != nil && v < *r.Min {
+						problems = append(problems, fmt.Sprintf("below %g", *r.Min))
+					}
+					if r.Max != nil && v > *r.Max {
+						problems = append(problems, fmt.Sprintf("above %g", *r.Max))
+					}
+					if o := getField(row, r.AtLeast); r.AtLeast != "" && v < o {
+						problems = append(problems, fmt.Sprintf("below %s (%s)", strings.ToLower(typeLabel(r.AtLeast)), formatNum(o)))
+					}
+					if o := getField(row, r.AtMost); r.AtMost != "" && v > o {
+						problems = append(problems, fmt.Sprintf("above %s (%s)", strings.ToLower(typeLabel(r.AtMost)), formatNum(o)))
+					}
+					if len(problems) == 0 {
+						continue
+					}
+					out = append(out, ruleViolation{
+						Rule: r.Name, Severity: r.Severity, Period: period,
+						County: s.County, Municipality: s.Municipality, Page: s.PageNumber,
+						Message: fmt.Sprintf("%s %s is %s, %s", metricLabel(m), strings.ToLower(typeLabel(t)), formatNum(v), strings.Join(problems, " and ")),
+					})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// rulesFrom loads the rules file at path, or returns defaultRules when path
+// is empty.
+func rulesFrom(path string) ([]rule, error) {
+	if path != "" {
+		return loadRules(path)
+	}
+	rules := append([]rule(nil), defaultRules...)
+	for i := range rules {
+		if err := checkRule(&rules[i]); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestCheckRules(t *testing.T) {
+	rules, err := rulesFrom("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok := rateStat("ATLANTIC", "ABSECON", "100", "90", "90%")
+	ok.Filings.CurrentPeriod.CriminalTotal = "40"
+	bad := rateStat("ATLANTIC", "BRIGANTINE", "10", "-5", "600%")
+	bad.Filings.CurrentPeriod.CriminalTotal = "40"
+	bad.Filings.CurrentPeriod.TrafficTotal = "- -" // missing values pass
+	bad.PageNumber = 4
+
+	got := checkRules([]parser.MunicipalityStats{ok, bad}, "2024-06", rules)
+	want := []string{
+		"error BRIGANTINE counts are not negative: Resolutions grand total is -5, below 0",
+		"error BRIGANTINE grand total covers criminal: Filings grand total is 10, below criminal total (40)",
+		"warning BRIGANTINE clearance % in range: Clearance % grand total is 600, above 500",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d violations %+v, want %d", len(got), got, len(want))
+	}
+	for i, v := range got {
+		if s := v.Severity + " " + v.Municipality + " " + v.Rule + ": " + v.Message; s != want[i] {
+			t.Errorf("violation %d = %q, want %q", i, s, want[i])
+		}
+		if v.Page != 4 || v.Period != "2024-06" {
+			t.Errorf("violation %d at page %d of %s", i, v.Page, v.Period)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(body string) string {
+		path := filepath.Join(dir, "rules.json")
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rules, err := loadRules(write(`{"rules": [{"metrics": ["filings"], "types": ["parking"], "min": 0, "max": 1e6}, {"types": ["grand-total"], "atLeast": "criminal-total", "severity": "warning"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules[0].Name != "filings parking between 0 and 1e+06" || rules[0].Severity != "error" {
+		t.Errorf("rule 1 = %q (%s)", rules[0].Name, rules[0].Severity)
+	}
+	if rules[1].Name != "all metrics grand-total >= criminal-total" || rules[1].Severity != "warning" {
+		t.Errorf("rule 2 = %q (%s)", rules[1].Name, rules[1].Severity)
+	}
+
+	for body, msg := range map[string]string{
+		`{"rules": [{"metrics": ["fillings"], "min": 0}]}`: "unknown metric",
+		`{"rules": [{"atMost": "traffic"}]}`:               "unknown type",
+		`{"rules": [{"metrics": ["filings"]}]}`:            "needs min, max",
+		`{"rules": [{"min": 0, "severity": "fatal"}]}`:     "invalid severity",
+		`{"rules": [{"min": 0, "between": [0, 1]}]}`:       "unknown field",
+	} {
+		if _, err := loadRules(write(body)); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: error %v, want %q", body, err, msg)
+		}
+	}
+}
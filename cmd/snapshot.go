@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// Chart images default to the 1.91:1 aspect ratio recommended for OpenGraph
+// previews, 1200×630 pixels at 120 DPI.
+const (
+	snapshotDPI      = 120
+	snapshotWidthPx  = 1200
+	snapshotHeightPx = 630
+)
+
+// Bounds on requested chart image dimensions, in pixels.
+const (
+	minChartPx = 300
+	maxChartPx = 2400
+)
+
+// chartImageMargin is the blank border around rendered chart images, much
+// tighter than the PDF page margin.
+const chartImageMargin = 0.15 * vg.Inch
+
+type snapshotPage struct {
+	Title    string
+	ImageURL string
+	PageURL  string
+	Width    int
+	Height   int
+}
+
+var snapshotTemplate = template.Must(template.New("snapshot").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="New Jersey Municipal Court Statistics">
+<meta property="og:url" content="{{.PageURL}}">
+<meta property="og:image" content="{{.ImageURL}}">
+<meta property="og:image:width" content="{{.Width}}">
+<meta property="og:image:height" content="{{.Height}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:image" content="{{.ImageURL}}">
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, system-ui, sans-serif; margin: 2rem; color: #1c1917; }
+  img { max-width: 100%; height: auto; border: 1px solid #e7e5e4; }
+  a { color: #57534e; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p><img src="{{.ImageURL}}" width="{{.Width}}" height="{{.Height}}" alt="{{.Title}}"></p>
+<p><a href="/">Open the interactive dashboard</a></p>
+</body>
+</html>
+`))
+
+// snapshotTitle describes a chart request in the same form as the viz titles,
+// naming the selected entity when there is one.
+func snapshotTitle(metric, caseType, level, county, municipality string) string {
+	title := metricLabel(metric) + " — " + typeLabel(caseType)
+	switch {
+	case level == "state":
+		title += " — STATEWIDE"
+	case level == "municipality" && municipality != "":
+		title += " — " + municipality
+	case county != "":
+		title += " — " + county
+	}
+	return title
+}
+
+// renderChartPNG draws series as a PNG: a single line chart when there is
+// one entity, otherwise the multi-series overview with a legend.
+func renderChartPNG(w io.Writer, title string, series map[string][]dataPoint, sortedDates []string, width, height vg.Length) error {
+	return writePNG(w, width, height, func(area draw.Canvas) {
+		if len(series) == 1 {
+			for _, points := range series {
+				drawChart(area, title, points, sortedDates)
+			}
+		} else {
+			drawOverview(area, title, series, sortedEntityNames(series), sortedDates, false)
+		}
+	})
+}
+
+// writePNG renders a width × height image at snapshotDPI, drawn by fill
+// inside the image margin, as a PNG to w.
+func writePNG(w io.Writer, width, height vg.Length, fill func(area draw.Canvas)) error {
+	c := vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(snapshotDPI))
+	fill(draw.Crop(draw.New(c), chartImageMargin, -chartImageMargin, chartImageMargin, -chartImageMargin))
+	_, err := vgimg.PngCanvas{Canvas: c}.WriteTo(w)
+	return err
+}
+
+// writePNGFile is writePNG to a file, sized in pixels.
+func writePNGFile(path string, widthPx, heightPx int, fill func(area draw.Canvas)) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	px := func(n int) vg.Length { return vg.Length(n) / snapshotDPI * vg.Inch }
+	if err := writePNG(f, px(widthPx), px(heightPx), fill); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// pixelsToLength converts a pixel dimension from a query parameter into a
+// canvas length at snapshotDPI, using def when the value is missing or
+// invalid and clamping to a sane range.
+func pixelsToLength(s string, def int) vg.Length {
+	px, err := strconv.Atoi(s)
+	if err != nil {
+		px = def
+	}
+	px = max(minChartPx, min(maxChartPx, px))
+	return vg.Length(px) / snapshotDPI * vg.Inch
+}
+
+// requestBaseURL returns the scheme and host to put in links back to the
+// server: base (--base-url) if given, otherwise the ones the client used.
+// A proxy's X-Forwarded-Proto and X-Forwarded-Host are only honored with
+// trustProxy, since any client can send them.
+func requestBaseURL(r *http.Request, base string, trustProxy bool) string {
+	if base != "" {
+		return base
+	}
+	scheme, host := "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if trustProxy {
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+			scheme = proto
+		}
+		if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+			host, _, _ = strings.Cut(fwd, ",")
+			host = strings.TrimSpace(host)
+		}
+	}
+	return scheme + "://" + host
+}
+
+// checkBaseURL validates a --base-url, an absolute http or https URL, and
+// returns it without a trailing slash.
+func checkBaseURL(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid --base-url %q: want an http or https URL such as https://stats.example.org", s)
+	}
+	return strings.TrimSuffix(s, "/"), nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// statsResponse summarizes one metric across municipalities for a period.
+type statsResponse struct {
+	Metric      string             `json:"metric"`
+	Type        string             `json:"type"`
+	Date        string             `json:"date"`
+	Count       int                `json:"count"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Mean        float64            `json:"mean"`
+	Median      float64            `json:"median"`
+	Percentiles map[string]float64 `json:"percentiles"`
+	Highest     []entityValue      `json:"highest"`
+	Lowest      []entityValue      `json:"lowest"`
+	Entity      *entityValue       `json:"entity,omitempty"` // the requested municipality, if any
+}
+
+type entityValue struct {
+	County       string   `json:"county"`
+	Municipality string   `json:"municipality"`
+	ID           string   `json:"id"`
+	Value        float64  `json:"value"`
+	Percentile   *float64 `json:"percentile,omitempty"` // share of municipalities at or below Value
+}
+
+// reportedPercentiles are the percentiles included in statsResponse.
+var reportedPercentiles = []float64{5, 10, 25, 75, 90, 95, 99}
+
+// extremesCount is how many highest/lowest entities are listed.
+const extremesCount = 5
+
+// periodValues returns every municipality's value of metric for date,
+// skipping missing values.
+func periodValues(records []timeRecord, metric, caseType, date string) []entityValue {
+	var vals []entityValue
+	for _, rec := range records {
+		if rec.date != date {
+			continue
+		}
+		for _, s := range rec.stats {
+			v := getField(getRow(s, metric), caseType)
+			if math.IsNaN(v) {
+				continue
+			}
+			vals = append(vals, entityValue{
+				County:       strings.ToUpper(s.County),
+				Municipality: entityLabel(s),
+				ID:           entityID(s),
+				Value:        v,
+			})
+		}
+	}
+	return vals
+}
+
+// buildStats summarizes metric across municipalities for date, and places
+// county/municipality (if non-empty) within the distribution. It returns
+// false if there are no values for that period.
+func buildStats(records []timeRecord, metric, caseType, date, county, municipality string) (statsResponse, bool) {
+	vals := periodValues(records, metric, caseType, date)
+	if len(vals) == 0 {
+		return statsResponse{}, false
+	}
+	sort.SliceStable(vals, func(i, j int) bool { return vals[i].Value < vals[j].Value })
+
+	sorted := make([]float64, len(vals))
+	var sum float64
+	for i, v := range vals {
+		sorted[i] = v.Value
+		sum += v.Value
+	}
+
+	resp := statsResponse{
+		Metric:      metric,
+		Type:        caseType,
+		Date:        date,
+		Count:       len(vals),
+		Min:         sorted[0],
+		Max:         sorted[len(sorted)-1],
+		Mean:        sum / float64(len(sorted)),
+		Median:      percentile(sorted, 50),
+		Percentiles: make(map[string]float64, len(reportedPercentiles)),
+	}
+	for _, p := range reportedPercentiles {
+		resp.Percentiles["p"+strconv.Itoa(int(p))] = percentile(sorted, p)
+	}
+
+	for _, v := range vals {
+		if v.County == county && v.Municipality == nameLabel(county, municipality) {
+			rank := percentileRank(sorted, v.Value)
+			v.Percentile = &rank
+			resp.Entity = &v
+			break
+		}
+	}
+
+	n := min(extremesCount, len(vals))
+	resp.Lowest = append([]entityValue(nil), vals[:n]...)
+	for i := len(vals) - 1; i >= len(vals)-n; i-- {
+		resp.Highest = append(resp.Highest, vals[i])
+	}
+	return resp, true
+}
+
+// percentile returns the p-th percentile (0-100) of sorted values using
+// linear interpolation between closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// percentileRank returns the percentage of sorted values at or below v.
+func percentileRank(sorted []float64, v float64) float64 {
+	n := sort.Search(len(sorted), func(i int) bool { return sorted[i] > v })
+	return float64(n) / float64(len(sorted)) * 100
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	tests := map[float64]float64{0: 10, 50: 25, 100: 40, 25: 17.5}
+	for p, want := range tests {
+		if got := percentile(sorted, p); math.Abs(got-want) > 1e-9 {
+			t.Errorf("percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+	if got := percentileRank(sorted, 30); got != 75 {
+		t.Errorf("percentileRank(30) = %v, want 75", got)
+	}
+}
+
+func TestBuildStats(t *testing.T) {
+	var stats []parser.MunicipalityStats
+	for i, name := range []string{"A", "B", "C", "D", "E", "F", "G"} {
+		stats = append(stats, rateStat("ESSEX", name, formatNum(float64((i+1)*100)), "0", ""))
+	}
+	stats = append(stats, rateStat("ESSEX", "MISSING", "- -", "0", ""))
+	records := []timeRecord{{date: "2024-06", stats: stats}}
+
+	resp, ok := buildStats(records, "filings", "grand-total", "2024-06", "ESSEX", "F")
+	if !ok {
+		t.Fatal("no stats")
+	}
+	if resp.Count != 7 || resp.Min != 100 || resp.Max != 700 || resp.Median != 400 || resp.Mean != 400 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.Highest) != extremesCount || resp.Highest[0].Municipality != "G" || resp.Lowest[0].Municipality != "A" {
+		t.Errorf("highest = %v, lowest = %v", resp.Highest, resp.Lowest)
+	}
+	if resp.Entity == nil || math.Abs(*resp.Entity.Percentile-600.0/7) > 1e-9 {
+		t.Errorf("entity = %+v", resp.Entity)
+	}
+
+	if _, ok := buildStats(records, "filings", "grand-total", "1999-06", "", ""); ok {
+		t.Error("expected no stats for an unknown period")
+	}
+}
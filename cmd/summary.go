@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// summaryTotals are statewide sums for one period, from either the prior
+// or current rows of each report.
+type summaryTotals struct {
+	filings, resolutions, backlog, activePending float64
+}
+
+func (t summaryTotals) clearancePct() float64 {
+	if t.filings == 0 {
+		return math.NaN()
+	}
+	return t.resolutions / t.filings * 100
+}
+
+// sumRows adds up caseType across stats, reading the prior-period rows if
+// prior is set and the current-period rows otherwise. Each report carries
+// the same months a year earlier as its prior period, so the two give a
+// year-over-year comparison from a single file.
+func sumRows(stats []parser.MunicipalityStats, caseType string, prior bool) summaryTotals {
+	pick := func(sec parser.SectionWithChange) parser.RowData {
+		if prior {
+			return sec.PriorPeriod
+		}
+		return sec.CurrentPeriod
+	}
+	add := func(total *float64, row parser.RowData) {
+		if v := getField(row, caseType); !math.IsNaN(v) {
+			*total += v
+		}
+	}
+
+	var t summaryTotals
+	for _, s := range stats {
+		add(&t.filings, pick(s.Filings))
+		add(&t.resolutions, pick(s.Resolutions))
+		add(&t.backlog, pick(s.Backlog))
+		add(&t.activePending, pick(s.ActivePending))
+	}
+	return t
+}
+
+// Summary implements the "summary" subcommand: a few lines describing the
+// newest report statewide.
+func Summary(args []string) {
+	fs := flag.NewFlagSet("summary", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	caseType := fs.String("type", "grand-total", "case type column")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt summary [dir] [--type grand-total]\n\nPrint the latest period's statewide totals against the same months a year earlier.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+	useASCII(*ascii)
+	useColor(color)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if !contains(validTypes, *caseType) {
+		fmt.Fprintf(os.Stderr, "invalid --type %q; valid options: %s\n", *caseType, strings.Join(validTypes, ", "))
+		os.Exit(1)
+	}
+
+	records, err := loadRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	latest := records[len(records)-1]
+	cur := sumRows(latest.stats, *caseType, false)
+	prev := sumRows(latest.stats, *caseType, true)
+
+	dateRange := ""
+	if len(latest.stats) > 0 {
+		dateRange = strings.Join(strings.Fields(latest.stats[0].DateRange), " ")
+	}
+	fmt.Println(paint(colorStdout, styleBold, fmt.Sprintf("Statewide summary%s%s (%s, %d municipalities, %s)", glyphs.dash, latest.date, dateRange, len(latest.stats), typeLabel(*caseType))))
+	fmt.Println()
+	fmt.Println(paint(colorStdout, styleDim, fmt.Sprintf("%-16s %14s %14s %10s", "", "Current", "Prior year", "Change")))
+	printSummaryCount("Filings", cur.filings, prev.filings)
+	printSummaryCount("Resolutions", cur.resolutions, prev.resolutions)
+	printSummaryRate("Clearance %", cur.clearancePct(), prev.clearancePct())
+	printSummaryCount("Backlog", cur.backlog, prev.backlog)
+	printSummaryCount("Active pending", cur.activePending, prev.activePending)
+}
+
+func printSummaryCount(label string, cur, prev float64) {
+	change := "- -"
+	if prev != 0 {
+		change = fmt.Sprintf("%+.1f%%", (cur-prev)/prev*100)
+	}
+	fmt.Printf("%-16s %14s %14s %10s\n", label, formatNum(cur), formatNum(prev), change)
+}
+
+func printSummaryRate(label string, cur, prev float64) {
+	change := "- -"
+	if !math.IsNaN(cur) && !math.IsNaN(prev) {
+		change = fmt.Sprintf("%+.1f pts", cur-prev)
+	}
+	fmt.Printf("%-16s %14s %14s %10s\n", label, formatPct(cur), formatPct(prev), change)
+}
+
+func formatPct(v float64) string {
+	if math.IsNaN(v) {
+		return "- -"
+	}
+	return fmt.Sprintf("%.1f%%", v)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestSumRows(t *testing.T) {
+	a := rateStat("ATLANTIC", "ABSECON", "100", "50", "")
+	a.Filings.PriorPeriod.GrandTotal = "80"
+	a.Resolutions.PriorPeriod.GrandTotal = "80"
+	b := rateStat("ATLANTIC", "BRIGANTINE", "300", "350", "")
+	b.Filings.PriorPeriod.GrandTotal = "- -"
+	b.Resolutions.PriorPeriod.GrandTotal = "20"
+	stats := []parser.MunicipalityStats{a, b}
+
+	cur := sumRows(stats, "grand-total", false)
+	if cur.filings != 400 || cur.resolutions != 400 {
+		t.Errorf("current = %+v, want 400 filings and resolutions", cur)
+	}
+	if got := cur.clearancePct(); got != 100 {
+		t.Errorf("current clearance = %v, want 100", got)
+	}
+
+	// Missing values are skipped rather than poisoning the total.
+	prev := sumRows(stats, "grand-total", true)
+	if prev.filings != 80 || prev.resolutions != 100 {
+		t.Errorf("prior = %+v, want 80 filings and 100 resolutions", prev)
+	}
+
+	if got := (summaryTotals{}).clearancePct(); !math.IsNaN(got) {
+		t.Errorf("clearance with no filings = %v, want NaN", got)
+	}
+}
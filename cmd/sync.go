@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// syncStateName is the file, inside the sync directory, recording what each
+// sync run has done so far.
+const syncStateName = ".sync-state.json"
+
+// syncState records every step a sync has completed or failed. It is saved
+// after each step, so an interrupted run resumes where it stopped.
+type syncState struct {
+	Downloads map[string]syncDownload `json:"downloads"` // by URL
+	Parsed    map[string]syncParse    `json:"parsed"`    // by PDF file name
+	Failures  map[string]syncFailure  `json:"failures"`  // by step key, e.g. "parse:<file>"
+
+	// Webhook deliveries that failed, kept with their payload so a later
+	// run can send them again.
+	PendingWebhooks []syncWebhook `json:"pendingWebhooks,omitempty"`
+}
+
+type syncDownload struct {
+	File string    `json:"file"`
+	At   time.Time `json:"at"`
+}
+
+// syncParse identifies the PDF contents a parse was run on. Size and
+// modification time let a later run skip rehashing an unchanged file.
+type syncParse struct {
+	SHA256  string    `json:"sha256"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Output  string    `json:"output"`
+	At      time.Time `json:"at"`
+}
+
+type syncWebhook struct {
+	URL     string          `json:"url"`
+	Period  string          `json:"period"`
+	Payload json.RawMessage `json:"payload"`
+	Queued  time.Time       `json:"queued"`
+}
+
+// webhookMaxAge is how long a failed delivery is retried before it is
+// dropped.
+const webhookMaxAge = 7 * 24 * time.Hour
+
+type syncFailure struct {
+	Step  string    `json:"step"` // "scrape", "download", "parse" or "webhook"
+	Item  string    `json:"item"` // URL, PDF file name, or webhook URL and period
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
+}
+
+// loadSyncState reads the state at path; a missing file yields an empty one.
+func loadSyncState(path string) (*syncState, error) {
+	st := &syncState{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+		}
+	}
+	if st.Downloads == nil {
+		st.Downloads = make(map[string]syncDownload)
+	}
+	if st.Parsed == nil {
+		st.Parsed = make(map[string]syncParse)
+	}
+	if st.Failures == nil {
+		st.Failures = make(map[string]syncFailure)
+	}
+	return st, nil
+}
+
+// save writes the state through a temporary file, so a run killed mid-write
+// leaves the previous state intact.
+func (st *syncState) save(path string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// record notes the outcome of a step: a nil err clears any earlier failure.
+func (st *syncState) record(step, item string, err error) {
+	key := step + ":" + item
+	if err == nil {
+		delete(st.Failures, key)
+		return
+	}
+	st.Failures[key] = syncFailure{Step: step, Item: item, Error: err.Error(), At: time.Now().UTC().Truncate(time.Second)}
+}
+
+// Sync implements the "sync" subcommand: download any reports not yet on
+// disk and parse any PDFs that are new or changed since their last parse.
+func Sync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory for downloaded PDFs and parsed output")
+	statePath := fs.String("state", "", "state file (default <dir>/"+syncStateName+")")
+	var extraPatterns stringList
+	fs.Var(&extraPatterns, "pattern", "report file name pattern using {yyyy}, {yy}, {mm} (repeatable; tried before the built-ins)")
+	noParse := fs.Bool("no-parse", false, "only download")
+	postFlags := addPostParseFlags(fs)
+	var hookURLs stringList
+	fs.Var(&hookURLs, "webhook", "URL to POST a summary to whenever a new or changed report is parsed (repeatable)")
+	hookSecret := fs.String("webhook-secret", os.Getenv("MUNICOURT_WEBHOOK_SECRET"), "key for the X-Municourt-Signature HMAC header (default $MUNICOURT_WEBHOOK_SECRET)")
+	netFlags := addHTTPFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt sync [dir] [--state path] [--pattern munm{yy}{mm}.pdf ...] [--no-parse] [--duplicates policy] [--rules file] [--strict] [--webhook URL ...] [--webhook-secret key] [--proxy URL] [--timeout 60s] [--insecure]\n\nDownload new reports and parse new or changed PDFs, resuming from the last run's state.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(*dir, syncStateName)
+	}
+	patterns, err := compilePatterns(extraPatterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --pattern: %v\n", err)
+		os.Exit(1)
+	}
+	if err := netFlags.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	post, err := postFlags.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "error creating directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	st, err := loadSyncState(*statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading sync state: %v\n", err)
+		os.Exit(1)
+	}
+	save := func() {
+		if err := st.save(*statePath); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing sync state: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	hooks := webhookConfig{urls: hookURLs, secret: *hookSecret}
+	retryWebhooks(st, save, hooks)
+
+	downloaded, err := syncDownloads(st, save, *dir, patterns)
+	st.record("scrape", statisticsPageURL, err)
+	save()
+	if err != nil {
+		// Parsing what is already on disk is still worthwhile.
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	parsed := 0
+	if !*noParse {
+		parsed = syncParses(st, save, *dir, post, hooks)
+	}
+
+	fmt.Fprintf(os.Stderr, "Done: %d downloaded, %d parsed, %d failed steps\n", downloaded, parsed, len(st.Failures))
+	if len(st.Failures) > 0 {
+		keys := make([]string, 0, len(st.Failures))
+		for k := range st.Failures {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f := st.Failures[k]
+			fmt.Fprintf(os.Stderr, "  %s %s: %s\n", f.Step, f.Item, f.Error)
+		}
+		os.Exit(1)
+	}
+}
+
+// syncDownloads downloads every linked report that the state doesn't
+// already have on disk, returning how many were downloaded.
+func syncDownloads(st *syncState, save func(), dir string, patterns []urlPattern) (int, error) {
+	cachePath := filepath.Join(dir, pageCacheName)
+	page, _, err := fetchStatisticsPage(statisticsPageURL, loadPageCache(cachePath))
+	if err != nil {
+		return 0, err
+	}
+	if page.ETag != "" || page.LastModified != "" {
+		page.save(cachePath)
+	}
+	base, _ := url.Parse(statisticsPageURL)
+	links := extractPDFLinks(page.Body, base, patterns)
+
+	m, err := loadManifest(dir)
+	if err != nil {
+		return 0, fmt.Errorf("error loading manifest: %w", err)
+	}
+
+	n := 0
+	for _, link := range links {
+		outName := "municipal-courts-" + link.period + ".pdf"
+		if _, err := os.Stat(filepath.Join(dir, outName)); err == nil {
+			// Downloaded by an earlier sync, or by download or fetch.
+			if _, ok := st.Downloads[link.url]; !ok {
+				st.Downloads[link.url] = syncDownload{File: outName, At: time.Now().UTC().Truncate(time.Second)}
+				save()
+			}
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "downloading %s -> %s\n", link.url, outName)
+		remote, err := downloadReport(link.url, dir, outName)
+		st.record("download", link.url, err)
+		if err == nil {
+			st.Downloads[link.url] = syncDownload{File: outName, At: time.Now().UTC().Truncate(time.Second)}
+			m[outName] = remote.entry(link.url)
+			if err := m.save(dir); err != nil {
+				fmt.Fprintf(os.Stderr, "error writing manifest: %v\n", err)
+			}
+			n++
+		} else {
+			fmt.Fprintf(os.Stderr, "error downloading %s: %v\n", link.url, err)
+		}
+		save()
+	}
+	return n, nil
+}
+
+// syncParses parses every report PDF in dir that is new or whose contents
+// changed since the state's record of its last parse, returning how many
+// were parsed. Each parse is announced to hooks.
+func syncParses(st *syncState, save func(), dir string, post postParse, hooks webhookConfig) int {
+	pdfs, err := filepath.Glob(filepath.Join(dir, "municipal-courts-*.pdf"))
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for _, pdf := range pdfs {
+		name := filepath.Base(pdf)
+		info, err := os.Stat(pdf)
+		if err != nil {
+			continue
+		}
+		jsonOut := strings.TrimSuffix(pdf, ".pdf") + ".json"
+		prev, ok := st.Parsed[name]
+		_, outErr := os.Stat(jsonOut)
+		if ok && outErr == nil && prev.Size == info.Size() && prev.ModTime.Equal(info.ModTime()) {
+			continue
+		}
+
+		prov, err := fileProvenance(pdf)
+		if err != nil {
+			st.record("parse", name, err)
+			save()
+			continue
+		}
+		if ok && outErr == nil && prev.SHA256 == prov.SHA256 {
+			// Touched but not changed: just refresh the recorded file info.
+			prev.Size, prev.ModTime = info.Size(), info.ModTime()
+			st.Parsed[name] = prev
+			save()
+			continue
+		}
+
+		r := parsePDFFile(pdf)
+		switch {
+		case post.process(&r, filepath.Dir(pdf)):
+			err = writeResults(r, jsonOut, "", tableOptions{})
+		case r.failed:
+			err = fmt.Errorf("could not read PDF")
+		default:
+			err = fmt.Errorf("%s; not written (--strict)", strictFailure(r))
+		}
+		st.record("parse", name, err)
+		if err == nil {
+			st.Parsed[name] = syncParse{
+				SHA256:  prov.SHA256,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Output:  filepath.Base(jsonOut),
+				At:      time.Now().UTC().Truncate(time.Second),
+			}
+			n++
+			save()
+			notifyWebhooks(st, hooks, buildWebhookPayload(r, ok))
+		}
+		save()
+	}
+	return n
+}
+
+// webhookConfig is where sync announces parsed reports.
+type webhookConfig struct {
+	urls   []string
+	secret string
+}
+
+// notifyWebhooks sends p to every configured URL, queueing failed
+// deliveries in the state for the next run.
+func notifyWebhooks(st *syncState, hooks webhookConfig, p webhookPayload) {
+	if len(hooks.urls) == 0 {
+		return
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	for _, u := range hooks.urls {
+		err := deliverWebhook(u, body, hooks.secret)
+		st.record("webhook", u+" "+p.Period, err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "webhook %s: %v (will retry on the next sync)\n", u, err)
+			st.PendingWebhooks = append(st.PendingWebhooks, syncWebhook{URL: u, Period: p.Period, Payload: body, Queued: time.Now().UTC().Truncate(time.Second)})
+		}
+	}
+}
+
+// retryWebhooks resends deliveries that failed on an earlier run, keeping
+// those that fail again. Deliveries to URLs no longer in hooks, or queued
+// more than webhookMaxAge ago, are dropped instead.
+func retryWebhooks(st *syncState, save func(), hooks webhookConfig) {
+	if len(st.PendingWebhooks) == 0 {
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	var still []syncWebhook
+	for _, w := range st.PendingWebhooks {
+		item := w.URL + " " + w.Period
+		if !contains(hooks.urls, w.URL) {
+			fmt.Fprintf(os.Stderr, "webhook %s: no longer configured; dropping the %s delivery\n", w.URL, w.Period)
+			st.record("webhook", item, nil)
+			continue
+		}
+		if w.Queued.IsZero() {
+			// Queued by a version that didn't record when.
+			w.Queued = now
+		}
+		if now.Sub(w.Queued) > webhookMaxAge {
+			fmt.Fprintf(os.Stderr, "webhook %s: dropping the %s delivery, queued %s\n", w.URL, w.Period, w.Queued.Format(time.DateOnly))
+			st.record("webhook", item, nil)
+			continue
+		}
+		err := deliverWebhook(w.URL, w.Payload, hooks.secret)
+		st.record("webhook", item, err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "webhook %s: %v\n", w.URL, err)
+			still = append(still, w)
+		}
+	}
+	st.PendingWebhooks = still
+	save()
+}
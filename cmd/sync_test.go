@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncParses_Resume(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile("../parser/testdata/page.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf := filepath.Join(dir, "municipal-courts-2024-06.pdf")
+	if err := os.WriteFile(pdf, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	statePath := filepath.Join(dir, syncStateName)
+	st, err := loadSyncState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func() {
+		if err := st.save(statePath); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := syncParses(st, save, dir, postParse{duplicates: "later"}, webhookConfig{}); n != 1 {
+		t.Fatalf("first run parsed %d PDFs, want 1", n)
+	}
+
+	// A fresh run from the saved state has nothing to do, even after the
+	// PDF is touched without being changed.
+	st, err = loadSyncState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(pdf, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if n := syncParses(st, save, dir, postParse{duplicates: "later"}, webhookConfig{}); n != 0 {
+		t.Errorf("resumed run parsed %d PDFs, want 0", n)
+	}
+	if len(st.Failures) != 0 {
+		t.Errorf("failures = %v", st.Failures)
+	}
+
+	// Losing the output means parsing again.
+	os.Remove(filepath.Join(dir, "municipal-courts-2024-06.json"))
+	if n := syncParses(st, save, dir, postParse{duplicates: "later"}, webhookConfig{}); n != 1 {
+		t.Errorf("after removing the output, parsed %d PDFs, want 1", n)
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// tableOptions controls how records are flattened into CSV and export rows.
+type tableOptions struct {
+	cleanNumbers bool             // see cleanNumber
+	sections     []string         // recordRow.section values to keep; nil keeps all
+	rows         []string         // recordRow.row values to keep; nil keeps all
+	split        bool             // one table per section; see writeSectionTables
+	population   *populationTable // adds a Population column; see exportRecords
+}
+
+// recordRow is one report row as flattened into CSV columns: a label column
+// followed by one column per case type.
+type recordRow struct {
+	section string // --sections name
+	row     string // --rows name: prior, current or change
+	prefix  string // column name prefix
+	get     func(s *parser.MunicipalityStats) *parser.RowData
+}
+
+var recordRows = []recordRow{
+	{"filings", "prior", "Filings_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Filings.PriorPeriod }},
+	{"filings", "current", "Filings_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Filings.CurrentPeriod }},
+	{"filings", "change", "Filings_PctChange", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Filings.PctChange }},
+	{"resolutions", "prior", "Resolutions_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Resolutions.PriorPeriod }},
+	{"resolutions", "current", "Resolutions_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Resolutions.CurrentPeriod }},
+	{"resolutions", "change", "Resolutions_PctChange", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Resolutions.PctChange }},
+	{"clearance", "prior", "Clearance_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Clearance.PriorPeriod }},
+	{"clearance", "current", "Clearance_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Clearance.CurrentPeriod }},
+	{"clearance-pct", "prior", "ClearancePct_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.ClearancePct.PriorPeriod }},
+	{"clearance-pct", "current", "ClearancePct_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.ClearancePct.CurrentPeriod }},
+	{"backlog", "prior", "Backlog_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Backlog.PriorPeriod }},
+	{"backlog", "current", "Backlog_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Backlog.CurrentPeriod }},
+	{"backlog", "change", "Backlog_PctChange", func(s *parser.MunicipalityStats) *parser.RowData { return &s.Backlog.PctChange }},
+	{"backlog-per-100", "prior", "BacklogPer100_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.BacklogPer100.PriorPeriod }},
+	{"backlog-per-100", "current", "BacklogPer100_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.BacklogPer100.CurrentPeriod }},
+	{"backlog-per-100", "change", "BacklogPer100_PctChange", func(s *parser.MunicipalityStats) *parser.RowData { return &s.BacklogPer100.PctChange }},
+	{"backlog-pct", "prior", "BacklogPct_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.BacklogPct.PriorPeriod }},
+	{"backlog-pct", "current", "BacklogPct_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.BacklogPct.CurrentPeriod }},
+	{"active-pending", "prior", "ActivePending_Prior", func(s *parser.MunicipalityStats) *parser.RowData { return &s.ActivePending.PriorPeriod }},
+	{"active-pending", "current", "ActivePending_Current", func(s *parser.MunicipalityStats) *parser.RowData { return &s.ActivePending.CurrentPeriod }},
+	{"active-pending", "change", "ActivePending_PctChange", func(s *parser.MunicipalityStats) *parser.RowData { return &s.ActivePending.PctChange }},
+}
+
+// tableSections and tableRowKinds are the valid --sections and --rows values.
+var (
+	tableSections = []string{
+		"filings", "resolutions", "clearance", "clearance-pct",
+		"backlog", "backlog-per-100", "backlog-pct", "active-pending",
+	}
+	tableRowKinds = []string{"prior", "current", "change"}
+)
+
+// caseTypeColumns names the per-case-type columns of a row, in RowData order.
+var caseTypeColumns = []string{"Indictables", "DPAndPDP", "OtherCriminal", "CriminalTotal",
+	"DWI", "TrafficMoving", "Parking", "TrafficTotal", "GrandTotal"}
+
+// parseTableOptions builds tableOptions from the comma-separated --sections
+// and --rows flags, rejecting unknown names.
+func parseTableOptions(cleanNumbers, split bool, sections, rows string) (tableOptions, error) {
+	opts := tableOptions{cleanNumbers: cleanNumbers, split: split}
+	var err error
+	if opts.sections, err = splitChoices("--sections", sections, tableSections); err != nil {
+		return opts, err
+	}
+	if opts.rows, err = splitChoices("--rows", rows, tableRowKinds); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func splitChoices(flagName, value string, valid []string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(strings.ToLower(v))
+		if !contains(valid, v) {
+			return nil, fmt.Errorf("invalid %s value %q; valid options: %s", flagName, v, strings.Join(valid, ", "))
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// selectedRows returns the recordRows kept by opts, in their fixed order.
+func (opts tableOptions) selectedRows() []recordRow {
+	var out []recordRow
+	for _, r := range recordRows {
+		if opts.sections != nil && !contains(opts.sections, r.section) {
+			continue
+		}
+		if opts.rows != nil && !contains(opts.rows, r.row) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// recordColumns returns the flat column names used for a record in CSV and
+// export output: one column per section row and case type, then the
+// court's stable ID, trailing so the earlier columns keep their positions.
+func recordColumns(opts tableOptions) []string {
+	header := []string{"County", "Municipality", "DateRange"}
+	for _, r := range opts.selectedRows() {
+		header = append(header, r.prefix+"_Label")
+		for _, col := range caseTypeColumns {
+			header = append(header, r.prefix+"_"+col)
+		}
+	}
+	return append(header, "EntityID")
+}
+
+// recordValues flattens s in recordColumns order.
+func recordValues(s parser.MunicipalityStats, opts tableOptions) []string {
+	row := []string{s.County, s.Municipality, s.DateRange}
+	for _, rr := range opts.selectedRows() {
+		r := *rr.get(&s)
+		row = append(append(row, r.Label), caseTypeValues(r, opts)...)
+	}
+	return append(row, entityID(s))
+}
+
+// caseTypeValues returns r's values in caseTypeColumns order.
+func caseTypeValues(r parser.RowData, opts tableOptions) []string {
+	vals := []string{r.Indictables, r.DPAndPDP, r.OtherCriminal,
+		r.CriminalTotal, r.DWI, r.TrafficMoving, r.Parking, r.TrafficTotal, r.GrandTotal}
+	if opts.cleanNumbers {
+		for i, v := range vals {
+			vals[i] = cleanNumber(v)
+		}
+	}
+	return vals
+}
+
+// sectionColumns returns the header of a per-section table, with the
+// court's stable ID last as in recordColumns.
+func sectionColumns() []string {
+	header := append([]string{"County", "Municipality", "Date", "Period", "Label"}, caseTypeColumns...)
+	return append(header, "EntityID")
+}
+
+// writeSectionTables writes one tidy table per selected section instead of
+// the combined one: a row per municipality, report date and section row
+// (prior, current or change), then the case-type columns. Each table is
+// opened with open at pathFor(section). It returns the paths written.
+func writeSectionTables(open func(path string) (exportWriter, error), pathFor func(section string) string, records []timeRecord, opts tableOptions) ([]string, error) {
+	var written []string
+	for _, section := range tableSections {
+		var rows []recordRow
+		for _, r := range opts.selectedRows() {
+			if r.section == section {
+				rows = append(rows, r)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		path := pathFor(section)
+		w, err := open(path)
+		if err != nil {
+			return written, err
+		}
+		err = w.WriteHeader(sectionColumns())
+		for _, rec := range records {
+			for _, s := range rec.stats {
+				for _, rr := range rows {
+					if err != nil {
+						break
+					}
+					r := *rr.get(&s)
+					row := append([]string{s.County, s.Municipality, rec.date, rr.row, r.Label}, caseTypeValues(r, opts)...)
+					err = w.WriteRow(append(row, entityID(s)))
+				}
+			}
+		}
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// cleanNumber rewrites a report value as a plain number that spreadsheets
+// and loaders read as numeric: "1,749" becomes "1749", "98.1%" becomes
+// "0.981", and "- -" (no data) becomes empty. Anything else that doesn't
+// parse is returned unchanged.
+func cleanNumber(s string) string {
+	s = strings.TrimSpace(s)
+	n := strings.ReplaceAll(s, ",", "")
+	pct := strings.HasSuffix(n, "%")
+	n = strings.TrimSpace(strings.TrimSuffix(n, "%"))
+	// The no-data marker is sometimes split across cells, leaving a lone
+	// "-" or "%".
+	if n == "" || n == "- -" || n == "--" || n == "-" {
+		return ""
+	}
+	v, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return s
+	}
+	if !pct {
+		return n
+	}
+	// Keep the input's precision, shifted two places, so 98.1% doesn't come
+	// out as 0.9809999999999999.
+	decimals := 0
+	if i := strings.IndexByte(n, '.'); i >= 0 {
+		decimals = len(n) - i - 1
+	}
+	out := strconv.FormatFloat(v/100, 'f', decimals+2, 64)
+	if strings.Contains(out, ".") {
+		out = strings.TrimRight(strings.TrimRight(out, "0"), ".")
+	}
+	return out
+}
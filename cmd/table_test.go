@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestCleanNumber(t *testing.T) {
+	tests := map[string]string{
+		"1,749":  "1749",
+		" 152 ":  "152",
+		"98.1%":  "0.981",
+		"-13%":   "-0.13",
+		"100%":   "1",
+		"0.5%":   "0.005",
+		"- -":    "",
+		"-":      "",
+		"%":      "",
+		"":       "",
+		"Jul 04": "Jul 04",
+	}
+	for in, want := range tests {
+		if got := cleanNumber(in); got != want {
+			t.Errorf("cleanNumber(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTableOptions_Selection(t *testing.T) {
+	opts, err := parseTableOptions(true, false, "filings,Backlog", "current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols := recordColumns(opts)
+	if len(cols) != 3+2*10+1 {
+		t.Fatalf("got %d columns, want 24: %v", len(cols), cols)
+	}
+	if cols[3] != "Filings_Current_Label" || cols[13] != "Backlog_Current_Label" || cols[23] != "EntityID" {
+		t.Errorf("columns = %v", cols)
+	}
+
+	s := rateStat("ATLANTIC", "ABSECON", "1,749", "", "")
+	s.Backlog.CurrentPeriod.GrandTotal = "- -"
+	vals := recordValues(s, opts)
+	if len(vals) != len(cols) {
+		t.Fatalf("got %d values for %d columns", len(vals), len(cols))
+	}
+	if vals[12] != "1749" || vals[22] != "" || vals[23] != "atlantic.absecon" {
+		t.Errorf("filings = %q, backlog = %q, id = %q", vals[12], vals[22], vals[23])
+	}
+
+	if all := recordColumns(tableOptions{}); len(all) != 214 {
+		t.Errorf("default columns = %d, want 214", len(all))
+	}
+	if _, err := parseTableOptions(false, false, "", "latest"); err == nil {
+		t.Error("expected an error for an unknown --rows value")
+	}
+}
+
+func TestWriteSectionTables(t *testing.T) {
+	dir := t.TempDir()
+	s := rateStat("ATLANTIC", "ABSECON", "1,749", "1,700", "97%")
+	s.Filings.PriorPeriod.GrandTotal = "1,969"
+	records := []timeRecord{{date: "2005-06", stats: []parser.MunicipalityStats{s}}}
+
+	opts, _ := parseTableOptions(false, true, "filings,clearance-pct", "prior,current")
+	pathFor := func(section string) string { return filepath.Join(dir, section+".csv") }
+	written, err := writeSectionTables(newCSVExport, pathFor, records, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("wrote %v, want filings and clearance-pct", written)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "filings.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want header + prior + current", len(rows))
+	}
+	want := []string{"ATLANTIC", "ABSECON", "2005-06", "prior"}
+	for i, w := range want {
+		if rows[1][i] != w {
+			t.Errorf("rows[1][%d] = %q, want %q", i, rows[1][i], w)
+		}
+	}
+	last := len(rows[2]) - 1
+	if rows[0][last] != "EntityID" || rows[2][last] != "atlantic.absecon" {
+		t.Errorf("last column = %q: %q, want EntityID: atlantic.absecon", rows[0][last], rows[2][last])
+	}
+	if got := rows[2][last-1]; got != "1,749" {
+		t.Errorf("current GrandTotal = %q, want 1,749", got)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsFlags configures HTTPS for web and api: certificates obtained
+// automatically from Let's Encrypt for --tls-domain, or read from --cert
+// and --key.
+type tlsFlags struct {
+	domains  *stringList
+	email    *string
+	cacheDir *string
+	cert     *string
+	key      *string
+	httpAddr *string
+}
+
+// addTLSFlags registers the TLS flags. Call config after parsing and serve
+// once the handler is ready.
+func addTLSFlags(fs *flag.FlagSet) tlsFlags {
+	t := tlsFlags{domains: new(stringList)}
+	fs.Var(t.domains, "tls-domain", "serve HTTPS for this domain with a certificate from Let's Encrypt (repeatable)")
+	t.email = fs.String("tls-email", "", "contact address given to Let's Encrypt for expiry and policy notices")
+	t.cacheDir = fs.String("tls-cache", defaultTLSCacheDir(), "directory keeping --tls-domain certificates and the account key")
+	t.cert = fs.String("cert", "", "serve HTTPS with this PEM certificate file (with --key)")
+	t.key = fs.String("key", "", "PEM private key file for --cert")
+	t.httpAddr = fs.String("http-addr", ":80", "with HTTPS, also listen here to redirect http:// to https:// and answer Let's Encrypt challenges (\"\" to skip)")
+	return t
+}
+
+// defaultTLSCacheDir is autocert in the user cache directory.
+func defaultTLSCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "autocert"
+	}
+	return filepath.Join(dir, "municourt", "autocert")
+}
+
+// enabled reports whether HTTPS was asked for.
+func (t tlsFlags) enabled() bool {
+	return len(*t.domains) > 0 || *t.cert != "" || *t.key != ""
+}
+
+// config checks the flags and builds the TLS configuration, nil if HTTPS
+// wasn't asked for, and the handler for the plain HTTP listener: autocert's,
+// which answers HTTP-01 challenges, or a redirect.
+func (t tlsFlags) config() (*tls.Config, http.Handler, error) {
+	switch {
+	case !t.enabled():
+		return nil, nil, nil
+	case len(*t.domains) > 0 && (*t.cert != "" || *t.key != ""):
+		return nil, nil, fmt.Errorf("--tls-domain can't be combined with --cert and --key")
+	case len(*t.domains) > 0:
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(*t.domains...),
+			Cache:      autocert.DirCache(*t.cacheDir),
+			Email:      *t.email,
+		}
+		return m.TLSConfig(), m.HTTPHandler(nil), nil
+	case *t.cert == "" || *t.key == "":
+		return nil, nil, fmt.Errorf("--cert and --key must be given together")
+	}
+	cert, err := tls.LoadX509KeyPair(*t.cert, *t.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading --cert and --key: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	return cfg, http.HandlerFunc(redirectToHTTPS), nil
+}
+
+// redirectToHTTPS sends a plain HTTP request to the same URL over HTTPS,
+// on the default port.
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
+// serve runs srv over HTTPS with cfg and plain from config, or over plain
+// HTTP if cfg is nil. A failure to start the plain listener (port 80 often
+// needs privileges) is only a warning: autocert can still validate the
+// domain over the TLS port itself.
+func (t tlsFlags) serve(srv *http.Server, cfg *tls.Config, plain http.Handler) error {
+	if cfg == nil {
+		return srv.ListenAndServe()
+	}
+	srv.TLSConfig = cfg
+	if *t.httpAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(*t.httpAddr, plain); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: not redirecting HTTP on %s: %v\n", *t.httpAddr, err)
+			}
+		}()
+	}
+	return srv.ListenAndServeTLS("", "")
+}
+
+// defaultTLSAddr moves a listen address still at its default to :443 when
+// serving HTTPS, leaving one given explicitly (set) alone.
+func defaultTLSAddr(addr string, set bool, cfg *tls.Config) string {
+	if cfg == nil || set {
+		return addr
+	}
+	return ":443"
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// serveURL is the URL to print for a server listening on addr.
+func serveURL(addr string, t tlsFlags, cfg *tls.Config) string {
+	scheme, host := "http", "localhost"
+	if cfg != nil {
+		scheme = "https"
+		if len(*t.domains) > 0 {
+			host = (*t.domains)[0]
+		}
+	}
+	if h, port, err := net.SplitHostPort(addr); err == nil {
+		if h != "" {
+			host = h
+		}
+		if !(scheme == "https" && port == "443") && !(scheme == "http" && port == "80") {
+			host = net.JoinHostPort(host, port)
+		}
+	}
+	return scheme + "://" + host
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeSelfSigned(t, certFile, keyFile)
+
+	parse := func(args ...string) tlsFlags {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		tf := addTLSFlags(fs)
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return tf
+	}
+
+	if cfg, _, err := parse().config(); cfg != nil || err != nil {
+		t.Errorf("no flags: %v %v", cfg, err)
+	}
+	for _, args := range [][]string{
+		{"--cert", certFile},
+		{"--tls-domain", "stats.example.org", "--cert", certFile, "--key", keyFile},
+		{"--cert", keyFile, "--key", certFile},
+	} {
+		if _, _, err := parse(args...).config(); err == nil {
+			t.Errorf("%v: no error", args)
+		}
+	}
+
+	cfg, plain, err := parse("--cert", certFile, "--key", keyFile).config()
+	if err != nil || len(cfg.Certificates) != 1 {
+		t.Fatalf("--cert and --key: %v %v", cfg, err)
+	}
+	w := httptest.NewRecorder()
+	plain.ServeHTTP(w, httptest.NewRequest("GET", "http://stats.example.org:80/api/series?level=state", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "https://stats.example.org/api/series?level=state" {
+		t.Errorf("redirect: %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	tf := parse("--tls-domain", "stats.example.org", "--tls-cache", dir)
+	if cfg, _, err = tf.config(); err != nil || cfg.GetCertificate == nil {
+		t.Fatalf("--tls-domain: %v %v", cfg, err)
+	}
+	if got := defaultTLSAddr(":8080", false, cfg); got != ":443" {
+		t.Errorf("default address with TLS: %q", got)
+	}
+	if got := defaultTLSAddr(":8443", true, cfg); got != ":8443" {
+		t.Errorf("explicit address with TLS: %q", got)
+	}
+	if got := serveURL(":443", tf, cfg); got != "https://stats.example.org" {
+		t.Errorf("serveURL: %q", got)
+	}
+	if got := serveURL(":8080", parse(), nil); got != "http://localhost:8080" {
+		t.Errorf("serveURL without TLS: %q", got)
+	}
+}
+
+func writeSelfSigned(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "stats.example.org"},
+		DNSNames:     []string{"stats.example.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
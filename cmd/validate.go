@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Validate implements the "validate" subcommand: check every record in a
+// parsed directory against sanity-check rules, exiting 1 if any rule of
+// severity error is broken.
+func Validate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory containing parsed JSON files")
+	rulesPath := fs.String("rules", "", "JSON rules file (default the built-in rules)")
+	format := fs.String("format", "table", "output format: table, csv, json")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt validate [dir] [--rules rules.json] [--format table|csv|json]\n\nCheck parsed values against sanity-check rules.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		*dir = fs.Arg(0)
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q; valid options: table, csv, json\n", *format)
+		os.Exit(1)
+	}
+	rules, err := rulesFrom(*rulesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading --rules: %v\n", err)
+		os.Exit(1)
+	}
+
+	records, err := loadRecords(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", *dir)
+		os.Exit(1)
+	}
+
+	violations := []ruleViolation{}
+	nRecords := 0
+	for _, rec := range records {
+		nRecords += len(rec.stats)
+		violations = append(violations, checkRules(rec.stats, rec.date, rules)...)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(violations)
+	case "csv":
+		err = writeViolationsCSV(os.Stdout, violations)
+	default:
+		for _, v := range violations {
+			fmt.Printf("%s  %-7s %s: %s: %s\n", v.Period, v.Severity, v.where(), v.Rule, v.Message)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+
+	errs := 0
+	for _, v := range violations {
+		if v.Severity == "error" {
+			errs++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d rules, %d records in %d periods: %d errors, %d warnings\n",
+		len(rules), nRecords, len(records), errs, len(violations)-errs)
+	if errs > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeViolationsCSV(out io.Writer, violations []ruleViolation) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"Period", "County", "Municipality", "Page", "Severity", "Rule", "Message"})
+	for _, v := range violations {
+		page := ""
+		if v.Page > 0 {
+			page = strconv.Itoa(v.Page)
+		}
+		w.Write([]string{v.Period, v.County, v.Municipality, page, v.Severity, v.Rule, v.Message})
+	}
+	w.Flush()
+	return w.Error()
+}
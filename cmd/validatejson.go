@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+// ValidateJSON implements the "validate-json" subcommand: check output
+// files, however they were produced, against the published JSON Schema,
+// exiting 1 if any doesn't conform.
+func ValidateJSON(args []string) {
+	fs := flag.NewFlagSet("validate-json", flag.ExitOnError)
+	printSchema := fs.Bool("schema", false, "print the JSON Schema and exit")
+	maxErrors := fs.Int("max-errors", 20, "errors to list per file (0 for all)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: municourt validate-json <dir | file.json ...> [--max-errors 20]\n       municourt validate-json --schema\n\nCheck JSON output files against the output format's JSON Schema.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	args = reorderArgs(args)
+	fs.Parse(args)
+
+	if *printSchema {
+		os.Stdout.Write(parser.Schema)
+		return
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var paths []string
+	for _, arg := range fs.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*.json"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error globbing directory: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range matches {
+			if isOutputJSON(path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "no JSON files found in %s\n", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	invalid := 0
+	for _, path := range paths {
+		name := filepath.Base(path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("%s: %v\n", name, err)
+			invalid++
+			continue
+		}
+		errs, err := parser.ValidateSchema(data)
+		if err != nil {
+			fmt.Printf("%s: not valid JSON: %v\n", name, err)
+			invalid++
+			continue
+		}
+		if len(errs) == 0 {
+			continue
+		}
+		invalid++
+		for i, e := range errs {
+			if *maxErrors > 0 && i == *maxErrors {
+				fmt.Printf("%s: ... and %d more\n", name, len(errs)-i)
+				break
+			}
+			fmt.Printf("%s: %s\n", name, e)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d of %d files valid\n", len(paths)-invalid, len(paths))
+	if invalid > 0 {
+		os.Exit(1)
+	}
+}
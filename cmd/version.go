@@ -0,0 +1,28 @@
+package cmd
+
+import "runtime/debug"
+
+// Version is the municourt release, set at build time with
+// -ldflags "-X github.com/zalepa/municourt/cmd.Version=v1.2.3".
+var Version = ""
+
+// version returns Version, falling back to the module version or VCS
+// revision recorded by the Go toolchain.
+func version() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return "devel-" + s.Value
+		}
+	}
+	return "devel"
+}
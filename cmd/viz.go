@@ -1,16 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/zalepa/municourt/parser"
 )
@@ -30,15 +31,46 @@ var validMetrics = []string{
 	"backlog", "backlog-per-100", "backlog-pct", "active-pending",
 }
 
+// changeSections lists the sections whose reports include a % Change row,
+// selectable with --section when --metric is reported-change.
+var changeSections = []string{
+	"filings", "resolutions", "backlog", "backlog-per-100", "active-pending",
+}
+
 var validTypes = []string{
 	"grand-total", "indictables", "dp-pdp", "other-criminal",
 	"criminal-total", "dwi", "traffic-moving", "parking", "traffic-total",
 }
 
 var rateMetrics = map[string]bool{
-	"clearance-pct": true,
-	"backlog-pct":   true,
-	"backlog-per-100": true,
+	"clearance-pct":          true,
+	"backlog-pct":            true,
+	"backlog-per-100":        true,
+	"filings-change":         true,
+	"resolutions-change":     true,
+	"backlog-change":         true,
+	"backlog-per-100-change": true,
+	"active-pending-change":  true,
+}
+
+// checkScope reports an error if the flags choosing what to chart
+// conflict. --statewide only charts just the statewide series and a
+// distribution chart every municipality in scope, so each sets the level
+// itself and can't be given a different --level (levelSet is whether it
+// was given); --statewide only also leaves nothing for --county or
+// --municipality to narrow.
+func checkScope(chart, level string, levelSet bool, statewide, county, municipality string) error {
+	switch {
+	case statewide == "only" && distributionCharts[chart]:
+		return fmt.Errorf("--chart %s charts municipalities; it can't be combined with --statewide only", chart)
+	case statewide == "only" && levelSet && level != "state":
+		return fmt.Errorf("--statewide only charts the statewide series; it can't be combined with --level %s", level)
+	case statewide == "only" && (county != "" || municipality != ""):
+		return fmt.Errorf("--statewide only charts the statewide series; drop --county and --municipality")
+	case distributionCharts[chart] && levelSet && level != "municipality":
+		return fmt.Errorf("--chart %s charts every municipality in scope; drop --level %s (--county narrows the scope)", chart, level)
+	}
+	return nil
 }
 
 // Viz implements the "viz" subcommand.
@@ -47,10 +79,42 @@ func Viz(args []string) {
 	dir := fs.String("dir", ".", "directory containing parsed JSON files")
 	level := fs.String("level", "county", "aggregation level: state, county, municipality")
 	metric := fs.String("metric", "filings", "metric to display")
+	section := fs.String("section", "filings", "section whose % Change row is read by --metric reported-change")
 	caseType := fs.String("type", "grand-total", "case type column")
 	county := fs.String("county", "", "county filter")
 	municipality := fs.String("municipality", "", "municipality filter")
 	pdfOut := fs.String("pdf", "", "output PDF file path (omit for terminal output)")
+	htmlOut := fs.String("html", "", "output a self-contained interactive HTML chart to this path")
+	pngOut := fs.String("png", "", "output a PNG image of the chart to this path")
+	pageName := fs.String("page", "letter", "PDF page size: letter, a4, legal")
+	landscape := fs.Bool("landscape", false, "lay PDF pages out in landscape orientation")
+	fontPath := fs.String("font", "", "TrueType font file to embed for PDF text (default Liberation Serif)")
+	reportTitle := fs.String("report-title", "", "PDF report heading shown on the first page")
+	subtitle := fs.String("subtitle", "", "PDF report subtitle under the heading")
+	author := fs.String("author", "", "PDF author or organization line under the heading")
+	footer := fs.String("footer", "", "text printed at the bottom of every PDF page")
+	logoPath := fs.String("logo", "", "PNG or JPEG image drawn at the top right of the first PDF page")
+	statewide := fs.String("statewide", "include", "statewide row: include, exclude, only")
+	weighted := fs.Bool("weighted", false, "compute rate metrics as ratios of summed components instead of averaging")
+	normalize := fs.Bool("normalize", false, "index each line on the PDF overview page to 100 at its first period")
+	vs := fs.String("vs-state", "none", "chart each value against the statewide average for its period: none, diff (difference), ratio")
+	indexBase := fs.String("index", "", "rescale every series to 100 at this period (e.g. 2019-06, or 2019 with --interval year)")
+	continuous := fs.Bool("continuous", false, "fold renamed or merged municipalities into their successor's series")
+	interval := fs.String("interval", "month", "roll report periods up to: month (no rollup), quarter, year")
+	values := fs.String("values", "cumulative", "filings, resolutions and clearance as reported (cumulative from July) or per month: "+strings.Join(validValues, ", "))
+	courtYear := fs.Bool("court-year", false, "make quarters and years follow the July–June court year (labels like CY2023-24)")
+	chart := fs.String("chart", "line", "chart: line, braille (terminal line chart in 2×4 dots per cell), box (distribution across municipalities per period), hist (histogram across municipalities for one period), scatter (one metric against another across municipalities)")
+	date := fs.String("date", "", "with --chart hist or scatter: period to chart, YYYY-MM or an --interval label (default newest)")
+	bins := fs.Int("bins", 10, "with --chart hist: most bins to sort municipalities into")
+	xMetric := fs.String("x", "", "with --chart scatter: metric on the x axis")
+	yMetric := fs.String("y", "", "with --chart scatter: metric on the y axis")
+	colorBy := fs.String("color-by", "none", "with --chart scatter: color points by none or county")
+	labels := fs.Int("labels", 10, "with --chart scatter: most outliers to label by name")
+	width := fs.Int("width", 100, "terminal chart width in characters, including the y-axis labels")
+	height := fs.Int("height", 15, "terminal chart height in rows, not counting the x axis")
+	downsample := fs.String("downsample", "auto", "average sparkline values into buckets: auto (only when they don't fit), none, quarterly, yearly, or N periods")
+	ascii := addASCIIFlag(fs)
+	color := addColorFlags(fs)
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: municourt viz [dir] [flags]
@@ -61,15 +125,34 @@ Flags:
 `)
 		fs.PrintDefaults()
 		fmt.Fprintf(os.Stderr, `
-Metrics: %s
+Metrics: %s, reported-change
+Sections (for reported-change): %s
 Types:   %s
 
 Examples:
   municourt viz ./parsed --level state --metric filings
   municourt viz ./parsed --level county --pdf county.pdf
+  municourt viz ./parsed --level county --pdf county.pdf --page a4 --landscape
+  municourt viz ./parsed --level county --html county.html
+  municourt viz ./parsed --pdf county.pdf --report-title "Court Backlog Review" --author "Office of Research" --logo seal.png --footer "Draft"
   municourt viz --dir ./parsed --level county --county ATLANTIC
   municourt viz --dir ./parsed --level municipality --county ATLANTIC
-`, strings.Join(validMetrics, ", "), strings.Join(validTypes, ", "))
+  municourt viz ./parsed --metric clearance-pct --statewide only --weighted
+  municourt viz ./parsed --metric reported-change --section backlog
+  municourt viz ./parsed --level county --metric clearance-pct --weighted --vs-state diff
+  municourt viz ./parsed --level municipality --county ESSEX --index 2019-06 --pdf essex.pdf
+  municourt viz ./parsed --level municipality --county MERCER --continuous
+  municourt viz ./parsed --level municipality --county MERCER --municipality TRENTON --chart braille
+  municourt viz ./parsed --metric backlog --chart box --interval year
+  municourt viz ./parsed --metric clearance-pct --chart box --county ESSEX --pdf essex-box.pdf
+  municourt viz ./parsed --metric backlog --chart hist --date 2024-06 --bins 20
+  municourt viz ./parsed --chart scatter --x filings --y backlog-per-100 --date 2024-06 --color-by county --png scatter.png
+  municourt viz ./parsed --level state --width 72 --height 10 > filings.txt
+  municourt viz ./parsed --level county --downsample yearly
+  municourt viz ./parsed --level state --metric clearance-pct --interval year --weighted
+  municourt viz ./parsed --level county --interval year --court-year
+  municourt viz ./parsed --level state --values monthly --interval quarter
+`, strings.Join(validMetrics, ", "), strings.Join(changeSections, ", "), strings.Join(validTypes, ", "))
 	}
 	// Reorder args so the first positional arg (dir) comes after all flags.
 	// Go's flag package stops parsing at the first non-flag argument.
@@ -79,9 +162,17 @@ Examples:
 	if fs.NArg() > 0 {
 		*dir = fs.Arg(0)
 	}
+	useASCII(*ascii)
+	useColor(color)
 
-	if !contains(validMetrics, *metric) {
-		fmt.Fprintf(os.Stderr, "invalid --metric %q; valid options: %s\n", *metric, strings.Join(validMetrics, ", "))
+	if *metric == "reported-change" {
+		if !contains(changeSections, *section) {
+			fmt.Fprintf(os.Stderr, "invalid --section %q; valid options: %s\n", *section, strings.Join(changeSections, ", "))
+			os.Exit(1)
+		}
+		*metric = *section + "-change"
+	} else if !contains(validMetrics, *metric) {
+		fmt.Fprintf(os.Stderr, "invalid --metric %q; valid options: %s, reported-change\n", *metric, strings.Join(validMetrics, ", "))
 		os.Exit(1)
 	}
 	if !contains(validTypes, *caseType) {
@@ -92,11 +183,115 @@ Examples:
 		fmt.Fprintf(os.Stderr, "invalid --level %q; valid options: state, county, municipality\n", *level)
 		os.Exit(1)
 	}
+	if *statewide != "include" && *statewide != "exclude" && *statewide != "only" {
+		fmt.Fprintf(os.Stderr, "invalid --statewide %q; valid options: include, exclude, only\n", *statewide)
+		os.Exit(1)
+	}
+	if !contains(validCharts, *chart) {
+		fmt.Fprintf(os.Stderr, "invalid --chart %q; valid options: %s\n", *chart, strings.Join(validCharts, ", "))
+		os.Exit(1)
+	}
+	if err := checkScope(*chart, *level, flagSet(fs, "level"), *statewide, *county, *municipality); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if *statewide == "only" {
+		*level = "state"
+	}
+	if distributionCharts[*chart] {
+		if err := checkDistributionChart(*chart, *municipality, *vs, *indexBase, *normalize, *htmlOut); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		// Every municipality in scope is one value of the distribution.
+		*level = "municipality"
+	}
+	if *chart != "hist" && *chart != "scatter" && flagSet(fs, "date") {
+		fmt.Fprintf(os.Stderr, "--date only applies to --chart hist and scatter\n")
+		os.Exit(1)
+	}
+	if *chart != "hist" && flagSet(fs, "bins") {
+		fmt.Fprintf(os.Stderr, "--bins only applies to --chart hist\n")
+		os.Exit(1)
+	}
+	if *chart == "scatter" {
+		if flagSet(fs, "metric") || flagSet(fs, "section") {
+			fmt.Fprintf(os.Stderr, "--chart scatter takes --x and --y instead of --metric\n")
+			os.Exit(1)
+		}
+		for _, m := range []struct{ flag, value string }{{"--x", *xMetric}, {"--y", *yMetric}} {
+			if !contains(validMetrics, m.value) {
+				fmt.Fprintf(os.Stderr, "invalid %s %q; valid options: %s\n", m.flag, m.value, strings.Join(validMetrics, ", "))
+				os.Exit(1)
+			}
+		}
+		if *colorBy != "none" && *colorBy != "county" {
+			fmt.Fprintf(os.Stderr, "invalid --color-by %q; valid options: none, county\n", *colorBy)
+			os.Exit(1)
+		}
+		if *labels < 0 {
+			fmt.Fprintf(os.Stderr, "--labels can't be negative\n")
+			os.Exit(1)
+		}
+		// The y metric fills in for --metric in the title and series.
+		*metric = *yMetric
+	} else if flagSet(fs, "x") || flagSet(fs, "y") || flagSet(fs, "color-by") || flagSet(fs, "labels") {
+		fmt.Fprintf(os.Stderr, "--x, --y, --color-by and --labels only apply to --chart scatter\n")
+		os.Exit(1)
+	}
+	if *bins < 1 {
+		fmt.Fprintf(os.Stderr, "--bins must be at least 1\n")
+		os.Exit(1)
+	}
+	if !contains(validIntervals, *interval) {
+		fmt.Fprintf(os.Stderr, "invalid --interval %q; valid options: %s\n", *interval, strings.Join(validIntervals, ", "))
+		os.Exit(1)
+	}
+	*interval = withCourtYear(*interval, *courtYear)
+	if !contains(validValues, *values) {
+		fmt.Fprintf(os.Stderr, "invalid --values %q; valid options: %s\n", *values, strings.Join(validValues, ", "))
+		os.Exit(1)
+	}
+	if !contains(validVsState, *vs) {
+		fmt.Fprintf(os.Stderr, "invalid --vs-state %q; valid options: %s\n", *vs, strings.Join(validVsState, ", "))
+		os.Exit(1)
+	}
+	if *indexBase != "" && (*vs != "none" || *normalize) {
+		fmt.Fprintf(os.Stderr, "--index can't be combined with --vs-state or --normalize\n")
+		os.Exit(1)
+	}
+	if !validDownsample(*downsample) {
+		fmt.Fprintf(os.Stderr, "invalid --downsample %q; valid options: auto, none, quarterly, yearly, or a number of periods\n", *downsample)
+		os.Exit(1)
+	}
+	if *width < minChartWidth || *height < minChartHeight {
+		fmt.Fprintf(os.Stderr, "--width must be at least %d and --height at least %d\n", minChartWidth, minChartHeight)
+		os.Exit(1)
+	}
+	outputs := 0
+	for _, o := range []string{*pdfOut, *htmlOut, *pngOut} {
+		if o != "" {
+			outputs++
+		}
+	}
+	if outputs > 1 {
+		fmt.Fprintf(os.Stderr, "only one of --pdf, --html and --png can be given\n")
+		os.Exit(1)
+	}
+	page, ok := lookupPageSize(*pageName, *landscape)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "invalid --page %q; valid options: letter, a4, legal\n", *pageName)
+		os.Exit(1)
+	}
 
 	*county = strings.ToUpper(*county)
 	*municipality = strings.ToUpper(*municipality)
 
-	records, err := loadRecords(*dir)
+	metrics := []string{*metric}
+	if *chart == "scatter" {
+		metrics = append(metrics, *xMetric)
+	}
+	records, err := loadMetricRecords(*dir, metrics...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
 		os.Exit(1)
@@ -106,28 +301,171 @@ Examples:
 		os.Exit(1)
 	}
 
-	series, dates := buildSeries(records, *metric, *caseType, *level, *county, *municipality)
+	if *values == "monthly" {
+		records = monthlyRecords(records)
+	}
+
+	var notes map[string]string
+	if *continuous {
+		records, notes = foldHistory(records)
+	}
+
+	series, dates := intervalSeries(records, *metric, *caseType, *level, *county, *municipality, *weighted, *interval)
 	if len(series) == 0 {
 		fmt.Fprintf(os.Stderr, "no data matched the given filters\n")
 		os.Exit(1)
 	}
+	if *vs != "none" {
+		peers, _ := intervalSeries(records, *metric, *caseType, *level, "", "", *weighted, *interval)
+		state, _ := intervalSeries(records, *metric, *caseType, "state", "", "", *weighted, *interval)
+		series = vsState(series, stateAverage(peers, state["STATEWIDE"], rateMetrics[*metric]), *vs)
+	}
+	if *indexBase != "" {
+		if err := checkIndexBase(*indexBase, dates); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		var dropped []string
+		series, dropped = indexSeries(series, *indexBase)
+		if len(dropped) > 0 {
+			sort.Strings(dropped)
+			fmt.Fprintf(os.Stderr, "warning: no value to index at %s for %s\n", *indexBase, strings.Join(dropped, ", "))
+		}
+		if len(series) == 0 {
+			fmt.Fprintf(os.Stderr, "no series has a value at %s\n", *indexBase)
+			os.Exit(1)
+		}
+	}
+	if *level == "municipality" {
+		notes = markContinuity(series, notes)
+	} else {
+		notes = nil
+	}
 
-	title := metricLabel(*metric) + " — " + typeLabel(*caseType)
+	title := metricLabel(*metric)
+	if *chart == "scatter" {
+		title += " vs " + metricLabel(*xMetric)
+	}
+	title += glyphs.dash + typeLabel(*caseType)
+	switch *interval {
+	case "quarter":
+		title += " (quarterly)"
+	case "year":
+		title += " (yearly)"
+	case "court-quarter":
+		title += " (by court quarter)"
+	case "court-year":
+		title += " (by court year)"
+	}
+	title += vsStateLabel(*vs)
+	if *indexBase != "" {
+		title += " (index, " + *indexBase + " = 100)"
+	}
+
+	if distributionCharts[*chart] {
+		title += glyphs.dash + "across municipalities"
+		if *county != "" {
+			title += " in " + *county
+		}
+	}
+	var histVals []float64
+	var scatter scatterChart
+	if *chart == "hist" || *chart == "scatter" {
+		if *date == "" {
+			sorted := sortDates(dates)
+			*date = sorted[len(sorted)-1]
+		}
+		if err := checkChartDate(*date, dates); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		title += ", " + *date
+	}
+	if *chart == "hist" {
+		histVals = periodValueLists(series, []string{*date})[0]
+	}
+	if *chart == "scatter" {
+		xSeries, _ := intervalSeries(records, *xMetric, *caseType, *level, *county, "", *weighted, *interval)
+		scatter = scatterChart{
+			xLabel:   metricLabel(*xMetric),
+			yLabel:   metricLabel(*yMetric),
+			points:   buildScatter(xSeries, series, *date, entityCounties(records)),
+			byCounty: *colorBy == "county",
+		}
+		markScatterOutliers(scatter.points, *labels)
+	}
 
 	// Determine display mode: single entity → line chart, multiple → sparkline table.
-	singleEntity := false
-	switch *level {
-	case "state":
-		singleEntity = true
-	case "county":
-		singleEntity = *county != ""
-	case "municipality":
-		singleEntity = *municipality != ""
+	singleEntity := isSingleEntity(*level, *county, *municipality) && !distributionCharts[*chart]
+
+	var statewidePoints []dataPoint
+	if *statewide == "include" && *level == "county" && !singleEntity && len(series) > 1 && *vs == "none" && *chart == "line" {
+		state, _ := intervalSeries(records, *metric, *caseType, "state", "", "", *weighted, *interval)
+		statewidePoints = state["STATEWIDE"]
+		if *indexBase != "" {
+			statewidePoints, _ = indexPoints(statewidePoints, *indexBase)
+		}
+	}
+
+	if *htmlOut != "" {
+		chartTitle := title
+		if singleEntity {
+			for name := range series {
+				chartTitle += glyphs.dash + name
+			}
+		}
+		if err := renderHTML(*htmlOut, newHTMLChart(chartTitle, series, sortDates(dates), statewidePoints, notes)); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing HTML: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %s\n", *htmlOut)
+		return
+	}
+
+	if *pngOut != "" {
+		rep := pdfReport{title: title, series: series, sortedDates: sortDates(dates), singleEntity: singleEntity, chart: *chart, hist: buildHist(histVals, *bins), scatter: scatter, normalize: *normalize}
+		if err := renderPNG(*pngOut, rep); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing PNG: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %s\n", *pngOut)
+		return
 	}
 
 	if *pdfOut != "" {
-		sortedDates := sortDates(dates)
-		if err := renderPDF(*pdfOut, title, series, sortedDates, *level == "county", singleEntity); err != nil {
+		if *fontPath != "" {
+			if err := useFont(*fontPath); err != nil {
+				fmt.Fprintf(os.Stderr, "error loading font: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		brand := pdfBranding{title: *reportTitle, subtitle: *subtitle, author: *author, footer: *footer}
+		if *logoPath != "" {
+			logo, err := loadLogo(*logoPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error loading logo: %v\n", err)
+				os.Exit(1)
+			}
+			brand.logo = logo
+		}
+		rep := pdfReport{
+			title:           title,
+			series:          series,
+			sortedDates:     sortDates(dates),
+			statewidePoints: statewidePoints,
+			singleEntity:    singleEntity,
+			chart:           *chart,
+			hist:            buildHist(histVals, *bins),
+			scatter:         scatter,
+			overview:        *level == "county",
+			normalize:       *normalize,
+			notes:           notes,
+			page:            page,
+			brand:           brand,
+			downsample:      *downsample,
+			courtYear:       *courtYear,
+		}
+		if err := renderPDF(*pdfOut, rep); err != nil {
 			fmt.Fprintf(os.Stderr, "error writing PDF: %v\n", err)
 			os.Exit(1)
 		}
@@ -135,6 +473,18 @@ Examples:
 		return
 	}
 
+	if *chart == "box" {
+		renderBoxChart(title, buildBoxes(series, sortDates(dates)), *width)
+		return
+	}
+	if *chart == "hist" {
+		renderHistChart(title, buildHist(histVals, *bins), histVals, !rateMetrics[*metric], *width)
+		return
+	}
+	if *chart == "scatter" {
+		renderScatterSummary(title, scatter)
+		return
+	}
 	if singleEntity {
 		// Get the single entity name.
 		var name string
@@ -144,38 +494,224 @@ Examples:
 			points = v
 			break
 		}
-		renderChart(title+" — "+name, points)
+		// Braille patterns aren't ASCII, so --ascii keeps the plain chart.
+		if *chart == "braille" && !*ascii {
+			renderBrailleChart(title+glyphs.dash+name, points, *width, *height, nil)
+		} else {
+			renderChart(title+glyphs.dash+name, points, *width, *height, nil)
+		}
 	} else {
-		renderTable(title, series, dates, *level == "county")
+		renderTable(title, series, dates, statewidePoints, *width, *downsample, *courtYear)
+	}
+	printNotes(notes)
+}
+
+// isSingleEntity reports whether the filters select exactly one series, which
+// is drawn as a line chart rather than a table of sparklines.
+func isSingleEntity(level, county, municipality string) bool {
+	switch level {
+	case "state":
+		return true
+	case "county":
+		return county != ""
+	case "municipality":
+		return municipality != ""
+	}
+	return false
+}
+
+// statewideSeries computes the STATEWIDE row from the municipality records
+// rather than by summing the displayed series, so rate metrics come out as a
+// proper statewide average (or weighted ratio) instead of a sum of
+// percentages.
+func statewideSeries(records []timeRecord, metric, caseType string, weighted bool) []dataPoint {
+	series, _ := aggregateSeries(records, metric, caseType, "state", "", "", weighted)
+	return series["STATEWIDE"]
+}
+
+// foldHistory returns a copy of records in which predecessor municipalities
+// carry their successor's name, so aggregation combines them into one
+// continuous line. Renamed entities are folded in every period (the reports
+// often keep the old name for a while after the change); merged ones only
+// before the merger date. The returned notes describe each folded series,
+// keyed by successor name.
+func foldHistory(records []timeRecord) ([]timeRecord, map[string]string) {
+	type fold struct {
+		to, date string
+		rename   bool
+	}
+	folds := make(map[[2]string]fold)
+	for _, e := range parser.History {
+		for _, from := range e.From {
+			folds[[2]string{e.County, from}] = fold{to: e.To, date: e.Date, rename: e.Kind == "rename"}
+		}
+	}
+
+	folded := make(map[string]map[string]bool) // successor -> predecessors used
+	since := make(map[string]string)           // successor -> event date
+	out := make([]timeRecord, len(records))
+	for i, rec := range records {
+		out[i] = rec
+		copied := false
+		for j, s := range rec.stats {
+			f, ok := folds[[2]string{strings.ToUpper(s.County), strings.ToUpper(s.Municipality)}]
+			if !ok || (!f.rename && rec.date >= f.date) {
+				continue
+			}
+			if !copied {
+				out[i].stats = append([]parser.MunicipalityStats(nil), rec.stats...)
+				copied = true
+			}
+			if folded[f.to] == nil {
+				folded[f.to] = make(map[string]bool)
+			}
+			folded[f.to][strings.ToUpper(s.Municipality)] = true
+			since[f.to] = f.date
+			out[i].stats[j].Municipality = f.to
+			out[i].stats[j].ID = parser.EntityID(s.County, f.to)
+		}
+	}
+
+	notes := make(map[string]string, len(folded))
+	for to, froms := range folded {
+		names := make([]string, 0, len(froms))
+		for n := range froms {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		notes[to] = fmt.Sprintf("includes %s (changed %s)", strings.Join(names, ", "), since[to])
+	}
+	return out, notes
+}
+
+// markContinuity appends a "*" marker to the keys of folded series so they
+// stand out in tables and charts, and returns the notes keyed by the marked
+// names. Notes for series not present are dropped.
+func markContinuity(series map[string][]dataPoint, notes map[string]string) map[string]string {
+	marked := make(map[string]string)
+	for name, note := range notes {
+		pts, ok := series[name]
+		if !ok {
+			continue
+		}
+		delete(series, name)
+		series[name+" *"] = pts
+		marked[name+" *"] = note
+	}
+	return marked
+}
+
+func printNotes(notes map[string]string) {
+	if len(notes) == 0 {
+		return
+	}
+	names := make([]string, 0, len(notes))
+	for n := range notes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fmt.Println()
+	for _, n := range names {
+		fmt.Printf("%s: %s\n", n, notes[n])
 	}
 }
 
 var datePattern = regexp.MustCompile(`(\d{4})-(\d{2})`)
 
+// isOutputJSON reports whether path is a dated parse output file rather
+// than a CSV provenance sidecar or other JSON kept alongside.
+func isOutputJSON(path string) bool {
+	base := filepath.Base(path)
+	return datePattern.MatchString(base) && !strings.HasSuffix(base, ".meta.json")
+}
+
+// loadRecords reads every output file in dir. Files are decoded in
+// parallel and through the record cache, so only files that changed since
+// the last run are parsed again. dir may instead name a dataset file
+// written by merge.
 func loadRecords(dir string) ([]timeRecord, error) {
+	return loadRecordsWith(dir, nil)
+}
+
+// loadMetricRecords is loadRecords for callers that only read the given
+// metrics: each record keeps just the sections those metrics come from,
+// which cuts the memory a large corpus needs several times over.
+func loadMetricRecords(dir string, metrics ...string) ([]timeRecord, error) {
+	return loadRecordsWith(dir, func(s parser.MunicipalityStats) parser.MunicipalityStats {
+		return keepSections(s, metrics)
+	})
+}
+
+func loadRecordsWith(dir string, prune func(parser.MunicipalityStats) parser.MunicipalityStats) ([]timeRecord, error) {
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() && isDatasetFile(dir) {
+		return loadDatasetRecords(dir, prune)
+	}
 	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
 	if err != nil {
 		return nil, err
 	}
 
-	var records []timeRecord
+	type file struct {
+		path, date string
+		out        parser.Output
+		err        error
+	}
+	var files []*file
 	for _, path := range matches {
-		base := filepath.Base(path)
-		m := datePattern.FindStringSubmatch(base)
-		if m == nil {
+		m := datePattern.FindStringSubmatch(filepath.Base(path))
+		if m == nil || !isOutputJSON(path) {
 			continue
 		}
-		date := m[1] + "-" + m[2]
+		files = append(files, &file{path: path, date: m[1] + "-" + m[2]})
+	}
 
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("reading %s: %w", path, err)
+	cacheDir := recordCacheDir()
+	jobs := make(chan *file)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				f.out, f.err = decodeOutputFile(f.path, cacheDir)
+				if f.err == nil && prune != nil {
+					// Copy into a new slice so the full records can be freed.
+					kept := make([]parser.MunicipalityStats, len(f.out.Records))
+					for i, s := range f.out.Records {
+						kept[i] = prune(s)
+					}
+					f.out.Records = kept
+				}
+			}
+		}()
+	}
+	for _, f := range files {
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Of several files for one period, only the newest report is read.
+	periods := make(map[string]string, len(files))
+	outputs := make(map[string]parser.Output, len(files))
+	for _, f := range files {
+		if f.err != nil {
+			return nil, f.err
 		}
-		var stats []parser.MunicipalityStats
-		if err := json.Unmarshal(data, &stats); err != nil {
-			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		periods[filepath.Base(f.path)] = f.date
+		outputs[filepath.Base(f.path)] = f.out
+	}
+	for _, note := range dropSuperseded(periods, outputs) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", note)
+	}
+
+	// Normalize in file order so warnings come out in a stable order.
+	var records []timeRecord
+	for _, f := range files {
+		if _, ok := periods[filepath.Base(f.path)]; !ok {
+			continue
 		}
-		records = append(records, timeRecord{date: date, stats: stats})
+		records = append(records, timeRecord{date: f.date, stats: normalizeCounties(f.out.Records, filepath.Base(f.path))})
 	}
 
 	sort.Slice(records, func(i, j int) bool {
@@ -184,6 +720,68 @@ func loadRecords(dir string) ([]timeRecord, error) {
 	return records, nil
 }
 
+// keepSections returns s with only the identifying fields and the sections
+// that metrics (including the reported-change "-change" forms and the
+// components of weighted rates) are read from.
+func keepSections(s parser.MunicipalityStats, metrics []string) parser.MunicipalityStats {
+	kept := parser.MunicipalityStats{
+		County:       s.County,
+		Municipality: s.Municipality,
+		ID:           s.ID,
+		DateRange:    s.DateRange,
+		SourceFile:   s.SourceFile,
+		PageNumber:   s.PageNumber,
+	}
+	var need []string
+	for _, m := range metrics {
+		m = strings.TrimSuffix(m, "-change")
+		need = append(need, m)
+		if rc, ok := rateComponents[m]; ok {
+			need = append(need, rc.numerator, rc.denominator)
+		}
+	}
+	for _, m := range need {
+		switch m {
+		case "filings":
+			kept.Filings = s.Filings
+		case "resolutions":
+			kept.Resolutions = s.Resolutions
+		case "clearance":
+			kept.Clearance = s.Clearance
+		case "clearance-pct":
+			kept.ClearancePct = s.ClearancePct
+		case "backlog":
+			kept.Backlog = s.Backlog
+		case "backlog-per-100":
+			kept.BacklogPer100 = s.BacklogPer100
+		case "backlog-pct":
+			kept.BacklogPct = s.BacklogPct
+		case "active-pending":
+			kept.ActivePending = s.ActivePending
+		}
+	}
+	return kept
+}
+
+// normalizeCounties canonicalizes county names in place and refreshes the
+// entity IDs and rename/merger links. Records whose county isn't one of
+// the 21 NJ counties are reported and dropped so they don't show up as
+// spurious entities.
+func normalizeCounties(stats []parser.MunicipalityStats, file string) []parser.MunicipalityStats {
+	kept := stats[:0]
+	for _, s := range stats {
+		county, ok := parser.NormalizeCounty(s.County)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "warning: %s: skipping %s with unknown county %q\n", file, s.Municipality, s.County)
+			continue
+		}
+		s.County = county
+		parser.AnnotateHistory(&s)
+		kept = append(kept, s)
+	}
+	return kept
+}
+
 func buildSeries(records []timeRecord, metric, caseType, level, county, municipality string) (map[string][]dataPoint, map[string]bool) {
 	// For each time period, aggregate values by entity.
 	type accumulator struct {
@@ -232,6 +830,88 @@ func buildSeries(records []timeRecord, metric, caseType, level, county, municipa
 	return series, allDates
 }
 
+// rateComponent describes how a rate metric is derived from two count metrics:
+// value = numerator / denominator * scale.
+type rateComponent struct {
+	numerator   string
+	denominator string
+	scale       float64
+}
+
+// rateComponents maps each rate metric to the counts it is computed from.
+// Backlog per 100 monthly filings divides by one twelfth of the 12-month
+// filings total.
+var rateComponents = map[string]rateComponent{
+	"clearance-pct":   {numerator: "resolutions", denominator: "filings", scale: 100},
+	"backlog-pct":     {numerator: "backlog", denominator: "active-pending", scale: 100},
+	"backlog-per-100": {numerator: "backlog", denominator: "filings", scale: 1200},
+}
+
+// aggregateSeries builds series like buildSeries, but when weighted is set
+// rate metrics are recomputed from summed components (e.g. total resolutions
+// / total filings) rather than averaged across municipalities.
+func aggregateSeries(records []timeRecord, metric, caseType, level, county, municipality string, weighted bool) (map[string][]dataPoint, map[string]bool) {
+	rc, ok := rateComponents[metric]
+	if !weighted || !ok {
+		return buildSeries(records, metric, caseType, level, county, municipality)
+	}
+
+	num, den, allDates := componentSeries(records, rc, caseType, level, county, municipality)
+	series := make(map[string][]dataPoint)
+	for key, nums := range num {
+		for i, n := range nums {
+			if d := den[key][i].value; d != 0 {
+				series[key] = append(series[key], dataPoint{date: n.date, value: n.value / d * rc.scale})
+			}
+		}
+	}
+	return series, allDates
+}
+
+// componentSeries sums a rate's numerator and denominator per entity and
+// period, over the municipalities that report both. The two maps have the
+// same keys and dates.
+func componentSeries(records []timeRecord, rc rateComponent, caseType, level, county, municipality string) (num, den map[string][]dataPoint, allDates map[string]bool) {
+	type accumulator struct {
+		num, den float64
+	}
+
+	num = make(map[string][]dataPoint)
+	den = make(map[string][]dataPoint)
+	allDates = make(map[string]bool)
+
+	for _, rec := range records {
+		allDates[rec.date] = true
+		accum := make(map[string]*accumulator)
+
+		for _, s := range rec.stats {
+			key := entityKey(s, level, county, municipality)
+			if key == "" {
+				continue
+			}
+			n := getField(getRow(s, rc.numerator), caseType)
+			d := getField(getRow(s, rc.denominator), caseType)
+			if math.IsNaN(n) || math.IsNaN(d) {
+				continue
+			}
+			a, ok := accum[key]
+			if !ok {
+				a = &accumulator{}
+				accum[key] = a
+			}
+			a.num += n
+			a.den += d
+		}
+
+		for key, a := range accum {
+			num[key] = append(num[key], dataPoint{date: rec.date, value: a.num})
+			den[key] = append(den[key], dataPoint{date: rec.date, value: a.den})
+		}
+	}
+
+	return num, den, allDates
+}
+
 func entityKey(s parser.MunicipalityStats, level, countyFilter, muniFilter string) string {
 	switch level {
 	case "state":
@@ -242,15 +922,13 @@ func entityKey(s parser.MunicipalityStats, level, countyFilter, muniFilter strin
 		}
 		return strings.ToUpper(s.County)
 	case "municipality":
-		upperCounty := strings.ToUpper(s.County)
-		upperMuni := strings.ToUpper(s.Municipality)
-		if countyFilter != "" && upperCounty != countyFilter {
+		if countyFilter != "" && strings.ToUpper(s.County) != countyFilter {
 			return ""
 		}
-		if muniFilter != "" && upperMuni != muniFilter {
+		if muniFilter != "" && !matchesEntity(s, muniFilter) {
 			return ""
 		}
-		return upperMuni
+		return entityLabel(s)
 	}
 	return ""
 }
@@ -273,6 +951,17 @@ func getRow(s parser.MunicipalityStats, metric string) parser.RowData {
 		return s.BacklogPct.CurrentPeriod
 	case "active-pending":
 		return s.ActivePending.CurrentPeriod
+	// The report's own % Change rows (current vs prior period).
+	case "filings-change":
+		return s.Filings.PctChange
+	case "resolutions-change":
+		return s.Resolutions.PctChange
+	case "backlog-change":
+		return s.Backlog.PctChange
+	case "backlog-per-100-change":
+		return s.BacklogPer100.PctChange
+	case "active-pending-change":
+		return s.ActivePending.PctChange
 	}
 	return parser.RowData{}
 }
@@ -316,7 +1005,9 @@ func parseNumber(s string) float64 {
 	return v
 }
 
-func renderTable(title string, series map[string][]dataPoint, dates map[string]bool, includeStatewide bool) {
+// renderTable prints a row per series with its latest value and a
+// sparkline, bucketed by downsample (see sparkBuckets) to fit in width.
+func renderTable(title string, series map[string][]dataPoint, dates map[string]bool, statewidePoints []dataPoint, width int, downsample string, courtYear bool) {
 	// Sort dates for header.
 	sortedDates := make([]string, 0, len(dates))
 	for d := range dates {
@@ -331,22 +1022,6 @@ func renderTable(title string, series map[string][]dataPoint, dates map[string]b
 	}
 	sort.Strings(names)
 
-	// If county level, compute statewide aggregate and move it to end.
-	var statewidePoints []dataPoint
-	if includeStatewide && len(names) > 1 {
-		stateAgg := make(map[string]float64)
-		for _, pts := range series {
-			for _, p := range pts {
-				stateAgg[p.date] += p.value
-			}
-		}
-		for _, d := range sortedDates {
-			if v, ok := stateAgg[d]; ok {
-				statewidePoints = append(statewidePoints, dataPoint{date: d, value: v})
-			}
-		}
-	}
-
 	// Find max name length.
 	maxName := 0
 	for _, n := range names {
@@ -354,7 +1029,7 @@ func renderTable(title string, series map[string][]dataPoint, dates map[string]b
 			maxName = len(n)
 		}
 	}
-	if includeStatewide && len("STATEWIDE") > maxName {
+	if len(statewidePoints) > 0 && len("STATEWIDE") > maxName {
 		maxName = len("STATEWIDE")
 	}
 	if maxName < 10 {
@@ -366,27 +1041,32 @@ func renderTable(title string, series map[string][]dataPoint, dates map[string]b
 	if nPeriods > 0 {
 		dateRange = fmt.Sprintf("%s to %s (%d periods)", sortedDates[0], sortedDates[nPeriods-1], nPeriods)
 	}
+	buckets, bucketLabel := sparkBuckets(sortedDates, downsample, max(width-(maxName+2+10+3), 10), courtYear)
+	if bucketLabel != "" && nPeriods > 0 {
+		dateRange += ", trend averaged " + bucketLabel
+		nPeriods = buckets[nPeriods-1] + 1
+	}
 
-	fmt.Println(title)
+	fmt.Println(paint(colorStdout, styleBold, title))
 	fmt.Printf("Trend: %s\n\n", dateRange)
 
 	headerFmt := fmt.Sprintf("%%-%ds  %%10s   %%s", maxName)
 	fmt.Printf(headerFmt+"\n", "Entity", "Latest", "Trend")
-	fmt.Println(strings.Repeat("─", maxName+2+10+3+nPeriods))
+	fmt.Println(strings.Repeat(glyphs.hrule, maxName+2+10+3+nPeriods))
 
 	rowFmt := fmt.Sprintf("%%-%ds  %%10s   %%s", maxName)
 	for _, name := range names {
 		pts := series[name]
 		vals := alignValues(pts, sortedDates)
 		latest := lastNonNaN(vals)
-		fmt.Printf(rowFmt+"\n", name, formatNum(latest), sparkline(vals))
+		fmt.Printf(rowFmt+"\n", name, formatNum(latest), sparkline(bucketValues(vals, buckets)))
 	}
 
-	if includeStatewide && len(statewidePoints) > 0 {
-		fmt.Println(strings.Repeat("─", maxName+2+10+3+nPeriods))
+	if len(statewidePoints) > 0 {
+		fmt.Println(strings.Repeat(glyphs.hrule, maxName+2+10+3+nPeriods))
 		vals := alignValues(statewidePoints, sortedDates)
 		latest := lastNonNaN(vals)
-		fmt.Printf(rowFmt+"\n", "STATEWIDE", formatNum(latest), sparkline(vals))
+		fmt.Printf(rowFmt+"\n", "STATEWIDE", formatNum(latest), sparkline(bucketValues(vals, buckets)))
 	}
 }
 
@@ -417,7 +1097,7 @@ func lastNonNaN(vals []float64) float64 {
 }
 
 func sparkline(values []float64) string {
-	blocks := []rune("▁▂▃▄▅▆▇█")
+	blocks := glyphs.spark
 	n := len(blocks)
 
 	// Find min/max ignoring NaN.
@@ -458,9 +1138,20 @@ func sparkline(values []float64) string {
 	return sb.String()
 }
 
-func renderChart(title string, points []dataPoint) {
+// Terminal charts need room for the y-axis labels, a few columns of data
+// and five labeled rows.
+const (
+	chartLabelWidth = 10
+	minChartWidth   = chartLabelWidth + 10
+	minChartHeight  = 5
+)
+
+// renderChart prints a line chart of points within width columns (y-axis
+// labels included) and height rows plus the x axis. Periods in marks get a
+// marker under the x axis.
+func renderChart(title string, points []dataPoint, width, height int, marks map[string]bool) {
 	if len(points) == 0 {
-		fmt.Println(title)
+		fmt.Println(paint(colorStdout, styleBold, title))
 		fmt.Println("(no data)")
 		return
 	}
@@ -478,27 +1169,34 @@ func renderChart(title string, points []dataPoint) {
 		}
 	}
 	if len(filtered) == 0 {
-		fmt.Println(title)
+		fmt.Println(paint(colorStdout, styleBold, title))
 		fmt.Println("(no data)")
 		return
 	}
 	points = filtered
+	marked := make([]bool, len(points))
+	for i, p := range points {
+		marked[i] = marks[p.date]
+	}
+
+	available := width - chartLabelWidth
+	points, marked, label := bucketPoints(points, marked, available)
+	if label != "" {
+		title += " (averaged " + label + ")"
+	}
 
-	fmt.Println(title)
+	fmt.Println(paint(colorStdout, styleBold, title))
 	fmt.Println()
 
-	height := 15
 	nPoints := len(points)
 
-	// Determine column width: try to fit in ~100 chars for the data area.
-	labelWidth := 10 // y-axis label area
-	available := 100 - labelWidth
+	// Determine column width: try to fit in width chars.
 	colWidth := available / nPoints
 	if colWidth > 8 {
 		colWidth = 8
 	}
-	if colWidth < 3 {
-		colWidth = 3
+	if colWidth < 1 {
+		colWidth = 1
 	}
 
 	// Find value range.
@@ -545,9 +1243,9 @@ func renderChart(title string, points []dataPoint) {
 	// Place data points and connecting dots.
 	for i := 0; i < nPoints; i++ {
 		col := i*colWidth + colWidth/2
-		grid[pointRows[i]][col] = '●'
+		grid[pointRows[i]][col] = glyphs.point
 
-		// Connect to next point with · via linear interpolation.
+		// Connect to the next point via linear interpolation.
 		if i < nPoints-1 {
 			startCol := col
 			endCol := (i+1)*colWidth + colWidth/2
@@ -564,7 +1262,7 @@ func renderChart(title string, points []dataPoint) {
 					r = height - 1
 				}
 				if grid[r][c] == ' ' {
-					grid[r][c] = '·'
+					grid[r][c] = glyphs.trail
 				}
 			}
 		}
@@ -584,33 +1282,49 @@ func renderChart(title string, points []dataPoint) {
 		if l, ok := yLabels[r]; ok {
 			label = l
 		}
-		fmt.Printf("%8s │%s\n", label, string(grid[r]))
+		fmt.Printf("%8s %s%s\n", label, glyphs.vaxis, string(grid[r]))
 	}
 
 	// X-axis line.
-	fmt.Printf("%8s └%s\n", "", strings.Repeat("─", totalWidth))
+	fmt.Printf("%8s %s%s\n", "", glyphs.corner, strings.Repeat(glyphs.hrule, totalWidth))
 
-	// X-axis labels.
-	// Determine how many labels fit.
-	labelEvery := 1
-	if colWidth < 8 {
-		labelEvery = (8 + colWidth - 1) / colWidth
+	centers := make([]int, nPoints)
+	for i := range centers {
+		centers[i] = i*colWidth + colWidth/2
 	}
-	xLine := make([]byte, totalWidth)
-	for i := range xLine {
-		xLine[i] = ' '
+	if len(marks) > 0 {
+		fmt.Printf("%8s  %s\n", "", markLine(marked, centers, totalWidth))
 	}
-	for i := 0; i < nPoints; i += labelEvery {
-		pos := i*colWidth + colWidth/2 - len(points[i].date)/2
-		if pos < 0 {
-			pos = 0
+	fmt.Printf("%8s  %s\n", "", xAxisLabels(points, centers, totalWidth))
+}
+
+// xAxisLabels returns a line width columns wide with each point's date
+// centered on its column in centers, skipping any that would overlap the
+// one before.
+func xAxisLabels(points []dataPoint, centers []int, width int) string {
+	line := []byte(strings.Repeat(" ", width))
+	next := 0
+	for i, p := range points {
+		pos := min(max(centers[i]-len(p.date)/2, 0), width-len(p.date))
+		if pos < next || pos < 0 {
+			continue
 		}
-		label := points[i].date
-		for j := 0; j < len(label) && pos+j < totalWidth; j++ {
-			xLine[pos+j] = label[j]
+		copy(line[pos:], p.date)
+		next = pos + len(p.date) + 1
+	}
+	return string(line)
+}
+
+// markLine returns a line width columns wide with a marker at the column in
+// centers of each marked point.
+func markLine(marked []bool, centers []int, width int) string {
+	line := []rune(strings.Repeat(" ", width))
+	for i, m := range marked {
+		if m && centers[i] < width {
+			line[centers[i]] = glyphs.mark
 		}
 	}
-	fmt.Printf("%8s  %s\n", "", string(xLine))
+	return string(line)
 }
 
 func formatNum(v float64) string {
@@ -667,14 +1381,19 @@ func formatCompact(v float64) string {
 
 func metricLabel(m string) string {
 	labels := map[string]string{
-		"filings":        "Filings",
-		"resolutions":    "Resolutions",
-		"clearance":      "Clearance",
-		"clearance-pct":  "Clearance %",
-		"backlog":        "Backlog",
-		"backlog-per-100": "Backlog per 100",
-		"backlog-pct":    "Backlog %",
-		"active-pending": "Active Pending",
+		"filings":                "Filings",
+		"resolutions":            "Resolutions",
+		"clearance":              "Clearance",
+		"clearance-pct":          "Clearance %",
+		"backlog":                "Backlog",
+		"backlog-per-100":        "Backlog per 100",
+		"backlog-pct":            "Backlog %",
+		"active-pending":         "Active Pending",
+		"filings-change":         "Filings % Change (reported)",
+		"resolutions-change":     "Resolutions % Change (reported)",
+		"backlog-change":         "Backlog % Change (reported)",
+		"backlog-per-100-change": "Backlog per 100 % Change (reported)",
+		"active-pending-change":  "Active Pending % Change (reported)",
 	}
 	return labels[m]
 }
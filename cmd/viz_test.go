@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func rateStat(county, muni, filings, resolutions, clearancePct string) parser.MunicipalityStats {
+	s := stat(county, muni)
+	s.Filings.CurrentPeriod.GrandTotal = filings
+	s.Resolutions.CurrentPeriod.GrandTotal = resolutions
+	s.ClearancePct.CurrentPeriod.GrandTotal = clearancePct
+	return s
+}
+
+func TestAggregateSeries_Weighted(t *testing.T) {
+	records := []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			rateStat("ATLANTIC", "BRIGANTINE", "900", "900", "100%"),
+		}},
+	}
+
+	// Unweighted: plain mean of the municipality percentages.
+	series, _ := aggregateSeries(records, "clearance-pct", "grand-total", "state", "", "", false)
+	if got := series["STATEWIDE"][0].value; got != 75 {
+		t.Errorf("unweighted = %v, want 75", got)
+	}
+
+	// Weighted: total resolutions / total filings.
+	series, _ = aggregateSeries(records, "clearance-pct", "grand-total", "state", "", "", true)
+	if got := series["STATEWIDE"][0].value; got != 95 {
+		t.Errorf("weighted = %v, want 95", got)
+	}
+
+	// Count metrics are unaffected by weighting.
+	series, _ = aggregateSeries(records, "filings", "grand-total", "state", "", "", true)
+	if got := series["STATEWIDE"][0].value; got != 1000 {
+		t.Errorf("filings = %v, want 1000", got)
+	}
+}
+
+func TestAggregateSeries_WeightedSkipsMissingComponents(t *testing.T) {
+	records := []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			rateStat("ATLANTIC", "BRIGANTINE", "- -", "900", "- -"),
+		}},
+	}
+	series, _ := aggregateSeries(records, "clearance-pct", "grand-total", "county", "", "", true)
+	got := series["ATLANTIC"][0].value
+	if math.Abs(got-50) > 1e-9 {
+		t.Errorf("weighted = %v, want 50", got)
+	}
+}
+
+func TestFoldHistory(t *testing.T) {
+	records := []timeRecord{
+		{date: "2012-06", stats: []parser.MunicipalityStats{
+			rateStat("MERCER", "PRINCETON BORO", "100", "0", ""),
+			rateStat("MERCER", "PRINCETON TWP", "200", "0", ""),
+		}},
+		{date: "2013-06", stats: []parser.MunicipalityStats{
+			rateStat("MERCER", "PRINCETON BORO", "5", "0", ""),
+			rateStat("MERCER", "PRINCETON MUNICIPAL COUR", "400", "0", ""),
+		}},
+	}
+
+	folded, notes := foldHistory(records)
+	series, _ := aggregateSeries(folded, "filings", "grand-total", "municipality", "MERCER", "", false)
+
+	got := series["PRINCETON MUNICIPAL COUR"]
+	if len(got) != 2 || got[0].value != 300 || got[1].value != 400 {
+		t.Errorf("merged series = %v, want [300 400]", got)
+	}
+	// After the merger the leftover borough entry stays separate.
+	if b := series["PRINCETON BORO"]; len(b) != 1 || b[0].date != "2013-06" {
+		t.Errorf("PRINCETON BORO = %v", b)
+	}
+	if _, ok := notes["PRINCETON MUNICIPAL COUR"]; !ok {
+		t.Errorf("missing continuity note: %v", notes)
+	}
+	// The input records are left untouched.
+	if records[0].stats[0].Municipality != "PRINCETON BORO" {
+		t.Errorf("input mutated: %q", records[0].stats[0].Municipality)
+	}
+}
+
+func TestKeepSections(t *testing.T) {
+	s := rateStat("ATLANTIC", "ABSECON", "100", "50", "50%")
+	s.Backlog.PctChange.GrandTotal = "10%"
+
+	kept := keepSections(s, []string{"clearance-pct"})
+	if kept.ClearancePct != s.ClearancePct || kept.Filings != s.Filings || kept.Resolutions != s.Resolutions {
+		t.Error("clearance-pct lost its weighted components")
+	}
+	if kept.Backlog != (parser.SectionWithChange{}) {
+		t.Error("unneeded backlog section kept")
+	}
+	if kept.County != "ATLANTIC" || kept.Municipality != "ABSECON" {
+		t.Errorf("identity = %s/%s", kept.County, kept.Municipality)
+	}
+
+	kept = keepSections(s, []string{"backlog-change"})
+	if kept.Backlog.PctChange.GrandTotal != "10%" || kept.Filings != (parser.SectionWithChange{}) {
+		t.Error("backlog-change kept the wrong sections")
+	}
+}
+
+func TestReportedChange(t *testing.T) {
+	s := rateStat("ATLANTIC", "ABSECON", "100", "50", "50%")
+	for i, sec := range []*parser.SectionWithChange{&s.Filings, &s.Resolutions, &s.Backlog, &s.BacklogPer100, &s.ActivePending} {
+		sec.PctChange.GrandTotal = fmt.Sprintf("%d%%", (i+1)*10)
+		sec.PctChange.DWI = fmt.Sprintf("-%d%%", i+1)
+	}
+	records := []timeRecord{{date: "2024-06", stats: []parser.MunicipalityStats{s}}}
+
+	for i, section := range changeSections {
+		metric := section + "-change"
+		if got := getField(getRow(s, metric), "grand-total"); got != float64((i+1)*10) {
+			t.Errorf("%s grand-total = %v, want %d", metric, got, (i+1)*10)
+		}
+		series, _ := aggregateSeries(records, metric, "dwi", "municipality", "ATLANTIC", "ABSECON", false)
+		if pts := series["ABSECON"]; len(pts) != 1 || pts[0].value != -float64(i+1) {
+			t.Errorf("%s dwi series = %v, want %d", metric, pts, -(i + 1))
+		}
+	}
+
+	// The two-row sections have no % Change row to read.
+	for _, section := range []string{"clearance", "clearance-pct", "backlog-pct"} {
+		if contains(changeSections, section) {
+			t.Errorf("%s offered for reported-change", section)
+		}
+		if got := getField(getRow(s, section+"-change"), "grand-total"); !math.IsNaN(got) {
+			t.Errorf("%s-change = %v, want NaN", section, got)
+		}
+	}
+}
+
+func TestXAxisLabels(t *testing.T) {
+	pts := []dataPoint{{date: "2020-01"}, {date: "2020-02"}, {date: "2020-03"}, {date: "2020-04"}}
+	// The middle labels would overlap the first and are dropped; the last
+	// is pulled left to fit.
+	got := xAxisLabels(pts, []int{0, 4, 8, 14}, 18)
+	if want := "2020-01    2020-04"; got != want {
+		t.Errorf("xAxisLabels = %q, want %q", got, want)
+	}
+}
+
+func TestCheckScope(t *testing.T) {
+	for _, tc := range []struct {
+		chart, level string
+		levelSet     bool
+		statewide    string
+		county, muni string
+		ok           bool
+	}{
+		{"line", "county", false, "only", "", "", true},
+		{"line", "state", true, "only", "", "", true},
+		{"line", "county", true, "only", "", "", false},
+		{"line", "municipality", true, "only", "", "", false},
+		{"line", "county", false, "only", "ATLANTIC", "", false},
+		{"line", "county", false, "only", "", "ABSECON", false},
+		{"line", "municipality", true, "include", "ATLANTIC", "ABSECON", true},
+		{"box", "county", false, "include", "ATLANTIC", "", true},
+		{"box", "municipality", true, "include", "", "", true},
+		{"hist", "county", true, "include", "", "", false},
+		{"scatter", "state", true, "exclude", "", "", false},
+		{"box", "county", false, "only", "", "", false},
+	} {
+		err := checkScope(tc.chart, tc.level, tc.levelSet, tc.statewide, tc.county, tc.muni)
+		if (err == nil) != tc.ok {
+			t.Errorf("checkScope(%+v) = %v", tc, err)
+		}
+	}
+}
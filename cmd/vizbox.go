@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// validCharts are the --chart choices. line and braille draw each series
+// over time; box, hist and scatter draw the municipalities as a population
+// instead.
+var validCharts = []string{"line", "braille", "box", "hist", "scatter"}
+
+// distributionCharts are the --chart choices that draw every municipality
+// in scope as one value of a distribution, or for scatter one point.
+var distributionCharts = map[string]bool{"box": true, "hist": true, "scatter": true}
+
+// checkDistributionChart reports an error if chart, which draws values
+// across municipalities rather than series, is combined with a flag that
+// only applies to series.
+func checkDistributionChart(chart, municipality, vs, indexBase string, normalize bool, htmlOut string) error {
+	switch {
+	case municipality != "":
+		return fmt.Errorf("--chart %s shows every municipality in scope; drop --municipality", chart)
+	case vs != "none":
+		return fmt.Errorf("--chart %s can't be combined with --vs-state", chart)
+	case indexBase != "" || normalize:
+		return fmt.Errorf("--chart %s can't be combined with --index or --normalize", chart)
+	case htmlOut != "":
+		return fmt.Errorf("--chart %s can't be written as --html; use --pdf or --png", chart)
+	}
+	return nil
+}
+
+// boxSummary is one period's distribution of a metric across
+// municipalities, as a Tukey box plot: the median and quartiles, whiskers
+// to the furthest values within 1.5 IQR of the box, and the values beyond.
+type boxSummary struct {
+	date                      string
+	n                         int
+	low, q1, median, q3, high float64
+	outliers                  []float64
+}
+
+// periodValueLists returns each period's values across series, skipping
+// missing ones, in the order of sortedDates.
+func periodValueLists(series map[string][]dataPoint, sortedDates []string) [][]float64 {
+	idx := make(map[string]int, len(sortedDates))
+	for i, d := range sortedDates {
+		idx[d] = i
+	}
+	lists := make([][]float64, len(sortedDates))
+	for _, pts := range series {
+		for _, p := range pts {
+			if i, ok := idx[p.date]; ok && !math.IsNaN(p.value) {
+				lists[i] = append(lists[i], p.value)
+			}
+		}
+	}
+	return lists
+}
+
+// buildBoxes summarizes every period of series that has values. The
+// statistics are the ones gonum's box plot draws, so the terminal chart,
+// its table and the PDF agree.
+func buildBoxes(series map[string][]dataPoint, sortedDates []string) []boxSummary {
+	var boxes []boxSummary
+	for i, vals := range periodValueLists(series, sortedDates) {
+		if len(vals) == 0 {
+			continue
+		}
+		bp, err := plotter.NewBoxPlot(0, 0, plotter.Values(vals))
+		if err != nil {
+			continue
+		}
+		b := boxSummary{date: sortedDates[i], n: len(vals), low: bp.AdjLow, q1: bp.Quartile1,
+			median: bp.Median, q3: bp.Quartile3, high: bp.AdjHigh}
+		for _, o := range bp.Outside {
+			b.outliers = append(b.outliers, vals[o])
+		}
+		boxes = append(boxes, b)
+	}
+	return boxes
+}
+
+// renderBoxChart prints one box plot per period, on a value axis shared by
+// all of them, within width columns, followed by the figures. The axis
+// spans the whiskers; outliers beyond every period's whiskers are only
+// counted.
+func renderBoxChart(title string, boxes []boxSummary, width int) {
+	fmt.Println(paint(colorStdout, styleBold, title))
+	fmt.Println()
+	if len(boxes) == 0 {
+		fmt.Println("(no data)")
+		return
+	}
+
+	lo, hi := boxes[0].low, boxes[0].high
+	for _, b := range boxes {
+		lo, hi = math.Min(lo, b.low), math.Max(hi, b.high)
+	}
+	if hi == lo {
+		lo, hi = lo-0.5, hi+0.5
+	}
+	cols := width - chartLabelWidth
+	pos := func(v float64) int {
+		return min(max(int(math.Round((v-lo)/(hi-lo)*float64(cols-1))), 0), cols-1)
+	}
+
+	fmt.Printf("%-9s %s\n", "", valueAxis(lo, hi, cols))
+	fmt.Printf("%-9s %s\n", "", strings.Repeat(glyphs.hrule, cols))
+	whisker := []rune(glyphs.hrule)[0]
+	for _, b := range boxes {
+		line := []rune(strings.Repeat(" ", cols))
+		for c := pos(b.low); c <= pos(b.high); c++ {
+			line[c] = whisker
+		}
+		for c := pos(b.q1); c <= pos(b.q3); c++ {
+			line[c] = glyphs.boxFill
+		}
+		line[pos(b.low)], line[pos(b.high)] = glyphs.whiskerEnd, glyphs.whiskerEnd
+		line[pos(b.median)] = glyphs.boxMedian
+		for _, o := range b.outliers {
+			if o >= lo && o <= hi {
+				line[pos(o)] = glyphs.outlier
+			}
+		}
+		fmt.Printf("%-9s %s\n", b.date, strings.TrimRight(string(line), " "))
+	}
+
+	fmt.Println()
+	fmt.Printf("%-9s %6s %10s %10s %10s %10s %10s %8s\n", "Period", "Courts", "Low", "Q1", "Median", "Q3", "High", "Outliers")
+	for _, b := range boxes {
+		fmt.Printf("%-9s %6d %10s %10s %10s %10s %10s %8d\n", b.date, b.n,
+			formatNum(b.low), formatNum(b.q1), formatNum(b.median), formatNum(b.q3), formatNum(b.high), len(b.outliers))
+	}
+	fmt.Printf("\n%s box: Q1 to Q3, %c median; whiskers %c to the furthest values within 1.5 IQR; %c outliers\n",
+		string(glyphs.boxFill), glyphs.boxMedian, glyphs.whiskerEnd, glyphs.outlier)
+}
+
+// valueAxis labels a horizontal value axis cols wide running from lo to hi
+// at round values, as the PDF axes are, skipping labels that would
+// overlap.
+func valueAxis(lo, hi float64, cols int) string {
+	line := []byte(strings.Repeat(" ", cols))
+	next := 0
+	for _, t := range (numTicks{}).Ticks(lo, hi) {
+		if t.Label == "" || t.Value < lo || t.Value > hi {
+			continue
+		}
+		c := int(math.Round((t.Value - lo) / (hi - lo) * float64(cols-1)))
+		pos := min(max(c-len(t.Label)/2, 0), cols-len(t.Label))
+		if pos < next || pos < 0 {
+			continue
+		}
+		copy(line[pos:], t.Label)
+		next = pos + len(t.Label) + 1
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// drawBoxChart draws a box plot for each period of series filling area,
+// with periods along the x axis as in drawChart. Like the terminal chart,
+// the value axis spans the whiskers, so a few wild values don't flatten
+// every box; outliers beyond it are left out and counted in the title.
+func drawBoxChart(area draw.Canvas, title string, series map[string][]dataPoint, sortedDates []string) {
+	p := plot.New()
+	p.Title.TextStyle.Font.Size = vg.Points(12)
+	p.BackgroundColor = color.White
+
+	boxWidth := min(area.Size().X*0.8/vg.Length(max(len(sortedDates), 1))*0.6, vg.Points(24))
+	var boxes []*plotter.BoxPlot
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, vals := range periodValueLists(series, sortedDates) {
+		if len(vals) == 0 {
+			continue
+		}
+		bp, err := plotter.NewBoxPlot(boxWidth, float64(i), plotter.Values(vals))
+		if err != nil {
+			continue
+		}
+		bp.FillColor = color.RGBA{R: 174, G: 199, B: 232, A: 255}
+		bp.MedianStyle.Color = chartBlue
+		bp.MedianStyle.Width = vg.Points(1.5)
+		bp.GlyphStyle.Radius = vg.Points(1.5)
+		boxes = append(boxes, bp)
+		lo, hi = math.Min(lo, bp.AdjLow), math.Max(hi, bp.AdjHigh)
+	}
+	if len(boxes) == 0 {
+		return
+	}
+	if hi == lo {
+		lo, hi = lo-0.5, hi+0.5
+	}
+	pad := (hi - lo) * 0.05
+	lo, hi = lo-pad, hi+pad
+
+	hidden := 0
+	for _, bp := range boxes {
+		kept := bp.Outside[:0]
+		for _, o := range bp.Outside {
+			if v := bp.Values[o]; v >= lo && v <= hi {
+				kept = append(kept, o)
+			} else {
+				hidden++
+			}
+		}
+		bp.Outside = kept
+		p.Add(bp)
+	}
+	p.Add(plotter.NewGrid())
+
+	p.Title.Text = title
+	if hidden > 0 {
+		p.Title.Text += fmt.Sprintf("\n%d outliers beyond the axis not shown", hidden)
+	}
+	p.Y.Min, p.Y.Max = lo, hi
+	p.X.Tick.Marker = dateTicks(sortedDates)
+	p.X.Min = -0.5
+	p.X.Max = float64(len(sortedDates)) - 0.5
+	p.X.Tick.Label.Rotation = math.Pi / 4
+	p.X.Tick.Label.XAlign = draw.XRight
+	p.X.Tick.Label.YAlign = draw.YCenter
+
+	p.Y.Tick.Marker = numTicks{}
+
+	p.Draw(area)
+}
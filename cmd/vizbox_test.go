@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildBoxes(t *testing.T) {
+	series := map[string][]dataPoint{}
+	for i, v := range []float64{1, 2, 3, 4, 5, 6, 7, 8, 100} {
+		series[string(rune('A'+i))] = []dataPoint{{"2024-06", v}}
+	}
+	series["J"] = []dataPoint{{"2025-06", 10}}
+
+	boxes := buildBoxes(series, []string{"2023-06", "2024-06", "2025-06"})
+	if len(boxes) != 2 {
+		t.Fatalf("got %d boxes, want 2 (2023-06 has no values)", len(boxes))
+	}
+	b := boxes[0]
+	if b.date != "2024-06" || b.n != 9 || b.median != 5 || b.q1 != 2.5 || b.q3 != 7 {
+		t.Errorf("box = %+v", b)
+	}
+	if b.low != 1 || b.high != 8 || len(b.outliers) != 1 || b.outliers[0] != 100 {
+		t.Errorf("whiskers %v–%v, outliers %v", b.low, b.high, b.outliers)
+	}
+	if s := boxes[1]; s.n != 1 || s.median != 10 || s.low != 10 || s.high != 10 {
+		t.Errorf("single-value box = %+v", s)
+	}
+}
+
+func TestValueAxis(t *testing.T) {
+	axis := valueAxis(0, 1000, 40)
+	if !strings.HasPrefix(axis, "0") || !strings.HasSuffix(axis, "1k") {
+		t.Errorf("axis = %q", axis)
+	}
+}
+
+func TestRenderPNG_Box(t *testing.T) {
+	series := map[string][]dataPoint{
+		"A": {{"2024-06", 1}, {"2025-06", 2}},
+		"B": {{"2024-06", 3}, {"2025-06", 5}},
+		"C": {{"2024-06", 4}, {"2025-06", 900}},
+	}
+	path := filepath.Join(t.TempDir(), "box.png")
+	rep := pdfReport{title: "Backlog", series: series, sortedDates: []string{"2024-06", "2025-06"}, chart: "box"}
+	if err := renderPNG(path, rep); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != snapshotWidthPx || b.Dy() != snapshotHeightPx {
+		t.Errorf("image is %v", b)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// checkChartDate reports an error if date, a --date given to a chart of a
+// single period, isn't one of the charted periods.
+func checkChartDate(date string, dates map[string]bool) error {
+	if dates[date] {
+		return nil
+	}
+	sorted := sortDates(dates)
+	if len(sorted) == 0 {
+		return fmt.Errorf("--date %s: no periods to chart", date)
+	}
+	return fmt.Errorf("--date %s isn't one of the charted periods (%s to %s)", date, sorted[0], sorted[len(sorted)-1])
+}
+
+// histBin is one bar of a histogram: the n courts whose value is at least
+// lo and below hi (the last bin also takes hi).
+type histBin struct {
+	lo, hi float64
+	n      int
+}
+
+// buildHist sorts vals into at most bins bins of equal width. The width is
+// rounded up to 1, 2, 2.5 or 5 times a power of ten and the first bin
+// starts at a multiple of it, so the edges are round numbers. Values that
+// are all equal share one bin of width 1.
+func buildHist(vals []float64, bins int) []histBin {
+	if len(vals) == 0 {
+		return nil
+	}
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi == lo {
+		return []histBin{{lo: lo, hi: lo + 1, n: len(vals)}}
+	}
+
+	w := niceStep((hi - lo) / float64(bins))
+	start := math.Floor(lo/w) * w
+	n := int(math.Ceil((hi - start) / w))
+	for n > bins {
+		w = niceStep(w * 1.000001)
+		start = math.Floor(lo/w) * w
+		n = int(math.Ceil((hi - start) / w))
+	}
+
+	out := make([]histBin, n)
+	for i := range out {
+		out[i].lo = start + float64(i)*w
+		out[i].hi = start + float64(i+1)*w
+	}
+	for _, v := range vals {
+		i := min(max(int((v-start)/w), 0), n-1)
+		out[i].n++
+	}
+	return out
+}
+
+// niceStep returns the smallest of 1, 2, 2.5 and 5 times a power of ten
+// that is at least raw.
+func niceStep(raw float64) float64 {
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 2.5, 5} {
+		if m*mag >= raw {
+			return m * mag
+		}
+	}
+	return 10 * mag
+}
+
+// topShare returns the fraction of the total of vals held by the largest
+// tenth of them, and how many that is. ok is false if the values can't be
+// shared out that way: there are none, or some are negative, or they sum
+// to zero.
+func topShare(vals []float64) (share float64, top int, ok bool) {
+	sorted := append([]float64(nil), vals...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	var total float64
+	for _, v := range sorted {
+		if v < 0 {
+			return 0, 0, false
+		}
+		total += v
+	}
+	if total == 0 {
+		return 0, 0, false
+	}
+	top = int(math.Ceil(float64(len(sorted)) / 10))
+	var sum float64
+	for _, v := range sorted[:top] {
+		sum += v
+	}
+	return sum / total, top, true
+}
+
+// renderHistChart prints a histogram of vals, one bar per bin of bins,
+// within width columns, and a summary line. share adds how much of the
+// total the largest courts hold, which only means something for counts.
+func renderHistChart(title string, bins []histBin, vals []float64, share bool, width int) {
+	fmt.Println(paint(colorStdout, styleBold, title))
+	fmt.Println()
+	if len(bins) == 0 {
+		fmt.Println("(no data)")
+		return
+	}
+
+	labels := make([]string, len(bins))
+	labelWidth, most := len("Range"), 0
+	for i, b := range bins {
+		labels[i] = formatNum(b.lo) + " to " + formatNum(b.hi)
+		labelWidth = max(labelWidth, len(labels[i]))
+		most = max(most, b.n)
+	}
+	countWidth := max(len("Courts"), len(formatInt(int64(most))))
+	cols := max(width-labelWidth-countWidth-10, 10)
+
+	fmt.Println(paint(colorStdout, styleDim, fmt.Sprintf("%*s  %*s  %6s", labelWidth, "Range", countWidth, "Courts", "%")))
+	for i, b := range bins {
+		bar := 0
+		if b.n > 0 {
+			bar = max(int(math.Round(float64(b.n)/float64(most)*float64(cols))), 1)
+		}
+		pct := float64(b.n) / float64(len(vals)) * 100
+		fmt.Printf("%*s  %*s  %5.1f%%  %s\n", labelWidth, labels[i], countWidth, formatInt(int64(b.n)), pct, strings.Repeat(string(glyphs.bar), bar))
+	}
+
+	fmt.Printf("\n%d courts; median %s, mean %s\n", len(vals), formatNum(median(vals)), formatNum(meanValues(vals)))
+	if s, top, ok := topShare(vals); ok && share {
+		fmt.Printf("The largest %d (%d%%) hold %.1f%% of the total.\n", top, int(math.Round(float64(top)/float64(len(vals))*100)), s*100)
+	}
+	fmt.Println("Each range includes its lower bound; the last includes both.")
+}
+
+// drawHistChart draws bins as a histogram filling area, with the value on
+// the x axis and the number of courts on the y axis.
+func drawHistChart(area draw.Canvas, title string, bins []histBin) {
+	p := plot.New()
+	p.Title.Text = title
+	p.Title.TextStyle.Font.Size = vg.Points(12)
+	p.BackgroundColor = color.White
+	if len(bins) == 0 {
+		p.Draw(area)
+		return
+	}
+
+	h := &plotter.Histogram{
+		Width:     bins[0].hi - bins[0].lo,
+		FillColor: color.RGBA{R: 174, G: 199, B: 232, A: 255},
+		LineStyle: plotter.DefaultLineStyle,
+	}
+	h.LineStyle.Color = chartBlue
+	most := 0
+	for _, b := range bins {
+		h.Bins = append(h.Bins, plotter.HistogramBin{Min: b.lo, Max: b.hi, Weight: float64(b.n)})
+		most = max(most, b.n)
+	}
+	p.Add(plotter.NewGrid(), h)
+
+	p.X.Min, p.X.Max = bins[0].lo, bins[len(bins)-1].hi
+	// Tick the bin edges, labeling as many as fit.
+	var ticks plot.ConstantTicks
+	every := (len(bins) + 11) / 12
+	for i := 0; i <= len(bins); i++ {
+		edge := bins[0].lo + float64(i)*h.Width
+		t := plot.Tick{Value: edge}
+		if i%every == 0 {
+			t.Label = formatCompact(edge)
+		}
+		ticks = append(ticks, t)
+	}
+	p.X.Tick.Marker = ticks
+	p.Y.Min, p.Y.Max = 0, float64(most)*1.05
+	p.Y.Label.Text = "Courts"
+	p.Y.Tick.Marker = numTicks{}
+
+	p.Draw(area)
+}
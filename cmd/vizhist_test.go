@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBuildHist(t *testing.T) {
+	vals := []float64{3, 12, 14, 19, 20, 47}
+	bins := buildHist(vals, 5)
+	// (47-3)/5 = 8.8 rounds up to 10, so bins run 0 to 50 in tens.
+	if len(bins) != 5 {
+		t.Fatalf("got %d bins, want 5: %+v", len(bins), bins)
+	}
+	want := []int{1, 3, 1, 0, 1}
+	for i, b := range bins {
+		if b.lo != float64(i*10) || b.hi != float64(i*10+10) || b.n != want[i] {
+			t.Errorf("bin %d = %+v, want %d to %d with %d", i, b, i*10, i*10+10, want[i])
+		}
+	}
+
+	// 0 to 100 in 10 bins would need an 11th for the 100 itself, which
+	// the last bin takes instead.
+	if got := buildHist([]float64{0, 50, 100}, 10); len(got) != 10 || got[9].n != 1 {
+		t.Errorf("edge value: %+v", got)
+	}
+	// Never more bins than asked for.
+	if got := buildHist([]float64{1, 101}, 10); len(got) > 10 {
+		t.Errorf("got %d bins", len(got))
+	}
+	if got := buildHist([]float64{7, 7}, 10); len(got) != 1 || got[0].n != 2 {
+		t.Errorf("equal values: %+v", got)
+	}
+	if got := buildHist(nil, 10); got != nil {
+		t.Errorf("no values: %+v", got)
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	for _, tt := range []struct{ raw, want float64 }{
+		{8.8, 10}, {10, 10}, {1.2, 2}, {2.2, 2.5}, {3, 5}, {0.03, 0.05}, {4400, 5000},
+	} {
+		if got := niceStep(tt.raw); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("niceStep(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestTopShare(t *testing.T) {
+	vals := []float64{90, 1, 1, 1, 1, 1, 1, 1, 1, 2, 0}
+	share, top, ok := topShare(vals)
+	// 11 courts: the largest 2 hold 92 of 100.
+	if !ok || top != 2 || math.Abs(share-0.92) > 1e-9 {
+		t.Errorf("topShare = %v, %d, %v", share, top, ok)
+	}
+	if _, _, ok := topShare([]float64{5, -1}); ok {
+		t.Error("negative values should have no share")
+	}
+	if _, _, ok := topShare([]float64{0, 0}); ok {
+		t.Error("zero total should have no share")
+	}
+}
+
+func TestCheckChartDate(t *testing.T) {
+	dates := map[string]bool{"2023-06": true, "2024-06": true}
+	if err := checkChartDate("2024-06", dates); err != nil {
+		t.Error(err)
+	}
+	if err := checkChartDate("2022-06", dates); err == nil || err.Error() != "--date 2022-06 isn't one of the charted periods (2023-06 to 2024-06)" {
+		t.Errorf("err = %v", err)
+	}
+}
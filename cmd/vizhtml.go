@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// htmlChart is the data inlined into a --html chart page.
+type htmlChart struct {
+	Title  string       `json:"title"`
+	Dates  []string     `json:"dates"`
+	Series []htmlSeries `json:"series"`
+	Notes  []string     `json:"notes,omitempty"`
+	Source string       `json:"source,omitempty"` // link back to the dashboard view
+}
+
+// htmlSeries is one line of an htmlChart, with a value (or null for no
+// data) for each of the chart's dates.
+type htmlSeries struct {
+	Name      string     `json:"name"`
+	Values    []*float64 `json:"values"`
+	Statewide bool       `json:"statewide,omitempty"`
+}
+
+// newHTMLChart lays out series for an HTML chart: one line per entity in
+// name order, then the statewide line (drawn dashed, and hidden until
+// picked in the legend) if there is one. Notes on entities are listed
+// under the chart.
+func newHTMLChart(title string, series map[string][]dataPoint, sortedDates []string, statewidePoints []dataPoint, notes map[string]string) htmlChart {
+	c := htmlChart{Title: title, Dates: sortedDates}
+	line := func(name string, points []dataPoint) htmlSeries {
+		s := htmlSeries{Name: name, Values: make([]*float64, len(sortedDates))}
+		for i, v := range alignValues(points, sortedDates) {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				s.Values[i] = &v
+			}
+		}
+		return s
+	}
+	for _, name := range sortedEntityNames(series) {
+		c.Series = append(c.Series, line(name, series[name]))
+	}
+	if len(statewidePoints) > 0 {
+		s := line("STATEWIDE", statewidePoints)
+		s.Statewide = true
+		c.Series = append(c.Series, s)
+	}
+	names := make([]string, 0, len(notes))
+	for n := range notes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		c.Notes = append(c.Notes, n+": "+notes[n])
+	}
+	return c
+}
+
+// renderHTML writes c as a standalone HTML page to path.
+func renderHTML(path string, c htmlChart) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeHTML(f, c); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeHTML writes c as a single self-contained HTML page: the data is
+// inlined and the chart drawn as SVG by a few lines of script, with no
+// requests to any other site, so the file can be emailed or embedded as
+// is. Hovering shows each line's value at a period, dragging across the
+// chart zooms into those periods, and the legend hides and shows lines.
+func writeHTML(w io.Writer, c htmlChart) error {
+	return htmlChartTemplate.Execute(w, c)
+}
+
+var htmlChartTemplate = template.Must(template.New("chart").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Title}}</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, system-ui, sans-serif; margin: 1.5rem; color: #1c1917; }
+  h1 { font-size: 1.25rem; font-weight: 600; margin: 0 0 0.25rem; }
+  .hint { color: #78716c; font-size: 0.8rem; margin: 0 0 0.75rem; }
+  .wrap { position: relative; }
+  svg { width: 100%; height: 60vh; min-height: 300px; display: block; user-select: none; cursor: crosshair; }
+  svg text { font-size: 11px; fill: #57534e; }
+  .grid { stroke: #e7e5e4; }
+  .axis { stroke: #a8a29e; }
+  .line { fill: none; stroke-width: 1.75; }
+  .statewide { stroke-dasharray: 6 4; stroke-width: 2.5; }
+  .cross { stroke: #a8a29e; stroke-dasharray: 3 3; }
+  .select { fill: rgba(87, 83, 78, 0.12); }
+  #tip { position: absolute; pointer-events: none; background: #fff; border: 1px solid #d6d3d1; border-radius: 4px;
+         padding: 0.4rem 0.6rem; font-size: 0.8rem; box-shadow: 0 2px 6px rgba(0,0,0,0.12); display: none; white-space: nowrap; }
+  #tip table { border-collapse: collapse; }
+  #tip td { padding: 0 0.3rem; }
+  #tip td.v { text-align: right; font-variant-numeric: tabular-nums; }
+  .sw { display: inline-block; width: 0.7rem; height: 0.7rem; border-radius: 2px; margin-right: 0.3rem; vertical-align: -1px; }
+  #legend { display: flex; flex-wrap: wrap; gap: 0.25rem 0.9rem; margin-top: 0.75rem; font-size: 0.8rem; }
+  #legend button { border: none; background: none; padding: 0; font: inherit; color: inherit; cursor: pointer; }
+  #legend button.off { opacity: 0.35; }
+  #reset { display: none; font: inherit; font-size: 0.8rem; margin-left: 0.5rem; }
+  .notes { color: #57534e; font-size: 0.8rem; margin-top: 1rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="hint">Hover for values; drag across the chart to zoom, double-click to zoom out.<button id="reset">Reset zoom</button></p>
+<div class="wrap"><svg id="chart"></svg><div id="tip"></div></div>
+<div id="legend"></div>
+{{if .Notes}}<div class="notes">{{range .Notes}}<div>{{.}}</div>{{end}}</div>{{end}}
+{{if .Source}}<div class="notes">Exported from <a href="{{.Source}}">{{.Source}}</a></div>{{end}}
+<script>
+const data = {{.}};
+const palette = ['#2563eb', '#dc2626', '#16a34a', '#d97706', '#7c3aed', '#0891b2', '#db2777', '#65a30d', '#ea580c', '#4f46e5', '#0d9488', '#9333ea'];
+const margin = { left: 72, right: 16, top: 12, bottom: 36 };
+const svg = document.getElementById('chart');
+const tip = document.getElementById('tip');
+const reset = document.getElementById('reset');
+const hidden = new Set();
+let lo = 0, hi = data.dates.length - 1;
+let dragFrom = null, geom = null;
+
+data.series.forEach((s, i) => {
+  s.color = s.statewide ? '#1c1917' : palette[i % palette.length];
+  // The statewide sum dwarfs the lines it sums, so it starts hidden.
+  if (s.statewide && data.series.length > 1) hidden.add(s.name);
+});
+
+function esc(s) {
+  return String(s).replace(/[&<>"]/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;' })[c]);
+}
+
+function fmt(v) {
+  if (v === null) return '- -';
+  return Number.isInteger(v) ? v.toLocaleString('en-US') : v.toLocaleString('en-US', { minimumFractionDigits: 1, maximumFractionDigits: 1 });
+}
+
+function ticks(min, max, n) {
+  const span = max - min || Math.abs(max) || 1;
+  const raw = span / n, mag = Math.pow(10, Math.floor(Math.log10(raw)));
+  const step = [1, 2, 2.5, 5, 10].map(m => m * mag).find(s => s >= raw);
+  const out = [];
+  for (let v = Math.floor(min / step) * step; v <= max + step / 2; v += step) out.push(+v.toFixed(10));
+  return out;
+}
+
+function visible() { return data.series.filter(s => !hidden.has(s.name)); }
+
+function draw() {
+  const W = svg.clientWidth, H = svg.clientHeight;
+  svg.setAttribute('viewBox', '0 0 ' + W + ' ' + H);
+  let min = Infinity, max = -Infinity;
+  for (const s of visible()) {
+    for (let i = lo; i <= hi; i++) {
+      const v = s.values[i];
+      if (v !== null) { min = Math.min(min, v); max = Math.max(max, v); }
+    }
+  }
+  if (min === Infinity) { min = 0; max = 1; }
+  if (min > 0 && min < max / 2) min = 0;
+  const ys = ticks(min, max, 5);
+  const y0 = ys[0], y1 = ys[ys.length - 1];
+  const pw = W - margin.left - margin.right, ph = H - margin.top - margin.bottom;
+  const x = i => margin.left + (hi > lo ? (i - lo) / (hi - lo) : 0.5) * pw;
+  const y = v => margin.top + (1 - (v - y0) / (y1 - y0 || 1)) * ph;
+  geom = { x, pw };
+
+  let out = '';
+  for (const t of ys) {
+    out += '<line class="grid" x1="' + margin.left + '" x2="' + (W - margin.right) + '" y1="' + y(t) + '" y2="' + y(t) + '"/>';
+    out += '<text x="' + (margin.left - 6) + '" y="' + (y(t) + 4) + '" text-anchor="end">' + fmt(t) + '</text>';
+  }
+  out += '<line class="axis" x1="' + margin.left + '" x2="' + (W - margin.right) + '" y1="' + (H - margin.bottom) + '" y2="' + (H - margin.bottom) + '"/>';
+  const every = Math.max(1, Math.ceil((hi - lo + 1) / Math.max(1, Math.floor(pw / 64))));
+  for (let i = lo; i <= hi; i += every) {
+    out += '<text x="' + x(i) + '" y="' + (H - margin.bottom + 16) + '" text-anchor="middle">' + esc(data.dates[i]) + '</text>';
+  }
+  for (const s of visible()) {
+    let d = '', pen = false;
+    for (let i = lo; i <= hi; i++) {
+      const v = s.values[i];
+      if (v === null) { pen = false; continue; }
+      d += (pen ? 'L' : 'M') + x(i).toFixed(1) + ' ' + y(v).toFixed(1);
+      pen = true;
+    }
+    out += '<path class="line' + (s.statewide ? ' statewide' : '') + '" stroke="' + s.color + '" d="' + d + '"/>';
+    if (hi - lo < 40) {
+      for (let i = lo; i <= hi; i++) {
+        if (s.values[i] !== null) out += '<circle r="2.5" fill="' + s.color + '" cx="' + x(i) + '" cy="' + y(s.values[i]) + '"/>';
+      }
+    }
+  }
+  out += '<line id="cross" class="cross" y1="' + margin.top + '" y2="' + (H - margin.bottom) + '" visibility="hidden"/>';
+  out += '<rect id="select" class="select" y="' + margin.top + '" height="' + ph + '" width="0" visibility="hidden"/>';
+  svg.innerHTML = out;
+  reset.style.display = lo > 0 || hi < data.dates.length - 1 ? 'inline' : 'none';
+}
+
+function indexAt(evt) {
+  const r = svg.getBoundingClientRect();
+  const px = evt.clientX - r.left;
+  const i = hi > lo ? Math.round(lo + (px - margin.left) / geom.pw * (hi - lo)) : lo;
+  return Math.min(hi, Math.max(lo, i));
+}
+
+function showTip(evt) {
+  const i = indexAt(evt);
+  const cross = document.getElementById('cross');
+  cross.setAttribute('x1', geom.x(i));
+  cross.setAttribute('x2', geom.x(i));
+  cross.setAttribute('visibility', 'visible');
+  const rows = visible().filter(s => s.values[i] !== null).sort((a, b) => b.values[i] - a.values[i]);
+  let html = '<strong>' + esc(data.dates[i]) + '</strong><table>';
+  for (const s of rows.slice(0, 15)) {
+    html += '<tr><td><span class="sw" style="background:' + s.color + '"></span>' + esc(s.name) + '</td><td class="v">' + fmt(s.values[i]) + '</td></tr>';
+  }
+  html += '</table>';
+  if (rows.length > 15) html += '… and ' + (rows.length - 15) + ' more';
+  if (rows.length === 0) html += 'no data';
+  tip.innerHTML = html;
+  tip.style.display = 'block';
+  const r = svg.getBoundingClientRect();
+  let left = geom.x(i) + 12;
+  if (left + tip.offsetWidth > r.width) left = geom.x(i) - tip.offsetWidth - 12;
+  tip.style.left = Math.max(0, left) + 'px';
+  tip.style.top = margin.top + 'px';
+}
+
+svg.addEventListener('mousedown', evt => { dragFrom = indexAt(evt); });
+svg.addEventListener('mousemove', evt => {
+  showTip(evt);
+  if (dragFrom === null) return;
+  const a = geom.x(dragFrom), b = geom.x(indexAt(evt));
+  const sel = document.getElementById('select');
+  sel.setAttribute('x', Math.min(a, b));
+  sel.setAttribute('width', Math.abs(b - a));
+  sel.setAttribute('visibility', 'visible');
+});
+window.addEventListener('mouseup', evt => {
+  if (dragFrom === null) return;
+  const to = indexAt(evt), from = dragFrom;
+  dragFrom = null;
+  if (Math.abs(to - from) >= 1) {
+    lo = Math.min(from, to);
+    hi = Math.max(from, to);
+  }
+  draw();
+});
+svg.addEventListener('mouseleave', () => {
+  tip.style.display = 'none';
+  const cross = document.getElementById('cross');
+  if (cross) cross.setAttribute('visibility', 'hidden');
+});
+function zoomOut() { lo = 0; hi = data.dates.length - 1; draw(); }
+svg.addEventListener('dblclick', zoomOut);
+reset.addEventListener('click', zoomOut);
+window.addEventListener('resize', draw);
+
+const legend = document.getElementById('legend');
+for (const s of data.series) {
+  const b = document.createElement('button');
+  b.innerHTML = '<span class="sw" style="background:' + s.color + '"></span>' + esc(s.name);
+  b.classList.toggle('off', hidden.has(s.name));
+  b.addEventListener('click', () => {
+    if (hidden.has(s.name)) hidden.delete(s.name); else hidden.add(s.name);
+    b.classList.toggle('off', hidden.has(s.name));
+    draw();
+  });
+  legend.appendChild(b);
+}
+draw();
+</script>
+</body>
+</html>
+`))
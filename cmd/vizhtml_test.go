@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNewHTMLChart(t *testing.T) {
+	series := map[string][]dataPoint{
+		"MERCER":   {{"2024-06", 3}, {"2025-06", 4.5}},
+		"ATLANTIC": {{"2025-06", 2}},
+	}
+	dates := []string{"2024-06", "2025-06"}
+	c := newHTMLChart("Filings", series, dates, []dataPoint{{"2024-06", 10}, {"2025-06", 12}}, map[string]string{"MERCER": "renamed"})
+
+	var names []string
+	for _, s := range c.Series {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "ATLANTIC,MERCER,STATEWIDE" || !c.Series[2].Statewide {
+		t.Errorf("series = %v", names)
+	}
+	if c.Series[0].Values[0] != nil || *c.Series[0].Values[1] != 2 || *c.Series[1].Values[1] != 4.5 {
+		t.Errorf("ATLANTIC/MERCER values wrong: %+v", c.Series[:2])
+	}
+	if len(c.Notes) != 1 || c.Notes[0] != "MERCER: renamed" {
+		t.Errorf("notes = %q", c.Notes)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	series := map[string][]dataPoint{"ST. MARY'S </script>": {{"2024-06", 1}, {"2025-06", 2}}}
+	c := newHTMLChart("Filings — Grand Total", series, []string{"2024-06", "2025-06"}, nil, nil)
+	var buf bytes.Buffer
+	if err := writeHTML(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	// Self-contained: nothing is fetched from elsewhere.
+	if regexp.MustCompile(`(src|href)="?https?:`).MatchString(page) {
+		t.Error("page loads a remote resource")
+	}
+	if strings.Count(page, "</script>") != 1 {
+		t.Error("series name broke out of the script")
+	}
+
+	// The inlined data decodes back to the chart.
+	m := regexp.MustCompile(`const data = (.*);\n`).FindStringSubmatch(page)
+	if m == nil {
+		t.Fatal("no inlined data")
+	}
+	var got htmlChart
+	if err := json.Unmarshal([]byte(m[1]), &got); err != nil {
+		t.Fatalf("inlined data: %v", err)
+	}
+	if got.Title != c.Title || got.Series[0].Name != "ST. MARY'S </script>" || *got.Series[0].Values[1] != 2 {
+		t.Errorf("inlined data = %+v", got)
+	}
+}
@@ -2,36 +2,203 @@ package cmd
 
 import (
 	"fmt"
+	"image"
 	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
 	"math"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/text/encoding/charmap"
 	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
 	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 	"gonum.org/v1/plot/vg/vgpdf"
 )
 
-const (
-	pageWidth  = 8.5 * vg.Inch
-	pageHeight = 11 * vg.Inch
-	pdfMargin  = 0.75 * vg.Inch
-)
+const pdfMargin = 0.75 * vg.Inch
+
+// pageSize is a PDF page's dimensions.
+type pageSize struct {
+	width, height vg.Length
+}
+
+// pageSizes are the --page choices, in portrait orientation.
+var pageSizes = map[string]pageSize{
+	"letter": {8.5 * vg.Inch, 11 * vg.Inch},
+	"a4":     {210 * vg.Millimeter, 297 * vg.Millimeter},
+	"legal":  {8.5 * vg.Inch, 14 * vg.Inch},
+}
+
+// lookupPageSize returns the named page size, rotated if landscape.
+func lookupPageSize(name string, landscape bool) (pageSize, bool) {
+	size, ok := pageSizes[strings.ToLower(name)]
+	if landscape {
+		size.width, size.height = size.height, size.width
+	}
+	return size, ok
+}
 
 var chartBlue = color.RGBA{R: 31, G: 119, B: 180, A: 255}
 
-func renderPDF(path, title string, series map[string][]dataPoint, sortedDates []string, includeStatewide bool, singleEntity bool) error {
-	// Replace em dashes with plain dashes — the Liberation font in vgpdf
-	// doesn't render the em dash glyph correctly.
-	title = strings.ReplaceAll(title, "\u2014", "-")
-	title = strings.ReplaceAll(title, "\u2013", "-")
+// pdfCanvas is a vgpdf canvas that encodes text as Windows-1252, the code
+// page vgpdf builds its embedded fonts with. vgpdf writes strings to the PDF
+// byte for byte, so UTF-8 text such as an em dash or "é" would otherwise
+// come out as several unrelated glyphs.
+//
+// It also prints footer, if set, at the bottom of each page as the page is
+// finished.
+type pdfCanvas struct {
+	*vgpdf.Canvas
+	footer string
+}
+
+func (c pdfCanvas) NextPage() {
+	c.drawFooter()
+	c.Canvas.NextPage()
+}
+
+func (c pdfCanvas) WriteTo(w io.Writer) (int64, error) {
+	c.drawFooter()
+	return c.Canvas.WriteTo(w)
+}
+
+func (c pdfCanvas) drawFooter() {
+	if c.footer == "" {
+		return
+	}
+	dc := draw.New(c)
+	sty := draw.TextStyle{
+		Color:   color.Gray{Y: 100},
+		Font:    plot.DefaultFont,
+		Handler: plot.DefaultTextHandler,
+		XAlign:  draw.XCenter,
+	}
+	sty.Font.Size = vg.Points(8)
+	dc.FillText(sty, vg.Point{X: (dc.Min.X + dc.Max.X) / 2, Y: pdfMargin / 2}, c.footer)
+}
+
+func (c pdfCanvas) FillString(f font.Face, pt vg.Point, s string) {
+	c.Canvas.FillString(f, pt, toCP1252(s))
+}
 
-	c := vgpdf.New(pageWidth, pageHeight)
+// toCP1252 re-encodes s as Windows-1252, drawing characters outside that
+// code page as "?".
+func toCP1252(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if c, ok := charmap.Windows1252.EncodeRune(r); ok {
+			b = append(b, c)
+		} else {
+			b = append(b, '?')
+		}
+	}
+	return string(b)
+}
 
-	if singleEntity {
+// useFont makes the TrueType font at path the font for all chart text. It
+// is embedded in every PDF written afterwards.
+func useFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ttf, err := opentype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	fnt := font.Font{Typeface: font.Typeface(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))}
+	font.DefaultCache.Add(font.Collection{{Font: fnt, Face: ttf}})
+	plot.DefaultFont = fnt
+	return nil
+}
+
+// pdfReport describes the content of a rendered PDF report.
+type pdfReport struct {
+	title           string
+	series          map[string][]dataPoint
+	sortedDates     []string
+	statewidePoints []dataPoint
+	singleEntity    bool
+	chart           string            // "box", "hist" or "scatter" draws one page of that chart instead
+	hist            []histBin         // with chart "hist", the bins to draw
+	scatter         scatterChart      // with chart "scatter", the points to draw
+	overview        bool              // lead with the multi-series overview page
+	normalize       bool              // index overview lines to their first period
+	notes           map[string]string // per-entity continuity notes, shown under chart titles
+	page            pageSize          // zero means portrait US Letter
+	brand           pdfBranding
+	downsample      string // summary sparkline bucketing, see sparkBuckets
+	courtYear       bool   // bucket by court year rather than calendar year
+}
+
+// pdfBranding is optional report furniture for reports that get circulated.
+type pdfBranding struct {
+	title    string      // report heading, above the metric title
+	subtitle string      // line under the heading
+	author   string      // author or organization line
+	footer   string      // printed at the bottom of every page
+	logo     image.Image // drawn at the top right of the first page
+}
+
+// loadLogo reads a PNG or JPEG image for pdfBranding.logo.
+func loadLogo(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+func renderPDF(path string, rep pdfReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writePDF(f, rep); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writePDF renders rep as a PDF document to w.
+func writePDF(w io.Writer, rep pdfReport) error {
+	title := rep.title
+	series, sortedDates, statewidePoints := rep.series, rep.sortedDates, rep.statewidePoints
+
+	page := rep.page
+	if page == (pageSize{}) {
+		page = pageSizes["letter"]
+	}
+	c := pdfCanvas{Canvas: vgpdf.New(page.width, page.height), footer: rep.brand.footer}
+
+	if rep.chart == "box" {
+		area := pageArea(c)
+		area.Max.Y = drawBrandHeader(area, rep.brand)
+		drawBoxChart(area, title, series, sortedDates)
+	} else if rep.chart == "hist" {
+		area := pageArea(c)
+		area.Max.Y = drawBrandHeader(area, rep.brand)
+		drawHistChart(area, title, rep.hist)
+	} else if rep.chart == "scatter" {
+		area := pageArea(c)
+		area.Max.Y = drawBrandHeader(area, rep.brand)
+		drawScatterChart(area, title, rep.scatter)
+	} else if rep.singleEntity {
 		var name string
 		var points []dataPoint
 		for k, v := range series {
@@ -39,46 +206,61 @@ func renderPDF(path, title string, series map[string][]dataPoint, sortedDates []
 			points = v
 			break
 		}
-		drawChartPage(c, title+" - "+name, points, sortedDates)
+		area := pageArea(c)
+		area.Max.Y = drawBrandHeader(area, rep.brand)
+		drawChart(area, chartTitle(title, name, rep.notes), points, sortedDates)
 	} else {
 		names := sortedEntityNames(series)
 
-		var statewidePoints []dataPoint
-		if includeStatewide && len(names) > 1 {
-			stateAgg := make(map[string]float64)
-			for _, pts := range series {
-				for _, p := range pts {
-					stateAgg[p.date] += p.value
-				}
-			}
-			for _, d := range sortedDates {
-				if v, ok := stateAgg[d]; ok {
-					statewidePoints = append(statewidePoints, dataPoint{date: d, value: v})
-				}
-			}
+		if rep.overview {
+			drawOverviewPage(c, title, series, names, sortedDates, rep.normalize)
+			c.NextPage()
 		}
 
-		drawSummaryPages(c, title, series, names, sortedDates, statewidePoints)
+		drawSummaryPages(c, page, rep.brand, title, series, names, sortedDates, statewidePoints, rep.downsample, rep.courtYear)
 
 		for _, name := range names {
 			c.NextPage()
-			drawChartPage(c, title+" - "+name, series[name], sortedDates)
+			drawChartPage(c, chartTitle(title, name, rep.notes), series[name], sortedDates)
 		}
 		if len(statewidePoints) > 0 {
 			c.NextPage()
-			drawChartPage(c, title+" - STATEWIDE", statewidePoints, sortedDates)
+			drawChartPage(c, title+" — STATEWIDE", statewidePoints, sortedDates)
 		}
 	}
 
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	if _, err := c.WriteTo(f); err != nil {
-		f.Close()
-		return err
+	_, err := c.WriteTo(w)
+	return err
+}
+
+// renderPNG draws the chart of rep as a single image at the snapshot size:
+// its box plots, histogram or scatter chart, its one series, or the
+// overview of all of them. Branding and the per-entity pages are PDF only.
+func renderPNG(path string, rep pdfReport) error {
+	return writePNGFile(path, snapshotWidthPx, snapshotHeightPx, func(area draw.Canvas) {
+		switch {
+		case rep.chart == "box":
+			drawBoxChart(area, rep.title, rep.series, rep.sortedDates)
+		case rep.chart == "hist":
+			drawHistChart(area, rep.title, rep.hist)
+		case rep.chart == "scatter":
+			drawScatterChart(area, rep.title, rep.scatter)
+		case rep.singleEntity:
+			for name, points := range rep.series {
+				drawChart(area, chartTitle(rep.title, name, rep.notes), points, rep.sortedDates)
+			}
+		default:
+			drawOverview(area, rep.title, rep.series, sortedEntityNames(rep.series), rep.sortedDates, rep.normalize)
+		}
+	})
+}
+
+func chartTitle(title, name string, notes map[string]string) string {
+	t := title + " — " + name
+	if note, ok := notes[name]; ok {
+		t += "\n" + note
 	}
-	return f.Close()
+	return t
 }
 
 func sortedEntityNames(series map[string][]dataPoint) []string {
@@ -96,9 +278,13 @@ const (
 	valueColWidth    = 0.9 * vg.Inch
 )
 
-func drawSummaryPages(c *vgpdf.Canvas, title string, series map[string][]dataPoint, names []string, sortedDates []string, statewidePoints []dataPoint) {
-	usableW := pageWidth - 2*pdfMargin
-	usableH := pageHeight - 2*pdfMargin
+// sparkPointWidth is the narrowest spacing of summary sparkline values that
+// stays readable (4pt); longer histories are bucketed to fit.
+const sparkPointWidth = vg.Length(4)
+
+func drawSummaryPages(c pdfCanvas, page pageSize, brand pdfBranding, title string, series map[string][]dataPoint, names []string, sortedDates []string, statewidePoints []dataPoint, downsample string, courtYear bool) {
+	usableW := page.width - 2*pdfMargin
+	usableH := page.height - 2*pdfMargin
 	sparkColWidth := usableW - nameColWidth - valueColWidth
 
 	headerHeight := 1.0 * vg.Inch
@@ -109,6 +295,10 @@ func drawSummaryPages(c *vgpdf.Canvas, title string, series map[string][]dataPoi
 	if len(sortedDates) > 0 {
 		dateRange = fmt.Sprintf("%s to %s (%d periods)", sortedDates[0], sortedDates[len(sortedDates)-1], len(sortedDates))
 	}
+	buckets, bucketLabel := sparkBuckets(sortedDates, downsample, int(sparkColWidth/sparkPointWidth), courtYear)
+	if bucketLabel != "" {
+		dateRange += ", trend averaged " + bucketLabel
+	}
 
 	type row struct {
 		name   string
@@ -138,7 +328,7 @@ func drawSummaryPages(c *vgpdf.Canvas, title string, series map[string][]dataPoi
 
 		var yTop vg.Length
 		if pageNum == 1 {
-			yTop = area.Max.Y
+			yTop = drawBrandHeader(area, brand)
 			fillText(area, title, vg.Points(14), area.Min.X, yTop-vg.Points(14), color.Black)
 			fillText(area, dateRange, vg.Points(10), area.Min.X, yTop-0.35*vg.Inch, color.Gray{Y: 100})
 
@@ -187,7 +377,7 @@ func drawSummaryPages(c *vgpdf.Canvas, title string, series map[string][]dataPoi
 					Max: vg.Point{X: sparkX + sparkColWidth, Y: sparkY + summaryRowHeight - vg.Points(3)},
 				},
 			}
-			drawSparkline(sparkArea, vals)
+			drawSparkline(sparkArea, bucketValues(vals, buckets))
 
 			drawn++
 		}
@@ -238,7 +428,58 @@ func drawSparkline(c draw.Canvas, vals []float64) {
 	p.Draw(c)
 }
 
-func drawChartPage(c *vgpdf.Canvas, title string, points []dataPoint, sortedDates []string) {
+func drawChartPage(c vg.CanvasSizer, title string, points []dataPoint, sortedDates []string) {
+	drawChart(pageArea(c), title, points, sortedDates)
+}
+
+// pageArea returns the page inside its margins.
+func pageArea(c vg.CanvasSizer) draw.Canvas {
+	return draw.Crop(draw.New(c), pdfMargin, -pdfMargin, pdfMargin, -pdfMargin)
+}
+
+// logoHeight is the height the branding logo is scaled to.
+const logoHeight = 0.6 * vg.Inch
+
+// drawBrandHeader draws the branding title, subtitle, author line and logo
+// at the top of area, returning the y coordinate below them. With no
+// branding it returns area.Max.Y.
+func drawBrandHeader(area draw.Canvas, b pdfBranding) vg.Length {
+	top := area.Max.Y
+	y := top
+	for _, line := range []struct {
+		text string
+		size vg.Length
+		clr  color.Color
+	}{
+		{b.title, vg.Points(16), color.Black},
+		{b.subtitle, vg.Points(11), color.Gray{Y: 60}},
+		{b.author, vg.Points(10), color.Gray{Y: 100}},
+	} {
+		if line.text == "" {
+			continue
+		}
+		fillText(area, line.text, line.size, area.Min.X, y-line.size, line.clr)
+		y -= line.size + vg.Points(8)
+	}
+
+	if b.logo != nil {
+		bounds := b.logo.Bounds()
+		w := logoHeight * vg.Length(bounds.Dx()) / vg.Length(bounds.Dy())
+		area.DrawImage(vg.Rectangle{
+			Min: vg.Point{X: area.Max.X - w, Y: top - logoHeight},
+			Max: vg.Point{X: area.Max.X, Y: top},
+		}, b.logo)
+		y = min(y, top-logoHeight)
+	}
+
+	if y < top {
+		y -= vg.Points(12)
+	}
+	return y
+}
+
+// drawChart draws a single-series line chart filling area.
+func drawChart(area draw.Canvas, title string, points []dataPoint, sortedDates []string) {
 	sort.Slice(points, func(i, j int) bool {
 		return points[i].date < points[j].date
 	})
@@ -297,11 +538,114 @@ func drawChartPage(c *vgpdf.Canvas, title string, points []dataPoint, sortedDate
 
 	p.Y.Tick.Marker = numTicks{}
 
-	dc := draw.New(c)
-	area := draw.Crop(dc, pdfMargin, -pdfMargin, pdfMargin, -pdfMargin)
 	p.Draw(area)
 }
 
+const (
+	legendRowHeight   = 0.22 * vg.Inch
+	legendColumnWidth = 1.6 * vg.Inch
+)
+
+// drawOverviewPage draws every series as a colored line on one page with a
+// legend below it. When normalize is set, each series is indexed to 100 at
+// its first non-missing value so entities of different sizes share a scale.
+func drawOverviewPage(c vg.CanvasSizer, title string, series map[string][]dataPoint, names []string, sortedDates []string, normalize bool) {
+	dc := draw.New(c)
+	drawOverview(draw.Crop(dc, pdfMargin, -pdfMargin, pdfMargin, -pdfMargin), title, series, names, sortedDates, normalize)
+}
+
+// drawOverview draws the multi-series chart and its legend filling area.
+func drawOverview(area draw.Canvas, title string, series map[string][]dataPoint, names []string, sortedDates []string, normalize bool) {
+	p := plot.New()
+	p.Title.Text = title
+	if normalize {
+		p.Title.Text += " (index, first period = 100)"
+	}
+	p.Title.TextStyle.Font.Size = vg.Points(12)
+	p.BackgroundColor = color.White
+	p.Add(plotter.NewGrid())
+
+	type legendEntry struct {
+		name  string
+		style draw.LineStyle
+	}
+	var legend []legendEntry
+
+	for i, name := range names {
+		vals := alignValues(series[name], sortedDates)
+		if normalize {
+			vals = indexToFirst(vals)
+		}
+		var pts plotter.XYs
+		for x, v := range vals {
+			if !math.IsNaN(v) {
+				pts = append(pts, plotter.XY{X: float64(x), Y: v})
+			}
+		}
+		if len(pts) == 0 {
+			continue
+		}
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			continue
+		}
+		line.Color = plotutil.Color(i)
+		line.Dashes = plotutil.Dashes(i / len(plotutil.DefaultColors))
+		line.Width = vg.Points(1.5)
+		p.Add(line)
+		legend = append(legend, legendEntry{name: name, style: line.LineStyle})
+	}
+
+	p.X.Tick.Marker = dateTicks(sortedDates)
+	p.X.Min = -0.5
+	p.X.Max = float64(len(sortedDates)) - 0.5
+	p.X.Tick.Label.Rotation = math.Pi / 4
+	p.X.Tick.Label.XAlign = draw.XRight
+	p.X.Tick.Label.YAlign = draw.YCenter
+	p.Y.Tick.Marker = numTicks{}
+
+	legendColumns := int((area.Max.X - area.Min.X) / legendColumnWidth)
+	if legendColumns < 1 {
+		legendColumns = 1
+	}
+	legendRows := (len(legend) + legendColumns - 1) / legendColumns
+	legendHeight := vg.Length(legendRows)*legendRowHeight + vg.Points(12)
+	chartArea := draw.Crop(area, 0, 0, legendHeight, 0)
+	p.Draw(chartArea)
+
+	colWidth := (area.Max.X - area.Min.X) / vg.Length(legendColumns)
+	sampleWidth := 0.35 * vg.Inch
+	for i, e := range legend {
+		col := i % legendColumns
+		row := i / legendColumns
+		x := area.Min.X + vg.Length(col)*colWidth
+		y := area.Min.Y + legendHeight - vg.Points(12) - vg.Length(row)*legendRowHeight - legendRowHeight/2
+		area.StrokeLine2(e.style, x, y, x+sampleWidth, y)
+		fillText(area, e.name, vg.Points(8), x+sampleWidth+vg.Points(4), y-vg.Points(3), color.Black)
+	}
+}
+
+// indexToFirst rescales vals so the first non-NaN value becomes 100. Series
+// whose first value is zero can't be indexed and are returned as all NaN.
+func indexToFirst(vals []float64) []float64 {
+	base := math.NaN()
+	for _, v := range vals {
+		if !math.IsNaN(v) {
+			base = v
+			break
+		}
+	}
+	out := make([]float64, len(vals))
+	for i, v := range vals {
+		if math.IsNaN(base) || base == 0 {
+			out[i] = math.NaN()
+		} else {
+			out[i] = v / base * 100
+		}
+	}
+	return out
+}
+
 type dateTicks []string
 
 func (dt dateTicks) Ticks(min, max float64) []plot.Tick {
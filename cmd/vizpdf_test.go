@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/vgpdf"
+)
+
+func TestLookupPageSize(t *testing.T) {
+	size, ok := lookupPageSize("A4", true)
+	if !ok {
+		t.Fatal("A4 not found")
+	}
+	if size.width != 297*vg.Millimeter || size.height != 210*vg.Millimeter {
+		t.Errorf("A4 landscape = %v x %v", size.width, size.height)
+	}
+	if _, ok := lookupPageSize("tabloid", false); ok {
+		t.Error("unexpected page size tabloid")
+	}
+}
+
+func TestWritePDF_PageSize(t *testing.T) {
+	rep := pdfReport{
+		title:        "Filings",
+		series:       map[string][]dataPoint{"ATLANTIC": {{"2024-06", 1}, {"2025-06", 2}}},
+		sortedDates:  []string{"2024-06", "2025-06"},
+		singleEntity: true,
+	}
+	rep.page, _ = lookupPageSize("legal", true)
+
+	var buf bytes.Buffer
+	if err := writePDF(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/MediaBox [0 0 1008.00 612.00]")) {
+		t.Error("PDF is not landscape US Legal")
+	}
+}
+
+func TestIndexToFirst(t *testing.T) {
+	nan := math.NaN()
+	for _, tc := range []struct {
+		in, want []float64
+	}{
+		{[]float64{50, 100, 25}, []float64{100, 200, 50}},
+		{[]float64{nan, 20, nan, 30}, []float64{nan, 100, nan, 150}},
+		{[]float64{0, 10}, []float64{nan, nan}},
+		{[]float64{nan, nan}, []float64{nan, nan}},
+		{nil, []float64{}},
+	} {
+		got := indexToFirst(tc.in)
+		if len(got) != len(tc.want) {
+			t.Errorf("indexToFirst(%v) = %v, want %v", tc.in, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] && !(math.IsNaN(got[i]) && math.IsNaN(tc.want[i])) {
+				t.Errorf("indexToFirst(%v) = %v, want %v", tc.in, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRenderPDF_Overview(t *testing.T) {
+	rep := pdfReport{
+		title: "Filings",
+		series: map[string][]dataPoint{
+			"ATLANTIC": {{"2024-06", 100}, {"2025-06", 150}},
+			"BERGEN":   {{"2024-06", 4000}, {"2025-06", 3000}},
+			"CAPE MAY": {{"2024-06", 0}, {"2025-06", 10}},
+			"ESSEX":    {{"2025-06", 50}},
+		},
+		sortedDates: []string{"2024-06", "2025-06"},
+		overview:    true,
+		normalize:   true,
+	}
+	path := filepath.Join(t.TempDir(), "overview.pdf")
+	if err := renderPDF(path, rep); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("not a PDF: %q", data[:min(len(data), 16)])
+	}
+	// The overview, the summary table, then a page per series.
+	pages := bytes.Count(data, []byte("/Type /Page\n"))
+	if want := 1 + 1 + len(rep.series); pages != want {
+		t.Errorf("%d pages, want %d", pages, want)
+	}
+}
+
+func TestToCP1252(t *testing.T) {
+	got := toCP1252("Filings — PEÑA – café ✓")
+	want := "Filings \x97 PE\xd1A \x96 caf\xe9 ?"
+	if got != want {
+		t.Errorf("toCP1252 = %q, want %q", got, want)
+	}
+}
+
+func TestUseFont(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GoRegular.ttf")
+	if err := os.WriteFile(path, goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defaultFont := plot.DefaultFont
+	defer func() { plot.DefaultFont = defaultFont }()
+
+	if err := useFont(path); err != nil {
+		t.Fatal(err)
+	}
+	rep := pdfReport{
+		title:        "Filings — Grand Total",
+		series:       map[string][]dataPoint{"ATLANTIC": {{"2024-06", 1}, {"2025-06", 2}}},
+		sortedDates:  []string{"2024-06", "2025-06"},
+		singleEntity: true,
+	}
+	var buf bytes.Buffer
+	if err := writePDF(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/BaseFont /GoRegular")) {
+		t.Error("custom font not embedded")
+	}
+
+	if err := useFont(filepath.Join(t.TempDir(), "missing.ttf")); err == nil {
+		t.Error("expected an error for a missing font file")
+	}
+}
+
+func TestWritePDF_Branding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logo.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	logo, err := loadLogo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep := pdfReport{
+		title: "Filings",
+		series: map[string][]dataPoint{
+			"ATLANTIC": {{"2024-06", 1}, {"2025-06", 2}},
+			"BERGEN":   {{"2024-06", 3}, {"2025-06", 4}},
+		},
+		sortedDates: []string{"2024-06", "2025-06"},
+		brand:       pdfBranding{title: "Backlog Review", author: "Office of Research", footer: "Draft", logo: logo},
+	}
+	var buf bytes.Buffer
+	if err := writePDF(&buf, rep); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/Subtype /Image")) {
+		t.Error("PDF has no logo image")
+	}
+}
+
+func TestDrawBrandHeader(t *testing.T) {
+	c := pdfCanvas{Canvas: vgpdf.New(8.5*vg.Inch, 11*vg.Inch)}
+	area := pageArea(c)
+	if y := drawBrandHeader(area, pdfBranding{}); y != area.Max.Y {
+		t.Errorf("empty branding moved the top to %v", y)
+	}
+	text := drawBrandHeader(area, pdfBranding{title: "Title", subtitle: "Subtitle"})
+	if text >= area.Max.Y {
+		t.Fatalf("branding header took no space")
+	}
+	logo := drawBrandHeader(area, pdfBranding{title: "Title", logo: image.NewGray(image.Rect(0, 0, 10, 10))})
+	if logo > area.Max.Y-logoHeight {
+		t.Errorf("header ends at %v, above the bottom of the logo", logo)
+	}
+}
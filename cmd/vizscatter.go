@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// scatterPoint is one municipality's pair of values in a scatter chart.
+type scatterPoint struct {
+	name, county string
+	x, y         float64
+	label        int // 1 for the first outlier labeled by name, 2 for the next; 0 if unlabeled
+}
+
+// scatterChart is what --chart scatter draws: a point per municipality.
+type scatterChart struct {
+	xLabel, yLabel string
+	points         []scatterPoint
+	byCounty       bool // color the points by county, with a legend
+}
+
+// entityCounties maps each municipality's series name to its county.
+func entityCounties(records []timeRecord) map[string]string {
+	counties := make(map[string]string)
+	for _, rec := range records {
+		for _, s := range rec.stats {
+			counties[entityLabel(s)] = strings.ToUpper(s.County)
+		}
+	}
+	return counties
+}
+
+// buildScatter pairs each series' values in xSeries and ySeries at date,
+// skipping those missing either, sorted by name.
+func buildScatter(xSeries, ySeries map[string][]dataPoint, date string, counties map[string]string) []scatterPoint {
+	valueAt := func(pts []dataPoint) float64 {
+		for _, p := range pts {
+			if p.date == date {
+				return p.value
+			}
+		}
+		return math.NaN()
+	}
+	var pts []scatterPoint
+	for name, xs := range xSeries {
+		x, y := valueAt(xs), valueAt(ySeries[name])
+		if math.IsNaN(x) || math.IsNaN(y) {
+			continue
+		}
+		pts = append(pts, scatterPoint{name: name, county: counties[name], x: x, y: y})
+	}
+	sort.Slice(pts, func(i, j int) bool { return pts[i].name < pts[j].name })
+	return pts
+}
+
+// markScatterOutliers labels up to maxLabels points whose x or y lies
+// beyond the 1.5 IQR fences a box plot's whiskers stop at, furthest
+// first. The x and y outliers take turns, so one skewed metric doesn't
+// take every label.
+func markScatterOutliers(pts []scatterPoint, maxLabels int) {
+	var queues [2][]int
+	for axis := range queues {
+		vals := make(plotter.Values, len(pts))
+		for i, p := range pts {
+			vals[i] = p.x
+			if axis == 1 {
+				vals[i] = p.y
+			}
+		}
+		bp, err := plotter.NewBoxPlot(0, 0, vals)
+		if err != nil {
+			continue
+		}
+		distance := func(i int) float64 {
+			return math.Max(vals[i]-bp.Quartile3, bp.Quartile1-vals[i])
+		}
+		q := append([]int(nil), bp.Outside...)
+		sort.SliceStable(q, func(a, b int) bool { return distance(q[a]) > distance(q[b]) })
+		queues[axis] = q
+	}
+
+	for i := range pts {
+		pts[i].label = 0
+	}
+	n := 0
+	for len(queues[0])+len(queues[1]) > 0 && n < maxLabels {
+		for axis := range queues {
+			for len(queues[axis]) > 0 && n < maxLabels {
+				i := queues[axis][0]
+				queues[axis] = queues[axis][1:]
+				if pts[i].label == 0 {
+					n++
+					pts[i].label = n
+					break
+				}
+			}
+		}
+	}
+}
+
+// scatterR returns the Pearson correlation of the points' x and y.
+func scatterR(pts []scatterPoint) float64 {
+	xs := make([]float64, len(pts))
+	ys := make([]float64, len(pts))
+	for i, p := range pts {
+		xs[i], ys[i] = p.x, p.y
+	}
+	return pearson(xs, ys)
+}
+
+// renderScatterSummary prints what a terminal can show of a scatter chart:
+// the number of points, their correlation and the labeled outliers.
+func renderScatterSummary(title string, sc scatterChart) {
+	fmt.Println(paint(colorStdout, styleBold, title))
+	fmt.Println()
+	if len(sc.points) == 0 {
+		fmt.Println("(no data)")
+		return
+	}
+	fmt.Printf("%d courts; r = %s\n", len(sc.points), formatR(scatterR(sc.points)))
+
+	var labeled []scatterPoint
+	width := len("Municipality")
+	for _, p := range sc.points {
+		if p.label > 0 {
+			labeled = append(labeled, p)
+			width = max(width, len(p.name))
+		}
+	}
+	if len(labeled) > 0 {
+		sort.Slice(labeled, func(i, j int) bool { return labeled[i].label < labeled[j].label })
+		fmt.Println()
+		fmt.Println(paint(colorStdout, styleDim, fmt.Sprintf("%-*s  %-10s  %14s  %14s", width, "Municipality", "County", sc.xLabel, sc.yLabel)))
+		for _, p := range labeled {
+			fmt.Printf("%-*s  %-10s  %14s  %14s\n", width, p.name, p.county, formatNum(p.x), formatNum(p.y))
+		}
+	}
+	fmt.Println("\nUse --pdf or --png to draw the chart.")
+}
+
+// drawScatterChart draws sc filling area, with the correlation under the
+// title and the outliers labeled by name.
+func drawScatterChart(area draw.Canvas, title string, sc scatterChart) {
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("%s\n%d courts, r = %s", title, len(sc.points), formatR(scatterR(sc.points)))
+	p.Title.TextStyle.Font.Size = vg.Points(12)
+	p.BackgroundColor = color.White
+	p.Add(plotter.NewGrid())
+	p.X.Label.Text = sc.xLabel
+	p.Y.Label.Text = sc.yLabel
+	p.X.Tick.Marker = numTicks{}
+	p.Y.Tick.Marker = numTicks{}
+
+	groups := map[string]plotter.XYs{}
+	var names []string
+	var labels plotter.XYLabels
+	for _, pt := range sc.points {
+		key := ""
+		if sc.byCounty {
+			key = pt.county
+		}
+		if _, ok := groups[key]; !ok {
+			names = append(names, key)
+		}
+		groups[key] = append(groups[key], plotter.XY{X: pt.x, Y: pt.y})
+		if pt.label > 0 {
+			labels.XYs = append(labels.XYs, plotter.XY{X: pt.x, Y: pt.y})
+			labels.Labels = append(labels.Labels, pt.name)
+		}
+	}
+	sort.Strings(names)
+
+	type legendEntry struct {
+		name  string
+		style draw.GlyphStyle
+	}
+	var legend []legendEntry
+	for i, name := range names {
+		s, err := plotter.NewScatter(groups[name])
+		if err != nil {
+			continue
+		}
+		s.GlyphStyle.Shape = draw.CircleGlyph{}
+		s.GlyphStyle.Radius = vg.Points(2)
+		s.GlyphStyle.Color = chartBlue
+		if sc.byCounty {
+			s.GlyphStyle.Color = plotutil.Color(i)
+			s.GlyphStyle.Shape = plotutil.Shape(i / len(plotutil.DefaultColors))
+		}
+		p.Add(s)
+		legend = append(legend, legendEntry{name: name, style: s.GlyphStyle})
+	}
+	if len(labels.XYs) > 0 {
+		l, err := plotter.NewLabels(labels)
+		if err == nil {
+			for i := range l.TextStyle {
+				l.TextStyle[i].Font.Size = vg.Points(7)
+				l.TextStyle[i].XAlign = draw.XLeft
+			}
+			l.Offset = vg.Point{X: vg.Points(4), Y: vg.Points(2)}
+			p.Add(l)
+			// Leave room for the labels of the rightmost points.
+			p.X.Max += (p.X.Max - p.X.Min) * 0.08
+		}
+	}
+
+	if !sc.byCounty {
+		p.Draw(area)
+		return
+	}
+
+	legendColumns := max(int((area.Max.X-area.Min.X)/legendColumnWidth), 1)
+	legendRows := (len(legend) + legendColumns - 1) / legendColumns
+	legendHeight := vg.Length(legendRows)*legendRowHeight + vg.Points(12)
+	p.Draw(draw.Crop(area, 0, 0, legendHeight, 0))
+
+	colWidth := (area.Max.X - area.Min.X) / vg.Length(legendColumns)
+	for i, e := range legend {
+		x := area.Min.X + vg.Length(i%legendColumns)*colWidth
+		y := area.Min.Y + legendHeight - vg.Points(12) - vg.Length(i/legendColumns)*legendRowHeight - legendRowHeight/2
+		area.DrawGlyph(e.style, vg.Point{X: x + e.style.Radius, Y: y})
+		fillText(area, e.name, vg.Points(8), x+e.style.Radius*2+vg.Points(4), y-vg.Points(3), color.Black)
+	}
+}
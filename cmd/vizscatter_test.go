@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildScatter(t *testing.T) {
+	xs := map[string][]dataPoint{
+		"A": {{"2024-06", 10}, {"2025-06", 11}},
+		"B": {{"2024-06", 20}},
+		"C": {{"2025-06", 30}}, // no x at 2024-06
+	}
+	ys := map[string][]dataPoint{
+		"A": {{"2024-06", 1}},
+		"B": {{"2024-06", 2}},
+		"C": {{"2024-06", 3}},
+	}
+	pts := buildScatter(xs, ys, "2024-06", map[string]string{"A": "ATLANTIC", "B": "BERGEN"})
+	if len(pts) != 2 {
+		t.Fatalf("got %d points, want 2: %+v", len(pts), pts)
+	}
+	if p := pts[0]; p.name != "A" || p.county != "ATLANTIC" || p.x != 10 || p.y != 1 {
+		t.Errorf("pts[0] = %+v", p)
+	}
+	if p := pts[1]; p.name != "B" || p.x != 20 || p.y != 2 {
+		t.Errorf("pts[1] = %+v", p)
+	}
+}
+
+func TestMarkScatterOutliers(t *testing.T) {
+	var pts []scatterPoint
+	for i := range 9 {
+		pts = append(pts, scatterPoint{name: string(rune('A' + i)), x: float64(i), y: float64(i)})
+	}
+	// X is far out on x, Y further out on y than Z is.
+	pts = append(pts,
+		scatterPoint{name: "X", x: 1000, y: 4},
+		scatterPoint{name: "Y", x: 4, y: 500},
+		scatterPoint{name: "Z", x: 4, y: 100},
+	)
+	markScatterOutliers(pts, 2)
+	got := map[string]int{}
+	for _, p := range pts {
+		if p.label > 0 {
+			got[p.name] = p.label
+		}
+	}
+	// x and y take turns, so Y is labeled before the closer Z.
+	if len(got) != 2 || got["X"] != 1 || got["Y"] != 2 {
+		t.Errorf("labels = %v", got)
+	}
+
+	markScatterOutliers(pts, 10)
+	if pts[11].label != 3 {
+		t.Errorf("Z label = %d, want 3", pts[11].label)
+	}
+}
+
+func TestRenderPNG_Scatter(t *testing.T) {
+	sc := scatterChart{xLabel: "Filings", yLabel: "Backlog", byCounty: true, points: []scatterPoint{
+		{name: "A", county: "ATLANTIC", x: 1, y: 2},
+		{name: "B", county: "BERGEN", x: 3, y: 4, label: 1},
+	}}
+	path := filepath.Join(t.TempDir(), "scatter.png")
+	if err := renderPNG(path, pdfReport{title: "Backlog vs Filings", chart: "scatter", scatter: sc}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Errorf("no image written: %v", err)
+	}
+}
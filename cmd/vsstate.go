@@ -0,0 +1,70 @@
+package cmd
+
+import "math"
+
+// validVsState are the choices for --vs-state: values as they are, or
+// their difference from or ratio to the statewide average.
+var validVsState = []string{"none", "diff", "ratio"}
+
+// vsStateLabel is the title suffix for a --vs-state mode.
+func vsStateLabel(mode string) string {
+	switch mode {
+	case "diff":
+		return " (difference from state average)"
+	case "ratio":
+		return " (ratio to state average)"
+	}
+	return ""
+}
+
+// stateAverage is the statewide average for each period. For counts it is
+// the mean over peers, every entity of the level being compared, so a
+// county is measured against the average county rather than the state
+// total. For rates it is the statewide rate, state.
+func stateAverage(peers map[string][]dataPoint, state []dataPoint, rate bool) map[string]float64 {
+	avg := make(map[string]float64)
+	if rate {
+		for _, p := range state {
+			if !math.IsNaN(p.value) {
+				avg[p.date] = p.value
+			}
+		}
+		return avg
+	}
+	counts := make(map[string]int)
+	for _, pts := range peers {
+		for _, p := range pts {
+			if !math.IsNaN(p.value) {
+				avg[p.date] += p.value
+				counts[p.date]++
+			}
+		}
+	}
+	for date, n := range counts {
+		avg[date] /= float64(n)
+	}
+	return avg
+}
+
+// vsState re-expresses each series against avg: as value minus the average
+// for "diff", or value over it for "ratio", where 1 is the state norm.
+// Periods with no average, or an average of zero for a ratio, are dropped.
+func vsState(series map[string][]dataPoint, avg map[string]float64, mode string) map[string][]dataPoint {
+	out := make(map[string][]dataPoint, len(series))
+	for key, pts := range series {
+		var rel []dataPoint
+		for _, p := range pts {
+			a, ok := avg[p.date]
+			if !ok || (mode == "ratio" && a == 0) {
+				continue
+			}
+			v := p.value - a
+			if mode == "ratio" {
+				v = p.value / a
+			}
+			rel = append(rel, dataPoint{date: p.date, value: v})
+		}
+		out[key] = rel
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestVsState(t *testing.T) {
+	records := []timeRecord{{date: "2024-06", stats: []parser.MunicipalityStats{
+		rateStat("ATLANTIC", "A", "100", "90", "90"),
+		rateStat("ATLANTIC", "B", "300", "150", "50"),
+		rateStat("BERGEN", "C", "200", "200", "100"),
+	}}}
+
+	// Counts: each county against the average county, (400+200)/2.
+	series, _ := buildSeries(records, "filings", "grand-total", "county", "", "")
+	peers, _ := buildSeries(records, "filings", "grand-total", "county", "", "")
+	state, _ := buildSeries(records, "filings", "grand-total", "state", "", "")
+	avg := stateAverage(peers, state["STATEWIDE"], false)
+	if avg["2024-06"] != 300 {
+		t.Fatalf("county average = %v, want 300", avg["2024-06"])
+	}
+	diff := vsState(series, avg, "diff")
+	if diff["ATLANTIC"][0].value != 100 || diff["BERGEN"][0].value != -100 {
+		t.Errorf("diff = %v", diff)
+	}
+	ratio := vsState(series, avg, "ratio")
+	if math.Abs(ratio["BERGEN"][0].value-2.0/3) > 1e-9 {
+		t.Errorf("ratio = %v", ratio)
+	}
+
+	// Rates: against the statewide rate, whatever municipalities are shown.
+	series, _ = aggregateSeries(records, "clearance-pct", "grand-total", "municipality", "ATLANTIC", "", true)
+	peers, _ = aggregateSeries(records, "clearance-pct", "grand-total", "municipality", "", "", true)
+	state, _ = aggregateSeries(records, "clearance-pct", "grand-total", "state", "", "", true)
+	diff = vsState(series, stateAverage(peers, state["STATEWIDE"], true), "diff")
+	if len(diff) != 2 || math.Abs(diff["B"][0].value-(50-440.0/600*100)) > 1e-9 {
+		t.Errorf("rate diff = %v", diff)
+	}
+
+	if got := vsState(series, map[string]float64{"2024-06": 0}, "ratio"); len(got["A"]) != 0 {
+		t.Errorf("ratio to a zero average kept %v", got["A"])
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// fileStamp is what the watcher compares to notice a PDF has changed.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}
+}
+
+// pdfWatcher polls a directory for PDFs that are new or have changed.
+// Because files may still be being written when first seen, a PDF is only
+// reported once its size and modification time are unchanged between two
+// consecutive scans.
+type pdfWatcher struct {
+	dir       string
+	recursive bool
+	seen      map[string]fileStamp // as of the last time the PDF was reported
+	pending   map[string]fileStamp // changed, waiting to settle
+}
+
+func newPDFWatcher(dir string, recursive bool) *pdfWatcher {
+	return &pdfWatcher{
+		dir:       dir,
+		recursive: recursive,
+		seen:      make(map[string]fileStamp),
+		pending:   make(map[string]fileStamp),
+	}
+}
+
+// list returns the stamp of every PDF currently in the watched directory.
+func (w *pdfWatcher) list() (map[string]fileStamp, error) {
+	found := make(map[string]fileStamp)
+	err := filepath.WalkDir(w.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A file removed mid-walk is simply gone.
+			if p != w.dir && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if p != w.dir && (!w.recursive || d.Name() == quarantineDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".pdf" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		found[p] = stampOf(info)
+		return nil
+	})
+	return found, err
+}
+
+// prime records the PDFs already in the directory, returning those that
+// need parsing now: the ones for which upToDate reports false.
+func (w *pdfWatcher) prime(upToDate func(path string, stamp fileStamp) bool) ([]string, error) {
+	found, err := w.list()
+	if err != nil {
+		return nil, err
+	}
+	var ready []string
+	for p, st := range found {
+		w.seen[p] = st
+		if !upToDate(p, st) {
+			ready = append(ready, p)
+		}
+	}
+	sort.Strings(ready)
+	return ready, nil
+}
+
+// scan returns the PDFs that have appeared or changed and since settled.
+func (w *pdfWatcher) scan() ([]string, error) {
+	found, err := w.list()
+	if err != nil {
+		return nil, err
+	}
+	var ready []string
+	for p, st := range found {
+		if prev, ok := w.seen[p]; ok && prev == st {
+			delete(w.pending, p)
+			continue
+		}
+		if prev, ok := w.pending[p]; ok && prev == st {
+			delete(w.pending, p)
+			w.seen[p] = st
+			ready = append(ready, p)
+			continue
+		}
+		w.pending[p] = st
+	}
+	// Forget removed files so one dropped in again is parsed again.
+	for p := range w.seen {
+		if _, ok := found[p]; !ok {
+			delete(w.seen, p)
+		}
+	}
+	for p := range w.pending {
+		if _, ok := found[p]; !ok {
+			delete(w.pending, p)
+		}
+	}
+	sort.Strings(ready)
+	return ready, nil
+}
+
+// watchPDFs parses the PDFs in dir whose output is missing or stale, then
+// polls every interval and parses each new or changed PDF once it settles.
+// It runs until the process is interrupted.
+func watchPDFs(dir string, recursive bool, interval time.Duration, upToDate func(string, fileStamp) bool, parse func(string)) error {
+	w := newPDFWatcher(dir, recursive)
+	ready, err := w.prime(upToDate)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "watching %s for PDFs every %s; press Ctrl-C to stop\n", dir, interval)
+	for {
+		for _, p := range ready {
+			parse(p)
+		}
+		time.Sleep(interval)
+		ready, err = w.scan()
+		if err != nil {
+			return err
+		}
+	}
+}
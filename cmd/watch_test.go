@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPDFWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	scan := func(w *pdfWatcher) []string {
+		t.Helper()
+		ready, err := w.scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ready
+	}
+
+	a := write("a.pdf", "a")
+	done := write("done.pdf", "done")
+	write("notes.txt", "x")
+	write("sub/c.pdf", "c")
+
+	w := newPDFWatcher(dir, false)
+	ready, err := w.prime(func(p string, _ fileStamp) bool { return p == done })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{a}; !reflect.DeepEqual(ready, want) {
+		t.Fatalf("prime = %v, want %v", ready, want)
+	}
+	if got := scan(w); got != nil {
+		t.Errorf("unchanged directory: scan = %v", got)
+	}
+
+	// A new file is reported once it has settled for one scan.
+	b := write("b.pdf", "b")
+	if got := scan(w); got != nil {
+		t.Errorf("new file reported before settling: %v", got)
+	}
+	if got, want := scan(w), []string{b}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after settling, scan = %v, want %v", got, want)
+	}
+
+	// A file still growing waits until it stops.
+	write("a.pdf", "aa")
+	scan(w)
+	write("a.pdf", "aaa")
+	if got := scan(w); got != nil {
+		t.Errorf("growing file reported: %v", got)
+	}
+	if got, want := scan(w), []string{a}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after change, scan = %v, want %v", got, want)
+	}
+
+	// A file removed and dropped in again is reported again.
+	os.Remove(b)
+	scan(w)
+	write("b.pdf", "b")
+	scan(w)
+	if got, want := scan(w), []string{b}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after re-adding, scan = %v, want %v", got, want)
+	}
+}
+
+func TestPDFWatcher_Recursive(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2023/a.pdf", quarantineDir + "/bad.pdf"} {
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := newPDFWatcher(dir, true)
+	ready, err := w.prime(func(string, fileStamp) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "2023", "a.pdf")}; !reflect.DeepEqual(ready, want) {
+		t.Errorf("prime = %v, want %v", ready, want)
+	}
+}
@@ -1,25 +1,35 @@
 package cmd
 
 import (
+	"bytes"
 	"embed"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
 )
 
 //go:embed web.html
 var htmlContent embed.FS
 
 type metadata struct {
-	Counties       []string                `json:"counties"`
-	Municipalities map[string][]string     `json:"municipalities"`
-	Metrics        []labelValue            `json:"metrics"`
-	Types          []labelValue            `json:"types"`
+	Datasets       []string            `json:"datasets,omitempty"`
+	Counties       []string            `json:"counties"`
+	Municipalities map[string][]string `json:"municipalities"`
+	Metrics        []labelValue        `json:"metrics"`
+	Types          []labelValue        `json:"types"`
+
+	// EntityIDs maps each county's municipality names to their stable IDs.
+	EntityIDs map[string]map[string]string `json:"entityIds"`
 }
 
 type labelValue struct {
@@ -35,17 +45,119 @@ type seriesResponse struct {
 
 type seriesData struct {
 	Name   string     `json:"name"`
+	ID     string     `json:"id,omitempty"` // municipality series within a county
 	Values []*float64 `json:"values"`
 }
 
+// countyResponse is one county's aggregate series plus the latest value
+// of each member municipality.
+type countyResponse struct {
+	County         string             `json:"county"`
+	Title          string             `json:"title"`
+	Metric         string             `json:"metric"`
+	Type           string             `json:"type"`
+	Weighted       bool               `json:"weighted"`
+	Dates          []string           `json:"dates"`
+	Values         []*float64         `json:"values"`
+	Municipalities []countyMemberData `json:"municipalities"`
+}
+
+type countyMemberData struct {
+	Name   string   `json:"name"`
+	ID     string   `json:"id"`
+	Latest *float64 `json:"latest"`
+	Period string   `json:"period,omitempty"` // period of latest
+}
+
+// statusResponse summarizes what data the server has loaded. Entity counts
+// are for the latest period, so a short count flags a partial month.
+type statusResponse struct {
+	LatestPeriod   string         `json:"latestPeriod"`
+	FirstPeriod    string         `json:"firstPeriod"`
+	Periods        int            `json:"periods"`
+	Entities       int            `json:"entities"`
+	CountyEntities map[string]int `json:"countyEntities"`
+	LoadedAt       time.Time      `json:"loadedAt"`
+}
+
+// detailResponse holds every metric/case-type series for one municipality,
+// keyed by metric then case type.
+type detailResponse struct {
+	County       string                           `json:"county"`
+	Municipality string                           `json:"municipality"`
+	ID           string                           `json:"id"`
+	History      []parser.HistoryEvent            `json:"history,omitempty"`
+	Dates        []string                         `json:"dates"`
+	Series       map[string]map[string][]*float64 `json:"series"`
+}
+
+// dashboardResponse holds the series of every metric for one scope,
+// aligned to the same dates, for a grid of charts.
+type dashboardResponse struct {
+	Level        string            `json:"level"`
+	County       string            `json:"county,omitempty"`
+	Municipality string            `json:"municipality,omitempty"`
+	ID           string            `json:"id,omitempty"` // of the municipality
+	Type         string            `json:"type"`
+	Dates        []string          `json:"dates"`
+	Metrics      []dashboardMetric `json:"metrics"`
+}
+
+type dashboardMetric struct {
+	Metric   string     `json:"metric"`
+	Title    string     `json:"title"`
+	Weighted bool       `json:"weighted"`
+	Values   []*float64 `json:"values"`
+}
+
+// dataset is one directory of parsed JSON files served by the web command,
+// with its precomputed metadata and status responses.
+type dataset struct {
+	name       string
+	records    []timeRecord
+	metaJSON   []byte
+	statusJSON []byte
+}
+
+// datasetFlag collects repeated --dataset name=dir values, preserving order.
+type datasetFlag [][2]string
+
+func (d *datasetFlag) String() string {
+	var parts []string
+	for _, kv := range *d {
+		parts = append(parts, kv[0]+"="+kv[1])
+	}
+	return strings.Join(parts, ",")
+}
+
+func (d *datasetFlag) Set(v string) error {
+	name, dir, ok := strings.Cut(v, "=")
+	if !ok || name == "" || dir == "" {
+		return fmt.Errorf("expected name=dir, got %q", v)
+	}
+	for _, kv := range *d {
+		if kv[0] == name {
+			return fmt.Errorf("duplicate dataset %q", name)
+		}
+	}
+	*d = append(*d, [2]string{name, dir})
+	return nil
+}
+
 // Web implements the "web" subcommand.
 func Web(args []string) {
 	fs := flag.NewFlagSet("web", flag.ExitOnError)
 	dir := fs.String("dir", ".", "directory containing parsed JSON files")
 	port := fs.String("port", "8080", "HTTP server port")
+	enableGraphQL := fs.Bool("graphql", false, "also serve a GraphQL endpoint at /api/graphql")
+	baseURL := fs.String("base-url", "", "public URL the dashboard is served at, e.g. https://stats.example.org, for the links in /snapshot pages (default: taken from each request)")
+	var datasetFlags datasetFlag
+	fs.Var(&datasetFlags, "dataset", "named dataset as name=dir (repeatable; the first is the default)")
+	limitOpts := addLimitFlags(fs)
+	tlsOpts := addTLSFlags(fs)
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: municourt web [dir] [--port 8080]\n\nStart an interactive web dashboard.\n\nFlags:\n")
+		fmt.Fprintf(os.Stderr, "Usage: municourt web [dir] [--port 8080] [--dataset name=dir ...] [--graphql] [--base-url URL] [--tls-domain host | --cert file --key file] [--read-timeout 10s] [--write-timeout 60s] [--rate-limit 10] [--request-timeout 60s] ...\n\nStart an interactive web dashboard.\n\nFlags:\n")
 		fs.PrintDefaults()
 	}
 	args = reorderArgs(args)
@@ -54,103 +166,653 @@ func Web(args []string) {
 	if fs.NArg() > 0 {
 		*dir = fs.Arg(0)
 	}
-
-	records, err := loadRecords(*dir)
+	if len(datasetFlags) == 0 {
+		datasetFlags = datasetFlag{{"default", *dir}}
+	}
+	limits, err := limitOpts.limits()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error loading data: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "warning: no JSON files found in %s, starting with empty data\n", *dir)
+	*baseURL, err = checkBaseURL(*baseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	tlsConfig, plain, err := tlsOpts.config()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	api, err := loadDatasets(datasetFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
 
-	meta := buildMetadata(records)
-	metaJSON, _ := json.Marshal(meta)
+	api.graphql = *enableGraphQL
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	mux := http.NewServeMux()
+	api.register(mux, "/api")
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		data, _ := htmlContent.ReadFile("web.html")
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.Write(data)
 	})
 
-	http.HandleFunc("/api/metadata", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(metaJSON)
+	mux.HandleFunc("/snapshot", func(w http.ResponseWriter, r *http.Request) {
+		level, metric, caseType, county, municipality := parseSeriesQuery(r)
+		base := requestBaseURL(r, *baseURL, limits.trustProxy)
+		page := snapshotPage{
+			Title:    snapshotTitle(metric, caseType, level, county, municipality),
+			ImageURL: base + "/snapshot.png?" + r.URL.RawQuery,
+			PageURL:  base + r.URL.RequestURI(),
+			Width:    snapshotWidthPx,
+			Height:   snapshotHeightPx,
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := snapshotTemplate.Execute(w, page); err != nil {
+			fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+		}
 	})
+	mux.HandleFunc("/snapshot.png", api.chartPNG)
+
+	addr := defaultTLSAddr(":"+*port, flagSet(fs, "port"), tlsConfig)
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      limits.wrap(mux),
+		ReadTimeout:  limits.read,
+		WriteTimeout: limits.write,
+	}
+	fmt.Printf("serving on %s\n", serveURL(addr, tlsOpts, tlsConfig))
+	if err := tlsOpts.serve(srv, tlsConfig, plain); err != nil {
+		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	http.HandleFunc("/api/series", func(w http.ResponseWriter, r *http.Request) {
-		q := r.URL.Query()
-		level := q.Get("level")
-		metric := q.Get("metric")
-		caseType := q.Get("type")
-		county := strings.ToUpper(q.Get("county"))
-		municipality := strings.ToUpper(q.Get("municipality"))
+// apiServer serves the JSON API over one or more datasets. It is shared by
+// the web command, which adds the dashboard, and the headless api command.
+type apiServer struct {
+	names          []string
+	datasets       map[string]*dataset
+	defaultDataset string
+	graphql        bool // serve /graphql
+}
 
-		if !contains(validMetrics, metric) {
-			metric = "filings"
+// loadDatasets loads each name=dir pair, in order, and precomputes its
+// metadata and status responses. The first dataset is the default.
+func loadDatasets(flags datasetFlag) (*apiServer, error) {
+	s := &apiServer{datasets: make(map[string]*dataset)}
+	loadedAt := time.Now().UTC()
+	for _, kv := range flags {
+		name, dsDir := kv[0], kv[1]
+		records, err := loadRecords(dsDir)
+		if err != nil {
+			return nil, fmt.Errorf("error loading data for dataset %q: %w", name, err)
 		}
-		if !contains(validTypes, caseType) {
-			caseType = "grand-total"
+		if len(records) == 0 {
+			fmt.Fprintf(os.Stderr, "warning: no JSON files found in %s, starting with empty data\n", dsDir)
 		}
-		if level != "state" && level != "county" && level != "municipality" {
-			level = "county"
+		s.names = append(s.names, name)
+		s.datasets[name] = &dataset{name: name, records: records}
+	}
+	s.defaultDataset = s.names[0]
+	for _, ds := range s.datasets {
+		meta := buildMetadata(ds.records)
+		if len(s.names) > 1 {
+			meta.Datasets = s.names
 		}
+		ds.metaJSON, _ = json.Marshal(meta)
+		ds.statusJSON, _ = json.Marshal(buildStatus(ds.records, loadedAt))
+	}
+	return s, nil
+}
+
+// datasetFor resolves the ?dataset= parameter, writing a 404 for unknown
+// names.
+func (s *apiServer) datasetFor(w http.ResponseWriter, r *http.Request) (*dataset, bool) {
+	name := r.FormValue("dataset")
+	if name == "" {
+		name = s.defaultDataset
+	}
+	ds, ok := s.datasets[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown dataset %q", name), http.StatusNotFound)
+	}
+	return ds, ok
+}
+
+// register adds the API endpoints to mux under prefix, e.g. "/api".
+func (s *apiServer) register(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc(prefix+"/metadata", s.metadata)
+	mux.HandleFunc(prefix+"/status", s.status)
+	mux.HandleFunc(prefix+"/series", s.series)
+	mux.HandleFunc(prefix+"/chart.png", s.chartPNG)
+	mux.HandleFunc(prefix+"/report", s.report)
+	mux.HandleFunc(prefix+"/export.html", s.exportHTML)
+	mux.HandleFunc(prefix+"/county/{name}", s.county)
+	mux.HandleFunc(prefix+"/stats", s.stats)
+	mux.HandleFunc(prefix+"/detail", s.detail)
+	mux.HandleFunc(prefix+"/dashboard", s.dashboard)
+	if s.graphql {
+		mux.HandleFunc(prefix+"/graphql", s.serveGraphQL)
+	}
+}
+
+func (s *apiServer) metadata(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(ds.metaJSON)
+}
+
+func (s *apiServer) status(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(ds.statusJSON)
+}
+
+func (s *apiServer) series(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	level, metric, caseType, county, municipality := parseSeriesQuery(r)
+
+	series, dates := buildSeries(ds.records, metric, caseType, level, county, municipality)
+	vs := r.FormValue("vs")
+	if vs != "diff" && vs != "ratio" {
+		vs = "none"
+	}
+	if vs != "none" {
+		peers, _ := buildSeries(ds.records, metric, caseType, level, "", "")
+		state, _ := buildSeries(ds.records, metric, caseType, "state", "", "")
+		series = vsState(series, stateAverage(peers, state["STATEWIDE"], rateMetrics[metric]), vs)
+	}
+	sortedDates := sortDates(dates)
+	title := metricLabel(metric) + " — " + typeLabel(caseType) + vsStateLabel(vs)
+
+	resp := seriesResponse{
+		Title: title,
+		Dates: sortedDates,
+	}
 
-		series, dates := buildSeries(records, metric, caseType, level, county, municipality)
-		sortedDates := sortDates(dates)
-		title := metricLabel(metric) + " — " + typeLabel(caseType)
+	// Sort series names for stable ordering.
+	names := make([]string, 0, len(series))
+	for k := range series {
+		names = append(names, k)
+	}
+	sort.Strings(names)
 
-		resp := seriesResponse{
-			Title: title,
-			Dates: sortedDates,
+	for _, name := range names {
+		values := nullableValues(alignValues(series[name], sortedDates))
+		d := seriesData{Name: name, Values: values}
+		if level == "municipality" && county != "" {
+			d.ID = parser.EntityID(county, name)
 		}
+		resp.Series = append(resp.Series, d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// chartPNG renders the requested series as an image. Optional width and
+// height parameters are in pixels.
+func (s *apiServer) chartPNG(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	level, metric, caseType, county, municipality := parseSeriesQuery(r)
+	series, dates := buildSeries(ds.records, metric, caseType, level, county, municipality)
+	if len(series) == 0 {
+		http.Error(w, "no data matched the given filters", http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	width := pixelsToLength(q.Get("width"), snapshotWidthPx)
+	height := pixelsToLength(q.Get("height"), snapshotHeightPx)
+	title := snapshotTitle(metric, caseType, level, county, municipality)
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if err := renderChartPNG(w, title, series, sortDates(dates), width, height); err != nil {
+		fmt.Fprintf(os.Stderr, "chart: %v\n", err)
+	}
+}
+
+// maxExportBody caps the view a dashboard may POST to /export.html.
+const maxExportBody = 8 << 20
 
-		// Sort series names for stable ordering.
-		names := make([]string, 0, len(series))
-		for k := range series {
-			names = append(names, k)
+// exportHTML returns a chart as a self-contained interactive HTML page, as
+// written by viz --html. A GET charts the /series parameters; a POST charts
+// the view in its JSON body, the dashboard's current selection of series.
+func (s *apiServer) exportHTML(w http.ResponseWriter, r *http.Request) {
+	var c htmlChart
+	switch r.Method {
+	case http.MethodGet:
+		ds, ok := s.datasetFor(w, r)
+		if !ok {
+			return
 		}
-		sort.Strings(names)
+		level, metric, caseType, county, municipality := parseSeriesQuery(r)
+		series, dates := buildSeries(ds.records, metric, caseType, level, county, municipality)
+		if len(series) == 0 {
+			http.Error(w, "no data matched the given filters", http.StatusNotFound)
+			return
+		}
+		c = newHTMLChart(snapshotTitle(metric, caseType, level, county, municipality), series, sortDates(dates), nil, nil)
+	case http.MethodPost:
+		var err error
+		c, err = decodeExportView(http.MaxBytesReader(w, r.Body, maxExportBody))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-		for _, name := range names {
-			pts := series[name]
-			aligned := alignValues(pts, sortedDates)
-			values := make([]*float64, len(aligned))
-			for i, v := range aligned {
-				if math.IsNaN(v) {
-					values[i] = nil
-				} else {
-					f := v
-					values[i] = &f
+	var buf bytes.Buffer
+	if err := writeHTML(&buf, c); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		http.Error(w, "error rendering HTML", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="municipal-court-stats.html"`)
+	w.Write(buf.Bytes())
+}
+
+// decodeExportView reads a view POSTed to /export.html: a title, the dates,
+// and series with a value (or null) per date, as /series returns them, plus
+// an optional source link back to the view.
+func decodeExportView(r io.Reader) (htmlChart, error) {
+	var c htmlChart
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return c, fmt.Errorf("invalid view: %v", err)
+	}
+	if len(c.Dates) == 0 || len(c.Series) == 0 {
+		return c, fmt.Errorf("invalid view: no dates or no series")
+	}
+	for _, s := range c.Series {
+		if len(s.Values) != len(c.Dates) {
+			return c, fmt.Errorf("invalid view: series %q has %d values for %d dates", s.Name, len(s.Values), len(c.Dates))
+		}
+	}
+	if c.Title == "" {
+		c.Title = "Municipal Court Statistics"
+	}
+	if c.Source != "" {
+		if u, err := url.Parse(c.Source); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			c.Source = ""
+		}
+	}
+	return c, nil
+}
+
+func (s *apiServer) report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	level, metric, caseType, county, municipality := parseSeriesQuery(r)
+	weighted := r.FormValue("weighted") == "true"
+	series, dates := aggregateSeries(ds.records, metric, caseType, level, county, municipality, weighted)
+	if len(series) == 0 {
+		http.Error(w, "no data matched the given filters", http.StatusNotFound)
+		return
+	}
+	pageName := r.FormValue("page")
+	if pageName == "" {
+		pageName = "letter"
+	}
+	page, ok := lookupPageSize(pageName, r.FormValue("landscape") == "true")
+	if !ok {
+		http.Error(w, "invalid page size; valid options: letter, a4, legal", http.StatusBadRequest)
+		return
+	}
+	singleEntity := isSingleEntity(level, county, municipality)
+	rep := pdfReport{
+		title:        metricLabel(metric) + " — " + typeLabel(caseType),
+		series:       series,
+		sortedDates:  sortDates(dates),
+		singleEntity: singleEntity,
+		overview:     level == "county",
+		normalize:    r.FormValue("normalize") == "true",
+		page:         page,
+		brand: pdfBranding{
+			title:    r.FormValue("title"),
+			subtitle: r.FormValue("subtitle"),
+			author:   r.FormValue("author"),
+			footer:   r.FormValue("footer"),
+		},
+	}
+	if level == "county" && !singleEntity && r.FormValue("statewide") != "exclude" {
+		rep.statewidePoints = statewideSeries(ds.records, metric, caseType, weighted)
+	}
+
+	// Render to a buffer first so a failure can still return an error
+	// status instead of a truncated PDF.
+	var buf bytes.Buffer
+	if err := writePDF(&buf, rep); err != nil {
+		fmt.Fprintf(os.Stderr, "report: %v\n", err)
+		http.Error(w, "error rendering PDF", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "municourt-"+metric+"-"+caseType+".pdf"))
+	w.Write(buf.Bytes())
+}
+
+func (s *apiServer) county(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	county, known := parser.NormalizeCounty(r.PathValue("name"))
+	if !known {
+		http.Error(w, "unknown county", http.StatusNotFound)
+		return
+	}
+	_, metric, caseType, _, _ := parseSeriesQuery(r)
+	// Rate metrics are recomputed from summed components unless the
+	// caller asks for the plain mean used by /api/series.
+	weighted := r.FormValue("weighted") != "false"
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildCounty(ds.records, county, metric, caseType, weighted))
+}
+
+func (s *apiServer) stats(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	_, metric, caseType, county, municipality := parseSeriesQuery(r)
+	date := r.FormValue("date")
+	if date == "" && len(ds.records) > 0 {
+		date = ds.records[len(ds.records)-1].date
+	}
+
+	resp, found := buildStats(ds.records, metric, caseType, date, county, municipality)
+	if !found {
+		http.Error(w, "no data for that period", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *apiServer) detail(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	county := strings.ToUpper(q.Get("county"))
+	municipality := strings.ToUpper(q.Get("municipality"))
+	if county == "" || municipality == "" {
+		http.Error(w, "county and municipality are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, found := buildDetail(ds.records, county, municipality)
+	if !found {
+		http.Error(w, "municipality not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *apiServer) dashboard(w http.ResponseWriter, r *http.Request) {
+	ds, ok := s.datasetFor(w, r)
+	if !ok {
+		return
+	}
+	level, _, caseType, county, municipality := parseSeriesQuery(r)
+	switch {
+	case level == "state":
+		county, municipality = "", ""
+	case county == "":
+		http.Error(w, "county is required for level "+level, http.StatusBadRequest)
+		return
+	case level == "municipality" && municipality == "":
+		http.Error(w, "municipality is required for level municipality", http.StatusBadRequest)
+		return
+	case level == "county":
+		municipality = ""
+	}
+	if county != "" {
+		var known bool
+		if county, known = parser.NormalizeCounty(county); !known {
+			http.Error(w, "unknown county", http.StatusNotFound)
+			return
+		}
+	}
+	// As for /county, rate metrics are recomputed from summed components
+	// unless the caller asks for the plain mean.
+	weighted := r.FormValue("weighted") != "false"
+
+	resp, found := buildDashboard(ds.records, level, county, municipality, caseType, weighted)
+	if !found {
+		http.Error(w, "no data matched the given scope", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// parseSeriesQuery reads the level/metric/type/county/municipality parameters
+// shared by the series, chart, and report endpoints, from either the query
+// string or a POST form, falling back to defaults for missing or invalid
+// values.
+func parseSeriesQuery(r *http.Request) (level, metric, caseType, county, municipality string) {
+	level = r.FormValue("level")
+	metric = r.FormValue("metric")
+	caseType = r.FormValue("type")
+	county = strings.ToUpper(r.FormValue("county"))
+	municipality = strings.ToUpper(r.FormValue("municipality"))
+
+	if !contains(validMetrics, metric) {
+		metric = "filings"
+	}
+	if !contains(validTypes, caseType) {
+		caseType = "grand-total"
+	}
+	if level != "state" && level != "county" && level != "municipality" {
+		level = "county"
+	}
+	return level, metric, caseType, county, municipality
+}
+
+// nullableValues converts NaN gaps to nil so they encode as JSON null.
+func nullableValues(vals []float64) []*float64 {
+	values := make([]*float64, len(vals))
+	for i, v := range vals {
+		if !math.IsNaN(v) {
+			f := v
+			values[i] = &f
+		}
+	}
+	return values
+}
+
+// buildCounty returns county's aggregate series for metric and caseType,
+// with rates recomputed from summed components if weighted, and each
+// member municipality's latest value.
+func buildCounty(records []timeRecord, county, metric, caseType string, weighted bool) countyResponse {
+	series, dates := aggregateSeries(records, metric, caseType, "county", county, "", weighted)
+	sortedDates := sortDates(dates)
+
+	resp := countyResponse{
+		County:   county,
+		Title:    metricLabel(metric) + " — " + typeLabel(caseType),
+		Metric:   metric,
+		Type:     caseType,
+		Weighted: weighted && rateComponents[metric].denominator != "",
+		Dates:    sortedDates,
+		Values:   nullableValues(alignValues(series[county], sortedDates)),
+	}
+
+	munis, _ := buildSeries(records, metric, caseType, "municipality", county, "")
+	for _, name := range sortedEntityNames(munis) {
+		m := countyMemberData{Name: name, ID: parser.EntityID(county, name)}
+		pts := munis[name]
+		sort.Slice(pts, func(i, j int) bool { return pts[i].date < pts[j].date })
+		for i := len(pts) - 1; i >= 0; i-- {
+			if !math.IsNaN(pts[i].value) {
+				v := pts[i].value
+				m.Latest = &v
+				m.Period = pts[i].date
+				break
+			}
+		}
+		resp.Municipalities = append(resp.Municipalities, m)
+	}
+	return resp
+}
+
+// buildDashboard charts every metric for one scope: the state, a county,
+// or one municipality of a county. found is false if no metric has data
+// for the scope.
+func buildDashboard(records []timeRecord, level, county, municipality, caseType string, weighted bool) (resp dashboardResponse, found bool) {
+	resp = dashboardResponse{
+		Level:        level,
+		County:       county,
+		Municipality: municipality,
+		Type:         caseType,
+	}
+	var key string // the scope's series, as keyed by entityKey
+	switch level {
+	case "state":
+		key = "STATEWIDE"
+	case "county":
+		key = county
+	default:
+		key = nameLabel(county, municipality)
+		resp.ID = parser.EntityID(county, municipality)
+	}
+	for _, metric := range validMetrics {
+		series, dates := aggregateSeries(records, metric, caseType, level, county, municipality, weighted)
+		if resp.Dates == nil {
+			resp.Dates = sortDates(dates)
+		}
+		points := series[key]
+		found = found || len(points) > 0
+		resp.Metrics = append(resp.Metrics, dashboardMetric{
+			Metric:   metric,
+			Title:    metricLabel(metric) + " — " + typeLabel(caseType),
+			Weighted: weighted && rateComponents[metric].denominator != "",
+			Values:   nullableValues(alignValues(points, resp.Dates)),
+		})
+	}
+	return resp, found
+}
+
+// buildDetail collects every metric and case type for a single municipality
+// in one pass over the records. Values are aligned to all loaded dates. The
+// second return value is false if the municipality never appears.
+func buildDetail(records []timeRecord, county, municipality string) (detailResponse, bool) {
+	dates := make([]string, len(records))
+	for i, rec := range records {
+		dates[i] = rec.date
+	}
+
+	resp := detailResponse{
+		County:       county,
+		Municipality: municipality,
+		ID:           parser.EntityID(county, municipality),
+		History:      parser.HistoryFor(county, municipality),
+		Dates:        dates,
+		Series:       make(map[string]map[string][]*float64, len(validMetrics)),
+	}
+	vals := make(map[string]map[string][]float64, len(validMetrics))
+	for _, m := range validMetrics {
+		vals[m] = make(map[string][]float64, len(validTypes))
+		for _, t := range validTypes {
+			col := make([]float64, len(records))
+			for i := range col {
+				col[i] = math.NaN()
+			}
+			vals[m][t] = col
+		}
+	}
+
+	found := false
+	for i, rec := range records {
+		for _, s := range rec.stats {
+			if strings.ToUpper(s.County) != county || !matchesEntity(s, municipality) {
+				continue
+			}
+			found = true
+			for _, m := range validMetrics {
+				row := getRow(s, m)
+				for _, t := range validTypes {
+					vals[m][t][i] = getField(row, t)
 				}
 			}
-			resp.Series = append(resp.Series, seriesData{Name: name, Values: values})
+			break
 		}
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
-	})
+	for m, byType := range vals {
+		resp.Series[m] = make(map[string][]*float64, len(byType))
+		for t, col := range byType {
+			resp.Series[m][t] = nullableValues(col)
+		}
+	}
+	return resp, found
+}
 
-	addr := ":" + *port
-	fmt.Printf("serving on http://localhost%s\n", addr)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
-		os.Exit(1)
+func buildStatus(records []timeRecord, loadedAt time.Time) statusResponse {
+	status := statusResponse{
+		Periods:        len(records),
+		CountyEntities: make(map[string]int),
+		LoadedAt:       loadedAt,
+	}
+	if len(records) == 0 {
+		return status
+	}
+	// Records are sorted by date.
+	latest := records[len(records)-1]
+	status.FirstPeriod = records[0].date
+	status.LatestPeriod = latest.date
+	for _, s := range latest.stats {
+		status.CountyEntities[strings.ToUpper(s.County)]++
+		status.Entities++
 	}
+	return status
 }
 
 func buildMetadata(records []timeRecord) metadata {
 	countySet := make(map[string]bool)
-	muniMap := make(map[string]map[string]bool)
+	muniMap := make(map[string]map[string]string)
 
 	for _, rec := range records {
 		for _, s := range rec.stats {
 			c := strings.ToUpper(s.County)
 			countySet[c] = true
 			if _, ok := muniMap[c]; !ok {
-				muniMap[c] = make(map[string]bool)
+				muniMap[c] = make(map[string]string)
 			}
-			muniMap[c][strings.ToUpper(s.Municipality)] = true
+			muniMap[c][entityLabel(s)] = entityID(s)
 		}
 	}
 
@@ -184,5 +846,6 @@ func buildMetadata(records []timeRecord) metadata {
 		Municipalities: municipalities,
 		Metrics:        metrics,
 		Types:          types,
+		EntityIDs:      muniMap,
 	}
 }
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	_ "image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+	"gonum.org/v1/plot/vg"
+)
+
+func TestBuildCounty(t *testing.T) {
+	records := []timeRecord{
+		{date: "2023-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			rateStat("ATLANTIC", "BRIGANTINE", "900", "900", "100%"),
+			rateStat("BERGEN", "ALLENDALE", "10", "10", "100%"),
+		}},
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "100", "100%"),
+		}},
+	}
+
+	resp := buildCounty(records, "ATLANTIC", "clearance-pct", "grand-total", true)
+	if !resp.Weighted || len(resp.Values) != 2 || *resp.Values[0] != 95 || *resp.Values[1] != 100 {
+		t.Errorf("aggregate = %v (weighted %v)", resp.Values, resp.Weighted)
+	}
+	if len(resp.Municipalities) != 2 {
+		t.Fatalf("municipalities = %+v", resp.Municipalities)
+	}
+	// BRIGANTINE's latest value is from the period it last reported.
+	b := resp.Municipalities[1]
+	if b.Name != "BRIGANTINE" || b.ID != "atlantic.brigantine" || b.Period != "2023-06" || *b.Latest != 100 {
+		t.Errorf("BRIGANTINE = %+v", b)
+	}
+
+	// Count metrics are never reported as weighted.
+	if resp := buildCounty(records, "ATLANTIC", "filings", "grand-total", true); resp.Weighted {
+		t.Error("filings reported as weighted")
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	ds := &dataset{name: "default", records: []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{rateStat("ATLANTIC", "ABSECON", "100", "50", "50%")}},
+	}}
+	api := &apiServer{names: []string{"default"}, datasets: map[string]*dataset{"default": ds}, defaultDataset: "default"}
+	mux := http.NewServeMux()
+	api.register(mux, "/api")
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do("GET", "/api/export.html?level=county&county=ATLANTIC", "")
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"name":"ATLANTIC"`) {
+		t.Errorf("GET: %d %.200s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment") {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	view := `{"title": "Backlog", "dates": ["2024-06", "2025-06"], "series": [{"name": "HUDSON · DWI", "values": [1, null]}], "source": "http://localhost:8080/?e=county:HUDSON"}`
+	rec = do("POST", "/api/export.html", view)
+	if body := rec.Body.String(); rec.Code != 200 || !strings.Contains(body, `"values":[1,null]`) || !strings.Contains(body, `href="http://localhost:8080/?e=county:HUDSON"`) {
+		t.Errorf("POST: %d %.200s", rec.Code, body)
+	}
+
+	for name, body := range map[string]string{
+		"not json":     "{",
+		"no series":    `{"dates": ["2024-06"], "series": []}`,
+		"short values": `{"dates": ["2024-06", "2025-06"], "series": [{"name": "X", "values": [1]}]}`,
+	} {
+		if rec := do("POST", "/api/export.html", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d", name, rec.Code)
+		}
+	}
+	if rec := do("POST", "/api/export.html", `{"dates": ["2024-06"], "series": [{"name": "X", "values": [1]}], "source": "javascript:alert(1)"}`); strings.Contains(rec.Body.String(), "javascript:") {
+		t.Error("non-http source linked")
+	}
+}
+
+func TestBuildDashboard(t *testing.T) {
+	records := []timeRecord{
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			rateStat("ESSEX", "NEWARK", "100", "50", "50%"),
+			rateStat("ESSEX", "IRVINGTON", "300", "300", "100%"),
+			rateStat("MERCER", "TRENTON", "1000", "10", "1%"),
+		}},
+		{date: "2025-06", stats: []parser.MunicipalityStats{
+			rateStat("ESSEX", "NEWARK", "200", "100", "50%"),
+		}},
+	}
+
+	resp, found := buildDashboard(records, "county", "ESSEX", "", "grand-total", true)
+	if !found || len(resp.Metrics) != len(validMetrics) || len(resp.Dates) != 2 {
+		t.Fatalf("county: found=%v %+v", found, resp)
+	}
+	byMetric := make(map[string]dashboardMetric)
+	for _, m := range resp.Metrics {
+		if len(m.Values) != len(resp.Dates) {
+			t.Errorf("%s: %d values for %d dates", m.Metric, len(m.Values), len(resp.Dates))
+		}
+		byMetric[m.Metric] = m
+	}
+	if v := byMetric["filings"].Values; v[0] == nil || *v[0] != 400 || *v[1] != 200 {
+		t.Errorf("filings = %v", v)
+	}
+	// Weighted: 350 resolutions of 400 filings.
+	if m := byMetric["clearance-pct"]; !m.Weighted || m.Values[0] == nil || *m.Values[0] != 87.5 {
+		t.Errorf("clearance-pct = %+v", m)
+	}
+	if v := byMetric["backlog"].Values; v[0] != nil {
+		t.Errorf("backlog with no data = %v", *v[0])
+	}
+
+	resp, found = buildDashboard(records, "municipality", "MERCER", "TRENTON", "grand-total", true)
+	if !found || resp.ID != "mercer.trenton" || *resp.Metrics[0].Values[0] != 1000 || resp.Metrics[0].Values[1] != nil {
+		t.Errorf("municipality: %s %+v", resp.ID, resp.Metrics[0])
+	}
+	if _, found := buildDashboard(records, "municipality", "MERCER", "NEWARK", "grand-total", true); found {
+		t.Error("found NEWARK in MERCER")
+	}
+}
+
+func TestBuildDetail(t *testing.T) {
+	absecon := rateStat("ATLANTIC", "ABSECON", "100", "50", "50%")
+	absecon.Filings.CurrentPeriod.Parking = "40"
+	records := []timeRecord{
+		{date: "2023-06", stats: []parser.MunicipalityStats{rateStat("ATLANTIC", "BRIGANTINE", "900", "900", "100%")}},
+		{date: "2024-06", stats: []parser.MunicipalityStats{absecon}},
+	}
+
+	resp, found := buildDetail(records, "ATLANTIC", "ABSECON")
+	if !found || resp.ID != "atlantic.absecon" || len(resp.Dates) != 2 {
+		t.Fatalf("found=%v %+v", found, resp)
+	}
+	if len(resp.Series) != len(validMetrics) || len(resp.Series["filings"]) != len(validTypes) {
+		t.Fatalf("series: %d metrics, %d filings types", len(resp.Series), len(resp.Series["filings"]))
+	}
+	for _, tc := range []struct {
+		metric, caseType string
+		want             float64
+	}{
+		{"filings", "grand-total", 100},
+		{"filings", "parking", 40},
+		{"resolutions", "grand-total", 50},
+		{"clearance-pct", "grand-total", 50},
+	} {
+		v := resp.Series[tc.metric][tc.caseType]
+		if len(v) != 2 || v[0] != nil || v[1] == nil || *v[1] != tc.want {
+			t.Errorf("%s/%s = %v, want [nil %v]", tc.metric, tc.caseType, v, tc.want)
+		}
+	}
+
+	if _, found := buildDetail(records, "ATLANTIC", "MARGATE"); found {
+		t.Error("found MARGATE")
+	}
+	if _, found := buildDetail(records, "BERGEN", "ABSECON"); found {
+		t.Error("found ABSECON in BERGEN")
+	}
+}
+
+func TestBuildStatus(t *testing.T) {
+	loaded := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	records := []timeRecord{
+		{date: "2023-06", stats: []parser.MunicipalityStats{stat("ATLANTIC", "ABSECON")}},
+		{date: "2024-06", stats: []parser.MunicipalityStats{
+			stat("ATLANTIC", "ABSECON"), stat("Atlantic", "BRIGANTINE"), stat("BERGEN", "ALLENDALE"),
+		}},
+	}
+	got := buildStatus(records, loaded)
+	if got.FirstPeriod != "2023-06" || got.LatestPeriod != "2024-06" || got.Periods != 2 || got.Entities != 3 || !got.LoadedAt.Equal(loaded) {
+		t.Errorf("status = %+v", got)
+	}
+	if got.CountyEntities["ATLANTIC"] != 2 || got.CountyEntities["BERGEN"] != 1 {
+		t.Errorf("county entities = %v", got.CountyEntities)
+	}
+
+	empty := buildStatus(nil, loaded)
+	if empty.Periods != 0 || empty.LatestPeriod != "" || empty.CountyEntities == nil {
+		t.Errorf("empty status = %+v", empty)
+	}
+}
+
+func TestPixelsToLength(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int
+	}{
+		{"", snapshotWidthPx},
+		{"wide", snapshotWidthPx},
+		{"800", 800},
+		{"10", minChartPx},
+		{"-800", minChartPx},
+		{"100000", maxChartPx},
+	} {
+		if got := pixelsToLength(tc.in, snapshotWidthPx); got != vg.Length(tc.want)/snapshotDPI*vg.Inch {
+			t.Errorf("pixelsToLength(%q) = %v, want %d px", tc.in, got, tc.want)
+		}
+	}
+}
+
+// webTestServer serves the API over two datasets, each one parsed file
+// written to a directory and loaded the way web loads them.
+func webTestServer(t *testing.T) *http.ServeMux {
+	t.Helper()
+	t.Setenv("MUNICOURT_CACHE_DIR", "off")
+	var flags datasetFlag
+	for _, ds := range []struct {
+		name, period string
+		stats        []parser.MunicipalityStats
+	}{
+		{"current", "2024-06", []parser.MunicipalityStats{
+			rateStat("ATLANTIC", "ABSECON", "100", "50", "50%"),
+			rateStat("ATLANTIC", "BRIGANTINE", "900", "900", "100%"),
+		}},
+		{"old", "2010-06", []parser.MunicipalityStats{rateStat("BERGEN", "ALLENDALE", "10", "10", "100%")}},
+	} {
+		dir := t.TempDir()
+		if err := writeOutputJSON(filepath.Join(dir, "municipal-courts-"+ds.period+".json"), nil, ds.stats); err != nil {
+			t.Fatal(err)
+		}
+		flags = append(flags, [2]string{ds.name, dir})
+	}
+	api, err := loadDatasets(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	api.register(mux, "/api")
+	return mux
+}
+
+func TestWebEndpoints(t *testing.T) {
+	mux := webTestServer(t)
+	report := func(form string) *http.Request {
+		req := httptest.NewRequest("POST", "/api/report", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		req         *http.Request
+		wantCode    int
+		contentType string
+		check       func(t *testing.T, body []byte)
+	}{
+		{"status", httptest.NewRequest("GET", "/api/status", nil), 200, "application/json", func(t *testing.T, body []byte) {
+			var got statusResponse
+			if err := json.Unmarshal(body, &got); err != nil || got.LatestPeriod != "2024-06" || got.Entities != 2 || got.CountyEntities["ATLANTIC"] != 2 {
+				t.Errorf("status = %s", body)
+			}
+		}},
+		{"status of another dataset", httptest.NewRequest("GET", "/api/status?dataset=old", nil), 200, "application/json", func(t *testing.T, body []byte) {
+			var got statusResponse
+			if err := json.Unmarshal(body, &got); err != nil || got.LatestPeriod != "2010-06" || got.Entities != 1 {
+				t.Errorf("status = %s", body)
+			}
+		}},
+		{"metadata lists the datasets", httptest.NewRequest("GET", "/api/metadata?dataset=old", nil), 200, "application/json", func(t *testing.T, body []byte) {
+			var got metadata
+			if err := json.Unmarshal(body, &got); err != nil || len(got.Datasets) != 2 || len(got.Counties) != 1 || got.Counties[0] != "BERGEN" {
+				t.Errorf("metadata = %s", body)
+			}
+		}},
+		{"status of an unknown dataset", httptest.NewRequest("GET", "/api/status?dataset=nope", nil), 404, "", nil},
+		{"detail of an unknown dataset", httptest.NewRequest("GET", "/api/detail?dataset=nope&county=ATLANTIC&municipality=ABSECON", nil), 404, "", nil},
+
+		{"detail", httptest.NewRequest("GET", "/api/detail?county=atlantic&municipality=absecon", nil), 200, "application/json", func(t *testing.T, body []byte) {
+			var got detailResponse
+			if err := json.Unmarshal(body, &got); err != nil || got.ID != "atlantic.absecon" || len(got.Dates) != 1 {
+				t.Fatalf("detail = %.200s", body)
+			}
+			if v := got.Series["filings"]["grand-total"]; len(v) != 1 || v[0] == nil || *v[0] != 100 {
+				t.Errorf("filings = %v", v)
+			}
+		}},
+		{"detail without a municipality", httptest.NewRequest("GET", "/api/detail?county=ATLANTIC", nil), 400, "", nil},
+		{"detail of an unknown municipality", httptest.NewRequest("GET", "/api/detail?county=ATLANTIC&municipality=MARGATE", nil), 404, "", nil},
+		{"detail of an unknown county", httptest.NewRequest("GET", "/api/detail?county=NOWHERE&municipality=ABSECON", nil), 404, "", nil},
+		{"detail in the wrong dataset", httptest.NewRequest("GET", "/api/detail?dataset=old&county=ATLANTIC&municipality=ABSECON", nil), 404, "", nil},
+		{"unknown county", httptest.NewRequest("GET", "/api/county/NOWHERE", nil), 404, "", nil},
+
+		{"chart", httptest.NewRequest("GET", "/api/chart.png?level=municipality&county=ATLANTIC&municipality=ABSECON", nil), 200, "image/png", func(t *testing.T, body []byte) {
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(body))
+			if err != nil || cfg.Width != snapshotWidthPx || cfg.Height != snapshotHeightPx {
+				t.Errorf("chart: %dx%d, %v", cfg.Width, cfg.Height, err)
+			}
+		}},
+		{"chart size clamped", httptest.NewRequest("GET", "/api/chart.png?level=county&county=ATLANTIC&width=10&height=100000", nil), 200, "image/png", func(t *testing.T, body []byte) {
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(body))
+			if err != nil || cfg.Width != minChartPx || cfg.Height != maxChartPx {
+				t.Errorf("chart: %dx%d, %v", cfg.Width, cfg.Height, err)
+			}
+		}},
+		{"chart of nothing", httptest.NewRequest("GET", "/api/chart.png?level=county&county=CAMDEN", nil), 404, "", nil},
+		{"chart of an unknown dataset", httptest.NewRequest("GET", "/api/chart.png?dataset=nope", nil), 404, "", nil},
+
+		{"report", report("level=county&county=ATLANTIC&title=Atlantic"), 200, "application/pdf", func(t *testing.T, body []byte) {
+			if !strings.HasPrefix(string(body), "%PDF-") {
+				t.Errorf("report body starts %q", body[:min(len(body), 16)])
+			}
+		}},
+		{"report by GET", httptest.NewRequest("GET", "/api/report?level=county&county=ATLANTIC", nil), 405, "", nil},
+		{"report of nothing", report("level=county&county=CAMDEN"), 404, "", nil},
+		{"report page size", report(url.Values{"level": {"state"}, "page": {"tabloid"}}.Encode()), 400, "", nil},
+		{"report of an unknown dataset", report("dataset=nope&level=state"), 404, "", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, tc.req)
+			if rec.Code != tc.wantCode {
+				t.Fatalf("status %d, want %d: %.200s", rec.Code, tc.wantCode, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); tc.contentType != "" && got != tc.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tc.contentType)
+			}
+			if tc.check != nil {
+				tc.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestRequestBaseURL(t *testing.T) {
+	forwarded := httptest.NewRequest("GET", "http://internal:8080/snapshot", nil)
+	forwarded.Header.Set("X-Forwarded-Proto", "https")
+	forwarded.Header.Set("X-Forwarded-Host", "stats.example.org, internal")
+
+	for _, tc := range []struct {
+		name       string
+		r          *http.Request
+		base       string
+		trustProxy bool
+		want       string
+	}{
+		{"from the request", httptest.NewRequest("GET", "http://localhost:8080/snapshot", nil), "", false, "http://localhost:8080"},
+		{"forwarded headers ignored", forwarded, "", false, "http://internal:8080"},
+		{"forwarded headers trusted", forwarded, "", true, "https://stats.example.org"},
+		{"base URL wins", forwarded, "https://courts.example.org", true, "https://courts.example.org"},
+	} {
+		if got := requestBaseURL(tc.r, tc.base, tc.trustProxy); got != tc.want {
+			t.Errorf("%s: %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCheckBaseURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                             "",
+		"https://stats.example.org/":   "https://stats.example.org",
+		"http://localhost:8080/courts": "http://localhost:8080/courts",
+	} {
+		if got, err := checkBaseURL(in); err != nil || got != want {
+			t.Errorf("checkBaseURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"stats.example.org", "ftp://example.org", "https://", "https://example.org/?x=1"} {
+		if _, err := checkBaseURL(in); err == nil {
+			t.Errorf("checkBaseURL(%q) accepted", in)
+		}
+	}
+}
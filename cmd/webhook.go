@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// webhookClient posts webhook deliveries. It is separate from httpClient,
+// whose proxy and TLS settings are for the courts' site.
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// webhookAttempts and webhookRetryDelay bound how hard one delivery is
+// tried before it is left for the next sync run.
+var (
+	webhookAttempts   = 3
+	webhookRetryDelay = 2 * time.Second
+)
+
+// webhookPayload is the JSON body POSTed when sync parses a new or changed
+// report.
+type webhookPayload struct {
+	Event     string           `json:"event"` // always "report.parsed"
+	Text      string           `json:"text"`  // one-line summary, shown by Slack-style receivers
+	Period    string           `json:"period"`
+	File      string           `json:"file"`
+	SHA256    string           `json:"sha256,omitempty"`
+	Changed   bool             `json:"changed"` // true when replacing an earlier parse of the same file
+	Statewide webhookStatewide `json:"statewide"`
+	Quality   webhookQuality   `json:"quality"`
+	At        time.Time        `json:"at"`
+}
+
+// webhookStatewide holds the report's grand totals against the same months
+// a year earlier. Change is a percentage for counts and percentage points
+// for clearancePct.
+type webhookStatewide struct {
+	Filings       webhookDelta `json:"filings"`
+	Resolutions   webhookDelta `json:"resolutions"`
+	ClearancePct  webhookDelta `json:"clearancePct"`
+	Backlog       webhookDelta `json:"backlog"`
+	ActivePending webhookDelta `json:"activePending"`
+}
+
+type webhookDelta struct {
+	Current *float64 `json:"current"`
+	Prior   *float64 `json:"prior"`
+	Change  *float64 `json:"change"`
+}
+
+type webhookQuality struct {
+	Pages      int      `json:"pages"`
+	Records    int      `json:"records"`
+	Errors     int      `json:"errors"`
+	Duplicates int      `json:"duplicates"`
+	Shortfalls []string `json:"shortfalls,omitempty"`
+}
+
+func nullable(v float64) *float64 {
+	if math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+func countDelta(cur, prior float64) webhookDelta {
+	d := webhookDelta{Current: nullable(cur), Prior: nullable(prior)}
+	if prior != 0 {
+		d.Change = nullable((cur - prior) / prior * 100)
+	}
+	return d
+}
+
+func rateDelta(cur, prior float64) webhookDelta {
+	return webhookDelta{Current: nullable(cur), Prior: nullable(prior), Change: nullable(cur - prior)}
+}
+
+// buildWebhookPayload summarizes a parsed report.
+func buildWebhookPayload(r parseResult, changed bool) webhookPayload {
+	period := r.date
+	if period == "" && len(r.results) > 0 {
+		period, _ = periodFromDateRange(r.results[0].DateRange)
+	}
+	cur := sumRows(r.results, "grand-total", false)
+	prior := sumRows(r.results, "grand-total", true)
+
+	p := webhookPayload{
+		Event:   "report.parsed",
+		Period:  period,
+		File:    filepath.Base(r.inputPath),
+		Changed: changed,
+		Statewide: webhookStatewide{
+			Filings:       countDelta(cur.filings, prior.filings),
+			Resolutions:   countDelta(cur.resolutions, prior.resolutions),
+			ClearancePct:  rateDelta(cur.clearancePct(), prior.clearancePct()),
+			Backlog:       countDelta(cur.backlog, prior.backlog),
+			ActivePending: countDelta(cur.activePending, prior.activePending),
+		},
+		Quality: webhookQuality{
+			Pages:      r.nPages,
+			Records:    len(r.results),
+			Errors:     len(r.errors),
+			Duplicates: len(r.duplicates),
+			Shortfalls: r.shortfalls,
+		},
+		At: time.Now().UTC().Truncate(time.Second),
+	}
+	if r.provenance != nil {
+		p.SHA256 = r.provenance.SHA256
+	}
+
+	verb := "New report"
+	if changed {
+		verb = "Updated report"
+	}
+	p.Text = fmt.Sprintf("%s %s: %d municipalities, filings %s, backlog %s", verb, period, len(r.results), formatNum(cur.filings), formatNum(cur.backlog))
+	if c := p.Statewide.Backlog.Change; c != nil {
+		p.Text += fmt.Sprintf(" (%+.1f%% year over year)", *c)
+	}
+	if n := len(r.errors) + len(r.shortfalls); n > 0 {
+		p.Text += fmt.Sprintf("; %d parse warnings", n)
+	}
+	return p
+}
+
+// webhookSignature is the value of the X-Municourt-Signature header: an
+// HMAC-SHA256 of the body keyed with the shared secret.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverWebhook POSTs body to url, retrying failed attempts. Any 2xx
+// response counts as delivered.
+func deliverWebhook(url string, body []byte, secret string) error {
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(webhookRetryDelay * time.Duration(attempt-1))
+		}
+		if err = postWebhook(url, body, secret); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func postWebhook(url string, body []byte, secret string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "municourt/"+version())
+	req.Header.Set("X-Municourt-Event", "report.parsed")
+	if secret != "" {
+		req.Header.Set("X-Municourt-Signature", webhookSignature(secret, body))
+	}
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return nil
+}
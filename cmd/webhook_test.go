@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zalepa/municourt/parser"
+)
+
+func TestBuildWebhookPayload(t *testing.T) {
+	s := rateStat("ATLANTIC", "ABSECON", "110", "99", "90%")
+	s.Filings.PriorPeriod.GrandTotal = "100"
+	s.Resolutions.PriorPeriod.GrandTotal = "100"
+	r := parseResult{
+		inputPath:  "data/municipal-courts-2024-06.pdf",
+		date:       "2024-06",
+		results:    []parser.MunicipalityStats{s},
+		errors:     []string{"page 3: bad"},
+		shortfalls: []string{"ATLANTIC: 1 of 23 municipalities"},
+		nPages:     4,
+	}
+	p := buildWebhookPayload(r, false)
+	if p.Period != "2024-06" || p.File != "municipal-courts-2024-06.pdf" || p.Changed {
+		t.Errorf("payload = %+v", p)
+	}
+	f := p.Statewide.Filings
+	if *f.Current != 110 || *f.Prior != 100 || *f.Change != 10 {
+		t.Errorf("filings = %v %v %v", *f.Current, *f.Prior, *f.Change)
+	}
+	if c := p.Statewide.ClearancePct.Change; c == nil || *c != -10 {
+		t.Errorf("clearance change = %v, want -10 points", c)
+	}
+	if p.Statewide.Backlog.Change != nil {
+		t.Errorf("backlog change with no prior backlog = %v", *p.Statewide.Backlog.Change)
+	}
+	if q := p.Quality; q.Pages != 4 || q.Records != 1 || q.Errors != 1 || len(q.Shortfalls) != 1 {
+		t.Errorf("quality = %+v", q)
+	}
+}
+
+func TestNotifyWebhooks_RetryNextRun(t *testing.T) {
+	webhookAttempts, webhookRetryDelay = 1, 0
+	defer func() { webhookAttempts, webhookRetryDelay = 3, 2*time.Second }()
+
+	up := false
+	var got []byte
+	var sig string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		got, _ = io.ReadAll(r.Body)
+		sig = r.Header.Get("X-Municourt-Signature")
+	}))
+	defer srv.Close()
+
+	st, _ := loadSyncState("")
+	hooks := webhookConfig{urls: []string{srv.URL}, secret: "s3cret"}
+	notifyWebhooks(st, hooks, webhookPayload{Event: "report.parsed", Period: "2024-06"})
+	if len(st.PendingWebhooks) != 1 || len(st.Failures) != 1 {
+		t.Fatalf("after failure: pending %d, failures %d", len(st.PendingWebhooks), len(st.Failures))
+	}
+
+	up = true
+	retryWebhooks(st, func() {}, hooks)
+	if len(st.PendingWebhooks) != 0 || len(st.Failures) != 0 {
+		t.Errorf("after retry: pending %d, failures %v", len(st.PendingWebhooks), st.Failures)
+	}
+	var p webhookPayload
+	if err := json.Unmarshal(got, &p); err != nil || p.Period != "2024-06" {
+		t.Errorf("delivered %s", got)
+	}
+	if sig != webhookSignature("s3cret", got) {
+		t.Errorf("signature %q doesn't match the body", sig)
+	}
+}
+
+func TestRetryWebhooks_Drops(t *testing.T) {
+	webhookAttempts, webhookRetryDelay = 1, 0
+	defer func() { webhookAttempts, webhookRetryDelay = 3, 2*time.Second }()
+
+	var hits []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC()
+	st, _ := loadSyncState("")
+	st.PendingWebhooks = []syncWebhook{
+		{URL: srv.URL + "/kept", Period: "2024-06", Queued: now.Add(-time.Hour)},
+		{URL: srv.URL + "/removed", Period: "2024-06", Queued: now.Add(-time.Hour)},
+		{URL: srv.URL + "/kept", Period: "2024-05", Queued: now.Add(-webhookMaxAge - time.Hour)},
+	}
+	for _, w := range st.PendingWebhooks {
+		st.record("webhook", w.URL+" "+w.Period, errors.New("503"))
+	}
+
+	retryWebhooks(st, func() {}, webhookConfig{urls: []string{srv.URL + "/kept"}})
+	if len(hits) != 1 || hits[0] != "/kept" {
+		t.Errorf("delivered to %v, want only the current, recent one", hits)
+	}
+	if len(st.PendingWebhooks) != 0 || len(st.Failures) != 0 {
+		t.Errorf("pending %d, failures %v", len(st.PendingWebhooks), st.Failures)
+	}
+}
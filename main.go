@@ -18,10 +18,64 @@ func main() {
 		cmd.Parse(os.Args[2:])
 	case "download":
 		cmd.Download(os.Args[2:])
+	case "list-remote":
+		cmd.ListRemote(os.Args[2:])
 	case "viz":
 		cmd.Viz(os.Args[2:])
 	case "web":
 		cmd.Web(os.Args[2:])
+	case "api":
+		cmd.API(os.Args[2:])
+	case "grpc":
+		cmd.GRPC(os.Args[2:])
+	case "dedupe":
+		cmd.Dedupe(os.Args[2:])
+	case "apply-aliases":
+		cmd.ApplyAliases(os.Args[2:])
+	case "correlate":
+		cmd.Correlate(os.Args[2:])
+	case "cluster":
+		cmd.Cluster(os.Args[2:])
+	case "changepoints":
+		cmd.Changepoints(os.Args[2:])
+	case "consolidations":
+		cmd.Consolidations(os.Args[2:])
+	case "coverage":
+		cmd.Coverage(os.Args[2:])
+	case "munis":
+		cmd.Munis(os.Args[2:])
+	case "validate":
+		cmd.Validate(os.Args[2:])
+	case "validate-json":
+		cmd.ValidateJSON(os.Args[2:])
+	case "ledger":
+		cmd.Ledger(os.Args[2:])
+	case "merge":
+		cmd.Merge(os.Args[2:])
+	case "convert":
+		cmd.Convert(os.Args[2:])
+	case "fetch":
+		cmd.Fetch(os.Args[2:])
+	case "sync":
+		cmd.Sync(os.Args[2:])
+	case "migrate":
+		cmd.Migrate(os.Args[2:])
+	case "summary":
+		cmd.Summary(os.Args[2:])
+	case "leaderboard":
+		cmd.Leaderboard(os.Args[2:])
+	case "baseline":
+		cmd.Baseline(os.Args[2:])
+	case "rankings":
+		cmd.Rankings(os.Args[2:])
+	case "export":
+		cmd.Export(os.Args[2:])
+	case "influx":
+		cmd.Influx(os.Args[2:])
+	case "population":
+		cmd.Population(os.Args[2:])
+	case "prom-exporter":
+		cmd.PromExporter(os.Args[2:])
 	default:
 		usage()
 		os.Exit(1)
@@ -29,5 +83,39 @@ func main() {
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: municourt <command>\n\nCommands:\n  parse      Parse municipal court PDF statistics\n  download   Download municipal court PDFs from njcourts.gov\n  viz        Visualize statistics over time in the terminal\n  web        Start interactive web dashboard\n")
+	fmt.Fprintf(os.Stderr, `Usage: municourt <command>
+
+Commands:
+  parse          Parse municipal court PDF statistics
+  download       Download municipal court PDFs from njcourts.gov
+  list-remote    List the reports njcourts.gov links to and which are local
+  fetch          Download a single report PDF from a URL
+  sync           Download new reports and parse new or changed PDFs
+  viz            Visualize statistics over time in the terminal
+  summary        Print the newest report's statewide totals
+  leaderboard    List the municipalities with the biggest changes
+  baseline       Compare a municipality with its county and state averages
+  rankings       Show how stable municipalities' ranks on a metric are
+  correlate      Correlate two metrics, optionally with a lag
+  cluster        Group municipalities with similar trend shapes
+  changepoints   Find breaks in a series' level or trend
+  export         Export parsed data as CSV, JSON, SQLite, Parquet or XLSX
+  influx         Write parsed data as InfluxDB line protocol or push it to InfluxDB
+  population     Fetch Census population and show it for each court
+  prom-exporter  Serve the newest period's values as Prometheus metrics
+  web            Start interactive web dashboard
+  api            Serve the JSON API without the dashboard
+  grpc           Serve parsed data over gRPC
+  dedupe         List likely duplicate municipality names
+  apply-aliases  Rename counties/municipalities in parsed output files
+  consolidations List likely court consolidations (courts merging into others)
+  coverage       Show which municipalities have data in which periods
+  munis          List the counties and municipalities in the data
+  validate       Check parsed values against sanity-check rules
+  validate-json  Check JSON files against the output format's JSON Schema
+  ledger         Keep an append-only ledger of values and corrections
+  merge          Combine a parsed directory into one dataset file
+  convert        Convert a parsed output file to CSV, JSONL, Parquet or typed JSON
+  migrate        Upgrade parsed JSON files to the current schema
+`)
 }
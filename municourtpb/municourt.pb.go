@@ -0,0 +1,1233 @@
+// Protobuf schema for the municourt gRPC service (municourt grpc).
+//
+// Regenerate the Go code after editing with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	    municourtpb/municourt.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: municourtpb/municourt.proto
+
+package municourtpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListPeriodsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPeriodsRequest) Reset() {
+	*x = ListPeriodsRequest{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPeriodsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPeriodsRequest) ProtoMessage() {}
+
+func (x *ListPeriodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPeriodsRequest.ProtoReflect.Descriptor instead.
+func (*ListPeriodsRequest) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{0}
+}
+
+type ListPeriodsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Periods       []string               `protobuf:"bytes,1,rep,name=periods,proto3" json:"periods,omitempty"` // YYYY-MM
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPeriodsResponse) Reset() {
+	*x = ListPeriodsResponse{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPeriodsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPeriodsResponse) ProtoMessage() {}
+
+func (x *ListPeriodsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPeriodsResponse.ProtoReflect.Descriptor instead.
+func (*ListPeriodsResponse) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListPeriodsResponse) GetPeriods() []string {
+	if x != nil {
+		return x.Periods
+	}
+	return nil
+}
+
+type ListMunicipalitiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	County        string                 `protobuf:"bytes,1,opt,name=county,proto3" json:"county,omitempty"` // optional filter
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMunicipalitiesRequest) Reset() {
+	*x = ListMunicipalitiesRequest{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMunicipalitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMunicipalitiesRequest) ProtoMessage() {}
+
+func (x *ListMunicipalitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMunicipalitiesRequest.ProtoReflect.Descriptor instead.
+func (*ListMunicipalitiesRequest) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListMunicipalitiesRequest) GetCounty() string {
+	if x != nil {
+		return x.County
+	}
+	return ""
+}
+
+type ListMunicipalitiesResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Municipalities []*Municipality        `protobuf:"bytes,1,rep,name=municipalities,proto3" json:"municipalities,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListMunicipalitiesResponse) Reset() {
+	*x = ListMunicipalitiesResponse{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMunicipalitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMunicipalitiesResponse) ProtoMessage() {}
+
+func (x *ListMunicipalitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMunicipalitiesResponse.ProtoReflect.Descriptor instead.
+func (*ListMunicipalitiesResponse) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListMunicipalitiesResponse) GetMunicipalities() []*Municipality {
+	if x != nil {
+		return x.Municipalities
+	}
+	return nil
+}
+
+type Municipality struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	County        string                 `protobuf:"bytes,1,opt,name=county,proto3" json:"county,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Id            string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"` // stable entity ID, e.g. "atlantic.absecon"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Municipality) Reset() {
+	*x = Municipality{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Municipality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Municipality) ProtoMessage() {}
+
+func (x *Municipality) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Municipality.ProtoReflect.Descriptor instead.
+func (*Municipality) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Municipality) GetCounty() string {
+	if x != nil {
+		return x.County
+	}
+	return ""
+}
+
+func (x *Municipality) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Municipality) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetRecordsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional filters; empty matches everything. Periods are YYYY-MM and
+	// inclusive.
+	From          string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	County        string `protobuf:"bytes,3,opt,name=county,proto3" json:"county,omitempty"`
+	Municipality  string `protobuf:"bytes,4,opt,name=municipality,proto3" json:"municipality,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRecordsRequest) Reset() {
+	*x = GetRecordsRequest{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecordsRequest) ProtoMessage() {}
+
+func (x *GetRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecordsRequest.ProtoReflect.Descriptor instead.
+func (*GetRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetRecordsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetRecordsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetRecordsRequest) GetCounty() string {
+	if x != nil {
+		return x.County
+	}
+	return ""
+}
+
+func (x *GetRecordsRequest) GetMunicipality() string {
+	if x != nil {
+		return x.Municipality
+	}
+	return ""
+}
+
+// Record is one municipality's statistics for one report period.
+type Record struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        string                 `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"` // YYYY-MM
+	Stats         *MunicipalityStats     `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Record) Reset() {
+	*x = Record{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Record) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Record) ProtoMessage() {}
+
+func (x *Record) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Record.ProtoReflect.Descriptor instead.
+func (*Record) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Record) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *Record) GetStats() *MunicipalityStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+// MunicipalityStats mirrors the JSON records written by municourt parse.
+// Values are the report's text as printed, e.g. "1,749", "98.1%" or "- -".
+type MunicipalityStats struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	County                    string                 `protobuf:"bytes,1,opt,name=county,proto3" json:"county,omitempty"`
+	Municipality              string                 `protobuf:"bytes,2,opt,name=municipality,proto3" json:"municipality,omitempty"`
+	DateRange                 string                 `protobuf:"bytes,3,opt,name=date_range,json=dateRange,proto3" json:"date_range,omitempty"`
+	Filings                   *SectionWithChange     `protobuf:"bytes,4,opt,name=filings,proto3" json:"filings,omitempty"`
+	Resolutions               *SectionWithChange     `protobuf:"bytes,5,opt,name=resolutions,proto3" json:"resolutions,omitempty"`
+	Clearance                 *SectionTwoRow         `protobuf:"bytes,6,opt,name=clearance,proto3" json:"clearance,omitempty"`
+	ClearancePercent          *SectionTwoRow         `protobuf:"bytes,7,opt,name=clearance_percent,json=clearancePercent,proto3" json:"clearance_percent,omitempty"`
+	Backlog                   *SectionWithChange     `protobuf:"bytes,8,opt,name=backlog,proto3" json:"backlog,omitempty"`
+	BacklogPer100MthlyFilings *SectionWithChange     `protobuf:"bytes,9,opt,name=backlog_per100_mthly_filings,json=backlogPer100MthlyFilings,proto3" json:"backlog_per100_mthly_filings,omitempty"`
+	BacklogPercent            *SectionTwoRow         `protobuf:"bytes,10,opt,name=backlog_percent,json=backlogPercent,proto3" json:"backlog_percent,omitempty"`
+	ActivePending             *SectionWithChange     `protobuf:"bytes,11,opt,name=active_pending,json=activePending,proto3" json:"active_pending,omitempty"`
+	Predecessors              []string               `protobuf:"bytes,12,rep,name=predecessors,proto3" json:"predecessors,omitempty"`
+	Successor                 string                 `protobuf:"bytes,13,opt,name=successor,proto3" json:"successor,omitempty"`
+	SourceFile                string                 `protobuf:"bytes,14,opt,name=source_file,json=sourceFile,proto3" json:"source_file,omitempty"`
+	PageNumber                int32                  `protobuf:"varint,15,opt,name=page_number,json=pageNumber,proto3" json:"page_number,omitempty"`
+	Warnings                  []string               `protobuf:"bytes,16,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Id                        string                 `protobuf:"bytes,17,opt,name=id,proto3" json:"id,omitempty"` // stable entity ID, e.g. "atlantic.absecon"
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *MunicipalityStats) Reset() {
+	*x = MunicipalityStats{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MunicipalityStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MunicipalityStats) ProtoMessage() {}
+
+func (x *MunicipalityStats) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MunicipalityStats.ProtoReflect.Descriptor instead.
+func (*MunicipalityStats) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MunicipalityStats) GetCounty() string {
+	if x != nil {
+		return x.County
+	}
+	return ""
+}
+
+func (x *MunicipalityStats) GetMunicipality() string {
+	if x != nil {
+		return x.Municipality
+	}
+	return ""
+}
+
+func (x *MunicipalityStats) GetDateRange() string {
+	if x != nil {
+		return x.DateRange
+	}
+	return ""
+}
+
+func (x *MunicipalityStats) GetFilings() *SectionWithChange {
+	if x != nil {
+		return x.Filings
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetResolutions() *SectionWithChange {
+	if x != nil {
+		return x.Resolutions
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetClearance() *SectionTwoRow {
+	if x != nil {
+		return x.Clearance
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetClearancePercent() *SectionTwoRow {
+	if x != nil {
+		return x.ClearancePercent
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetBacklog() *SectionWithChange {
+	if x != nil {
+		return x.Backlog
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetBacklogPer100MthlyFilings() *SectionWithChange {
+	if x != nil {
+		return x.BacklogPer100MthlyFilings
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetBacklogPercent() *SectionTwoRow {
+	if x != nil {
+		return x.BacklogPercent
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetActivePending() *SectionWithChange {
+	if x != nil {
+		return x.ActivePending
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetPredecessors() []string {
+	if x != nil {
+		return x.Predecessors
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetSuccessor() string {
+	if x != nil {
+		return x.Successor
+	}
+	return ""
+}
+
+func (x *MunicipalityStats) GetSourceFile() string {
+	if x != nil {
+		return x.SourceFile
+	}
+	return ""
+}
+
+func (x *MunicipalityStats) GetPageNumber() int32 {
+	if x != nil {
+		return x.PageNumber
+	}
+	return 0
+}
+
+func (x *MunicipalityStats) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *MunicipalityStats) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type SectionWithChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PriorPeriod   *RowData               `protobuf:"bytes,1,opt,name=prior_period,json=priorPeriod,proto3" json:"prior_period,omitempty"`
+	CurrentPeriod *RowData               `protobuf:"bytes,2,opt,name=current_period,json=currentPeriod,proto3" json:"current_period,omitempty"`
+	PctChange     *RowData               `protobuf:"bytes,3,opt,name=pct_change,json=pctChange,proto3" json:"pct_change,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SectionWithChange) Reset() {
+	*x = SectionWithChange{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SectionWithChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SectionWithChange) ProtoMessage() {}
+
+func (x *SectionWithChange) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SectionWithChange.ProtoReflect.Descriptor instead.
+func (*SectionWithChange) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SectionWithChange) GetPriorPeriod() *RowData {
+	if x != nil {
+		return x.PriorPeriod
+	}
+	return nil
+}
+
+func (x *SectionWithChange) GetCurrentPeriod() *RowData {
+	if x != nil {
+		return x.CurrentPeriod
+	}
+	return nil
+}
+
+func (x *SectionWithChange) GetPctChange() *RowData {
+	if x != nil {
+		return x.PctChange
+	}
+	return nil
+}
+
+type SectionTwoRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PriorPeriod   *RowData               `protobuf:"bytes,1,opt,name=prior_period,json=priorPeriod,proto3" json:"prior_period,omitempty"`
+	CurrentPeriod *RowData               `protobuf:"bytes,2,opt,name=current_period,json=currentPeriod,proto3" json:"current_period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SectionTwoRow) Reset() {
+	*x = SectionTwoRow{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SectionTwoRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SectionTwoRow) ProtoMessage() {}
+
+func (x *SectionTwoRow) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SectionTwoRow.ProtoReflect.Descriptor instead.
+func (*SectionTwoRow) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SectionTwoRow) GetPriorPeriod() *RowData {
+	if x != nil {
+		return x.PriorPeriod
+	}
+	return nil
+}
+
+func (x *SectionTwoRow) GetCurrentPeriod() *RowData {
+	if x != nil {
+		return x.CurrentPeriod
+	}
+	return nil
+}
+
+type RowData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Indictables   string                 `protobuf:"bytes,2,opt,name=indictables,proto3" json:"indictables,omitempty"`
+	DpAndPdp      string                 `protobuf:"bytes,3,opt,name=dp_and_pdp,json=dpAndPdp,proto3" json:"dp_and_pdp,omitempty"`
+	OtherCriminal string                 `protobuf:"bytes,4,opt,name=other_criminal,json=otherCriminal,proto3" json:"other_criminal,omitempty"`
+	CriminalTotal string                 `protobuf:"bytes,5,opt,name=criminal_total,json=criminalTotal,proto3" json:"criminal_total,omitempty"`
+	Dwi           string                 `protobuf:"bytes,6,opt,name=dwi,proto3" json:"dwi,omitempty"`
+	TrafficMoving string                 `protobuf:"bytes,7,opt,name=traffic_moving,json=trafficMoving,proto3" json:"traffic_moving,omitempty"`
+	Parking       string                 `protobuf:"bytes,8,opt,name=parking,proto3" json:"parking,omitempty"`
+	TrafficTotal  string                 `protobuf:"bytes,9,opt,name=traffic_total,json=trafficTotal,proto3" json:"traffic_total,omitempty"`
+	GrandTotal    string                 `protobuf:"bytes,10,opt,name=grand_total,json=grandTotal,proto3" json:"grand_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RowData) Reset() {
+	*x = RowData{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RowData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RowData) ProtoMessage() {}
+
+func (x *RowData) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RowData.ProtoReflect.Descriptor instead.
+func (*RowData) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RowData) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *RowData) GetIndictables() string {
+	if x != nil {
+		return x.Indictables
+	}
+	return ""
+}
+
+func (x *RowData) GetDpAndPdp() string {
+	if x != nil {
+		return x.DpAndPdp
+	}
+	return ""
+}
+
+func (x *RowData) GetOtherCriminal() string {
+	if x != nil {
+		return x.OtherCriminal
+	}
+	return ""
+}
+
+func (x *RowData) GetCriminalTotal() string {
+	if x != nil {
+		return x.CriminalTotal
+	}
+	return ""
+}
+
+func (x *RowData) GetDwi() string {
+	if x != nil {
+		return x.Dwi
+	}
+	return ""
+}
+
+func (x *RowData) GetTrafficMoving() string {
+	if x != nil {
+		return x.TrafficMoving
+	}
+	return ""
+}
+
+func (x *RowData) GetParking() string {
+	if x != nil {
+		return x.Parking
+	}
+	return ""
+}
+
+func (x *RowData) GetTrafficTotal() string {
+	if x != nil {
+		return x.TrafficTotal
+	}
+	return ""
+}
+
+func (x *RowData) GetGrandTotal() string {
+	if x != nil {
+		return x.GrandTotal
+	}
+	return ""
+}
+
+type GetSeriesRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Level        string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`   // state, county (default) or municipality
+	Metric       string                 `protobuf:"bytes,2,opt,name=metric,proto3" json:"metric,omitempty"` // default filings
+	Type         string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`     // default grand-total
+	County       string                 `protobuf:"bytes,4,opt,name=county,proto3" json:"county,omitempty"`
+	Municipality string                 `protobuf:"bytes,5,opt,name=municipality,proto3" json:"municipality,omitempty"`
+	// Recompute rate metrics from summed counts rather than averaging.
+	Weighted      bool   `protobuf:"varint,6,opt,name=weighted,proto3" json:"weighted,omitempty"`
+	From          string `protobuf:"bytes,7,opt,name=from,proto3" json:"from,omitempty"`
+	To            string `protobuf:"bytes,8,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSeriesRequest) Reset() {
+	*x = GetSeriesRequest{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSeriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSeriesRequest) ProtoMessage() {}
+
+func (x *GetSeriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSeriesRequest.ProtoReflect.Descriptor instead.
+func (*GetSeriesRequest) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetSeriesRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *GetSeriesRequest) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *GetSeriesRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *GetSeriesRequest) GetCounty() string {
+	if x != nil {
+		return x.County
+	}
+	return ""
+}
+
+func (x *GetSeriesRequest) GetMunicipality() string {
+	if x != nil {
+		return x.Municipality
+	}
+	return ""
+}
+
+func (x *GetSeriesRequest) GetWeighted() bool {
+	if x != nil {
+		return x.Weighted
+	}
+	return false
+}
+
+func (x *GetSeriesRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetSeriesRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type GetSeriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Periods       []string               `protobuf:"bytes,2,rep,name=periods,proto3" json:"periods,omitempty"`
+	Series        []*Series              `protobuf:"bytes,3,rep,name=series,proto3" json:"series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSeriesResponse) Reset() {
+	*x = GetSeriesResponse{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSeriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSeriesResponse) ProtoMessage() {}
+
+func (x *GetSeriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSeriesResponse.ProtoReflect.Descriptor instead.
+func (*GetSeriesResponse) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetSeriesResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *GetSeriesResponse) GetPeriods() []string {
+	if x != nil {
+		return x.Periods
+	}
+	return nil
+}
+
+func (x *GetSeriesResponse) GetSeries() []*Series {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+type Series struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// One point per period in GetSeriesResponse.periods.
+	Points []*Point `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"`
+	// Stable entity ID of a municipality series when a county is given.
+	Id            string `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Series) Reset() {
+	*x = Series{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Series) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Series) ProtoMessage() {}
+
+func (x *Series) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Series.ProtoReflect.Descriptor instead.
+func (*Series) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Series) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Series) GetPoints() []*Point {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *Series) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Point struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         *float64               `protobuf:"fixed64,1,opt,name=value,proto3,oneof" json:"value,omitempty"` // unset where the report has no data
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	mi := &file_municourtpb_municourt_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_municourtpb_municourt_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_municourtpb_municourt_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Point) GetValue() float64 {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return 0
+}
+
+var File_municourtpb_municourt_proto protoreflect.FileDescriptor
+
+const file_municourtpb_municourt_proto_rawDesc = "" +
+	"\n" +
+	"\x1bmunicourtpb/municourt.proto\x12\fmunicourt.v1\"\x14\n" +
+	"\x12ListPeriodsRequest\"/\n" +
+	"\x13ListPeriodsResponse\x12\x18\n" +
+	"\aperiods\x18\x01 \x03(\tR\aperiods\"3\n" +
+	"\x19ListMunicipalitiesRequest\x12\x16\n" +
+	"\x06county\x18\x01 \x01(\tR\x06county\"`\n" +
+	"\x1aListMunicipalitiesResponse\x12B\n" +
+	"\x0emunicipalities\x18\x01 \x03(\v2\x1a.municourt.v1.MunicipalityR\x0emunicipalities\"J\n" +
+	"\fMunicipality\x12\x16\n" +
+	"\x06county\x18\x01 \x01(\tR\x06county\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\"s\n" +
+	"\x11GetRecordsRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x16\n" +
+	"\x06county\x18\x03 \x01(\tR\x06county\x12\"\n" +
+	"\fmunicipality\x18\x04 \x01(\tR\fmunicipality\"W\n" +
+	"\x06Record\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\x125\n" +
+	"\x05stats\x18\x02 \x01(\v2\x1f.municourt.v1.MunicipalityStatsR\x05stats\"\xcc\x06\n" +
+	"\x11MunicipalityStats\x12\x16\n" +
+	"\x06county\x18\x01 \x01(\tR\x06county\x12\"\n" +
+	"\fmunicipality\x18\x02 \x01(\tR\fmunicipality\x12\x1d\n" +
+	"\n" +
+	"date_range\x18\x03 \x01(\tR\tdateRange\x129\n" +
+	"\afilings\x18\x04 \x01(\v2\x1f.municourt.v1.SectionWithChangeR\afilings\x12A\n" +
+	"\vresolutions\x18\x05 \x01(\v2\x1f.municourt.v1.SectionWithChangeR\vresolutions\x129\n" +
+	"\tclearance\x18\x06 \x01(\v2\x1b.municourt.v1.SectionTwoRowR\tclearance\x12H\n" +
+	"\x11clearance_percent\x18\a \x01(\v2\x1b.municourt.v1.SectionTwoRowR\x10clearancePercent\x129\n" +
+	"\abacklog\x18\b \x01(\v2\x1f.municourt.v1.SectionWithChangeR\abacklog\x12`\n" +
+	"\x1cbacklog_per100_mthly_filings\x18\t \x01(\v2\x1f.municourt.v1.SectionWithChangeR\x19backlogPer100MthlyFilings\x12D\n" +
+	"\x0fbacklog_percent\x18\n" +
+	" \x01(\v2\x1b.municourt.v1.SectionTwoRowR\x0ebacklogPercent\x12F\n" +
+	"\x0eactive_pending\x18\v \x01(\v2\x1f.municourt.v1.SectionWithChangeR\ractivePending\x12\"\n" +
+	"\fpredecessors\x18\f \x03(\tR\fpredecessors\x12\x1c\n" +
+	"\tsuccessor\x18\r \x01(\tR\tsuccessor\x12\x1f\n" +
+	"\vsource_file\x18\x0e \x01(\tR\n" +
+	"sourceFile\x12\x1f\n" +
+	"\vpage_number\x18\x0f \x01(\x05R\n" +
+	"pageNumber\x12\x1a\n" +
+	"\bwarnings\x18\x10 \x03(\tR\bwarnings\x12\x0e\n" +
+	"\x02id\x18\x11 \x01(\tR\x02id\"\xc1\x01\n" +
+	"\x11SectionWithChange\x128\n" +
+	"\fprior_period\x18\x01 \x01(\v2\x15.municourt.v1.RowDataR\vpriorPeriod\x12<\n" +
+	"\x0ecurrent_period\x18\x02 \x01(\v2\x15.municourt.v1.RowDataR\rcurrentPeriod\x124\n" +
+	"\n" +
+	"pct_change\x18\x03 \x01(\v2\x15.municourt.v1.RowDataR\tpctChange\"\x87\x01\n" +
+	"\rSectionTwoRow\x128\n" +
+	"\fprior_period\x18\x01 \x01(\v2\x15.municourt.v1.RowDataR\vpriorPeriod\x12<\n" +
+	"\x0ecurrent_period\x18\x02 \x01(\v2\x15.municourt.v1.RowDataR\rcurrentPeriod\"\xc6\x02\n" +
+	"\aRowData\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12 \n" +
+	"\vindictables\x18\x02 \x01(\tR\vindictables\x12\x1c\n" +
+	"\n" +
+	"dp_and_pdp\x18\x03 \x01(\tR\bdpAndPdp\x12%\n" +
+	"\x0eother_criminal\x18\x04 \x01(\tR\rotherCriminal\x12%\n" +
+	"\x0ecriminal_total\x18\x05 \x01(\tR\rcriminalTotal\x12\x10\n" +
+	"\x03dwi\x18\x06 \x01(\tR\x03dwi\x12%\n" +
+	"\x0etraffic_moving\x18\a \x01(\tR\rtrafficMoving\x12\x18\n" +
+	"\aparking\x18\b \x01(\tR\aparking\x12#\n" +
+	"\rtraffic_total\x18\t \x01(\tR\ftrafficTotal\x12\x1f\n" +
+	"\vgrand_total\x18\n" +
+	" \x01(\tR\n" +
+	"grandTotal\"\xd0\x01\n" +
+	"\x10GetSeriesRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x16\n" +
+	"\x06metric\x18\x02 \x01(\tR\x06metric\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x16\n" +
+	"\x06county\x18\x04 \x01(\tR\x06county\x12\"\n" +
+	"\fmunicipality\x18\x05 \x01(\tR\fmunicipality\x12\x1a\n" +
+	"\bweighted\x18\x06 \x01(\bR\bweighted\x12\x12\n" +
+	"\x04from\x18\a \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\b \x01(\tR\x02to\"q\n" +
+	"\x11GetSeriesResponse\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
+	"\aperiods\x18\x02 \x03(\tR\aperiods\x12,\n" +
+	"\x06series\x18\x03 \x03(\v2\x14.municourt.v1.SeriesR\x06series\"Y\n" +
+	"\x06Series\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
+	"\x06points\x18\x02 \x03(\v2\x13.municourt.v1.PointR\x06points\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\",\n" +
+	"\x05Point\x12\x19\n" +
+	"\x05value\x18\x01 \x01(\x01H\x00R\x05value\x88\x01\x01B\b\n" +
+	"\x06_value2\xdd\x02\n" +
+	"\tMunicourt\x12R\n" +
+	"\vListPeriods\x12 .municourt.v1.ListPeriodsRequest\x1a!.municourt.v1.ListPeriodsResponse\x12g\n" +
+	"\x12ListMunicipalities\x12'.municourt.v1.ListMunicipalitiesRequest\x1a(.municourt.v1.ListMunicipalitiesResponse\x12E\n" +
+	"\n" +
+	"GetRecords\x12\x1f.municourt.v1.GetRecordsRequest\x1a\x14.municourt.v1.Record0\x01\x12L\n" +
+	"\tGetSeries\x12\x1e.municourt.v1.GetSeriesRequest\x1a\x1f.municourt.v1.GetSeriesResponseB)Z'github.com/zalepa/municourt/municourtpbb\x06proto3"
+
+var (
+	file_municourtpb_municourt_proto_rawDescOnce sync.Once
+	file_municourtpb_municourt_proto_rawDescData []byte
+)
+
+func file_municourtpb_municourt_proto_rawDescGZIP() []byte {
+	file_municourtpb_municourt_proto_rawDescOnce.Do(func() {
+		file_municourtpb_municourt_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_municourtpb_municourt_proto_rawDesc), len(file_municourtpb_municourt_proto_rawDesc)))
+	})
+	return file_municourtpb_municourt_proto_rawDescData
+}
+
+var file_municourtpb_municourt_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_municourtpb_municourt_proto_goTypes = []any{
+	(*ListPeriodsRequest)(nil),         // 0: municourt.v1.ListPeriodsRequest
+	(*ListPeriodsResponse)(nil),        // 1: municourt.v1.ListPeriodsResponse
+	(*ListMunicipalitiesRequest)(nil),  // 2: municourt.v1.ListMunicipalitiesRequest
+	(*ListMunicipalitiesResponse)(nil), // 3: municourt.v1.ListMunicipalitiesResponse
+	(*Municipality)(nil),               // 4: municourt.v1.Municipality
+	(*GetRecordsRequest)(nil),          // 5: municourt.v1.GetRecordsRequest
+	(*Record)(nil),                     // 6: municourt.v1.Record
+	(*MunicipalityStats)(nil),          // 7: municourt.v1.MunicipalityStats
+	(*SectionWithChange)(nil),          // 8: municourt.v1.SectionWithChange
+	(*SectionTwoRow)(nil),              // 9: municourt.v1.SectionTwoRow
+	(*RowData)(nil),                    // 10: municourt.v1.RowData
+	(*GetSeriesRequest)(nil),           // 11: municourt.v1.GetSeriesRequest
+	(*GetSeriesResponse)(nil),          // 12: municourt.v1.GetSeriesResponse
+	(*Series)(nil),                     // 13: municourt.v1.Series
+	(*Point)(nil),                      // 14: municourt.v1.Point
+}
+var file_municourtpb_municourt_proto_depIdxs = []int32{
+	4,  // 0: municourt.v1.ListMunicipalitiesResponse.municipalities:type_name -> municourt.v1.Municipality
+	7,  // 1: municourt.v1.Record.stats:type_name -> municourt.v1.MunicipalityStats
+	8,  // 2: municourt.v1.MunicipalityStats.filings:type_name -> municourt.v1.SectionWithChange
+	8,  // 3: municourt.v1.MunicipalityStats.resolutions:type_name -> municourt.v1.SectionWithChange
+	9,  // 4: municourt.v1.MunicipalityStats.clearance:type_name -> municourt.v1.SectionTwoRow
+	9,  // 5: municourt.v1.MunicipalityStats.clearance_percent:type_name -> municourt.v1.SectionTwoRow
+	8,  // 6: municourt.v1.MunicipalityStats.backlog:type_name -> municourt.v1.SectionWithChange
+	8,  // 7: municourt.v1.MunicipalityStats.backlog_per100_mthly_filings:type_name -> municourt.v1.SectionWithChange
+	9,  // 8: municourt.v1.MunicipalityStats.backlog_percent:type_name -> municourt.v1.SectionTwoRow
+	8,  // 9: municourt.v1.MunicipalityStats.active_pending:type_name -> municourt.v1.SectionWithChange
+	10, // 10: municourt.v1.SectionWithChange.prior_period:type_name -> municourt.v1.RowData
+	10, // 11: municourt.v1.SectionWithChange.current_period:type_name -> municourt.v1.RowData
+	10, // 12: municourt.v1.SectionWithChange.pct_change:type_name -> municourt.v1.RowData
+	10, // 13: municourt.v1.SectionTwoRow.prior_period:type_name -> municourt.v1.RowData
+	10, // 14: municourt.v1.SectionTwoRow.current_period:type_name -> municourt.v1.RowData
+	13, // 15: municourt.v1.GetSeriesResponse.series:type_name -> municourt.v1.Series
+	14, // 16: municourt.v1.Series.points:type_name -> municourt.v1.Point
+	0,  // 17: municourt.v1.Municourt.ListPeriods:input_type -> municourt.v1.ListPeriodsRequest
+	2,  // 18: municourt.v1.Municourt.ListMunicipalities:input_type -> municourt.v1.ListMunicipalitiesRequest
+	5,  // 19: municourt.v1.Municourt.GetRecords:input_type -> municourt.v1.GetRecordsRequest
+	11, // 20: municourt.v1.Municourt.GetSeries:input_type -> municourt.v1.GetSeriesRequest
+	1,  // 21: municourt.v1.Municourt.ListPeriods:output_type -> municourt.v1.ListPeriodsResponse
+	3,  // 22: municourt.v1.Municourt.ListMunicipalities:output_type -> municourt.v1.ListMunicipalitiesResponse
+	6,  // 23: municourt.v1.Municourt.GetRecords:output_type -> municourt.v1.Record
+	12, // 24: municourt.v1.Municourt.GetSeries:output_type -> municourt.v1.GetSeriesResponse
+	21, // [21:25] is the sub-list for method output_type
+	17, // [17:21] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_municourtpb_municourt_proto_init() }
+func file_municourtpb_municourt_proto_init() {
+	if File_municourtpb_municourt_proto != nil {
+		return
+	}
+	file_municourtpb_municourt_proto_msgTypes[14].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_municourtpb_municourt_proto_rawDesc), len(file_municourtpb_municourt_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_municourtpb_municourt_proto_goTypes,
+		DependencyIndexes: file_municourtpb_municourt_proto_depIdxs,
+		MessageInfos:      file_municourtpb_municourt_proto_msgTypes,
+	}.Build()
+	File_municourtpb_municourt_proto = out.File
+	file_municourtpb_municourt_proto_goTypes = nil
+	file_municourtpb_municourt_proto_depIdxs = nil
+}
@@ -0,0 +1,261 @@
+// Protobuf schema for the municourt gRPC service (municourt grpc).
+//
+// Regenerate the Go code after editing with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	    municourtpb/municourt.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: municourtpb/municourt.proto
+
+package municourtpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Municourt_ListPeriods_FullMethodName        = "/municourt.v1.Municourt/ListPeriods"
+	Municourt_ListMunicipalities_FullMethodName = "/municourt.v1.Municourt/ListMunicipalities"
+	Municourt_GetRecords_FullMethodName         = "/municourt.v1.Municourt/GetRecords"
+	Municourt_GetSeries_FullMethodName          = "/municourt.v1.Municourt/GetSeries"
+)
+
+// MunicourtClient is the client API for Municourt service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Municourt serves parsed municipal court statistics.
+type MunicourtClient interface {
+	// ListPeriods returns every report period loaded, oldest first.
+	ListPeriods(ctx context.Context, in *ListPeriodsRequest, opts ...grpc.CallOption) (*ListPeriodsResponse, error)
+	// ListMunicipalities returns the counties and municipalities that appear
+	// in any period.
+	ListMunicipalities(ctx context.Context, in *ListMunicipalitiesRequest, opts ...grpc.CallOption) (*ListMunicipalitiesResponse, error)
+	// GetRecords streams full records, one per municipality and period.
+	GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Record], error)
+	// GetSeries returns aggregated time series, as /api/series does.
+	GetSeries(ctx context.Context, in *GetSeriesRequest, opts ...grpc.CallOption) (*GetSeriesResponse, error)
+}
+
+type municourtClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMunicourtClient(cc grpc.ClientConnInterface) MunicourtClient {
+	return &municourtClient{cc}
+}
+
+func (c *municourtClient) ListPeriods(ctx context.Context, in *ListPeriodsRequest, opts ...grpc.CallOption) (*ListPeriodsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPeriodsResponse)
+	err := c.cc.Invoke(ctx, Municourt_ListPeriods_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *municourtClient) ListMunicipalities(ctx context.Context, in *ListMunicipalitiesRequest, opts ...grpc.CallOption) (*ListMunicipalitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMunicipalitiesResponse)
+	err := c.cc.Invoke(ctx, Municourt_ListMunicipalities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *municourtClient) GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Record], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Municourt_ServiceDesc.Streams[0], Municourt_GetRecords_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetRecordsRequest, Record]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Municourt_GetRecordsClient = grpc.ServerStreamingClient[Record]
+
+func (c *municourtClient) GetSeries(ctx context.Context, in *GetSeriesRequest, opts ...grpc.CallOption) (*GetSeriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSeriesResponse)
+	err := c.cc.Invoke(ctx, Municourt_GetSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MunicourtServer is the server API for Municourt service.
+// All implementations must embed UnimplementedMunicourtServer
+// for forward compatibility.
+//
+// Municourt serves parsed municipal court statistics.
+type MunicourtServer interface {
+	// ListPeriods returns every report period loaded, oldest first.
+	ListPeriods(context.Context, *ListPeriodsRequest) (*ListPeriodsResponse, error)
+	// ListMunicipalities returns the counties and municipalities that appear
+	// in any period.
+	ListMunicipalities(context.Context, *ListMunicipalitiesRequest) (*ListMunicipalitiesResponse, error)
+	// GetRecords streams full records, one per municipality and period.
+	GetRecords(*GetRecordsRequest, grpc.ServerStreamingServer[Record]) error
+	// GetSeries returns aggregated time series, as /api/series does.
+	GetSeries(context.Context, *GetSeriesRequest) (*GetSeriesResponse, error)
+	mustEmbedUnimplementedMunicourtServer()
+}
+
+// UnimplementedMunicourtServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMunicourtServer struct{}
+
+func (UnimplementedMunicourtServer) ListPeriods(context.Context, *ListPeriodsRequest) (*ListPeriodsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPeriods not implemented")
+}
+func (UnimplementedMunicourtServer) ListMunicipalities(context.Context, *ListMunicipalitiesRequest) (*ListMunicipalitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMunicipalities not implemented")
+}
+func (UnimplementedMunicourtServer) GetRecords(*GetRecordsRequest, grpc.ServerStreamingServer[Record]) error {
+	return status.Errorf(codes.Unimplemented, "method GetRecords not implemented")
+}
+func (UnimplementedMunicourtServer) GetSeries(context.Context, *GetSeriesRequest) (*GetSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSeries not implemented")
+}
+func (UnimplementedMunicourtServer) mustEmbedUnimplementedMunicourtServer() {}
+func (UnimplementedMunicourtServer) testEmbeddedByValue()                   {}
+
+// UnsafeMunicourtServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MunicourtServer will
+// result in compilation errors.
+type UnsafeMunicourtServer interface {
+	mustEmbedUnimplementedMunicourtServer()
+}
+
+func RegisterMunicourtServer(s grpc.ServiceRegistrar, srv MunicourtServer) {
+	// If the following call pancis, it indicates UnimplementedMunicourtServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Municourt_ServiceDesc, srv)
+}
+
+func _Municourt_ListPeriods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPeriodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MunicourtServer).ListPeriods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Municourt_ListPeriods_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MunicourtServer).ListPeriods(ctx, req.(*ListPeriodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Municourt_ListMunicipalities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMunicipalitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MunicourtServer).ListMunicipalities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Municourt_ListMunicipalities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MunicourtServer).ListMunicipalities(ctx, req.(*ListMunicipalitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Municourt_GetRecords_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetRecordsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MunicourtServer).GetRecords(m, &grpc.GenericServerStream[GetRecordsRequest, Record]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Municourt_GetRecordsServer = grpc.ServerStreamingServer[Record]
+
+func _Municourt_GetSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MunicourtServer).GetSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Municourt_GetSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MunicourtServer).GetSeries(ctx, req.(*GetSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Municourt_ServiceDesc is the grpc.ServiceDesc for Municourt service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Municourt_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "municourt.v1.Municourt",
+	HandlerType: (*MunicourtServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPeriods",
+			Handler:    _Municourt_ListPeriods_Handler,
+		},
+		{
+			MethodName: "ListMunicipalities",
+			Handler:    _Municourt_ListMunicipalities_Handler,
+		},
+		{
+			MethodName: "GetSeries",
+			Handler:    _Municourt_GetSeries_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetRecords",
+			Handler:       _Municourt_GetRecords_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "municourtpb/municourt.proto",
+}
@@ -69,8 +69,9 @@ func parseBFRange(section string, cmap CMap) {
 		lo := decodeUint16(tokens[i])
 		hi := decodeUint16(tokens[i+1])
 		dstStart := decodeUint16(tokens[i+2])
-		for g := lo; g <= hi; g++ {
-			cmap[g] = rune(dstStart + (g - lo))
+		// Loop in int: a uint16 counter would wrap and never pass hi=0xFFFF.
+		for g := int(lo); g <= int(hi); g++ {
+			cmap[uint16(g)] = rune(dstStart + (uint16(g) - lo))
 		}
 	}
 }
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// columnNames names the nine value columns, left to right, for warnings.
+var columnNames = [9]string{
+	"Indictables",
+	"D.P. & P.D.P.",
+	"Other Criminal",
+	"Criminal Total",
+	"D.W.I.",
+	"Traffic (moving)",
+	"Parking",
+	"Traffic Total",
+	"Grand Total",
+}
+
+// columnLayout is where each value column ends along the baseline, taken
+// from the column header labels above it. Values are right-aligned in
+// their columns, so a value belongs to the column whose header ends
+// nearest to where the value ends.
+type columnLayout [9]float64
+
+// calibrateColumns reads the column layout from a page's header lines, the
+// lines between the municipality and the first section. The last line
+// with nine labels ("Indictables", "P.D.P.", ... "Total") gives the
+// columns; labels stacked over them on other lines ("D.P. &", "Grand")
+// widen them. ok is false if there is no such line or its text wasn't
+// placed.
+func calibrateColumns(header [][]TextItem) (layout columnLayout, ok bool) {
+	base := -1
+	for i, line := range header {
+		if len(line) == len(layout) {
+			base = i
+		}
+	}
+	if base < 0 {
+		return layout, false
+	}
+	var starts [9]float64
+	for i, it := range header[base] {
+		if !it.Placed || (i > 0 && it.Start <= header[base][i-1].End) {
+			return layout, false
+		}
+		starts[i], layout[i] = it.Start, it.End
+	}
+	for i, line := range header {
+		if i == base {
+			continue
+		}
+		for _, it := range line {
+			if !it.Placed {
+				continue
+			}
+			for c := range layout {
+				if it.Start < layout[c] && it.End > starts[c] {
+					layout[c] = math.Max(layout[c], it.End)
+				}
+			}
+		}
+	}
+	return layout, true
+}
+
+// place assigns the values of a row, the items after its label, to
+// columns. A value split into pieces by kerning lands in one column and is
+// joined back: pieces that touch are concatenated ("8" + "3"), pieces
+// either side of a missing thousands separator get a comma ("1" + "000").
+// A column with no value gets the "- -" no-data marker and a warning.
+// recs says how each column's value was recovered.
+//
+// ok is false if any value wasn't placed, lies further from every column
+// than the columns lie from each other, or shares its column with a value
+// it can't be joined to; the caller then falls back to counting values.
+func (l columnLayout) place(values []TextItem) (cols [9]string, recs [9]Recovery, warnings []string, ok bool) {
+	tolerance := math.Inf(1)
+	for i := 1; i < len(l); i++ {
+		tolerance = math.Min(tolerance, l[i]-l[i-1])
+	}
+
+	var last [9]TextItem
+	for _, v := range values {
+		if !v.Placed {
+			return cols, recs, nil, false
+		}
+		c := sort.Search(len(l), func(i int) bool { return l[i] >= v.End })
+		if c == len(l) || (c > 0 && v.End-l[c-1] < l[c]-v.End) {
+			c--
+		}
+		if math.Abs(v.End-l[c]) > tolerance {
+			return cols, recs, nil, false
+		}
+		switch {
+		case cols[c] == "":
+			cols[c] = v.Text
+			recs[c] = RecoveryDirect
+		case v.Start-last[c].End < touchGap:
+			cols[c] += v.Text
+			recs[c] = worse(recs[c], RecoveryKerning)
+		case looksLikeCommaSplit(cols[c], v.Text):
+			cols[c] += "," + v.Text
+			recs[c] = worse(recs[c], RecoveryCommaMerge)
+		default:
+			return cols, recs, nil, false
+		}
+		last[c] = v
+	}
+
+	for c := range cols {
+		if cols[c] == "" {
+			cols[c] = "- -"
+			recs[c] = RecoveryEmpty
+			warnings = append(warnings, fmt.Sprintf("no value under %s", columnNames[c]))
+		}
+	}
+	return cols, recs, warnings, true
+}
+
+// worse returns whichever of a and b gives the lower confidence.
+func worse(a, b Recovery) Recovery {
+	if b.Confidence() < a.Confidence() {
+		return b
+	}
+	return a
+}
+
+// touchGap is how far apart, in user space units, two pieces of one value
+// may be and still be read as touching: well under the width of a digit.
+const touchGap = 1.0
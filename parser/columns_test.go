@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractPlacedItems(t *testing.T) {
+	// Every glyph 500/1000 wide at size 10: 5 units a character.
+	fonts := map[string]FontMetrics{"F1": {Default: 500}}
+	stream := []byte(`BT
+/F1 10 Tf
+1 0 0 1 100 700 Tm
+[(12)-2000(345)]TJ
+20 0 Td
+(6)Tj
+ET`)
+	items, err := ExtractPlacedItems(PageData{Content: stream, Fonts: fonts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TextItem{
+		{},
+		{Text: "12", Start: 100, End: 110, Placed: true},
+		{Text: "345", Start: 130, End: 145, Placed: true},
+		{Text: "6", Start: 120, End: 125, Placed: true},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("got %+v, want %+v", items, want)
+	}
+
+	// Without the font's widths the text is the same but not placed.
+	items, _ = ExtractPlacedItems(PageData{Content: stream})
+	if got := Texts(items); !reflect.DeepEqual(got, []string{"", "12", "345", "6"}) {
+		t.Errorf("texts = %q", got)
+	}
+	for _, it := range items {
+		if it.Placed {
+			t.Errorf("%q placed without font widths", it.Text)
+		}
+	}
+}
+
+func TestCalibrateColumns(t *testing.T) {
+	pages, err := ExtractContentStreams("testdata/page.pdf")
+	if err != nil {
+		t.Fatalf("ExtractContentStreams: %v", err)
+	}
+	items, _ := ExtractPlacedItems(pages[0])
+	lines := groupItemLines(items)
+	layout, ok := calibrateColumns(lines[4:tableStart(groupIntoLines(Texts(items)))])
+	if !ok {
+		t.Fatal("no column layout from the page's header")
+	}
+	for i := 1; i < len(layout); i++ {
+		if layout[i] <= layout[i-1] {
+			t.Fatalf("columns out of order: %v", layout)
+		}
+	}
+
+	// Parsing with the layout gives the same values as counting them off.
+	placed, err := ParsePageItems(items, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counted, err := ParsePage(Texts(items))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(placed, counted) {
+		t.Errorf("placed and counted parses differ:\n%+v\n%+v", placed, counted)
+	}
+
+	if _, ok := calibrateColumns(lines[:4]); ok {
+		t.Error("calibrated from the title lines")
+	}
+}
+
+func TestColumnLayoutPlace(t *testing.T) {
+	layout := columnLayout{100, 150, 200, 250, 300, 350, 400, 450, 500}
+	at := func(text string, end float64) TextItem {
+		return TextItem{Text: text, Start: end - 5*float64(len(text)), End: end, Placed: true}
+	}
+
+	// "83" kerned apart and "1,000" missing its comma, with the Parking
+	// column empty: counting would shift everything after them left.
+	values := []TextItem{
+		at("1", 101), at("2", 152), at("3", 199), at("6", 251), at("4", 300),
+		at("8", 346), at("3", 351), at("1", 432), at("000", 452), at("1,089", 503),
+	}
+	cols, recs, warnings, ok := layout.place(values)
+	if !ok {
+		t.Fatal("row not placed")
+	}
+	want := [9]string{"1", "2", "3", "6", "4", "83", "- -", "1,000", "1,089"}
+	if cols != want {
+		t.Errorf("cols = %q, want %q", cols, want)
+	}
+	if !reflect.DeepEqual(warnings, []string{"no value under Parking"}) {
+		t.Errorf("warnings = %q", warnings)
+	}
+	wantRecs := [9]Recovery{
+		RecoveryDirect, RecoveryDirect, RecoveryDirect, RecoveryDirect, RecoveryDirect,
+		RecoveryKerning, RecoveryEmpty, RecoveryCommaMerge, RecoveryDirect,
+	}
+	if recs != wantRecs {
+		t.Errorf("recs = %q, want %q", recs, wantRecs)
+	}
+
+	for name, values := range map[string][]TextItem{
+		"unplaced":       {at("1", 101), {Text: "2"}},
+		"far off":        {at("1", 101), at("2", 600)},
+		"two values":     {at("12", 130), at("34", 150)},
+		"label included": {at("Jul 2024", 20), at("1", 101)},
+	} {
+		if _, _, _, ok := layout.place(values); ok {
+			t.Errorf("%s: placed", name)
+		}
+	}
+}
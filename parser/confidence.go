@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Recovery is how the parser got a value out of a page's text.
+type Recovery string
+
+const (
+	RecoveryDirect     Recovery = "direct"       // one text item, as printed
+	RecoveryKerning    Recovery = "kerning"      // pieces kerned apart, concatenated
+	RecoveryCommaMerge Recovery = "comma-merge"  // pieces either side of a thousands separator, joined with a comma
+	RecoveryEmpty      Recovery = "empty-column" // nothing under the column header; "- -" supplied
+	RecoveryMiscounted Recovery = "miscounted"   // the row had more or fewer values than columns, so this one may belong to another column
+	RecoveryPadded     Recovery = "padded"       // the row ran short; "- -" supplied at its end
+)
+
+// Recoveries lists every Recovery, most trustworthy first.
+var Recoveries = []Recovery{
+	RecoveryDirect,
+	RecoveryKerning,
+	RecoveryCommaMerge,
+	RecoveryEmpty,
+	RecoveryMiscounted,
+	RecoveryPadded,
+}
+
+// Confidence scores a value recovered this way from 0 to 1: 1 for a value
+// read directly, down to 0.2 for "- -" made up to fill a short row.
+func (r Recovery) Confidence() float64 {
+	switch r {
+	case RecoveryDirect:
+		return 1
+	case RecoveryKerning:
+		return 0.9
+	case RecoveryCommaMerge:
+		return 0.8
+	case RecoveryEmpty:
+		return 0.7
+	case RecoveryMiscounted:
+		return 0.3
+	case RecoveryPadded:
+		return 0.2
+	}
+	return 0
+}
+
+// RowFields names RowData's values by their JSON field names, in column
+// order.
+var RowFields = [9]string{
+	"indictables",
+	"dpAndPdp",
+	"otherCriminal",
+	"criminalTotal",
+	"dwi",
+	"trafficMoving",
+	"parking",
+	"trafficTotal",
+	"grandTotal",
+}
+
+// RowRecovery says how each of a row's values, in RowFields order, was
+// recovered; "" is RecoveryDirect. In JSON it is an object of the values
+// that weren't read directly, keyed by field name, e.g.
+// {"trafficMoving": "kerning"}.
+type RowRecovery [9]Recovery
+
+func (r RowRecovery) MarshalJSON() ([]byte, error) {
+	m := make(map[string]Recovery)
+	for i, rec := range r {
+		if rec != "" && rec != RecoveryDirect {
+			m[RowFields[i]] = rec
+		}
+	}
+	return json.Marshal(m)
+}
+
+func (r *RowRecovery) UnmarshalJSON(data []byte) error {
+	var m map[string]Recovery
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*r = RowRecovery{}
+	for field, rec := range m {
+		i := fieldIndex(field)
+		if i < 0 {
+			return fmt.Errorf("recovered: unknown field %q", field)
+		}
+		r[i] = rec
+	}
+	return nil
+}
+
+func fieldIndex(field string) int {
+	for i, f := range RowFields {
+		if f == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// Recovery returns how the value of field, a JSON field name from
+// RowFields, was recovered.
+func (r RowData) Recovery(field string) Recovery {
+	if i := fieldIndex(field); i >= 0 && r.Recovered[i] != "" {
+		return r.Recovered[i]
+	}
+	return RecoveryDirect
+}
+
+// Confidence returns the confidence score of the value of field, a JSON
+// field name from RowFields.
+func (r RowData) Confidence(field string) float64 {
+	return r.Recovery(field).Confidence()
+}
+
+// recovered returns the RowRecovery of a row's values, leaving those read
+// directly empty so that a row read wholly directly is the zero value.
+func recovered(recs []Recovery) RowRecovery {
+	var r RowRecovery
+	for i, rec := range recs {
+		if rec != RecoveryDirect {
+			r[i] = rec
+		}
+	}
+	return r
+}
+
+// ConfidenceSummary tallies how the values of a set of records were
+// recovered.
+type ConfidenceSummary struct {
+	Values     int              `json:"values"`
+	ByRecovery map[Recovery]int `json:"byRecovery"`
+	Mean       float64          `json:"mean"` // mean confidence score
+}
+
+// SummarizeConfidence tallies the values of every row of records.
+func SummarizeConfidence(records []MunicipalityStats) ConfidenceSummary {
+	sum := ConfidenceSummary{ByRecovery: make(map[Recovery]int)}
+	total := 0.0
+	for i := range records {
+		for _, row := range records[i].Rows() {
+			for _, f := range RowFields {
+				rec := row.Recovery(f)
+				sum.Values++
+				sum.ByRecovery[rec]++
+				total += rec.Confidence()
+			}
+		}
+	}
+	if sum.Values > 0 {
+		sum.Mean = total / float64(sum.Values)
+	}
+	return sum
+}
+
+// Rows returns the record's rows in page order.
+func (s *MunicipalityStats) Rows() []RowData {
+	var rows []RowData
+	for _, sec := range []SectionWithChange{s.Filings, s.Resolutions} {
+		rows = append(rows, sec.PriorPeriod, sec.CurrentPeriod, sec.PctChange)
+	}
+	for _, sec := range []SectionTwoRow{s.Clearance, s.ClearancePct} {
+		rows = append(rows, sec.PriorPeriod, sec.CurrentPeriod)
+	}
+	for _, sec := range []SectionWithChange{s.Backlog, s.BacklogPer100} {
+		rows = append(rows, sec.PriorPeriod, sec.CurrentPeriod, sec.PctChange)
+	}
+	rows = append(rows, s.BacklogPct.PriorPeriod, s.BacklogPct.CurrentPeriod)
+	return append(rows, s.ActivePending.PriorPeriod, s.ActivePending.CurrentPeriod, s.ActivePending.PctChange)
+}
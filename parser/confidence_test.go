@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestRecoveredCounted(t *testing.T) {
+	pages, err := ExtractContentStreams("testdata/page.pdf")
+	if err != nil {
+		t.Fatalf("ExtractContentStreams: %v", err)
+	}
+	items := ExtractTextItems(pages[0])
+
+	stats, err := ParsePage(items)
+	if err != nil {
+		t.Fatalf("ParsePage: %v", err)
+	}
+	sum := SummarizeConfidence([]MunicipalityStats{stats})
+	if sum.Values != 21*9 || sum.ByRecovery[RecoveryDirect] != sum.Values || sum.Mean != 1 {
+		t.Errorf("whole page: %+v", sum)
+	}
+
+	// Split a thousands value in the first row at its comma, and drop the
+	// last value of the second row.
+	lines := groupIntoLines(items)
+	filings := tableStart(lines)
+	first, second := lines[filings+1], lines[filings+2]
+	if first[6] != "2,339" {
+		t.Fatalf("traffic moving %q; testdata changed?", first[6])
+	}
+	lines[filings+1] = append(append(first[:6:6], "2", "339"), first[7:]...)
+	lines[filings+2] = second[:len(second)-1]
+	var edited []string
+	for _, l := range lines {
+		edited = append(append(edited, l...), "")
+	}
+
+	stats, err = ParsePage(edited)
+	if err != nil {
+		t.Fatalf("ParsePage: %v", err)
+	}
+	prior, current := stats.Filings.PriorPeriod, stats.Filings.CurrentPeriod
+	if prior.TrafficMoving != "2,339" || prior.Recovery("trafficMoving") != RecoveryCommaMerge || prior.Confidence("dwi") != 1 {
+		t.Errorf("prior: %q %v", prior.TrafficMoving, prior.Recovered)
+	}
+	if current.Recovery("grandTotal") != RecoveryPadded || current.Recovery("indictables") != RecoveryMiscounted {
+		t.Errorf("current: %v", current.Recovered)
+	}
+
+	sum = SummarizeConfidence([]MunicipalityStats{stats})
+	if sum.ByRecovery[RecoveryCommaMerge] != 1 || sum.ByRecovery[RecoveryMiscounted] != 8 || sum.ByRecovery[RecoveryPadded] != 1 {
+		t.Errorf("edited page: %+v", sum.ByRecovery)
+	}
+	if sum.Mean >= 1 || sum.Mean < 0.9 {
+		t.Errorf("mean = %v", sum.Mean)
+	}
+}
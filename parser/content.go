@@ -1,9 +1,12 @@
 package parser
 
 import (
+	"encoding/binary"
+	"encoding/hex"
 	"math"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // kerningThreshold is the absolute value above which a kerning/spacing number
@@ -14,11 +17,61 @@ const kerningThreshold = 500
 // text strings. Empty strings ("") are inserted as line-break markers whenever
 // a TD/Td operator moves to a new line (non-zero y offset).
 func ExtractTextItems(page PageData) []string {
-	tokens := tokenize(string(page.Content))
-	var items []string
-	var stack []token  // operand stack
-	var tc float64     // current Tc (character spacing) in text space units
-	var curFont string // current font name from Tf operator
+	items, _ := ExtractTextItemsChecked(page)
+	return items
+}
+
+// ExtractTextItemsChecked is ExtractTextItems that also reports a malformed
+// content stream, as a *ContentError. The items are the same best-effort
+// extraction either way.
+func ExtractTextItemsChecked(page PageData) ([]string, error) {
+	items, err := ExtractPlacedItems(page)
+	return Texts(items), err
+}
+
+// TextItem is a text item with where it sits along its baseline, in user
+// space units. Start and End are only meaningful when Placed is set, i.e.
+// the widths of the font it is shown in are known. A TextItem with empty
+// Text is a line-break marker.
+type TextItem struct {
+	Text       string
+	Start, End float64
+	Placed     bool
+}
+
+// Texts returns the text of each item.
+func Texts(items []TextItem) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	texts := make([]string, len(items))
+	for i, it := range items {
+		texts[i] = it.Text
+	}
+	return texts
+}
+
+// PlainItems wraps text items with no position, e.g. for ParsePageItems.
+func PlainItems(texts []string) []TextItem {
+	if len(texts) == 0 {
+		return nil
+	}
+	items := make([]TextItem, len(texts))
+	for i, t := range texts {
+		items[i] = TextItem{Text: t}
+	}
+	return items
+}
+
+// ExtractPlacedItems is ExtractTextItemsChecked that also lays out the
+// text: each item carries its extent along the baseline, computed from the
+// text matrix and the font's glyph widths. The text of the items is exactly
+// what ExtractTextItemsChecked returns.
+func ExtractPlacedItems(page PageData) ([]TextItem, error) {
+	tokens, cerr := tokenize(string(page.Content))
+	var items []TextItem
+	var stack []token // operand stack
+	st := newTextState()
 
 	// Text matrix tracking for smart Tm line-break detection.
 	// linePos = a*f - b*e is the perpendicular distance from the text
@@ -28,6 +81,8 @@ func ExtractTextItems(page PageData) []string {
 	var curDet float64     // determinant of text matrix 2x2 part
 	hasPos := false        // whether we've established a line position
 
+	lineBreak := func() { items = append(items, TextItem{}) }
+
 	for i := 0; i < len(tokens); i++ {
 		t := tokens[i]
 		switch t.kind {
@@ -35,29 +90,18 @@ func ExtractTextItems(page PageData) []string {
 			switch t.value {
 			case "Tj":
 				// Single string show: the operand is the string on the stack.
+				// It lays out like a one-string TJ array, except that a
+				// string kept whole keeps its bytes as they are.
 				if len(stack) > 0 {
 					s := stack[len(stack)-1]
-					if s.kind == tokString {
-						tcThousandths := tc * 1000
-						if math.Abs(tcThousandths) > kerningThreshold {
-							// Large Tc: each character is visually in a
-							// different column, so emit them separately.
-							for _, ch := range s.value {
-								items = append(items, string(ch))
-							}
-						} else {
-							items = append(items, s.value)
-						}
-					} else if s.kind == tokHexString {
-						decoded := decodeHexToken(s.value, curFont, page.FontCMaps)
-						tcThousandths := tc * 1000
-						if math.Abs(tcThousandths) > kerningThreshold {
-							for _, ch := range decoded {
-								items = append(items, string(ch))
-							}
-						} else if decoded != "" {
-							items = append(items, decoded)
+					if s.kind == tokString && s.value == "" {
+						lineBreak()
+					} else if s.kind == tokString || s.kind == tokHexString {
+						shown := showText([]token{s}, st, page)
+						if s.kind == tokString && len(shown) == 1 {
+							shown[0].Text = s.value
 						}
+						items = append(items, shown...)
 					}
 				}
 				stack = stack[:0]
@@ -67,7 +111,7 @@ func ExtractTextItems(page PageData) []string {
 				if len(stack) > 0 {
 					a := stack[len(stack)-1]
 					if a.kind == tokArray {
-						items = append(items, processTJArray(a.children, tc*1000, curFont, page.FontCMaps)...)
+						items = append(items, showText(a.children, st, page)...)
 					}
 				}
 				stack = stack[:0]
@@ -79,12 +123,16 @@ func ExtractTextItems(page PageData) []string {
 					tyStr := stack[len(stack)-1].value
 					ty, err := strconv.ParseFloat(tyStr, 64)
 					if err == nil && ty != 0 {
-						items = append(items, "")
+						lineBreak()
 					}
 					// Update current line position: linePos += ty * det
 					if err == nil && hasPos {
 						curLinePos += ty * curDet
 					}
+					tx, errX := strconv.ParseFloat(stack[len(stack)-2].value, 64)
+					if err == nil && errX == nil {
+						st.moveLine(tx, ty)
+					}
 				}
 				stack = stack[:0]
 
@@ -112,28 +160,43 @@ func ExtractTextItems(page PageData) []string {
 						if scale > 0 && diff/scale <= 5.0 {
 							// Same line — no break.
 						} else {
-							items = append(items, "")
+							lineBreak()
 							inserted = true
 						}
 					} else {
-						items = append(items, "")
+						lineBreak()
 						inserted = true
 					}
 					curDet = a*d - b*c
 					curLinePos = linePos
 					hasPos = true
+					st.tm = [6]float64{a, b, c, d, e, f}
+					st.tlm = st.tm
 				}
 				if !inserted && !hasPos {
-					items = append(items, "")
+					lineBreak()
 				}
 				stack = stack[:0]
 
-			case "Tc":
-				// Character spacing operator: one numeric operand.
+			case "BT":
+				st.tm = identityMatrix
+				st.tlm = identityMatrix
+				stack = stack[:0]
+
+			case "Tc", "Tw", "Tz":
+				// Character spacing, word spacing and horizontal scaling:
+				// one numeric operand.
 				if len(stack) > 0 {
 					val, err := strconv.ParseFloat(stack[len(stack)-1].value, 64)
 					if err == nil {
-						tc = val
+						switch t.value {
+						case "Tc":
+							st.tc = val
+						case "Tw":
+							st.tw = val
+						case "Tz":
+							st.th = val / 100
+						}
 					}
 				}
 				stack = stack[:0]
@@ -143,7 +206,10 @@ func ExtractTextItems(page PageData) []string {
 				if len(stack) >= 2 {
 					nameToken := stack[len(stack)-2]
 					if nameToken.kind == tokName {
-						curFont = nameToken.value
+						st.font = nameToken.value
+					}
+					if size, err := strconv.ParseFloat(stack[len(stack)-1].value, 64); err == nil {
+						st.size = size
 					}
 				}
 				stack = stack[:0]
@@ -158,23 +224,116 @@ func ExtractTextItems(page PageData) []string {
 		}
 	}
 
-	return items
+	if cerr != nil {
+		return items, cerr
+	}
+	return items, nil
+}
+
+var identityMatrix = [6]float64{1, 0, 0, 1, 0, 0}
+
+// textState is the part of the PDF text state that places glyphs: the text
+// and line matrices, font, size and spacing. The current page transformation
+// is not tracked; positions are only compared within a page.
+type textState struct {
+	tm, tlm [6]float64 // text matrix, text line matrix: a b c d e f
+	font    string
+	size    float64 // Tf font size
+	tc, tw  float64 // character and word spacing
+	th      float64 // horizontal scaling, 1 = 100%
 }
 
-// decodeHexToken decodes a hex string token using the CMap for the given font.
-func decodeHexToken(hexStr, fontName string, fontCMaps map[string]CMap) string {
-	if fontCMaps == nil {
-		return ""
+func newTextState() *textState {
+	return &textState{tm: identityMatrix, tlm: identityMatrix, th: 1}
+}
+
+// moveLine starts a new line offset by (tx, ty) from the start of the
+// current one, as Td does.
+func (s *textState) moveLine(tx, ty float64) {
+	m := &s.tlm
+	m[4], m[5] = tx*m[0]+ty*m[2]+m[4], tx*m[1]+ty*m[3]+m[5]
+	s.tm = s.tlm
+}
+
+// pos returns the current point's distance along the baseline direction.
+func (s *textState) pos() float64 {
+	a, b := s.tm[0], s.tm[1]
+	scale := math.Hypot(a, b)
+	if scale == 0 {
+		return 0
+	}
+	return (s.tm[4]*a + s.tm[5]*b) / scale
+}
+
+// advance moves the current point tx unscaled text space units along the
+// baseline.
+func (s *textState) advance(tx float64) {
+	s.tm[4] += tx * s.tm[0]
+	s.tm[5] += tx * s.tm[1]
+}
+
+// glyph is one shown character: its text (empty for a glyph the CMap
+// doesn't map), its width in thousandths of a text space unit, and whether
+// word spacing applies to it.
+type glyph struct {
+	text  string
+	width float64
+	space bool
+}
+
+// glyphs splits a string token into glyphs in the current font. known is
+// false when the font's widths aren't known, in which case every width is
+// zero.
+func (s *textState) glyphs(t token, page PageData) (gs []glyph, known bool) {
+	m, known := page.Fonts[s.font]
+	if t.kind == tokHexString {
+		// Hex strings are two-byte glyph IDs decoded through the CMap,
+		// as in DecodeHexString.
+		cmap := page.FontCMaps[s.font]
+		b, err := hex.DecodeString(strings.Map(func(r rune) rune {
+			if isSpace(byte(r)) {
+				return -1
+			}
+			return r
+		}, t.value))
+		if err != nil {
+			return nil, known
+		}
+		for i := 0; i+1 < len(b); i += 2 {
+			gid := binary.BigEndian.Uint16(b[i : i+2])
+			g := glyph{width: m.width(int(gid))}
+			if r, ok := cmap[gid]; ok {
+				g.text = string(r)
+			}
+			gs = append(gs, g)
+		}
+		return gs, known
 	}
-	cmap, ok := fontCMaps[fontName]
-	if !ok {
-		return ""
+	if m.Composite {
+		known = false
 	}
-	return DecodeHexString(hexStr, cmap)
+	for i, ch := range t.value {
+		g := glyph{text: string(ch)}
+		if known {
+			// Each byte is a character code; an invalid UTF-8 byte is a
+			// rune of its own.
+			n := len(string(ch))
+			if ch == utf8.RuneError {
+				n = 1
+			}
+			for j := i; j < i+n && j < len(t.value); j++ {
+				g.width += m.width(int(t.value[j]))
+			}
+			g.space = t.value[i] == ' '
+		}
+		gs = append(gs, g)
+	}
+	return gs, known
 }
 
-// processTJArray takes the children of a TJ array and returns text items,
-// using the effective gap between characters to decide column boundaries.
+// showText lays out the children of a TJ array (or the string of a Tj) and
+// returns text items, using the effective gap between characters to decide
+// column boundaries.
 //
 // The effective gap accounts for both TJ displacement values and Tc (character
 // spacing). When Tc is large, the PDF spreads characters across columns using
@@ -186,27 +345,52 @@ func decodeHexToken(hexStr, fontName string, fontCMaps map[string]CMap) string {
 //   - Within a string: gap = Tc*1000 (no TJ value)
 //   - Across a TJ number: gap = Tc*1000 - TJ_value
 //
-// If abs(gap) > kerningThreshold, a column boundary is inserted.
-func processTJArray(children []token, tcThousandths float64, fontName string, fontCMaps map[string]CMap) []string {
-	// Resolve hex strings into regular strings before processing.
-	resolved := resolveHexChildren(children, fontName, fontCMaps)
+// If abs(gap) > kerningThreshold, a column boundary is inserted. The text
+// state's current point advances past the shown text.
+func showText(children []token, st *textState, page PageData) []TextItem {
+	tcThousandths := st.tc * 1000
 
-	var items []string
+	var items []TextItem
 	var cur strings.Builder
+	var start, end float64
+	placed := true
 	nextGap := 0.0
 	isFirst := true
 
-	for _, c := range resolved {
+	flush := func() {
+		if cur.Len() > 0 {
+			items = append(items, TextItem{Text: cur.String(), Start: start, End: end, Placed: placed})
+			cur.Reset()
+		}
+	}
+
+	for _, c := range children {
 		switch c.kind {
-		case tokString:
-			for _, ch := range c.value {
-				if !isFirst && cur.Len() > 0 && math.Abs(nextGap) > kerningThreshold {
-					items = append(items, cur.String())
-					cur.Reset()
+		case tokString, tokHexString:
+			gs, known := st.glyphs(c, page)
+			for _, g := range gs {
+				if g.text != "" {
+					if !isFirst && cur.Len() > 0 && math.Abs(nextGap) > kerningThreshold {
+						flush()
+					}
+					if cur.Len() == 0 {
+						start = st.pos()
+						placed = true
+					}
+					cur.WriteString(g.text)
+					placed = placed && known
+					isFirst = false
+					nextGap = tcThousandths // default for next char (intra-string)
 				}
-				cur.WriteRune(ch)
-				isFirst = false
-				nextGap = tcThousandths // default for next char (intra-string)
+				st.advance(g.width / 1000 * st.size * st.th)
+				if g.text != "" {
+					end = st.pos()
+				}
+				spacing := st.tc
+				if g.space {
+					spacing += st.tw
+				}
+				st.advance(spacing * st.th)
 			}
 		case tokNumber:
 			val, err := strconv.ParseFloat(c.value, 64)
@@ -217,41 +401,14 @@ func processTJArray(children []token, tcThousandths float64, fontName string, fo
 			// is subtracted from the text position, so it reduces the
 			// effective gap when positive and increases it when negative.
 			nextGap -= val
+			st.advance(-val / 1000 * st.size * st.th)
 		}
 	}
-
-	if cur.Len() > 0 {
-		items = append(items, cur.String())
-	}
+	flush()
 
 	return items
 }
 
-// resolveHexChildren converts tokHexString children to tokString using CMaps.
-func resolveHexChildren(children []token, fontName string, fontCMaps map[string]CMap) []token {
-	hasHex := false
-	for _, c := range children {
-		if c.kind == tokHexString {
-			hasHex = true
-			break
-		}
-	}
-	if !hasHex {
-		return children
-	}
-
-	resolved := make([]token, len(children))
-	for i, c := range children {
-		if c.kind == tokHexString {
-			decoded := decodeHexToken(c.value, fontName, fontCMaps)
-			resolved[i] = token{kind: tokString, value: decoded}
-		} else {
-			resolved[i] = c
-		}
-	}
-	return resolved
-}
-
 // Token types for the PDF content stream tokenizer.
 type tokenKind int
 
@@ -270,9 +427,32 @@ type token struct {
 	children []token // only for tokArray
 }
 
-// tokenize performs a simple tokenization of a PDF content stream.
-func tokenize(s string) []token {
+// maxNesting bounds how deeply arrays and dictionaries may nest in a
+// content stream. Real reports never nest at all; anything deeper is
+// corrupt or hostile input.
+const maxNesting = 32
+
+// isSpace reports whether ch is PDF whitespace.
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n' || ch == '\f' || ch == 0
+}
+
+// isHexDigit reports whether ch may appear in a hex string.
+func isHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F') || isSpace(ch)
+}
+
+// tokenize performs a simple tokenization of a PDF content stream. It never
+// fails: malformed input yields best-effort tokens, and the first problem
+// found is returned as a *ContentError (nil for a well-formed stream).
+func tokenize(s string) ([]token, *ContentError) {
 	var tokens []token
+	var cerr *ContentError
+	fail := func(offset int, reason string) {
+		if cerr == nil {
+			cerr = &ContentError{Offset: offset, Reason: reason}
+		}
+	}
 	i := 0
 	n := len(s)
 
@@ -280,7 +460,7 @@ func tokenize(s string) []token {
 		ch := s[i]
 
 		// Skip whitespace.
-		if ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n' {
+		if isSpace(ch) {
 			i++
 			continue
 		}
@@ -295,7 +475,10 @@ func tokenize(s string) []token {
 
 		// String literal (parenthesized).
 		if ch == '(' {
-			str, end := readString(s, i)
+			str, end, ok := readString(s, i)
+			if !ok {
+				fail(i, "unterminated string")
+			}
 			tokens = append(tokens, token{kind: tokString, value: str})
 			i = end
 			continue
@@ -303,7 +486,10 @@ func tokenize(s string) []token {
 
 		// Array.
 		if ch == '[' {
-			arr, end := readArray(s, i)
+			arr, end, err := readArray(s, i)
+			if err != nil {
+				fail(err.Offset, err.Reason)
+			}
 			tokens = append(tokens, arr)
 			i = end
 			continue
@@ -326,7 +512,7 @@ func tokenize(s string) []token {
 		if ch == '/' {
 			i++
 			start := i
-			for i < n && s[i] != ' ' && s[i] != '\t' && s[i] != '\r' && s[i] != '\n' &&
+			for i < n && !isSpace(s[i]) &&
 				s[i] != '/' && s[i] != '(' && s[i] != '[' && s[i] != '<' {
 				i++
 			}
@@ -338,12 +524,17 @@ func tokenize(s string) []token {
 		if ch == '<' {
 			if i+1 < n && s[i+1] == '<' {
 				// Dictionary marker << — skip to >>
+				start := i
 				i += 2
 				depth := 1
 				for i < n && depth > 0 {
 					if i+1 < n && s[i] == '<' && s[i+1] == '<' {
 						depth++
 						i += 2
+						if depth > maxNesting {
+							fail(start, "dictionaries nested too deeply")
+							depth = 0 // give up on the dictionary here
+						}
 					} else if i+1 < n && s[i] == '>' && s[i+1] == '>' {
 						depth--
 						i += 2
@@ -351,19 +542,17 @@ func tokenize(s string) []token {
 						i++
 					}
 				}
+				if depth > 0 {
+					fail(start, "unterminated dictionary")
+				}
 				continue
 			}
-			// Single hex string <...>
-			i++ // skip '<'
-			start := i
-			for i < n && s[i] != '>' {
-				i++
-			}
-			hexContent := s[start:i]
-			if i < n {
-				i++ // skip '>'
+			hexContent, end, ok := readHexString(s, i)
+			if !ok {
+				fail(i, "unterminated hex string")
 			}
 			tokens = append(tokens, token{kind: tokHexString, value: hexContent})
+			i = end
 			continue
 		}
 
@@ -375,7 +564,7 @@ func tokenize(s string) []token {
 
 		// Keyword / operator.
 		start := i
-		for i < n && s[i] != ' ' && s[i] != '\t' && s[i] != '\r' && s[i] != '\n' &&
+		for i < n && !isSpace(s[i]) &&
 			s[i] != '(' && s[i] != '[' && s[i] != '/' && s[i] != '<' {
 			i++
 		}
@@ -385,12 +574,27 @@ func tokenize(s string) []token {
 		}
 	}
 
-	return tokens
+	return tokens, cerr
 }
 
 // readString reads a parenthesized string starting at s[pos]=='(' and returns
 // the string content and the index after the closing ')'.
-func readString(s string, pos int) (string, int) {
+//
+// A string whose parentheses never balance is unterminated (ok is false). It
+// is then cut at the first unescaped ')' regardless of nesting, or at the end
+// of the line if there is none, so one stray '(' can't swallow the rest of
+// the page.
+func readString(s string, pos int) (str string, end int, ok bool) {
+	if str, end, ok := scanString(s, pos, true); ok {
+		return str, end, true
+	}
+	str, end, _ = scanString(s, pos, false)
+	return str, end, false
+}
+
+// scanString does the work of readString. With nested set, parentheses
+// nest; without it, the first unescaped ')' or line break ends the string.
+func scanString(s string, pos int, nested bool) (string, int, bool) {
 	var buf strings.Builder
 	i := pos + 1 // skip opening '('
 	depth := 1
@@ -424,7 +628,7 @@ func readString(s string, pos int) (string, int) {
 					buf.WriteByte(next)
 				}
 			}
-		} else if ch == '(' {
+		} else if ch == '(' && nested {
 			depth++
 			buf.WriteByte(ch)
 		} else if ch == ')' {
@@ -432,37 +636,75 @@ func readString(s string, pos int) (string, int) {
 			if depth > 0 {
 				buf.WriteByte(ch)
 			}
+		} else if !nested && (ch == '\n' || ch == '\r') {
+			return buf.String(), i, false
 		} else {
 			buf.WriteByte(ch)
 		}
 		i++
 	}
 
-	return buf.String(), i
+	return buf.String(), i, depth == 0
+}
+
+// readHexString reads a <...> hex string starting at s[pos]=='<' and returns
+// its content and the index after the closing '>'. A string that reaches a
+// character that can't be part of one before its '>' is unterminated (ok is
+// false) and ends there.
+func readHexString(s string, pos int) (string, int, bool) {
+	i := pos + 1 // skip '<'
+	start := i
+	for i < len(s) && s[i] != '>' {
+		if !isHexDigit(s[i]) {
+			return s[start:i], i, false
+		}
+		i++
+	}
+	if i == len(s) {
+		return s[start:i], i, false
+	}
+	return s[start:i], i + 1, true
 }
 
 // readArray reads a [...] array starting at s[pos]=='[' and returns a tokArray
-// token with children, plus the index after the closing ']'.
-func readArray(s string, pos int) (token, int) {
+// token with children, plus the index after the closing ']'. Nested arrays are
+// flattened into their parent.
+func readArray(s string, pos int) (token, int, *ContentError) {
 	var children []token
+	var cerr *ContentError
 	i := pos + 1 // skip '['
 	n := len(s)
+	depth := 1
 
-	for i < n {
+	for i < n && depth > 0 {
 		ch := s[i]
 
-		if ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n' {
+		if isSpace(ch) {
 			i++
 			continue
 		}
 
+		if ch == '[' {
+			depth++
+			i++
+			if depth > maxNesting {
+				return token{kind: tokArray, children: children}, i,
+					&ContentError{Offset: pos, Reason: "arrays nested too deeply"}
+			}
+			continue
+		}
+
 		if ch == ']' {
+			depth--
 			i++
-			break
+			continue
 		}
 
 		if ch == '(' {
-			str, end := readString(s, i)
+			str, end, ok := readString(s, i)
+			if !ok && cerr == nil {
+				cerr = &ContentError{Offset: i, Reason: "unterminated string"}
+			}
 			children = append(children, token{kind: tokString, value: str})
 			i = end
 			continue
@@ -470,16 +712,12 @@ func readArray(s string, pos int) (token, int) {
 
 		// Hex string inside array.
 		if ch == '<' {
-			i++ // skip '<'
-			start := i
-			for i < n && s[i] != '>' {
-				i++
-			}
-			hexContent := s[start:i]
-			if i < n {
-				i++ // skip '>'
+			hexContent, end, ok := readHexString(s, i)
+			if !ok && cerr == nil {
+				cerr = &ContentError{Offset: i, Reason: "unterminated hex string"}
 			}
 			children = append(children, token{kind: tokHexString, value: hexContent})
+			i = end
 			continue
 		}
 
@@ -500,5 +738,8 @@ func readArray(s string, pos int) (token, int) {
 		i++
 	}
 
-	return token{kind: tokArray, children: children}, i
+	if depth > 0 && cerr == nil {
+		cerr = &ContentError{Offset: pos, Reason: "unterminated array"}
+	}
+	return token{kind: tokArray, children: children}, i, cerr
 }
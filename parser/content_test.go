@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -331,3 +334,60 @@ ET`)
 		t.Errorf("expected '(moving)', got %q", nonEmpty[0])
 	}
 }
+
+func TestExtractTextItemsChecked_Malformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []string // non-empty items
+		reason string
+	}{
+		{"unterminated string", "BT\n(Filings (x)Tj\n(Parking)Tj\nET", []string{"Filings (x", "Parking"}, "unterminated string"},
+		{"string without close", "BT\n(Filings Tj\n(Parking)Tj\nET", []string{"Parking"}, "unterminated string"},
+		{"unterminated array", "BT\n(Filings)Tj\n[(a)0(b)", []string{"Filings"}, "unterminated array"},
+		{"deep arrays", "BT\n" + strings.Repeat("[", 100) + "(a)" + "]TJ\n(Parking)Tj\nET", []string{"a", "Parking"}, "arrays nested too deeply"},
+		{"unterminated dict", "BT\n(Filings)Tj\n<</MCID 0", []string{"Filings"}, "unterminated dictionary"},
+		{"unterminated hex", "BT\n<0041 Tj\n(Parking)Tj\nET", []string{"Parking"}, "unterminated hex string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := ExtractTextItemsChecked(PageData{Content: []byte(tt.stream)})
+			var cerr *ContentError
+			if !errors.As(err, &cerr) || cerr.Reason != tt.reason {
+				t.Errorf("err = %v, want %s", err, tt.reason)
+			}
+			var got []string
+			for _, s := range items {
+				if s != "" {
+					got = append(got, s)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("items = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ExtractTextItemsChecked(PageData{Content: []byte("BT\n[(a)-5000(b)]TJ\n<<>>\nET")}); err != nil {
+		t.Errorf("well-formed stream: %v", err)
+	}
+}
+
+func FuzzExtractTextItems(f *testing.F) {
+	f.Add([]byte("BT\n[(8)0(8)-4704.6(2)0(3)]TJ\nET"))
+	f.Add([]byte("BT /TT1 9 Tf 1 0 0 1 72 700 Tm (Filings)Tj 0 -12 TD <00410042>Tj ET"))
+	f.Add([]byte("((((\\(\\377)\n[[<<>>]]<0"))
+	f.Add([]byte{0, 0xff, '(', 0x80, '[', '<', '<', 0x1b})
+	cmaps := map[string]CMap{"TT1": {0x41: 'A', 0x42: 'B'}}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ExtractTextItemsChecked(PageData{Content: data, FontCMaps: cmaps})
+	})
+}
+
+func FuzzParseCMap(f *testing.F) {
+	f.Add([]byte("beginbfchar <0003> <0020> endbfchar beginbfrange <0024> <003d> <0041> endbfrange"))
+	f.Add([]byte("beginbfrange <fff0> <ffff> <0041> endbfrange"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ParseCMap(data)
+	})
+}
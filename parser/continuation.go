@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A few report vintages run a municipality's table onto a second page. The
+// first page parses up to where it was cut off; the second repeats the
+// report header (title and date range, sometimes the column headings) with
+// no county or municipality, then carries on with the remaining rows and
+// sections. IsFragment recognizes the first page, IsContinuation the
+// second, and StitchPages joins them so ParsePage sees one whole table.
+
+// IsFragment reports whether items, the text of a data page, stop before
+// the table's last section, i.e. the rest may be on the next page.
+func IsFragment(items []TextItem) bool {
+	if !ContainsFilings(Texts(items)) {
+		return false
+	}
+	last := knownSections[len(knownSections)-1]
+	for _, line := range groupIntoLines(Texts(items)) {
+		if matchSectionName(line) == last {
+			return false
+		}
+	}
+	return true
+}
+
+// IsContinuation reports whether items, the text of a page, continue a
+// table begun on the previous page: the page has no Filings section of its
+// own, but after any header lines it has section headings or data rows.
+func IsContinuation(items []TextItem) bool {
+	texts := Texts(items)
+	if ContainsFilings(texts) {
+		return false
+	}
+	return tableStart(groupIntoLines(texts)) >= 0
+}
+
+// StitchPages returns the text of first, a fragment, with the table lines
+// of cont, its continuation, appended in place of cont's header. Lines
+// after first's last table line, such as a page footer, are dropped so the
+// two parts meet. Items keep their places, so the continued rows are
+// placed under the first page's column headers.
+func StitchPages(first, cont []TextItem) []TextItem {
+	lines := groupItemLines(first)
+	end := len(lines)
+	for end > 0 && !isTableLine(Texts(lines[end-1])) {
+		end--
+	}
+	if end == 0 {
+		end = len(lines)
+	}
+	contLines := groupItemLines(cont)
+	start := tableStart(groupIntoLines(Texts(cont)))
+	if start < 0 {
+		start = len(contLines)
+	}
+
+	var out []TextItem
+	for _, l := range append(lines[:end:end], contLines[start:]...) {
+		if len(out) > 0 {
+			out = append(out, TextItem{})
+		}
+		out = append(out, l...)
+	}
+	return out
+}
+
+// tableStart returns the index of the first section heading or data row in
+// lines, or -1 if there is none.
+func tableStart(lines [][]string) int {
+	for i, l := range lines {
+		if isTableLine(l) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isTableLine(line []string) bool {
+	return matchSectionName(line) != "" || isDataRow(line)
+}
+
+// isDataRow reports whether line looks like a table row: a label followed
+// by values, at least half of them numbers, percentages or the "- -"
+// no-data marker. Short lines such as "Page 2 of 3" don't qualify.
+func isDataRow(line []string) bool {
+	if len(line) < 6 {
+		return false
+	}
+	values := 0
+	for _, v := range line[1:] {
+		if looksLikeValue(v) {
+			values++
+		}
+	}
+	return values*2 >= len(line)-1
+}
+
+func looksLikeValue(s string) bool {
+	s = strings.TrimSuffix(strings.ReplaceAll(s, ",", ""), "%")
+	switch s {
+	case "-", "--", "- -":
+		return true
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
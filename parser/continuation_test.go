@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// joinLines is the inverse of groupItemLines.
+func joinLines(lines [][]TextItem) []TextItem {
+	var items []TextItem
+	for _, l := range lines {
+		items = append(append(items, l...), TextItem{})
+	}
+	return items
+}
+
+func TestStitchPages(t *testing.T) {
+	pages, err := ExtractContentStreams("testdata/page.pdf")
+	if err != nil {
+		t.Fatalf("ExtractContentStreams: %v", err)
+	}
+	items, err := ExtractPlacedItems(pages[0])
+	if err != nil {
+		t.Fatalf("ExtractPlacedItems: %v", err)
+	}
+	whole, err := ParsePageItems(items, 0)
+	if err != nil {
+		t.Fatalf("ParsePageItems: %v", err)
+	}
+	lines := groupItemLines(items)
+	first := tableStart(groupIntoLines(Texts(items))) // first section heading; before it, the header
+
+	footer := PlainItems([]string{"Run", "Date:", "01/15/2025"})
+	for _, cut := range []struct {
+		name string
+		at   func(i int, l []string) bool
+	}{
+		{"at a section", func(i int, l []string) bool { return matchSectionName(l) == "Backlog" }},
+		{"mid-section", func(i int, l []string) bool { return i > 0 && matchSectionName(Texts(lines[i-1])) == "Backlog" }},
+	} {
+		at := -1
+		for i, l := range lines {
+			if cut.at(i, Texts(l)) {
+				at = i
+				break
+			}
+		}
+		if at < 0 {
+			t.Fatalf("%s: no cut point", cut.name)
+		}
+
+		// The first page ends early, with a footer; the second repeats the
+		// title, date range and column headings, but not the municipality.
+		page1 := joinLines(append(append([][]TextItem(nil), lines[:at]...), footer))
+		page2 := joinLines(append(append(append([][]TextItem(nil), lines[:2]...), lines[4:first]...), lines[at:]...))
+
+		if _, err := ParsePageItems(page1, 0); err == nil || !IsFragment(page1) {
+			t.Errorf("%s: first page parsed (err %v) or isn't a fragment", cut.name, err)
+		}
+		if !IsContinuation(page2) {
+			t.Errorf("%s: second page isn't a continuation", cut.name)
+		}
+		got, err := ParsePageItems(StitchPages(page1, page2), 0)
+		if err != nil {
+			t.Fatalf("%s: stitched: %v", cut.name, err)
+		}
+		got.Warnings, whole.Warnings = nil, nil
+		if !reflect.DeepEqual(got, whole) {
+			t.Errorf("%s: stitched page differs from the whole page", cut.name)
+		}
+	}
+
+	if IsFragment(items) {
+		t.Error("a whole page is a fragment")
+	}
+	if IsContinuation(items) {
+		t.Error("a whole page is a continuation")
+	}
+	if IsContinuation(PlainItems([]string{"NEW JERSEY JUDICIARY", "", "Page", "2", "of", "3", ""})) {
+		t.Error("a cover page is a continuation")
+	}
+}
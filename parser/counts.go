@@ -0,0 +1,56 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+)
+
+// CountChange records the number of municipalities listed for some counties
+// in every report from From (YYYY-MM) onward, until a later change.
+type CountChange struct {
+	From   string         `json:"from"`
+	Counts map[string]int `json:"counts"`
+}
+
+//go:embed counts.json
+var countsJSON []byte
+
+// CountChanges is the embedded table of per-county municipality counts,
+// sorted by date. The first entry lists every county; later entries list
+// only the counties whose count changed.
+var CountChanges = func() []CountChange {
+	changes, err := DecodeCountChanges(countsJSON)
+	if err != nil {
+		panic("parser: invalid counts.json: " + err.Error())
+	}
+	return changes
+}()
+
+// DecodeCountChanges reads a count table in the counts.json layout.
+func DecodeCountChanges(data []byte) ([]CountChange, error) {
+	var changes []CountChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].From < changes[j].From })
+	return changes, nil
+}
+
+// ExpectedCounts returns how many municipalities each county should have in
+// the report for period (YYYY-MM), or nil if period predates the table.
+func ExpectedCounts(changes []CountChange, period string) map[string]int {
+	var counts map[string]int
+	for _, c := range changes {
+		if c.From > period {
+			break
+		}
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		for county, n := range c.Counts {
+			counts[county] = n
+		}
+	}
+	return counts
+}
@@ -0,0 +1,36 @@
+package parser
+
+import "strings"
+
+// Counties lists New Jersey's 21 counties in the canonical uppercase form used
+// throughout the parsed output.
+var Counties = []string{
+	"ATLANTIC", "BERGEN", "BURLINGTON", "CAMDEN", "CAPE MAY", "CUMBERLAND",
+	"ESSEX", "GLOUCESTER", "HUDSON", "HUNTERDON", "MERCER", "MIDDLESEX",
+	"MONMOUTH", "MORRIS", "OCEAN", "PASSAIC", "SALEM", "SOMERSET", "SUSSEX",
+	"UNION", "WARREN",
+}
+
+// countyByCompact maps each canonical county with spaces removed to its
+// canonical name, so kerning-mangled variants like "CAPEMAY" still match.
+var countyByCompact = func() map[string]string {
+	m := make(map[string]string, len(Counties))
+	for _, c := range Counties {
+		m[strings.ReplaceAll(c, " ", "")] = c
+	}
+	return m
+}()
+
+// NormalizeCounty maps a county name as printed in a report to its canonical
+// form. It ignores case, surrounding and internal whitespace, and a trailing
+// "COUNTY" (e.g. "Atlantic County ", "CAPEMAY"). The second return value is
+// false if the name isn't one of the 21 NJ counties, in which case the input
+// is returned trimmed and uppercased.
+func NormalizeCounty(name string) (string, bool) {
+	upper := strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	upper = strings.TrimSuffix(upper, " COUNTY")
+	if c, ok := countyByCompact[strings.ReplaceAll(upper, " ", "")]; ok {
+		return c, true
+	}
+	return upper, false
+}
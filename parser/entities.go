@@ -0,0 +1,99 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+// Entity is one municipal court in the embedded registry. Its ID stays the
+// same whatever the reports call it: every name it has been printed under
+// (suffix variants like "ABSECON CITY", truncations, and the old name of a
+// rename in the history timeline) is one of its aliases. Courts formed by a
+// merger are new entities; their predecessors keep their own IDs.
+type Entity struct {
+	ID      string   `json:"id"`                // county and name slugs, as "atlantic.absecon"
+	County  string   `json:"county"`            // canonical county
+	Name    string   `json:"name"`              // display label: the name in the newest report
+	Aliases []string `json:"aliases,omitempty"` // other names it has appeared under
+}
+
+//go:embed entities.json
+var entitiesJSON []byte
+
+// Entities is the embedded entity registry, sorted by ID.
+var Entities = func() []Entity {
+	var entities []Entity
+	if err := json.Unmarshal(entitiesJSON, &entities); err != nil {
+		panic("parser: invalid entities.json: " + err.Error())
+	}
+	return entities
+}()
+
+// entityIndex finds registry entries by county and any of their names, and
+// by ID.
+var entityIndex, entityByID = func() (map[[2]string]int, map[string]int) {
+	byName := make(map[[2]string]int)
+	byID := make(map[string]int, len(Entities))
+	for i, e := range Entities {
+		byID[e.ID] = i
+		byName[entityKey(e.County, e.Name)] = i
+		for _, a := range e.Aliases {
+			byName[entityKey(e.County, a)] = i
+		}
+	}
+	return byName, byID
+}()
+
+func entityKey(county, municipality string) [2]string {
+	county, _ = NormalizeCounty(county)
+	return [2]string{county, strings.ToUpper(strings.Join(strings.Fields(municipality), " "))}
+}
+
+// LookupEntity returns the registry entry for a municipality as named in a
+// report. The second return value is false if the name isn't registered.
+func LookupEntity(county, municipality string) (Entity, bool) {
+	i, ok := entityIndex[entityKey(county, municipality)]
+	if !ok {
+		return Entity{}, false
+	}
+	return Entities[i], true
+}
+
+// EntityByID returns the registry entry with the given ID.
+func EntityByID(id string) (Entity, bool) {
+	i, ok := entityByID[id]
+	if !ok {
+		return Entity{}, false
+	}
+	return Entities[i], true
+}
+
+// EntityID returns the stable ID of a municipality as named in a report:
+// its registry ID, or for a court the registry doesn't know yet, one made
+// the same way from the county and name as printed.
+func EntityID(county, municipality string) string {
+	if e, ok := LookupEntity(county, municipality); ok {
+		return e.ID
+	}
+	k := entityKey(county, municipality)
+	return slug(k[0]) + "." + slug(k[1])
+}
+
+// slug lowercases s and joins its runs of letters and digits with hyphens.
+func slug(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
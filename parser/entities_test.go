@@ -0,0 +1,54 @@
+package parser
+
+import "testing"
+
+func TestEntityID(t *testing.T) {
+	tests := []struct {
+		county, muni, want string
+	}{
+		{"ATLANTIC", "ABSECON", "atlantic.absecon"},
+		{"Atlantic", "absecon  city", "atlantic.absecon"},
+		// A rename keeps the ID of the court it renamed.
+		{"OCEAN", "DOVER TWP", "ocean.toms-river"},
+		{"OCEAN", "TOMS RIVER", "ocean.toms-river"},
+		// Merged courts are entities of their own.
+		{"MERCER", "PRINCETON BORO", "mercer.princeton-borough"},
+		{"MERCER", "PRINCETON TWP", "mercer.princeton-township"},
+		// Names the registry doesn't know get an ID made the same way.
+		{"SALEM", "NEW COURT TWP.", "salem.new-court-twp"},
+	}
+	for _, tt := range tests {
+		if got := EntityID(tt.county, tt.muni); got != tt.want {
+			t.Errorf("EntityID(%q, %q) = %q, want %q", tt.county, tt.muni, got, tt.want)
+		}
+	}
+
+	e, ok := LookupEntity("OCEAN", "DOVER TWP")
+	if !ok || e.Name != "TOMS RIVER" {
+		t.Errorf("LookupEntity(DOVER TWP) = %+v, %v", e, ok)
+	}
+	if _, ok := EntityByID("ocean.toms-river"); !ok {
+		t.Error("EntityByID(ocean.toms-river) not found")
+	}
+}
+
+func TestEntitiesUnique(t *testing.T) {
+	ids := make(map[string]bool)
+	names := make(map[[2]string]string)
+	for _, e := range Entities {
+		if e.ID == "" || ids[e.ID] {
+			t.Errorf("missing or duplicate ID %q", e.ID)
+		}
+		ids[e.ID] = true
+		if _, ok := NormalizeCounty(e.County); !ok {
+			t.Errorf("%s: unknown county %q", e.ID, e.County)
+		}
+		for _, name := range append([]string{e.Name}, e.Aliases...) {
+			k := entityKey(e.County, name)
+			if other, ok := names[k]; ok {
+				t.Errorf("%s %q also names %s", e.County, name, other)
+			}
+			names[k] = e.ID
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotDataPage is returned by ParsePage for pages that aren't a
+// municipality's statistics page (cover sheets, notes, summaries). Callers
+// normally skip these.
+var ErrNotDataPage = errors.New("not a municipal court data page")
+
+// ErrUnexpectedEnd is returned when a page's text runs out before every
+// section has been read, usually because the page was truncated or its
+// content stream was only partly decoded.
+var ErrUnexpectedEnd = errors.New("unexpected end of page")
+
+// SectionMismatchError reports a section heading other than the one the
+// fixed page layout expects next.
+type SectionMismatchError struct {
+	Expected string
+	Got      string
+	Page     int // 1-based page number, 0 if unknown
+}
+
+func (e *SectionMismatchError) Error() string {
+	return fmt.Sprintf("expected section %q, got %q", e.Expected, e.Got)
+}
+
+// ShortRowError reports a data row with fewer values than the layout
+// requires. Rows that merely lack trailing columns are padded instead, so
+// this means the row carried no usable values at all.
+type ShortRowError struct {
+	Section string
+	Got     int // items found, including the label
+	Want    int
+	Page    int // 1-based page number, 0 if unknown
+}
+
+func (e *ShortRowError) Error() string {
+	return fmt.Sprintf("section %q: short data row (%d of %d values)", e.Section, e.Got, e.Want)
+}
+
+// ContentError reports a malformed page content stream: an unterminated
+// string, array, or dictionary, or nesting beyond what a real report uses.
+// The text around it is still extracted as a best effort.
+type ContentError struct {
+	Offset int // byte offset into the content stream
+	Reason string
+}
+
+func (e *ContentError) Error() string {
+	return fmt.Sprintf("malformed content stream at byte %d: %s", e.Offset, e.Reason)
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+// HistoryEvent records a municipality rename or merger. Names are as they
+// appear in the reports (uppercase, with the report's own suffixes), so a
+// series under From can be linked to the series under To.
+type HistoryEvent struct {
+	Date   string   `json:"date"` // YYYY-MM the change took effect
+	Kind   string   `json:"kind"` // "rename" or "merger"
+	County string   `json:"county"`
+	From   []string `json:"from"`
+	To     string   `json:"to"`
+	Note   string   `json:"note,omitempty"`
+}
+
+//go:embed history.json
+var historyJSON []byte
+
+// History is the embedded rename/merger timeline, sorted by date.
+var History = func() []HistoryEvent {
+	var events []HistoryEvent
+	if err := json.Unmarshal(historyJSON, &events); err != nil {
+		panic("parser: invalid history.json: " + err.Error())
+	}
+	return events
+}()
+
+// HistoryFor returns the events in which the given municipality is either a
+// predecessor or the successor.
+func HistoryFor(county, municipality string) []HistoryEvent {
+	county = strings.ToUpper(county)
+	municipality = strings.ToUpper(municipality)
+	var events []HistoryEvent
+	for _, e := range History {
+		if e.County != county {
+			continue
+		}
+		if e.To == municipality || containsName(e.From, municipality) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// AnnotateHistory fills in the Predecessors and Successor links on s from
+// the embedded timeline, and its entity ID from the registry.
+func AnnotateHistory(s *MunicipalityStats) {
+	s.ID = EntityID(s.County, s.Municipality)
+	s.Predecessors = nil
+	s.Successor = ""
+	muni := strings.ToUpper(s.Municipality)
+	for _, e := range HistoryFor(s.County, muni) {
+		if e.To == muni {
+			s.Predecessors = append(s.Predecessors, e.From...)
+		} else {
+			s.Successor = e.To
+		}
+	}
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
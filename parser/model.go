@@ -1,5 +1,7 @@
 package parser
 
+import "time"
+
 // MunicipalityStats holds all statistics for a single municipality page.
 type MunicipalityStats struct {
 	County        string             `json:"county"`
@@ -13,6 +15,27 @@ type MunicipalityStats struct {
 	BacklogPer100 SectionWithChange  `json:"backlogPer100MthlyFilings"`
 	BacklogPct    SectionTwoRow      `json:"backlogPercent"`
 	ActivePending SectionWithChange  `json:"activePending"`
+
+	// Links to the entities this one was renamed or merged from, or into,
+	// per the embedded history timeline.
+	Predecessors []string `json:"predecessors,omitempty"`
+	Successor    string   `json:"successor,omitempty"`
+
+	// ID is the stable entity ID (see EntityID), filled in with the history
+	// links. It, not Municipality, identifies a court across periods.
+	ID string `json:"id,omitempty"`
+
+	// Start and End are the first and last days DateRange covers (see
+	// ParsePeriod), zero if it can't be read. They aren't written to JSON;
+	// the parser and ReadOutput fill them in.
+	Start time.Time `json:"-"`
+	End   time.Time `json:"-"`
+
+	// Provenance: where the record came from, and anything the parser had
+	// to work around while extracting it.
+	SourceFile string   `json:"sourceFile,omitempty"`
+	PageNumber int      `json:"pageNumber,omitempty"` // 1-based
+	Warnings   []string `json:"warnings,omitempty"`
 }
 
 // SectionWithChange has three sub-rows: prior period, current period, and % change.
@@ -41,4 +64,13 @@ type RowData struct {
 	Parking       string `json:"parking"`
 	TrafficTotal  string `json:"trafficTotal"`
 	GrandTotal    string `json:"grandTotal"`
+
+	// Start and End are the first and last days Label covers, like those
+	// of MunicipalityStats; zero for the % Change rows.
+	Start time.Time `json:"-"`
+	End   time.Time `json:"-"`
+
+	// Recovered notes values the parser had to reconstruct rather than
+	// read directly; see Recovery.
+	Recovered RowRecovery `json:"recovered,omitzero"`
 }
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON output file layout written by
+// this package. Version 1 was a bare array of records; version 2 wraps the
+// records with a provenance header.
+const SchemaVersion = 2
+
+// Provenance identifies the source of a parsed output file.
+type Provenance struct {
+	SourceFile string    `json:"sourceFile"`
+	SHA256     string    `json:"sha256"`
+	ParsedAt   time.Time `json:"parsedAt"`
+	Version    string    `json:"municourtVersion"`
+
+	// Generated is when the report itself was produced, YYYY-MM-DD: the
+	// modification date in the PDF's document information, else its
+	// creation date. The page footers carry no run date. A re-issued report
+	// has the same period and a later Generated date.
+	Generated string `json:"generated,omitempty"`
+}
+
+// Output is the top-level JSON document for one parsed PDF.
+type Output struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	Provenance    *Provenance         `json:"provenance,omitempty"`
+	Records       []MunicipalityStats `json:"records"`
+}
+
+// DecodeOutput reads a JSON output file in either the current layout or the
+// original bare-array layout (reported as SchemaVersion 1, no provenance).
+func DecodeOutput(data []byte) (Output, error) {
+	return ReadOutput(bytes.NewReader(data))
+}
+
+// ReadOutput is DecodeOutput for a stream. Records are decoded one at a
+// time instead of from a buffered copy of the whole file, so reading a large
+// output file needs little more memory than the records themselves.
+func ReadOutput(r io.Reader) (Output, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return Output{}, err
+	}
+	switch tok {
+	case json.Delim('['):
+		records, err := decodeRecords(dec)
+		if err != nil {
+			return Output{}, err
+		}
+		return Output{SchemaVersion: 1, Records: records}, expectEOF(dec)
+	case json.Delim('{'):
+	default:
+		return Output{}, fmt.Errorf("not a municourt output file (unexpected %v)", tok)
+	}
+
+	var out Output
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Output{}, err
+		}
+		switch key, _ := tok.(string); key {
+		case "schemaVersion":
+			err = dec.Decode(&out.SchemaVersion)
+		case "provenance":
+			err = dec.Decode(&out.Provenance)
+		case "records":
+			if tok, err = dec.Token(); err != nil {
+				return Output{}, err
+			}
+			switch tok {
+			case json.Delim('['):
+				out.Records, err = decodeRecords(dec)
+			case nil:
+			default:
+				err = fmt.Errorf("records: expected an array, got %v", tok)
+			}
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return Output{}, err
+		}
+	}
+	if _, err := dec.Token(); err != nil { // closing brace
+		return Output{}, err
+	}
+	if err := expectEOF(dec); err != nil {
+		return Output{}, err
+	}
+
+	if out.SchemaVersion == 0 {
+		return Output{}, fmt.Errorf("not a municourt output file (no schemaVersion)")
+	}
+	if out.SchemaVersion > SchemaVersion {
+		return Output{}, fmt.Errorf("schema version %d is newer than supported version %d", out.SchemaVersion, SchemaVersion)
+	}
+	return out, nil
+}
+
+// decodeRecords decodes array elements up to and including the closing
+// bracket; the opening bracket has already been read. The records' periods,
+// which aren't stored, are parsed from their labels.
+func decodeRecords(dec *json.Decoder) ([]MunicipalityStats, error) {
+	var records []MunicipalityStats
+	for dec.More() {
+		var s MunicipalityStats
+		if err := dec.Decode(&s); err != nil {
+			return nil, err
+		}
+		s.ParsePeriods()
+		records = append(records, s)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// expectEOF reports an error if anything follows the top-level value.
+func expectEOF(dec *json.Decoder) error {
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after top-level value")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeOutput(t *testing.T) {
+	legacy, err := DecodeOutput([]byte(`[{"county": "ATLANTIC", "municipality": "ABSECON"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.SchemaVersion != 1 || legacy.Provenance != nil || len(legacy.Records) != 1 {
+		t.Errorf("legacy = %+v", legacy)
+	}
+
+	current, err := DecodeOutput([]byte(`{
+		"schemaVersion": 2,
+		"provenance": {"sourceFile": "munm2406.pdf", "sha256": "abc"},
+		"records": [{"county": "ATLANTIC", "municipality": "ABSECON"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Provenance == nil || current.Provenance.SHA256 != "abc" || current.Records[0].Municipality != "ABSECON" {
+		t.Errorf("current = %+v", current)
+	}
+
+	for _, bad := range []string{
+		`{"sourceFile": "munm2406.pdf"}`,       // a provenance sidecar
+		`{"schemaVersion": 99, "records": []}`, // from a newer municourt
+	} {
+		if _, err := DecodeOutput([]byte(bad)); err == nil {
+			t.Errorf("DecodeOutput(%s): expected error", bad)
+		}
+	}
+}
+
+func TestReadOutput_KeyOrder(t *testing.T) {
+	out, err := ReadOutput(strings.NewReader(`{
+		"records": [{"county": "ATLANTIC"}, {"county": "BERGEN"}],
+		"extra": {"ignored": [1, 2]},
+		"schemaVersion": 2
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.SchemaVersion != 2 || len(out.Records) != 2 || out.Records[1].County != "BERGEN" {
+		t.Errorf("out = %+v", out)
+	}
+
+	for _, bad := range []string{
+		`{"schemaVersion": 2, "records": {}}`,
+		`{"schemaVersion": 2, "records": []} []`,
+		`"records"`,
+	} {
+		if _, err := ReadOutput(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadOutput(%s): expected error", bad)
+		}
+	}
+}
@@ -51,16 +51,32 @@ var knownSections = []string{
 // markers. Adjacent empties are collapsed and leading/trailing empties trimmed.
 func groupIntoLines(items []string) [][]string {
 	var lines [][]string
-	var current []string
+	for _, l := range groupItemLines(PlainItems(items)) {
+		lines = append(lines, Texts(l))
+	}
+	return lines
+}
+
+// Lines groups a page's text items into the lines the parser reads, for
+// showing a page that failed to parse.
+func Lines(items []TextItem) [][]string {
+	return groupIntoLines(Texts(items))
+}
+
+// groupItemLines is groupIntoLines for placed items. Each item's text is
+// trimmed of surrounding space.
+func groupItemLines(items []TextItem) [][]TextItem {
+	var lines [][]TextItem
+	var current []TextItem
 	for _, item := range items {
-		s := strings.TrimSpace(item)
-		if s == "" {
+		item.Text = strings.TrimSpace(item.Text)
+		if item.Text == "" {
 			if len(current) > 0 {
 				lines = append(lines, current)
 				current = nil
 			}
 		} else {
-			current = append(current, s)
+			current = append(current, item)
 		}
 	}
 	if len(current) > 0 {
@@ -110,7 +126,10 @@ func matchSectionName(line []string) string {
 // Merges are prioritized: pairs where the right part has a leading zero (e.g.,
 // "000", "040") are merged first since those can't be standalone values. Then
 // pairs with a 1-digit left, then 2-digit left.
-func mergeCommaSplitNumbers(line []string, expectedLen int) []string {
+//
+// merged reports, for each item of the result, whether it was merged.
+func mergeCommaSplitNumbers(line []string, expectedLen int) (result []string, merged []bool) {
+	merged = make([]bool, len(line))
 	for len(line) > expectedLen {
 		bestIdx := -1
 		bestPriority := -1
@@ -145,14 +164,15 @@ func mergeCommaSplitNumbers(line []string, expectedLen int) []string {
 		}
 
 		// Merge the pair at bestIdx.
-		merged := line[bestIdx] + "," + line[bestIdx+1]
 		newLine := make([]string, 0, len(line)-1)
 		newLine = append(newLine, line[:bestIdx]...)
-		newLine = append(newLine, merged)
+		newLine = append(newLine, line[bestIdx]+","+line[bestIdx+1])
 		newLine = append(newLine, line[bestIdx+2:]...)
 		line = newLine
+		merged[bestIdx] = true
+		merged = append(merged[:bestIdx+1], merged[bestIdx+2:]...)
 	}
-	return line
+	return line, merged
 }
 
 // looksLikeCommaSplit returns true if left+right look like two halves of a
@@ -206,26 +226,38 @@ func isThreeDigits(s string) bool {
 }
 
 // ParsePage takes the text items extracted from a single page's content stream
-// and maps them to a MunicipalityStats struct.
+// and maps them to a MunicipalityStats struct. Errors wrap ErrNotDataPage or
+// ErrUnexpectedEnd, or are a *SectionMismatchError or *ShortRowError.
 func ParsePage(items []string) (MunicipalityStats, error) {
-	lines := groupIntoLines(items)
+	return ParsePageAt(items, 0)
+}
+
+// ParsePageAt is ParsePage for the given 1-based page number, which is
+// recorded as the result's PageNumber and in any *SectionMismatchError or
+// *ShortRowError returned.
+func ParsePageAt(items []string, page int) (MunicipalityStats, error) {
+	return ParsePageItems(PlainItems(items), page)
+}
+
+// ParsePageItems is ParsePageAt for placed items, as returned by
+// ExtractPlacedItems. Where the column header labels and a row's values
+// were placed, each value goes to the column under whose header it sits;
+// otherwise a row's values are counted off into the ten fields in order.
+func ParsePageItems(items []TextItem, page int) (MunicipalityStats, error) {
+	lines := groupItemLines(items)
 	pos := 0
-	var stats MunicipalityStats
+	stats := MunicipalityStats{PageNumber: page}
 
 	nextLine := func() ([]string, error) {
-		if pos >= len(lines) {
-			return nil, fmt.Errorf("unexpected end of lines at line %d", pos)
-		}
-		l := lines[pos]
-		pos++
-		return l, nil
+		l, err := nextItemLine(lines, &pos)
+		return Texts(l), err
 	}
 
 	peekLine := func() []string {
 		if pos >= len(lines) {
 			return nil
 		}
-		return lines[pos]
+		return Texts(lines[pos])
 	}
 
 	// Header: 4 single-item lines.
@@ -235,7 +267,7 @@ func ParsePage(items []string) (MunicipalityStats, error) {
 	}
 	title := joinClippedText(titleLine)
 	if !strings.Contains(title, "MUNICIPAL COURT") {
-		return stats, fmt.Errorf("expected title containing 'MUNICIPAL COURT', got %q", title)
+		return stats, fmt.Errorf("%w: expected title containing 'MUNICIPAL COURT', got %q", ErrNotDataPage, title)
 	}
 
 	dateLine, err := nextLine()
@@ -256,44 +288,64 @@ func ParsePage(items []string) (MunicipalityStats, error) {
 	}
 	stats.Municipality = joinClippedText(muniLine)
 
-	// Skip column header lines until we find a section name line.
+	// Skip column header lines until we find a section name line, keeping
+	// them to calibrate the columns.
+	headerStart := pos
 	for pos < len(lines) {
 		if name := matchSectionName(peekLine()); name != "" {
 			break
 		}
 		pos++
 	}
+	layout, calibrated := calibrateColumns(lines[headerStart:pos])
 
 	// readRow reads a data row line: label + 9 values.
 	readRow := func(sectionName string) (RowData, error) {
-		line, err := nextLine()
+		items, err := nextItemLine(lines, &pos)
 		if err != nil {
 			return RowData{}, fmt.Errorf("section %q: reading data row: %w", sectionName, err)
 		}
-		line = mergeCommaSplitNumbers(line, 10)
+		if calibrated && len(items) > 1 {
+			if cols, recs, warnings, ok := layout.place(items[1:]); ok {
+				for _, w := range warnings {
+					stats.Warnings = append(stats.Warnings, fmt.Sprintf("%s: row %q has %s", sectionName, items[0].Text, w))
+				}
+				row := rowData(items[0].Text, cols[:])
+				row.Recovered = recovered(recs[:])
+				return row, nil
+			}
+		}
+		line, merged := mergeCommaSplitNumbers(Texts(items), 10)
 		if len(line) < 1 {
-			return RowData{}, fmt.Errorf("section %q: empty data row", sectionName)
+			return RowData{}, &ShortRowError{Section: sectionName, Got: len(line), Want: 10, Page: page}
+		}
+		recs := make([]Recovery, 0, 10)
+		for _, m := range merged[1:] {
+			rec := RecoveryDirect
+			switch {
+			case len(line) != 10:
+				rec = RecoveryMiscounted
+			case m:
+				rec = RecoveryCommaMerge
+			}
+			recs = append(recs, rec)
 		}
 		// Pad short rows (e.g., statewide summary pages with fewer columns).
+		if len(line) < 10 {
+			stats.Warnings = append(stats.Warnings, fmt.Sprintf("%s: row %q padded from %d to 10 values", sectionName, line[0], len(line)))
+		}
 		for len(line) < 10 {
 			line = append(line, "- -")
+			recs = append(recs, RecoveryPadded)
 		}
 		if len(line) > 10 {
 			// Even after merge, too many items. Take first 10 and continue.
+			stats.Warnings = append(stats.Warnings, fmt.Sprintf("%s: row %q truncated from %d to 10 values", sectionName, line[0], len(line)))
 			line = line[:10]
 		}
-		return RowData{
-			Label:         line[0],
-			Indictables:   line[1],
-			DPAndPDP:      line[2],
-			OtherCriminal: line[3],
-			CriminalTotal: line[4],
-			DWI:           line[5],
-			TrafficMoving: line[6],
-			Parking:       line[7],
-			TrafficTotal:  line[8],
-			GrandTotal:    line[9],
-		}, nil
+		row := rowData(line[0], line[1:])
+		row.Recovered = recovered(recs[:9])
+		return row, nil
 	}
 
 	readSectionName := func(expected string) error {
@@ -306,7 +358,7 @@ func ParsePage(items []string) (MunicipalityStats, error) {
 			got = strings.Join(line, " ")
 		}
 		if got != expected {
-			return fmt.Errorf("expected section %q, got %q", expected, got)
+			return &SectionMismatchError{Expected: expected, Got: got, Page: page}
 		}
 		return nil
 	}
@@ -393,5 +445,33 @@ func ParsePage(items []string) (MunicipalityStats, error) {
 		return stats, err
 	}
 
+	stats.ParsePeriods()
+
 	return stats, nil
 }
+
+// nextItemLine returns lines[*pos] and advances *pos.
+func nextItemLine(lines [][]TextItem, pos *int) ([]TextItem, error) {
+	if *pos >= len(lines) {
+		return nil, fmt.Errorf("%w at line %d", ErrUnexpectedEnd, *pos)
+	}
+	l := lines[*pos]
+	*pos++
+	return l, nil
+}
+
+// rowData fills a row from its label and nine values.
+func rowData(label string, values []string) RowData {
+	return RowData{
+		Label:         label,
+		Indictables:   values[0],
+		DPAndPDP:      values[1],
+		OtherCriminal: values[2],
+		CriminalTotal: values[3],
+		DWI:           values[4],
+		TrafficMoving: values[5],
+		Parking:       values[6],
+		TrafficTotal:  values[7],
+		GrandTotal:    values[8],
+	}
+}
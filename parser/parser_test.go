@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -34,6 +36,49 @@ func TestMatchSectionName(t *testing.T) {
 	}
 }
 
+func TestNormalizeCounty(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"ATLANTIC", "ATLANTIC", true},
+		{"CAPEMAY", "CAPE MAY", true},
+		{"  cape   may ", "CAPE MAY", true},
+		{"ATLANTIC COUNTY", "ATLANTIC", true},
+		{"Hudson ", "HUDSON", true},
+		{"STATEWIDE", "STATEWIDE", false},
+		{"ATLANTIS", "ATLANTIS", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeCounty(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NormalizeCounty(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAnnotateHistory(t *testing.T) {
+	merged := MunicipalityStats{County: "MERCER", Municipality: "PRINCETON MUNICIPAL COUR"}
+	AnnotateHistory(&merged)
+	if !reflect.DeepEqual(merged.Predecessors, []string{"PRINCETON BORO", "PRINCETON TWP"}) {
+		t.Errorf("Predecessors = %v", merged.Predecessors)
+	}
+
+	old := MunicipalityStats{County: "MERCER", Municipality: "Princeton Twp"}
+	AnnotateHistory(&old)
+	if old.Successor != "PRINCETON MUNICIPAL COUR" || len(old.Predecessors) != 0 {
+		t.Errorf("Successor = %q, Predecessors = %v", old.Successor, old.Predecessors)
+	}
+
+	// Same name in another county is unrelated.
+	other := MunicipalityStats{County: "ATLANTIC", Municipality: "PRINCETON TWP"}
+	AnnotateHistory(&other)
+	if other.Successor != "" {
+		t.Errorf("unexpected Successor %q", other.Successor)
+	}
+}
+
 func TestMergeCommaSplitNumbers(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -99,7 +144,7 @@ func TestMergeCommaSplitNumbers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := mergeCommaSplitNumbers(tt.line, tt.expected)
+			got, _ := mergeCommaSplitNumbers(tt.line, tt.expected)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("got  %v\nwant %v", got, tt.want)
 			}
@@ -230,9 +275,136 @@ func TestCoverPageSkipped(t *testing.T) {
 	}
 }
 
+func TestForEachPage(t *testing.T) {
+	pages, err := ExtractContentStreams("testdata/page.pdf")
+	if err != nil {
+		t.Fatalf("ExtractContentStreams: %v", err)
+	}
+	var n int
+	err = ForEachPage("testdata/page.pdf", func(page PageData) error {
+		if !bytes.Equal(page.Content, pages[n].Content) {
+			t.Errorf("page %d content differs from ExtractContentStreams", n+1)
+		}
+		if page.Number != n+1 {
+			t.Errorf("page %d has Number %d", n+1, page.Number)
+		}
+		n++
+		return nil
+	})
+	if err != nil || n != len(pages) {
+		t.Fatalf("ForEachPage visited %d pages, err %v; want %d", n, err, len(pages))
+	}
+
+	stop := errors.New("stop")
+	if err := ForEachPage("testdata/page.pdf", func(PageData) error { return stop }); err != stop {
+		t.Errorf("err = %v, want the callback's error", err)
+	}
+}
+
+func TestForEachPage_NumbersSkippedPages(t *testing.T) {
+	// The first page has no content stream and is skipped.
+	var numbers []int
+	err := ForEachPage("testdata/blank-first-page.pdf", func(page PageData) error {
+		numbers = append(numbers, page.Number)
+		return nil
+	})
+	if err != nil || len(numbers) != 1 || numbers[0] != 2 {
+		t.Errorf("visited pages %v, err %v; want [2]", numbers, err)
+	}
+}
+
+func TestParsePageErrors(t *testing.T) {
+	pages, err := ExtractContentStreams("testdata/page.pdf")
+	if err != nil {
+		t.Fatalf("ExtractContentStreams: %v", err)
+	}
+	items := ExtractTextItems(pages[0])
+
+	// Cover pages are a sentinel callers can skip.
+	if _, err := ParsePage([]string{"NEW JERSEY JUDICIARY", ""}); !errors.Is(err, ErrNotDataPage) {
+		t.Errorf("cover: err = %v, want ErrNotDataPage", err)
+	}
+
+	// Truncated page.
+	if _, err := ParsePage(items[:len(items)/2]); !errors.Is(err, ErrUnexpectedEnd) {
+		t.Errorf("truncated: err = %v, want ErrUnexpectedEnd", err)
+	}
+
+	// Renaming the first section heading breaks the expected layout.
+	renamed := append([]string(nil), items...)
+	for i, it := range renamed {
+		if it == "Filings" {
+			renamed[i] = "Dispositions"
+			break
+		}
+	}
+	_, err = ParsePageAt(renamed, 7)
+	var mismatch *SectionMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("renamed: err = %v, want *SectionMismatchError", err)
+	}
+	if mismatch.Expected != "Filings" || mismatch.Page != 7 {
+		t.Errorf("mismatch = %+v", mismatch)
+	}
+}
+
+func TestParsePageAt_Provenance(t *testing.T) {
+	pages, err := ExtractContentStreams("testdata/page.pdf")
+	if err != nil {
+		t.Fatalf("ExtractContentStreams: %v", err)
+	}
+	items := ExtractTextItems(pages[0])
+
+	stats, err := ParsePageAt(items, 12)
+	if err != nil {
+		t.Fatalf("ParsePageAt: %v", err)
+	}
+	if stats.PageNumber != 12 || len(stats.Warnings) != 0 {
+		t.Errorf("PageNumber = %d, Warnings = %v", stats.PageNumber, stats.Warnings)
+	}
+
+	// Drop the last value of the first data row; it is padded with a warning.
+	short := append([]string(nil), items...)
+	for i, it := range short {
+		if it == "Filings" {
+			for j := i + 1; j < len(short); j++ {
+				if short[j] == "" && j > i+1 && short[j-1] != "" {
+					short = append(short[:j-1], short[j:]...)
+					break
+				}
+			}
+			break
+		}
+	}
+	stats, err = ParsePage(short)
+	if err != nil {
+		t.Fatalf("ParsePage: %v", err)
+	}
+	if len(stats.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want one padding warning", stats.Warnings)
+	}
+}
+
 func assertEqual(t *testing.T, field, got, want string) {
 	t.Helper()
 	if got != want {
 		t.Errorf("%s: got %q, want %q", field, got, want)
 	}
 }
+
+func TestExpectedCounts(t *testing.T) {
+	if got := ExpectedCounts(CountChanges, "2004-06"); got != nil {
+		t.Errorf("2004-06 = %v, want nil", got)
+	}
+	got := ExpectedCounts(CountChanges, "2024-06")
+	if len(got) != len(Counties) {
+		t.Fatalf("2024-06 has %d counties", len(got))
+	}
+	total := 0
+	for _, n := range got {
+		total += n
+	}
+	if total != 563 || got["BERGEN"] != 72 || got["CUMBERLAND"] != 14 {
+		t.Errorf("2024-06: total %d, BERGEN %d, CUMBERLAND %d", total, got["BERGEN"], got["CUMBERLAND"])
+	}
+}
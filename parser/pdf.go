@@ -4,16 +4,40 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
 
-// PageData holds the extracted content stream and font CMap data for a single page.
+// PageData holds the extracted content stream and font data for a single page.
 type PageData struct {
+	Number    int // 1-based page number in the document, counting skipped pages
 	Content   []byte
-	FontCMaps map[string]CMap // font name (e.g. "TT1") → CMap
+	FontCMaps map[string]CMap        // font name (e.g. "TT1") → CMap
+	Fonts     map[string]FontMetrics // font name → glyph widths
+
+	// DocDate is the document's modification date, else its creation
+	// date, from the PDF's document information; zero if it has neither.
+	// It is the same for every page.
+	DocDate time.Time
+}
+
+// FontMetrics holds a font's glyph widths in thousandths of text space
+// units, keyed by character code: one byte for simple fonts, two for
+// composite (Type0) fonts.
+type FontMetrics struct {
+	Widths    map[int]float64
+	Default   float64 // width of codes not in Widths
+	Composite bool
+}
+
+func (m FontMetrics) width(code int) float64 {
+	if w, ok := m.Widths[code]; ok {
+		return w
+	}
+	return m.Default
 }
 
 // ContainsFilings checks whether the extracted text items contain "Filings",
@@ -28,32 +52,51 @@ func ContainsFilings(items []string) bool {
 }
 
 // ExtractContentStreams opens a PDF file and returns the decompressed content
-// stream bytes and font CMap data for each page.
+// stream bytes and font CMap data for each page. It holds every page in
+// memory at once; use ForEachPage for large files.
 func ExtractContentStreams(path string) ([]PageData, error) {
+	var result []PageData
+	err := ForEachPage(path, func(page PageData) error {
+		result = append(result, page)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ForEachPage opens a PDF file and calls fn with the decompressed content
+// stream and font CMap data of each page in turn, skipping pages with no
+// content (PageData.Number still counts them). Only the current page's
+// decoded data is held, so memory stays bounded however many pages the
+// file has, provided fn doesn't retain it.
+// An error from fn stops the iteration and is returned.
+func ForEachPage(path string, fn func(PageData) error) error {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("open pdf: %w", err)
+		return fmt.Errorf("open pdf: %w", err)
 	}
 	defer f.Close()
 
 	ctx, err := pdfcpu.Read(f, model.NewDefaultConfiguration())
 	if err != nil {
-		return nil, fmt.Errorf("read pdf: %w", err)
+		return fmt.Errorf("read pdf: %w", err)
 	}
 
 	if err := pdfcpu.OptimizeXRefTable(ctx); err != nil {
-		return nil, fmt.Errorf("optimize xref: %w", err)
+		return fmt.Errorf("optimize xref: %w", err)
 	}
 
 	if err := ctx.EnsurePageCount(); err != nil {
-		return nil, fmt.Errorf("page count: %w", err)
+		return fmt.Errorf("page count: %w", err)
 	}
+	docDate := documentDate(ctx)
 
-	var result []PageData
 	for i := 1; i <= ctx.PageCount; i++ {
 		pageDict, _, _, err := ctx.PageDict(i, false)
 		if err != nil {
-			return nil, fmt.Errorf("page %d dict: %w", i, err)
+			return fmt.Errorf("page %d dict: %w", i, err)
 		}
 
 		obj, found := pageDict.Find("Contents")
@@ -63,49 +106,87 @@ func ExtractContentStreams(path string) ([]PageData, error) {
 
 		streamData, err := resolveContentStream(ctx, obj)
 		if err != nil {
-			return nil, fmt.Errorf("page %d content stream: %w", i, err)
+			return fmt.Errorf("page %d content stream: %w", i, err)
 		}
 
-		fontCMaps := extractFontCMaps(ctx, pageDict)
-
-		result = append(result, PageData{
+		fonts := pageFonts(ctx, pageDict)
+		if err := fn(PageData{
+			Number:    i,
 			Content:   streamData,
-			FontCMaps: fontCMaps,
-		})
+			FontCMaps: extractFontCMaps(ctx, fonts),
+			Fonts:     extractFontMetrics(ctx, fonts),
+			DocDate:   docDate,
+		}); err != nil {
+			return err
+		}
 	}
 
-	return result, nil
+	return nil
 }
 
-// extractFontCMaps extracts ToUnicode CMaps from each font in the page's
-// resource dictionary.
-func extractFontCMaps(ctx *model.Context, pageDict types.Dict) map[string]CMap {
-	cmaps := make(map[string]CMap)
+// documentDate returns the ModDate, else the CreationDate, in the
+// document information dictionary, or the zero time.
+func documentDate(ctx *model.Context) time.Time {
+	if ctx.Info == nil {
+		return time.Time{}
+	}
+	info, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || info == nil {
+		return time.Time{}
+	}
+	for _, key := range []string{"ModDate", "CreationDate"} {
+		obj, err := ctx.Dereference(info[key])
+		if err != nil {
+			continue
+		}
+		var s string
+		switch v := obj.(type) {
+		case types.StringLiteral:
+			s, err = types.StringLiteralToString(v)
+		case types.HexLiteral:
+			s, err = types.HexLiteralToString(v)
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if t, ok := types.DateTime(s, true); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// pageFonts returns the font dictionaries in the page's resource
+// dictionary by resource name.
+func pageFonts(ctx *model.Context, pageDict types.Dict) map[string]types.Dict {
+	fonts := make(map[string]types.Dict)
 
 	resourcesObj, found := pageDict.Find("Resources")
 	if !found {
-		return cmaps
+		return fonts
 	}
 	resourcesObj, err := ctx.Dereference(resourcesObj)
 	if err != nil {
-		return cmaps
+		return fonts
 	}
 	resources, ok := resourcesObj.(types.Dict)
 	if !ok {
-		return cmaps
+		return fonts
 	}
 
 	fontObj, found := resources.Find("Font")
 	if !found {
-		return cmaps
+		return fonts
 	}
 	fontObj, err = ctx.Dereference(fontObj)
 	if err != nil {
-		return cmaps
+		return fonts
 	}
 	fontDict, ok := fontObj.(types.Dict)
 	if !ok {
-		return cmaps
+		return fonts
 	}
 
 	for fontName, fontRef := range fontDict {
@@ -113,16 +194,23 @@ func extractFontCMaps(ctx *model.Context, pageDict types.Dict) map[string]CMap {
 		if err != nil {
 			continue
 		}
-		fontEntryDict, ok := fontEntry.(types.Dict)
-		if !ok {
-			continue
+		if d, ok := fontEntry.(types.Dict); ok {
+			fonts[fontName] = d
 		}
+	}
+	return fonts
+}
+
+// extractFontCMaps extracts the ToUnicode CMap of each font.
+func extractFontCMaps(ctx *model.Context, fonts map[string]types.Dict) map[string]CMap {
+	cmaps := make(map[string]CMap)
 
+	for fontName, fontEntryDict := range fonts {
 		tounicodeObj, found := fontEntryDict.Find("ToUnicode")
 		if !found {
 			continue
 		}
-		tounicodeObj, err = ctx.Dereference(tounicodeObj)
+		tounicodeObj, err := ctx.Dereference(tounicodeObj)
 		if err != nil {
 			continue
 		}
@@ -143,6 +231,122 @@ func extractFontCMaps(ctx *model.Context, pageDict types.Dict) map[string]CMap {
 	return cmaps
 }
 
+// extractFontMetrics extracts the glyph widths of each font: FirstChar and
+// Widths for a simple font, the descendant font's DW and W for a Type0
+// font. Fonts without widths, such as the standard 14, are left out, so
+// text in them has no known extent.
+func extractFontMetrics(ctx *model.Context, fonts map[string]types.Dict) map[string]FontMetrics {
+	metrics := make(map[string]FontMetrics)
+
+	for fontName, font := range fonts {
+		if sub := font.NameEntry("Subtype"); sub != nil && *sub == "Type0" {
+			descendants, ok := derefArray(ctx, font["DescendantFonts"])
+			if !ok || len(descendants) == 0 {
+				continue
+			}
+			obj, err := ctx.Dereference(descendants[0])
+			if err != nil {
+				continue
+			}
+			cid, ok := obj.(types.Dict)
+			if !ok {
+				continue
+			}
+			m := FontMetrics{Widths: make(map[int]float64), Default: 1000, Composite: true}
+			if dw, ok := derefNumber(ctx, cid["DW"]); ok {
+				m.Default = dw
+			}
+			if w, ok := derefArray(ctx, cid["W"]); ok {
+				readCIDWidths(ctx, w, m.Widths)
+			}
+			metrics[fontName] = m
+			continue
+		}
+
+		widths, ok := derefArray(ctx, font["Widths"])
+		if !ok {
+			continue
+		}
+		first, _ := derefNumber(ctx, font["FirstChar"])
+		m := FontMetrics{Widths: make(map[int]float64, len(widths))}
+		for i, w := range widths {
+			if v, ok := derefNumber(ctx, w); ok {
+				m.Widths[int(first)+i] = v
+			}
+		}
+		if obj, err := ctx.Dereference(font["FontDescriptor"]); err == nil {
+			if fd, ok := obj.(types.Dict); ok {
+				m.Default, _ = derefNumber(ctx, fd["MissingWidth"])
+			}
+		}
+		metrics[fontName] = m
+	}
+
+	return metrics
+}
+
+// readCIDWidths reads a CIDFont's W array, whose entries are either
+// "c [w1 w2 ...]", widths for consecutive codes from c, or "first last w",
+// one width for a range.
+func readCIDWidths(ctx *model.Context, w types.Array, widths map[int]float64) {
+	for i := 0; i+1 < len(w); {
+		c, ok := derefNumber(ctx, w[i])
+		if !ok {
+			return
+		}
+		if list, ok := derefArray(ctx, w[i+1]); ok {
+			for j, v := range list {
+				if n, ok := derefNumber(ctx, v); ok {
+					widths[int(c)+j] = n
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, ok1 := derefNumber(ctx, w[i+1])
+		n, ok2 := derefNumber(ctx, w[i+2])
+		if !ok1 || !ok2 || last-c > 0xFFFF {
+			return
+		}
+		for code := int(c); code <= int(last); code++ {
+			widths[code] = n
+		}
+		i += 3
+	}
+}
+
+func derefArray(ctx *model.Context, obj types.Object) (types.Array, bool) {
+	if obj == nil {
+		return nil, false
+	}
+	obj, err := ctx.Dereference(obj)
+	if err != nil {
+		return nil, false
+	}
+	a, ok := obj.(types.Array)
+	return a, ok
+}
+
+func derefNumber(ctx *model.Context, obj types.Object) (float64, bool) {
+	if obj == nil {
+		return 0, false
+	}
+	obj, err := ctx.Dereference(obj)
+	if err != nil {
+		return 0, false
+	}
+	switch v := obj.(type) {
+	case types.Integer:
+		return float64(v), true
+	case types.Float:
+		return float64(v), true
+	}
+	return 0, false
+}
+
 // resolveContentStream dereferences and decompresses a Contents entry, which
 // may be a single stream or an array of streams.
 func resolveContentStream(ctx *model.Context, obj types.Object) ([]byte, error) {
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// periodPattern matches a date range or row label as the reports print
+// them: "JULY 2022 - JUNE 2023", "Jul 2011- Jun 2012", "Jun 2023" or
+// "December 2024".
+var periodPattern = regexp.MustCompile(`(?i)^([a-z]+)\.?\s+(\d{4})(?:\s*-\s*([a-z]+)\.?\s+(\d{4}))?$`)
+
+// ParsePeriod parses a report date range or row label into the first day
+// of its first month and the last day of its last month. A single month,
+// as in the backlog rows' "Jun 2023", is both. ok is false for anything
+// else, such as "% Change", or a range that ends before it starts.
+func ParsePeriod(s string) (start, end time.Time, ok bool) {
+	m := periodPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	start, ok = parseMonth(m[1], m[2])
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	last := start
+	if m[3] != "" {
+		if last, ok = parseMonth(m[3], m[4]); !ok || last.Before(start) {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	return start, last.AddDate(0, 1, -1), true
+}
+
+// parseMonth returns the first day of the month named by name, in full or
+// abbreviated and in any case, in year.
+func parseMonth(name, year string) (time.Time, bool) {
+	name = strings.ToUpper(name[:1]) + strings.ToLower(name[1:])
+	if name == "Sept" {
+		name = "Sep"
+	}
+	for _, layout := range []string{"January 2006", "Jan 2006"} {
+		if t, err := time.Parse(layout, name+" "+year); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParsePeriods fills in the Start and End fields of s from its DateRange,
+// and those of each of its rows from the row's label. The parser and
+// ReadOutput call it; records built some other way can call it too.
+func (s *MunicipalityStats) ParsePeriods() {
+	s.Start, s.End, _ = ParsePeriod(s.DateRange)
+	for _, r := range s.rowPtrs() {
+		r.Start, r.End, _ = ParsePeriod(r.Label)
+	}
+}
+
+// rowPtrs returns pointers to the record's rows in page order, as Rows.
+func (s *MunicipalityStats) rowPtrs() []*RowData {
+	var rows []*RowData
+	for _, sec := range []*SectionWithChange{&s.Filings, &s.Resolutions} {
+		rows = append(rows, &sec.PriorPeriod, &sec.CurrentPeriod, &sec.PctChange)
+	}
+	for _, sec := range []*SectionTwoRow{&s.Clearance, &s.ClearancePct} {
+		rows = append(rows, &sec.PriorPeriod, &sec.CurrentPeriod)
+	}
+	for _, sec := range []*SectionWithChange{&s.Backlog, &s.BacklogPer100} {
+		rows = append(rows, &sec.PriorPeriod, &sec.CurrentPeriod, &sec.PctChange)
+	}
+	rows = append(rows, &s.BacklogPct.PriorPeriod, &s.BacklogPct.CurrentPeriod)
+	return append(rows, &s.ActivePending.PriorPeriod, &s.ActivePending.CurrentPeriod, &s.ActivePending.PctChange)
+}
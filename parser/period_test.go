@@ -0,0 +1,47 @@
+package parser
+
+import "testing"
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		in, start, end string
+	}{
+		{"JULY 2022 - JUNE 2023", "2022-07-01", "2023-06-30"},
+		{"JULY 2018 -  JUNE 2019", "2018-07-01", "2019-06-30"},
+		{"Jul 2011- Jun 2012", "2011-07-01", "2012-06-30"},
+		{"Jul 2022 - Dec 2022", "2022-07-01", "2022-12-31"},
+		{"Jun 2024", "2024-06-01", "2024-06-30"},
+		{"February 2024", "2024-02-01", "2024-02-29"},
+		{"Sept. 2020", "2020-09-01", "2020-09-30"},
+		{"% Change", "", ""},
+		{"", "", ""},
+		{"SMARCH 2024", "", ""},
+		{"JUNE 2024 - JULY 2023", "", ""},
+	}
+	for _, tt := range tests {
+		start, end, ok := ParsePeriod(tt.in)
+		if ok != (tt.start != "") {
+			t.Errorf("ParsePeriod(%q) ok = %v", tt.in, ok)
+			continue
+		}
+		if ok && (start.Format("2006-01-02") != tt.start || end.Format("2006-01-02") != tt.end) {
+			t.Errorf("ParsePeriod(%q) = %s, %s; want %s, %s", tt.in, start.Format("2006-01-02"), end.Format("2006-01-02"), tt.start, tt.end)
+		}
+	}
+}
+
+func TestParsePeriods(t *testing.T) {
+	s := MunicipalityStats{DateRange: "JULY 2022 - DECEMBER 2022"}
+	s.Backlog.CurrentPeriod.Label = "December 2022"
+	s.Backlog.PctChange.Label = "% Change"
+	s.ParsePeriods()
+	if s.End.Format("2006-01") != "2022-12" || s.Start.Format("2006-01") != "2022-07" {
+		t.Errorf("record period = %v – %v", s.Start, s.End)
+	}
+	if s.Backlog.CurrentPeriod.Start.Format("2006-01-02") != "2022-12-01" {
+		t.Errorf("backlog row starts %v", s.Backlog.CurrentPeriod.Start)
+	}
+	if !s.Backlog.PctChange.Start.IsZero() {
+		t.Errorf("%% Change row has a period")
+	}
+}
@@ -0,0 +1,301 @@
+package parser
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is the JSON Schema (draft 2020-12) for output files, in either
+// layout DecodeOutput reads. Files written by hand or by other tools that
+// pass ValidateSchema can be read like parsed ones.
+//
+//go:embed schema.json
+var Schema []byte
+
+// SchemaError is one place a document breaks the schema.
+type SchemaError struct {
+	Path    string // JSON pointer to the value, "" for the whole document
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// schemaRoot is Schema, decoded once.
+var schemaRoot = func() map[string]any {
+	var root map[string]any
+	if err := json.Unmarshal(Schema, &root); err != nil {
+		panic("parser: invalid schema.json: " + err.Error())
+	}
+	return root
+}()
+
+// ValidateSchema checks the JSON document data against Schema and returns
+// every violation: array elements in order, an object's properties by
+// name. err is set only if data isn't JSON.
+//
+// Only the keywords Schema uses are implemented: $ref to its own $defs,
+// type, enum, properties, required, additionalProperties, items, pattern,
+// minLength, minimum, maximum, format (date and date-time) and oneOf.
+func ValidateSchema(data []byte) ([]SchemaError, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := expectEOF(dec); err != nil {
+		return nil, err
+	}
+	v := validator{root: schemaRoot}
+	v.check(schemaRoot, doc, "")
+	return v.errs, nil
+}
+
+type validator struct {
+	root map[string]any
+	errs []SchemaError
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check validates value, found at path, against schema.
+func (v *validator) check(schema map[string]any, value any, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		v.check(v.resolve(ref), value, path)
+	}
+	if branches, ok := schema["oneOf"].([]any); ok {
+		v.checkOneOf(branches, value, path)
+	}
+	if t, ok := schema["type"]; ok && !typeMatches(t, value) {
+		v.fail(path, "expected %s, got %s", typeNames(t), jsonType(value))
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				found = true
+			}
+		}
+		if !found {
+			v.fail(path, "%s is not one of %s", describe(value), typeNames(enum))
+		}
+	}
+
+	switch x := value.(type) {
+	case map[string]any:
+		v.checkObject(schema, x, path)
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range x {
+				v.check(items, item, path+"/"+strconv.Itoa(i))
+			}
+		}
+	case string:
+		if n, ok := schema["minLength"].(float64); ok && float64(utf8.RuneCountInString(x)) < n {
+			v.fail(path, "%q is shorter than %v characters", x, n)
+		}
+		if p, ok := schema["pattern"].(string); ok && !schemaPattern(p).MatchString(x) {
+			v.fail(path, "%q does not match the pattern %s", x, p)
+		}
+		if f, ok := schema["format"].(string); ok && !formatMatches(f, x) {
+			v.fail(path, "%q is not a valid %s", x, f)
+		}
+	case json.Number:
+		n, _ := x.Float64()
+		if lo, ok := schema["minimum"].(float64); ok && n < lo {
+			v.fail(path, "%s is less than the minimum %v", x, lo)
+		}
+		if hi, ok := schema["maximum"].(float64); ok && n > hi {
+			v.fail(path, "%s is greater than the maximum %v", x, hi)
+		}
+	}
+}
+
+func (v *validator) checkObject(schema map[string]any, obj map[string]any, path string) {
+	props, _ := schema["properties"].(map[string]any)
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			if _, ok := obj[r.(string)]; !ok {
+				v.fail(path, "missing required property %q", r)
+			}
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		at := path + "/" + pointerEscaper.Replace(k)
+		if p, ok := props[k].(map[string]any); ok {
+			v.check(p, obj[k], at)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.fail(at, "unknown property %q", k)
+			}
+		case map[string]any:
+			v.check(extra, obj[k], at)
+		}
+	}
+}
+
+// checkOneOf requires value to match exactly one of branches. Where it
+// matches none, the errors reported are those against the first branch of
+// value's type, which is the one that was meant.
+func (v *validator) checkOneOf(branches []any, value any, path string) {
+	var first []SchemaError
+	matched := 0
+	for _, b := range branches {
+		sub := validator{root: v.root}
+		schema := b.(map[string]any)
+		sub.check(schema, value, path)
+		if len(sub.errs) == 0 {
+			matched++
+			continue
+		}
+		if first == nil && typeMatches(v.branchType(schema), value) {
+			first = sub.errs
+		}
+	}
+	switch {
+	case matched == 1:
+	case matched > 1:
+		v.fail(path, "matches more than one of the allowed forms")
+	case first != nil:
+		v.errs = append(v.errs, first...)
+	default:
+		v.fail(path, "unexpected %s", jsonType(value))
+	}
+}
+
+// branchType returns the type keyword of schema, following a $ref.
+func (v *validator) branchType(schema map[string]any) any {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = v.resolve(ref)
+	}
+	return schema["type"]
+}
+
+func (v *validator) resolve(ref string) map[string]any {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	defs, _ := v.root["$defs"].(map[string]any)
+	def, _ := defs[name].(map[string]any)
+	if !ok || def == nil {
+		panic("parser: schema.json: unresolvable $ref " + ref)
+	}
+	return def
+}
+
+var (
+	patternMu sync.Mutex
+	patterns  = make(map[string]*regexp.Regexp)
+)
+
+// schemaPattern compiles a pattern from the schema, once.
+func schemaPattern(p string) *regexp.Regexp {
+	patternMu.Lock()
+	defer patternMu.Unlock()
+	re, ok := patterns[p]
+	if !ok {
+		re = regexp.MustCompile(p)
+		patterns[p] = re
+	}
+	return re
+}
+
+func formatMatches(format, s string) bool {
+	var err error
+	switch format {
+	case "date":
+		_, err = time.Parse("2006-01-02", s)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339Nano, s)
+	}
+	return err == nil
+}
+
+// typeMatches reports whether value is of the type, or one of the types,
+// named by t. A missing type matches anything.
+func typeMatches(t any, value any) bool {
+	switch t := t.(type) {
+	case string:
+		got := jsonType(value)
+		if t == "number" && got == "integer" {
+			return true
+		}
+		return got == t
+	case []any:
+		for _, u := range t {
+			if typeMatches(u, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// jsonType names value's JSON type as the schema's type keyword does.
+func jsonType(value any) string {
+	switch x := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := x.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+// typeNames formats a type keyword or enum for a message.
+func typeNames(t any) string {
+	list, ok := t.([]any)
+	if !ok {
+		return fmt.Sprint(t)
+	}
+	names := make([]string, len(list))
+	for i, n := range list {
+		names[i] = fmt.Sprint(n)
+	}
+	return strings.Join(names, " or ")
+}
+
+// describe formats a value for a message.
+func describe(value any) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return jsonType(value)
+}
+
+// pointerEscaper escapes a property name for a JSON pointer (RFC 6901).
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSchemaMatchesModel keeps schema.json in step with the JSON the model
+// types write: each definition lists exactly the struct's fields.
+func TestSchemaMatchesModel(t *testing.T) {
+	defs := schemaRoot["$defs"].(map[string]any)
+	for def, typ := range map[string]reflect.Type{
+		"output":            reflect.TypeOf(Output{}),
+		"provenance":        reflect.TypeOf(Provenance{}),
+		"record":            reflect.TypeOf(MunicipalityStats{}),
+		"sectionWithChange": reflect.TypeOf(SectionWithChange{}),
+		"sectionTwoRow":     reflect.TypeOf(SectionTwoRow{}),
+		"row":               reflect.TypeOf(RowData{}),
+	} {
+		var fields []string
+		for i := range typ.NumField() {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if name != "" && name != "-" {
+				fields = append(fields, name)
+			}
+		}
+		var props []string
+		for p := range defs[def].(map[string]any)["properties"].(map[string]any) {
+			props = append(props, p)
+		}
+		sort.Strings(fields)
+		sort.Strings(props)
+		if !reflect.DeepEqual(fields, props) {
+			t.Errorf("%s: schema properties %v, struct fields %v", def, props, fields)
+		}
+	}
+
+	version := defs["output"].(map[string]any)["properties"].(map[string]any)["schemaVersion"].(map[string]any)
+	if version["maximum"] != float64(SchemaVersion) {
+		t.Errorf("schema allows versions up to %v, want %d", version["maximum"], SchemaVersion)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	page, err := os.ReadFile("testdata/page.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := DecodeOutput(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.SchemaVersion = SchemaVersion
+	out.Provenance = &Provenance{SourceFile: "page.pdf", ParsedAt: time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC), Version: "dev", Generated: "2025-06-30"}
+	out.Records[0].Filings.CurrentPeriod.Recovered[5] = RecoveryKerning
+	current, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, doc := range map[string][]byte{"bare array": page, "current": current} {
+		errs, err := ValidateSchema(doc)
+		if err != nil || len(errs) != 0 {
+			t.Errorf("%s: %v, %v", name, errs, err)
+		}
+	}
+
+	bad := `{"schemaVersion": 2, "provenance": {"sourceFile": "x.pdf", "sha256": "", "parsedAt": "yesterday", "municourtVersion": "dev"},
+		"records": [{"county": "ATLANTIC", "municipality": "ABSECON", "dateRange": "",
+			"filings": {"priorPeriod": {}, "currentPeriod": {}, "pctChange": {}},
+			"resolutions": {}, "clearance": {}, "clearancePercent": {}, "backlog": {}, "backlogPer100MthlyFilings": {}, "backlogPercent": {}, "activePending": {},
+			"grandtotal": "1"}]}`
+	errs, err := ValidateSchema([]byte(bad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		`/provenance/parsedAt: "yesterday" is not a valid date-time`:          true,
+		`/records/0/grandtotal: unknown property "grandtotal"`:                true,
+		`/records/0/filings/currentPeriod: missing required property "label"`: true,
+		`/records/0/resolutions: missing required property "priorPeriod"`:     true,
+	}
+	got := make(map[string]bool)
+	for _, e := range errs {
+		got[e.Error()] = true
+	}
+	for w := range want {
+		if !got[w] {
+			t.Errorf("missing error %s; got %v", w, errs)
+		}
+	}
+
+	errs, _ = ValidateSchema([]byte(`[{"county": "ATLANTIC", "municipality": "ABSECON", "dateRange": "", "pageNumber": 0}]`))
+	if len(errs) == 0 || errs[len(errs)-1].Error() != "/0/pageNumber: 0 is less than the minimum 1" {
+		t.Errorf("bare array errors = %v", errs)
+	}
+	if _, err := ValidateSchema([]byte(`{"schemaVersion": 2,`)); err == nil {
+		t.Error("truncated JSON validated")
+	}
+}